@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
 )
 
 func GetString(key string, defaultValue string) string {
@@ -28,3 +29,14 @@ func GetInt(key string, defaultValue int) int {
 	}
 	return defaultValue
 }
+
+// GetList reads a comma-separated list, dropping blank entries.
+func GetList(key string, defaultValue string) []string {
+	var result []string
+	for _, item := range strings.Split(GetString(key, defaultValue), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
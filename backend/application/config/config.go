@@ -9,8 +9,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
-	"golang.org/x/oauth2"
-	"golang.org/x/oauth2/google"
+	"github.com/pdhoang91/blog/pkg/oauth"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
@@ -18,15 +17,40 @@ import (
 
 // Config represents the application configuration.
 type Config struct {
+	APP_ENV        string
 	APP_PORT       string
 	PGURL          string
 	MAX_OPEN_CONNS int
 	MAX_IDLE_CONNS int
+	// Proxies whose X-Forwarded-For is trusted when resolving client IPs
+	TRUSTED_PROXIES []string
+
+	// JWT Configuration
+	JWT_ALGORITHM  string
+	JWT_SECRET     string
+	JWT_KEYS_DIR   string
+	JWT_ACTIVE_KID string
 
 	// OAuth Configuration
 	GOOGLE_CLIENT_ID     string
 	GOOGLE_CLIENT_SECRET string
 	GOOGLE_REDIRECT_URL  string
+	// Endpoint overrides, e.g. to point tests at a local fake provider
+	GOOGLE_AUTH_URL     string
+	GOOGLE_TOKEN_URL    string
+	GOOGLE_USERINFO_URL string
+
+	// Sign-in providers, see loadOAuthProviders
+	OAUTH_PROVIDERS []oauth.Config
+
+	// Mail Configuration
+	MAILER          string
+	MAIL_FROM       string
+	MAIL_OUTBOX_DIR string
+	SMTP_HOST       string
+	SMTP_PORT       int
+	SMTP_USERNAME   string
+	SMTP_PASSWORD   string
 
 	// AWS Configuration
 	AWS_REGION            string
@@ -39,21 +63,38 @@ type Config struct {
 
 // Deprecated: kept for backward compatibility. Use Config fields instead.
 var (
-	GoogleOauthConfig *oauth2.Config
-	S3Client          *s3.Client
+	S3Client *s3.Client
 )
 
 // NewConfig returns an initialized Config based on environment variables.
 func NewConfig() *Config {
 	cfg := &Config{
-		APP_PORT:       GetString("PORT", "81"),
-		MAX_OPEN_CONNS: GetInt("MAX_OPEN_CONNS", 25),
-		MAX_IDLE_CONNS: GetInt("MAX_IDLE_CONNS", 20),
-		PGURL:          buildDatabaseURL(),
+		APP_ENV:         GetString("APP_ENV", "development"),
+		APP_PORT:        GetString("PORT", "81"),
+		MAX_OPEN_CONNS:  GetInt("MAX_OPEN_CONNS", 25),
+		MAX_IDLE_CONNS:  GetInt("MAX_IDLE_CONNS", 20),
+		PGURL:           buildDatabaseURL(),
+		TRUSTED_PROXIES: GetList("TRUSTED_PROXIES", "127.0.0.1,::1,10.0.0.0/8,172.16.0.0/12,192.168.0.0/16"),
+
+		JWT_ALGORITHM:  GetString("JWT_ALGORITHM", "HS256"),
+		JWT_SECRET:     GetString("JWT_SECRET", ""),
+		JWT_KEYS_DIR:   GetString("JWT_KEYS_DIR", ""),
+		JWT_ACTIVE_KID: GetString("JWT_ACTIVE_KID", ""),
 
 		GOOGLE_CLIENT_ID:     GetString("GOOGLE_CLIENT_ID", ""),
 		GOOGLE_CLIENT_SECRET: GetString("GOOGLE_CLIENT_SECRET", ""),
 		GOOGLE_REDIRECT_URL:  GetString("GOOGLE_REDIRECT_URL", ""),
+		GOOGLE_AUTH_URL:      GetString("GOOGLE_AUTH_URL", ""),
+		GOOGLE_TOKEN_URL:     GetString("GOOGLE_TOKEN_URL", ""),
+		GOOGLE_USERINFO_URL:  GetString("GOOGLE_USERINFO_URL", ""),
+
+		MAILER:          GetString("MAILER", "outbox"),
+		MAIL_FROM:       GetString("MAIL_FROM", "Insight <no-reply@localhost>"),
+		MAIL_OUTBOX_DIR: GetString("MAIL_OUTBOX_DIR", "./outbox"),
+		SMTP_HOST:       GetString("SMTP_HOST", "localhost"),
+		SMTP_PORT:       GetInt("SMTP_PORT", 587),
+		SMTP_USERNAME:   GetString("SMTP_USERNAME", ""),
+		SMTP_PASSWORD:   GetString("SMTP_PASSWORD", ""),
 
 		AWS_REGION:            GetString("AWS_REGION", ""),
 		AWS_ACCESS_KEY_ID:     GetString("AWS_ACCESS_KEY_ID", ""),
@@ -63,13 +104,23 @@ func NewConfig() *Config {
 		AWS_CDN_DOMAIN:        GetString("AWS_CDN_DOMAIN", ""),
 	}
 
-	// Initialize OAuth and S3 clients
-	cfg.initOAuthConfig()
+	// Load sign-in providers and initialize the S3 client
+	cfg.OAUTH_PROVIDERS = cfg.loadOAuthProviders()
 	cfg.initS3Client()
 
 	return cfg
 }
 
+// IsDevelopment reports whether APP_ENV selects a local development setup,
+// where insecure defaults such as the built-in JWT secret are tolerated.
+func (cfg *Config) IsDevelopment() bool {
+	switch cfg.APP_ENV {
+	case "development", "dev", "local":
+		return true
+	}
+	return false
+}
+
 // buildDatabaseURL constructs the database URL from individual environment variables
 func buildDatabaseURL() string {
 	// First try to get DATABASE_URL directly
@@ -142,18 +193,6 @@ func CloseDBConnection(db *gorm.DB) error {
 	return database.Close()
 }
 
-func (cfg *Config) initOAuthConfig() {
-	if cfg.GOOGLE_CLIENT_ID != "" && cfg.GOOGLE_CLIENT_SECRET != "" {
-		GoogleOauthConfig = &oauth2.Config{
-			RedirectURL:  cfg.GOOGLE_REDIRECT_URL,
-			ClientID:     cfg.GOOGLE_CLIENT_ID,
-			ClientSecret: cfg.GOOGLE_CLIENT_SECRET,
-			Scopes:       []string{"email", "profile"},
-			Endpoint:     google.Endpoint,
-		}
-	}
-}
-
 func (cfg *Config) initS3Client() {
 	if cfg.AWS_REGION == "" || cfg.AWS_ACCESS_KEY_ID == "" || cfg.AWS_SECRET_ACCESS_KEY == "" {
 		log.Printf("Warning: Missing AWS credentials, some features may not work")
@@ -180,11 +219,6 @@ func (cfg *Config) initS3Client() {
 	S3Client = s3.NewFromConfig(awsCfg)
 }
 
-// Get returns the OAuth configuration. Returns nil if OAuth was not configured.
-func Get() *oauth2.Config {
-	return GoogleOauthConfig
-}
-
 // GetS3Config returns S3 configuration values
 func GetS3Config() (bucket, region, cdnDomain string) {
 	bucket = GetString("AWS_S3_BUCKET", "insight.storage")
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"strings"
+
+	"github.com/pdhoang91/blog/pkg/oauth"
+)
+
+// loadOAuthProviders reads sign-in providers from the environment.
+//
+// OAUTH_PROVIDERS lists provider names (e.g. "google,github,corp"); each is
+// configured through OAUTH_<NAME>_* variables: CLIENT_ID, CLIENT_SECRET,
+// REDIRECT_URL, TYPE (google, github, gitlab or oidc; defaults to the name
+// when it is a known type, otherwise oidc), DISPLAY_NAME, SCOPES, ISSUER,
+// BASE_URL, AUTH_URL, TOKEN_URL and USERINFO_URL.
+//
+// The legacy GOOGLE_* variables still register "google" when it is not listed.
+func (cfg *Config) loadOAuthProviders() []oauth.Config {
+	var providers []oauth.Config
+	seen := make(map[string]bool)
+
+	for _, name := range strings.Split(GetString("OAUTH_PROVIDERS", ""), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		prefix := "OAUTH_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+		providerType := GetString(prefix+"TYPE", "")
+		if providerType == "" {
+			switch name {
+			case oauth.TypeGoogle, oauth.TypeGitHub, oauth.TypeGitLab:
+				providerType = name
+			default:
+				providerType = oauth.TypeOIDC
+			}
+		}
+
+		var scopes []string
+		for _, scope := range strings.Split(GetString(prefix+"SCOPES", ""), ",") {
+			if scope = strings.TrimSpace(scope); scope != "" {
+				scopes = append(scopes, scope)
+			}
+		}
+
+		providers = append(providers, oauth.Config{
+			Name:         name,
+			Type:         providerType,
+			DisplayName:  GetString(prefix+"DISPLAY_NAME", ""),
+			ClientID:     GetString(prefix+"CLIENT_ID", ""),
+			ClientSecret: GetString(prefix+"CLIENT_SECRET", ""),
+			RedirectURL:  GetString(prefix+"REDIRECT_URL", ""),
+			Scopes:       scopes,
+			Issuer:       GetString(prefix+"ISSUER", ""),
+			BaseURL:      GetString(prefix+"BASE_URL", ""),
+			AuthURL:      GetString(prefix+"AUTH_URL", ""),
+			TokenURL:     GetString(prefix+"TOKEN_URL", ""),
+			UserInfoURL:  GetString(prefix+"USERINFO_URL", ""),
+		})
+	}
+
+	if !seen[oauth.TypeGoogle] && cfg.GOOGLE_CLIENT_ID != "" && cfg.GOOGLE_CLIENT_SECRET != "" {
+		providers = append(providers, oauth.Config{
+			Name:         oauth.TypeGoogle,
+			Type:         oauth.TypeGoogle,
+			ClientID:     cfg.GOOGLE_CLIENT_ID,
+			ClientSecret: cfg.GOOGLE_CLIENT_SECRET,
+			RedirectURL:  cfg.GOOGLE_REDIRECT_URL,
+			AuthURL:      cfg.GOOGLE_AUTH_URL,
+			TokenURL:     cfg.GOOGLE_TOKEN_URL,
+			UserInfoURL:  cfg.GOOGLE_USERINFO_URL,
+		})
+	}
+	return providers
+}
@@ -0,0 +1,44 @@
+// constants/permissions.go
+package constants
+
+// Permission is a capability granted to one or more roles.
+type Permission string
+
+const (
+	// PermPublish allows creating, editing and publishing one's own posts.
+	PermPublish Permission = "publish"
+	// PermReviewPosts allows approving posts submitted for review, or sending
+	// them back with requested changes. Holders may also publish without review.
+	PermReviewPosts Permission = "review_posts"
+	// PermEditAnyPost allows editing and deleting posts by other authors.
+	PermEditAnyPost Permission = "edit_any_post"
+	// PermModerateComments allows deleting comments and replies by other users.
+	PermModerateComments Permission = "moderate_comments"
+	// PermManageTaxonomy allows managing categories and tags.
+	PermManageTaxonomy Permission = "manage_taxonomy"
+	// PermManageUsers allows changing roles and security policies.
+	PermManageUsers Permission = "manage_users"
+)
+
+var rolePermissions = map[UserRole][]Permission{
+	RoleAdmin:     {PermPublish, PermReviewPosts, PermEditAnyPost, PermModerateComments, PermManageTaxonomy, PermManageUsers},
+	RoleEditor:    {PermPublish, PermReviewPosts, PermEditAnyPost, PermModerateComments, PermManageTaxonomy},
+	RoleModerator: {PermModerateComments},
+	RoleAuthor:    {PermPublish},
+	RoleReader:    {},
+}
+
+// RolePermissions returns the permissions granted to role.
+func RolePermissions(role UserRole) []Permission {
+	return rolePermissions[NormalizeRole(role)]
+}
+
+// HasPermission reports whether role grants perm.
+func HasPermission(role UserRole, perm Permission) bool {
+	for _, p := range RolePermissions(role) {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
@@ -4,22 +4,41 @@ package constants
 // UserRole represents the type for user roles
 type UserRole string
 
-// Simple user role constants - only 2 roles
+// User roles, from most to least privileged. What each role may do is
+// defined in permissions.go.
 const (
-	RoleUser  UserRole = "user"
-	RoleAdmin UserRole = "admin"
+	RoleAdmin     UserRole = "admin"
+	RoleEditor    UserRole = "editor"
+	RoleModerator UserRole = "moderator"
+	RoleAuthor    UserRole = "author"
+	RoleReader    UserRole = "reader"
+
+	// RoleUser is the role every account had before roles were split up.
+	// Migration 011 renames it to author; NormalizeRole maps any leftovers.
+	RoleUser UserRole = "user"
 )
 
-// Simple helper functions
+// AllRoles lists the assignable roles, most privileged first.
+func AllRoles() []UserRole {
+	return []UserRole{RoleAdmin, RoleEditor, RoleModerator, RoleAuthor, RoleReader}
+}
+
+// NormalizeRole maps legacy role names to their current equivalent.
+func NormalizeRole(role UserRole) UserRole {
+	if role == RoleUser {
+		return RoleAuthor
+	}
+	return role
+}
 
-// CanWritePosts checks if a role can write posts (admin only)
+// CanWritePosts checks if a role can create and publish its own posts
 func CanWritePosts(role UserRole) bool {
-	return role == RoleAdmin
+	return HasPermission(role, PermPublish)
 }
 
-// CanViewAllProfiles checks if a role can view all profiles (admin only)
+// CanViewAllProfiles checks if a role can view all profiles
 func CanViewAllProfiles(role UserRole) bool {
-	return role == RoleAdmin
+	return HasPermission(role, PermManageUsers)
 }
 
 // HasAdminAccess checks if a role has admin access (admin only)
@@ -32,34 +51,33 @@ func IsAdmin(role UserRole) bool {
 	return role == RoleAdmin
 }
 
-// IsUser checks if role is user
-func IsUser(role UserRole) bool {
-	return role == RoleUser
-}
-
-// IsValidRole checks if a role is valid
+// IsValidRole checks if a role can be assigned
 func IsValidRole(role string) bool {
-	switch UserRole(role) {
-	case RoleUser, RoleAdmin:
-		return true
-	default:
-		return false
+	for _, r := range AllRoles() {
+		if UserRole(role) == r {
+			return true
+		}
 	}
+	return false
 }
 
 // GetDefaultRole returns the default role for new users
 func GetDefaultRole() UserRole {
-	return RoleUser
+	return RoleAuthor
 }
 
 // GetRoleDisplayName returns a human-readable role name
 func GetRoleDisplayName(role UserRole) string {
-	switch role {
+	switch NormalizeRole(role) {
 	case RoleAdmin:
 		return "Admin"
-	case RoleUser:
-		return "User"
+	case RoleEditor:
+		return "Editor"
+	case RoleModerator:
+		return "Moderator"
+	case RoleReader:
+		return "Reader"
 	default:
-		return "User"
+		return "Author"
 	}
 }
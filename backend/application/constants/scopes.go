@@ -0,0 +1,30 @@
+// constants/scopes.go
+package constants
+
+// API token scopes
+const (
+	ScopeRead        = "read"
+	ScopePostsWrite  = "posts:write"
+	ScopeImagesWrite = "images:write"
+)
+
+// IsValidScope checks if a scope can be granted to an API token
+func IsValidScope(scope string) bool {
+	switch scope {
+	case ScopeRead, ScopePostsWrite, ScopeImagesWrite:
+		return true
+	default:
+		return false
+	}
+}
+
+// ScopeSatisfies reports whether the granted scopes allow an action needing want.
+// Every scope includes read access.
+func ScopeSatisfies(granted []string, want string) bool {
+	for _, scope := range granted {
+		if scope == want || want == ScopeRead {
+			return true
+		}
+	}
+	return false
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"time"
 )
 
 // AppError represents a domain-level error with HTTP status mapping
@@ -11,6 +12,11 @@ type AppError struct {
 	Code    int
 	Message string
 	Err     error
+	// RetryAfter is sent as the Retry-After header on 429 responses.
+	RetryAfter time.Duration
+	// Details is returned to the client alongside the message, e.g. the
+	// current server state on a version conflict.
+	Details interface{}
 }
 
 func (e *AppError) Error() string {
@@ -30,6 +36,12 @@ func NewBadRequest(msg string) *AppError {
 	return &AppError{Code: http.StatusBadRequest, Message: msg}
 }
 
+// NewInvalid reports a request that is well-formed but breaks a rule; details
+// says where, e.g. the path of a rejected node in a document.
+func NewInvalid(msg string, details interface{}) *AppError {
+	return &AppError{Code: http.StatusBadRequest, Message: msg, Details: details}
+}
+
 func NewUnauthorized(msg string) *AppError {
 	return &AppError{Code: http.StatusUnauthorized, Message: msg}
 }
@@ -42,6 +54,20 @@ func NewConflict(msg string) *AppError {
 	return &AppError{Code: http.StatusConflict, Message: msg}
 }
 
+// NewVersionConflict reports that the client edited a stale version. details
+// carries the current server state so the client can merge.
+func NewVersionConflict(msg string, details interface{}) *AppError {
+	return &AppError{Code: http.StatusConflict, Message: msg, Details: details}
+}
+
+func NewPreconditionRequired(msg string) *AppError {
+	return &AppError{Code: http.StatusPreconditionRequired, Message: msg}
+}
+
+func NewTooManyRequests(msg string, retryAfter time.Duration) *AppError {
+	return &AppError{Code: http.StatusTooManyRequests, Message: msg, RetryAfter: retryAfter}
+}
+
 func NewInternal(msg string, err error) *AppError {
 	return &AppError{Code: http.StatusInternalServerError, Message: msg, Err: err}
 }
@@ -50,7 +76,7 @@ func NewInternal(msg string, err error) *AppError {
 func Wrap(msg string, err error) *AppError {
 	var appErr *AppError
 	if errors.As(err, &appErr) {
-		return &AppError{Code: appErr.Code, Message: fmt.Sprintf("%s: %s", msg, appErr.Message), Err: appErr.Err}
+		return &AppError{Code: appErr.Code, Message: fmt.Sprintf("%s: %s", msg, appErr.Message), Err: appErr.Err, RetryAfter: appErr.RetryAfter, Details: appErr.Details}
 	}
 	return NewInternal(msg, err)
 }
@@ -64,6 +90,24 @@ func HTTPCode(err error) int {
 	return http.StatusInternalServerError
 }
 
+// RetryAfter extracts how long the client should wait before retrying; zero if unknown
+func RetryAfter(err error) time.Duration {
+	var appErr *AppError
+	if errors.As(err, &appErr) {
+		return appErr.RetryAfter
+	}
+	return 0
+}
+
+// Details extracts the client-facing details of err; nil if there are none
+func Details(err error) interface{} {
+	var appErr *AppError
+	if errors.As(err, &appErr) {
+		return appErr.Details
+	}
+	return nil
+}
+
 // UserMessage extracts user-safe message from error
 func UserMessage(err error) string {
 	var appErr *AppError
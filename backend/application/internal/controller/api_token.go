@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pdhoang91/blog/internal/dto"
+	"github.com/pdhoang91/blog/internal/service"
+	uuid "github.com/satori/go.uuid"
+)
+
+type APITokenController struct {
+	svc service.APITokenService
+}
+
+func (c *APITokenController) ListAPITokens(ctx *gin.Context) {
+	userID, ok := requireUserID(ctx)
+	if !ok {
+		return
+	}
+
+	tokens, err := c.svc.ListAPITokens(userID)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+	respondOK(ctx, tokens)
+}
+
+// CreateAPIToken returns the plaintext token; it cannot be retrieved again.
+func (c *APITokenController) CreateAPIToken(ctx *gin.Context) {
+	userID, ok := requireUserID(ctx)
+	if !ok {
+		return
+	}
+
+	var req dto.CreateAPITokenRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	token, err := c.svc.CreateAPIToken(userID, &req)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+	respondCreated(ctx, token)
+}
+
+func (c *APITokenController) RevokeAPIToken(ctx *gin.Context) {
+	userID, ok := requireUserID(ctx)
+	if !ok {
+		return
+	}
+
+	id, err := uuid.FromString(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid token ID"})
+		return
+	}
+
+	if err := c.svc.RevokeAPIToken(userID, id); err != nil {
+		respondError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "API token revoked"})
+}
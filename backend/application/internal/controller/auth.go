@@ -1,6 +1,7 @@
 package controller
 
 import (
+	"crypto/subtle"
 	"fmt"
 	"net/http"
 	"os"
@@ -8,6 +9,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/pdhoang91/blog/internal/dto"
 	"github.com/pdhoang91/blog/internal/service"
+	pkgjwt "github.com/pdhoang91/blog/pkg/jwt"
 )
 
 type AuthController struct {
@@ -21,7 +23,7 @@ func (c *AuthController) Register(ctx *gin.Context) {
 		return
 	}
 
-	response, err := c.svc.Register(&req)
+	response, err := c.svc.Register(&req, clientInfo(ctx))
 	if err != nil {
 		respondError(ctx, err)
 		return
@@ -36,7 +38,7 @@ func (c *AuthController) Login(ctx *gin.Context) {
 		return
 	}
 
-	response, err := c.svc.Login(&req)
+	response, err := c.svc.Login(&req, clientInfo(ctx))
 	if err != nil {
 		respondError(ctx, err)
 		return
@@ -44,37 +46,212 @@ func (c *AuthController) Login(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, response)
 }
 
-func (c *AuthController) GoogleLogin(ctx *gin.Context) {
-	url, err := c.svc.GoogleLogin()
+// VerifyMFA completes a login that returned mfa_required.
+func (c *AuthController) VerifyMFA(ctx *gin.Context) {
+	var req dto.VerifyMFARequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	response, err := c.svc.VerifyMFA(&req, clientInfo(ctx))
 	if err != nil {
 		respondError(ctx, err)
 		return
 	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+// oauthStateCookie binds an authorization request to the browser that started it,
+// so a callback URL cannot be replayed in another browser (login CSRF).
+const (
+	oauthStateCookie       = "oauth_state"
+	oauthStateCookieMaxAge = 10 * 60
+)
+
+func isSecureRequest(ctx *gin.Context) bool {
+	return ctx.Request.TLS != nil || ctx.GetHeader("X-Forwarded-Proto") == "https"
+}
+
+func setOAuthStateCookie(ctx *gin.Context, value string, maxAge int) {
+	ctx.SetSameSite(http.SameSiteLaxMode)
+	ctx.SetCookie(oauthStateCookie, value, maxAge, "/auth", "", isSecureRequest(ctx), true)
+}
+
+func (c *AuthController) ListProviders(ctx *gin.Context) {
+	respondOK(ctx, c.svc.ListOAuthProviders())
+}
+
+func (c *AuthController) OAuthLogin(ctx *gin.Context) {
+	url, state, err := c.svc.OAuthLogin(ctx.Param("provider"), ctx.Query("link"))
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+	setOAuthStateCookie(ctx, state, oauthStateCookieMaxAge)
 	ctx.Redirect(http.StatusTemporaryRedirect, url)
 }
 
-func (c *AuthController) GoogleCallback(ctx *gin.Context) {
+func (c *AuthController) OAuthCallback(ctx *gin.Context) {
 	code := ctx.Query("code")
 	if code == "" {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Code not provided"})
 		return
 	}
 
-	response, err := c.svc.GoogleCallback(code)
+	state := ctx.Query("state")
+	cookieState, err := ctx.Cookie(oauthStateCookie)
+	setOAuthStateCookie(ctx, "", -1)
+	if err != nil || state == "" || subtle.ConstantTimeCompare([]byte(state), []byte(cookieState)) != 1 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid OAuth state"})
+		return
+	}
+
+	result, err := c.svc.OAuthCallback(ctx.Param("provider"), code, state, clientInfo(ctx))
 	if err != nil {
 		respondError(ctx, err)
 		return
 	}
 
 	baseFeURL := os.Getenv("BASE_FE_URL")
-	frontendURL := fmt.Sprintf("%s/#token=%s", baseFeURL, response.Token)
+	if result.LinkedProvider != "" {
+		ctx.Redirect(http.StatusTemporaryRedirect, fmt.Sprintf("%s/?linked=%s", baseFeURL, result.LinkedProvider))
+		return
+	}
+	if result.Login.MFARequired {
+		ctx.Redirect(http.StatusTemporaryRedirect, fmt.Sprintf("%s/#mfa_token=%s", baseFeURL, result.Login.MFAToken))
+		return
+	}
+	frontendURL := fmt.Sprintf("%s/#token=%s&refresh_token=%s", baseFeURL, result.Login.Token, result.Login.RefreshToken)
 	ctx.Redirect(http.StatusTemporaryRedirect, frontendURL)
 }
 
+func (c *AuthController) ListIdentities(ctx *gin.Context) {
+	userID, ok := requireUserID(ctx)
+	if !ok {
+		return
+	}
+
+	response, err := c.svc.ListIdentities(userID)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+	respondOK(ctx, response)
+}
+
+// LinkIdentity returns the URL the browser should navigate to in order to link the provider.
+func (c *AuthController) LinkIdentity(ctx *gin.Context) {
+	userID, ok := requireUserID(ctx)
+	if !ok {
+		return
+	}
+	url, err := c.svc.StartIdentityLink(userID, ctx.Param("provider"))
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+	respondOK(ctx, gin.H{"url": url})
+}
+
+func (c *AuthController) UnlinkIdentity(ctx *gin.Context) {
+	userID, ok := requireUserID(ctx)
+	if !ok {
+		return
+	}
+
+	if err := c.svc.UnlinkIdentity(userID, ctx.Param("provider")); err != nil {
+		respondError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Identity unlinked"})
+}
+
 func (c *AuthController) Logout(ctx *gin.Context) {
+	sessionID, ok := requireSessionID(ctx)
+	if !ok {
+		return
+	}
+
+	if err := c.svc.Logout(sessionID); err != nil {
+		respondError(ctx, err)
+		return
+	}
 	ctx.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
 }
 
 func (c *AuthController) RefreshToken(ctx *gin.Context) {
-	ctx.JSON(http.StatusNotImplemented, gin.H{"error": "Not implemented"})
+	var req dto.RefreshTokenRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	response, err := c.svc.RefreshToken(req.RefreshToken, clientInfo(ctx))
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (c *AuthController) VerifyEmail(ctx *gin.Context) {
+	var req dto.VerifyEmailRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	if err := c.svc.VerifyEmail(req.Token); err != nil {
+		respondError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Email verified"})
+}
+
+func (c *AuthController) ResendVerification(ctx *gin.Context) {
+	userID, ok := requireUserID(ctx)
+	if !ok {
+		return
+	}
+
+	if err := c.svc.ResendVerification(userID); err != nil {
+		respondError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Verification email sent"})
+}
+
+func (c *AuthController) ForgotPassword(ctx *gin.Context) {
+	var req dto.ForgotPasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	if err := c.svc.ForgotPassword(req.Email); err != nil {
+		respondError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "If the email is registered, a reset link has been sent"})
+}
+
+func (c *AuthController) ResetPassword(ctx *gin.Context) {
+	var req dto.ResetPasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	if err := c.svc.ResetPassword(req.Token, req.Password); err != nil {
+		respondError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Password has been reset"})
+}
+
+// JWKS publishes the public keys that verify access tokens.
+func (c *AuthController) JWKS(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "public, max-age=300")
+	ctx.JSON(http.StatusOK, gin.H{"keys": pkgjwt.JWKS()})
 }
@@ -0,0 +1,82 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pdhoang91/blog/internal/dto"
+	"github.com/pdhoang91/blog/internal/service"
+	uuid "github.com/satori/go.uuid"
+)
+
+type AutosaveController struct {
+	svc service.AutosaveService
+}
+
+func (c *AutosaveController) GetAutosave(ctx *gin.Context) {
+	userID, ok := requireUserID(ctx)
+	if !ok {
+		return
+	}
+	postID, err := uuid.FromString(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
+		return
+	}
+
+	autosave, err := c.svc.GetAutosave(userID, postID)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+	setETag(ctx, autosave.Version)
+	respondOK(ctx, autosave)
+}
+
+func (c *AutosaveController) SaveAutosave(ctx *gin.Context) {
+	userID, ok := requireUserID(ctx)
+	if !ok {
+		return
+	}
+	postID, err := uuid.FromString(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
+		return
+	}
+
+	var req dto.AutosaveRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	version, ok := requireVersion(ctx, req.Version)
+	if !ok {
+		return
+	}
+
+	autosave, err := c.svc.SaveAutosave(userID, postID, version, &req)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+	setETag(ctx, autosave.Version)
+	respondOK(ctx, autosave)
+}
+
+func (c *AutosaveController) DiscardAutosave(ctx *gin.Context) {
+	userID, ok := requireUserID(ctx)
+	if !ok {
+		return
+	}
+	postID, err := uuid.FromString(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
+		return
+	}
+
+	if err := c.svc.DiscardAutosave(userID, postID); err != nil {
+		respondError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Autosave discarded"})
+}
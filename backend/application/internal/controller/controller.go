@@ -1,7 +1,10 @@
 package controller
 
 import (
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/pdhoang91/blog/internal/apperror"
@@ -14,8 +17,20 @@ import (
 // Each sub-controller depends only on the interface it needs (ISP).
 type Controller struct {
 	Auth       *AuthController
+	Session    *SessionController
+	MFA        *MFAController
+	APIToken   *APITokenController
+	Role       *RoleController
 	User       *UserController
 	Post       *PostController
+	Revision   *RevisionController
+	Autosave   *AutosaveController
+	PostAuthor *PostAuthorController
+	Review     *ReviewController
+	Series     *SeriesController
+	Trash      *TrashController
+	Import     *ImportController
+	Embed      *EmbedController
 	Comment    *CommentController
 	Engagement *EngagementController
 	Category   *CategoryController
@@ -28,8 +43,20 @@ type Controller struct {
 func NewController(svc service.Service) *Controller {
 	return &Controller{
 		Auth:       &AuthController{svc: svc},
+		Session:    &SessionController{svc: svc},
+		MFA:        &MFAController{svc: svc},
+		APIToken:   &APITokenController{svc: svc},
+		Role:       &RoleController{svc: svc},
 		User:       &UserController{svc: svc},
 		Post:       &PostController{svc: svc, user: svc},
+		Revision:   &RevisionController{svc: svc},
+		Autosave:   &AutosaveController{svc: svc},
+		PostAuthor: &PostAuthorController{svc: svc},
+		Review:     &ReviewController{svc: svc},
+		Series:     &SeriesController{svc: svc},
+		Trash:      &TrashController{svc: svc},
+		Import:     &ImportController{svc: svc},
+		Embed:      &EmbedController{svc: svc},
 		Comment:    &CommentController{svc: svc},
 		Engagement: &EngagementController{comment: svc},
 		Category:   &CategoryController{svc: svc},
@@ -43,7 +70,14 @@ func NewController(svc service.Service) *Controller {
 // --- Shared helpers ---
 
 func respondError(ctx *gin.Context, err error) {
-	ctx.JSON(apperror.HTTPCode(err), gin.H{"error": apperror.UserMessage(err)})
+	if retryAfter := apperror.RetryAfter(err); retryAfter > 0 {
+		ctx.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	}
+	body := gin.H{"error": apperror.UserMessage(err)}
+	if details := apperror.Details(err); details != nil {
+		body["details"] = details
+	}
+	ctx.JSON(apperror.HTTPCode(err), body)
 }
 
 // respondOK returns a 200 response with `{"data": data}`.
@@ -94,6 +128,28 @@ func requireUserID(ctx *gin.Context) (uuid.UUID, bool) {
 	return userID, true
 }
 
+// clientInfo captures where the request came from for session bookkeeping.
+func clientInfo(ctx *gin.Context) *dto.ClientInfo {
+	return &dto.ClientInfo{
+		IPAddress: ctx.ClientIP(),
+		UserAgent: ctx.Request.UserAgent(),
+	}
+}
+
+func requireSessionID(ctx *gin.Context) (uuid.UUID, bool) {
+	sessionIDStr, exists := ctx.Get("sessionID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return uuid.Nil, false
+	}
+	sessionID, err := uuid.FromString(sessionIDStr.(string))
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid session"})
+		return uuid.Nil, false
+	}
+	return sessionID, true
+}
+
 func optionalUserID(ctx *gin.Context) (uuid.UUID, bool) {
 	userIDStr, exists := ctx.Get("userID")
 	if !exists {
@@ -106,6 +162,31 @@ func optionalUserID(ctx *gin.Context) (uuid.UUID, bool) {
 	return userID, true
 }
 
+// requireVersion reads the version an edit is based on from If-Match ("3",
+// W/"3" or 3) or, failing that, from the request body. Edits without one are
+// answered with 428 so clients cannot overwrite changes they never saw.
+func requireVersion(ctx *gin.Context, body *int) (int, bool) {
+	if header := ctx.GetHeader("If-Match"); header != "" {
+		tag := strings.Trim(strings.TrimPrefix(strings.TrimSpace(header), "W/"), `"`)
+		version, err := strconv.Atoi(tag)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid If-Match header"})
+			return 0, false
+		}
+		return version, true
+	}
+	if body != nil {
+		return *body, true
+	}
+	respondError(ctx, apperror.NewPreconditionRequired("version or If-Match header is required"))
+	return 0, false
+}
+
+// setETag exposes a resource version for a later If-Match.
+func setETag(ctx *gin.Context, version int) {
+	ctx.Header("ETag", strconv.Quote(strconv.Itoa(version)))
+}
+
 func ensureNotNil[T any](slice []T) []T {
 	if slice == nil {
 		return []T{}
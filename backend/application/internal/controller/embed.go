@@ -0,0 +1,28 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pdhoang91/blog/internal/dto"
+	"github.com/pdhoang91/blog/internal/service"
+)
+
+type EmbedController struct {
+	svc service.EmbedService
+}
+
+// Unfurl returns the preview of a link for the editor to embed.
+func (c *EmbedController) Unfurl(ctx *gin.Context) {
+	var req dto.UnfurlRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	resp, err := c.svc.UnfurlLink(ctx.Request.Context(), &req)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+	respondOK(ctx, resp)
+}
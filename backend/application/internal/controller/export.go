@@ -0,0 +1,62 @@
+package controller
+
+import (
+	"mime"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pdhoang91/blog/internal/dto"
+	uuid "github.com/satori/go.uuid"
+)
+
+// ExportPost serves a published post as Markdown (format=md, the default) or
+// HTML (format=html).
+func (c *PostController) ExportPost(ctx *gin.Context) {
+	id, err := uuid.FromString(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
+		return
+	}
+	var req dto.ExportPostRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid format"})
+		return
+	}
+
+	export, err := c.svc.ExportPost(id, req.Format)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+	serveExport(ctx, export)
+}
+
+// ExportMyPost is ExportPost for drafts and other unpublished posts.
+func (c *PostController) ExportMyPost(ctx *gin.Context) {
+	userID, ok := requireUserID(ctx)
+	if !ok {
+		return
+	}
+	id, err := uuid.FromString(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
+		return
+	}
+	var req dto.ExportPostRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid format"})
+		return
+	}
+
+	export, err := c.svc.ExportMyPost(userID, id, req.Format)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+	serveExport(ctx, export)
+}
+
+func serveExport(ctx *gin.Context, export *dto.PostExport) {
+	ctx.Header("Content-Disposition", mime.FormatMediaType("inline", map[string]string{"filename": export.Filename}))
+	ctx.Data(http.StatusOK, export.ContentType, []byte(export.Body))
+}
@@ -0,0 +1,187 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pdhoang91/blog/internal/dto"
+	"github.com/pdhoang91/blog/internal/service"
+	"github.com/pdhoang91/blog/pkg/storage"
+	"github.com/pdhoang91/blog/pkg/wxr"
+)
+
+const (
+	maxDocumentSize = 5 << 20
+	// maxImportUploadSize bounds a whole import request, image bundle included.
+	maxImportUploadSize = 256 << 20
+	// importFormMemory is how much of a multipart form is held in memory;
+	// the rest goes to temporary files.
+	importFormMemory = 32 << 20
+)
+
+type ImportController struct {
+	svc service.ImportService
+}
+
+// ConvertDocument takes either JSON or a multipart form, where the document
+// can be sent as the "file" field and image files as a zip in "bundle".
+func (c *ImportController) ConvertDocument(ctx *gin.Context) {
+	userID, ok := requireUserID(ctx)
+	if !ok {
+		return
+	}
+
+	var req dto.ConvertDocumentRequest
+	var bundle *storage.Bundle
+	if strings.HasPrefix(ctx.ContentType(), "multipart/") {
+		if !parseImportForm(ctx) {
+			return
+		}
+		if err := ctx.ShouldBind(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+			return
+		}
+		if file, err := ctx.FormFile("file"); err == nil {
+			if req.Content, ok = readDocument(ctx, file); !ok {
+				return
+			}
+		}
+		var bundleFile multipart.File
+		if bundle, bundleFile, ok = openBundle(ctx); !ok {
+			return
+		}
+		if bundleFile != nil {
+			defer bundleFile.Close()
+		}
+	} else {
+		ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxDocumentSize)
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+			return
+		}
+	}
+	if strings.TrimSpace(req.Content) == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Document is empty"})
+		return
+	}
+
+	resp, err := c.svc.ConvertDocument(ctx.Request.Context(), userID, &req, bundle)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+	respondOK(ctx, resp)
+}
+
+// ImportWordPress takes a WXR export as the "file" field, optionally with the
+// uploads directory zipped as "bundle" and, as "authors", a JSON object giving
+// the username each author login is imported as. With dry_run=true nothing is
+// saved.
+func (c *ImportController) ImportWordPress(ctx *gin.Context) {
+	userID, ok := requireUserID(ctx)
+	if !ok {
+		return
+	}
+	if !parseImportForm(ctx) {
+		return
+	}
+
+	file, err := ctx.FormFile("file")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "No export uploaded"})
+		return
+	}
+	f, err := file.Open()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read export"})
+		return
+	}
+	defer f.Close()
+	export, err := wxr.Parse(f)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid WordPress export"})
+		return
+	}
+
+	bundle, bundleFile, ok := openBundle(ctx)
+	if !ok {
+		return
+	}
+	if bundleFile != nil {
+		defer bundleFile.Close()
+	}
+	var authorMap map[string]string
+	if authors := ctx.PostForm("authors"); authors != "" {
+		if err := json.Unmarshal([]byte(authors), &authorMap); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid author mapping"})
+			return
+		}
+	}
+	dryRun, _ := strconv.ParseBool(ctx.PostForm("dry_run"))
+
+	report, err := c.svc.ImportWordPress(ctx.Request.Context(), userID, export, bundle, authorMap, dryRun)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+	respondOK(ctx, report)
+}
+
+func parseImportForm(ctx *gin.Context) bool {
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxImportUploadSize)
+	if err := ctx.Request.ParseMultipartForm(importFormMemory); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Upload too large or malformed"})
+		return false
+	}
+	return true
+}
+
+func readDocument(ctx *gin.Context, file *multipart.FileHeader) (string, bool) {
+	if file.Size > maxDocumentSize {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Document too large"})
+		return "", false
+	}
+	f, err := file.Open()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read document"})
+		return "", false
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read document"})
+		return "", false
+	}
+	return string(data), true
+}
+
+// openBundle opens the optional "bundle" zip. The returned file backs the
+// bundle and must be closed once it is no longer used.
+func openBundle(ctx *gin.Context) (*storage.Bundle, multipart.File, bool) {
+	header, err := ctx.FormFile("bundle")
+	if errors.Is(err, http.ErrMissingFile) {
+		return nil, nil, true
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read bundle"})
+		return nil, nil, false
+	}
+	f, err := header.Open()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read bundle"})
+		return nil, nil, false
+	}
+	bundle, err := storage.OpenBundle(f, header.Size)
+	if err != nil {
+		f.Close()
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Bundle must be a zip archive"})
+		return nil, nil, false
+	}
+	return bundle, f, true
+}
@@ -0,0 +1,136 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pdhoang91/blog/internal/dto"
+	"github.com/pdhoang91/blog/internal/service"
+)
+
+type MFAController struct {
+	svc service.MFAService
+}
+
+func currentRole(ctx *gin.Context) string {
+	role, _ := ctx.Get("role")
+	s, _ := role.(string)
+	return s
+}
+
+func (c *MFAController) GetStatus(ctx *gin.Context) {
+	userID, ok := requireUserID(ctx)
+	if !ok {
+		return
+	}
+
+	status, err := c.svc.GetMFAStatus(userID, currentRole(ctx))
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+	respondOK(ctx, status)
+}
+
+// Enroll starts TOTP enrollment; the secret only takes effect after Activate.
+func (c *MFAController) Enroll(ctx *gin.Context) {
+	userID, ok := requireUserID(ctx)
+	if !ok {
+		return
+	}
+
+	response, err := c.svc.EnrollMFA(userID)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+	respondOK(ctx, response)
+}
+
+func (c *MFAController) Activate(ctx *gin.Context) {
+	userID, ok := requireUserID(ctx)
+	if !ok {
+		return
+	}
+	sessionID, ok := requireSessionID(ctx)
+	if !ok {
+		return
+	}
+	var req dto.MFACodeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	response, err := c.svc.ActivateMFA(userID, sessionID, req.Code)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+	respondOK(ctx, response)
+}
+
+func (c *MFAController) Disable(ctx *gin.Context) {
+	userID, ok := requireUserID(ctx)
+	if !ok {
+		return
+	}
+	var req dto.MFACodeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	if err := c.svc.DisableMFA(userID, currentRole(ctx), req.Code); err != nil {
+		respondError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled"})
+}
+
+func (c *MFAController) RegenerateRecoveryCodes(ctx *gin.Context) {
+	userID, ok := requireUserID(ctx)
+	if !ok {
+		return
+	}
+	var req dto.MFACodeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	response, err := c.svc.RegenerateRecoveryCodes(userID, req.Code)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+	respondOK(ctx, response)
+}
+
+func (c *MFAController) ListPolicies(ctx *gin.Context) {
+	policies, err := c.svc.ListMFAPolicies()
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+	respondOK(ctx, policies)
+}
+
+func (c *MFAController) UpdatePolicy(ctx *gin.Context) {
+	adminID, ok := requireUserID(ctx)
+	if !ok {
+		return
+	}
+	var req dto.UpdateMFAPolicyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	policy, err := c.svc.SetMFAPolicy(adminID, ctx.Param("role"), *req.Required)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+	respondOK(ctx, policy)
+}
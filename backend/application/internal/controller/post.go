@@ -3,6 +3,7 @@ package controller
 import (
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/pdhoang91/blog/internal/dto"
@@ -71,6 +72,15 @@ func (c *PostController) ListPosts(ctx *gin.Context) {
 }
 
 func (c *PostController) UpdatePost(ctx *gin.Context) {
+	c.updatePost(ctx, c.svc.UpdatePost)
+}
+
+// UpdateAnyPost is UpdatePost for editors changing other people's posts.
+func (c *PostController) UpdateAnyPost(ctx *gin.Context) {
+	c.updatePost(ctx, c.svc.UpdateAnyPost)
+}
+
+func (c *PostController) updatePost(ctx *gin.Context, update func(userID, id uuid.UUID, req *dto.UpdatePostRequest) (*dto.PostResponse, error)) {
 	userID, ok := requireUserID(ctx)
 	if !ok {
 		return
@@ -87,16 +97,31 @@ func (c *PostController) UpdatePost(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
 		return
 	}
+	version, ok := requireVersion(ctx, req.Version)
+	if !ok {
+		return
+	}
+	req.Version = &version
 
-	response, err := c.svc.UpdatePost(userID, id, &req)
+	response, err := update(userID, id, &req)
 	if err != nil {
 		respondError(ctx, err)
 		return
 	}
+	setETag(ctx, response.Version)
 	ctx.JSON(http.StatusOK, gin.H{"data": response})
 }
 
 func (c *PostController) DeletePost(ctx *gin.Context) {
+	c.deletePost(ctx, c.svc.DeletePost)
+}
+
+// DeleteAnyPost is DeletePost for editors deleting other people's posts.
+func (c *PostController) DeleteAnyPost(ctx *gin.Context) {
+	c.deletePost(ctx, c.svc.DeleteAnyPost)
+}
+
+func (c *PostController) deletePost(ctx *gin.Context, remove func(userID, id uuid.UUID) error) {
 	userID, ok := requireUserID(ctx)
 	if !ok {
 		return
@@ -108,13 +133,60 @@ func (c *PostController) DeletePost(ctx *gin.Context) {
 		return
 	}
 
-	if err := c.svc.DeletePost(userID, id); err != nil {
+	if err := remove(userID, id); err != nil {
 		respondError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, gin.H{"message": "Post deleted successfully"})
 }
 
+// ListMyPosts lists the caller's posts in any status; ?status= narrows it down.
+func (c *PostController) ListMyPosts(ctx *gin.Context) {
+	userID, ok := requireUserID(ctx)
+	if !ok {
+		return
+	}
+
+	req, err := parsePagination(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters"})
+		return
+	}
+
+	responses, total, err := c.svc.ListMyPosts(userID, ctx.Query("status"), req)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"data": ensureNotNil(responses), "total_count": total,
+		"limit": req.Limit, "offset": req.Offset,
+	})
+}
+
+// GetMyPost returns one of the caller's posts, drafts included, for editing or preview.
+func (c *PostController) GetMyPost(ctx *gin.Context) {
+	userID, ok := requireUserID(ctx)
+	if !ok {
+		return
+	}
+
+	id, err := uuid.FromString(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
+		return
+	}
+
+	response, err := c.svc.GetMyPost(userID, id)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+	setETag(ctx, response.Version)
+	ctx.JSON(http.StatusOK, gin.H{"data": gin.H{"post": response}})
+}
+
 func (c *PostController) GetLatestPosts(ctx *gin.Context) {
 	req, err := parsePagination(ctx)
 	if err != nil {
@@ -197,11 +269,18 @@ func (c *PostController) GetPostByTitleName(ctx *gin.Context) {
 		return
 	}
 
-	response, err := c.svc.GetPostBySlug(slug)
+	response, redirect, err := c.svc.GetPostBySlug(slug)
 	if err != nil {
 		respondError(ctx, err)
 		return
 	}
+	if redirect != "" {
+		// The post was renamed; send old links to its current address.
+		location := *ctx.Request.URL
+		location.Path = strings.TrimSuffix(location.Path, slug) + redirect
+		ctx.Redirect(http.StatusMovedPermanently, location.String())
+		return
+	}
 
 	ctx.JSON(http.StatusOK, gin.H{
 		"data": gin.H{"post": response},
@@ -0,0 +1,125 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pdhoang91/blog/internal/dto"
+	"github.com/pdhoang91/blog/internal/service"
+	uuid "github.com/satori/go.uuid"
+)
+
+type PostAuthorController struct {
+	svc service.PostAuthorService
+}
+
+func (c *PostAuthorController) ListAuthors(ctx *gin.Context) {
+	userID, ok := requireUserID(ctx)
+	if !ok {
+		return
+	}
+	postID, err := uuid.FromString(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
+		return
+	}
+
+	authors, err := c.svc.ListPostAuthors(userID, postID)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+	respondOK(ctx, authors)
+}
+
+func (c *PostAuthorController) InviteAuthor(ctx *gin.Context) {
+	userID, ok := requireUserID(ctx)
+	if !ok {
+		return
+	}
+	postID, err := uuid.FromString(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
+		return
+	}
+
+	var req dto.InvitePostAuthorRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	author, err := c.svc.InvitePostAuthor(userID, postID, &req)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+	respondCreated(ctx, author)
+}
+
+func (c *PostAuthorController) RemoveAuthor(ctx *gin.Context) {
+	userID, ok := requireUserID(ctx)
+	if !ok {
+		return
+	}
+	postID, err := uuid.FromString(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
+		return
+	}
+	authorID, err := uuid.FromString(ctx.Param("userId"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	if err := c.svc.RemovePostAuthor(userID, postID, authorID); err != nil {
+		respondError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Author removed"})
+}
+
+func (c *PostAuthorController) ListInvitations(ctx *gin.Context) {
+	userID, ok := requireUserID(ctx)
+	if !ok {
+		return
+	}
+
+	invitations, err := c.svc.ListMyInvitations(userID)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+	respondOK(ctx, invitations)
+}
+
+func (c *PostAuthorController) AcceptInvitation(ctx *gin.Context) {
+	c.respondToInvitation(ctx, true)
+}
+
+func (c *PostAuthorController) DeclineInvitation(ctx *gin.Context) {
+	c.respondToInvitation(ctx, false)
+}
+
+func (c *PostAuthorController) respondToInvitation(ctx *gin.Context, accept bool) {
+	userID, ok := requireUserID(ctx)
+	if !ok {
+		return
+	}
+	postID, err := uuid.FromString(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
+		return
+	}
+
+	if err := c.svc.RespondToInvitation(userID, postID, accept); err != nil {
+		respondError(ctx, err)
+		return
+	}
+	message := "Invitation declined"
+	if accept {
+		message = "Invitation accepted"
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": message})
+}
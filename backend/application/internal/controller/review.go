@@ -0,0 +1,102 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pdhoang91/blog/internal/dto"
+	"github.com/pdhoang91/blog/internal/service"
+	uuid "github.com/satori/go.uuid"
+)
+
+type ReviewController struct {
+	svc service.ReviewService
+}
+
+func (c *ReviewController) SubmitForReview(ctx *gin.Context) {
+	userID, ok := requireUserID(ctx)
+	if !ok {
+		return
+	}
+	postID, err := uuid.FromString(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
+		return
+	}
+
+	// The body, a note for the reviewers, is optional.
+	var req dto.SubmitForReviewRequest
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+			return
+		}
+	}
+
+	post, err := c.svc.SubmitPostForReview(userID, postID, &req)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+	respondOK(ctx, post)
+}
+
+func (c *ReviewController) ReviewPost(ctx *gin.Context) {
+	userID, ok := requireUserID(ctx)
+	if !ok {
+		return
+	}
+	postID, err := uuid.FromString(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
+		return
+	}
+
+	var req dto.ReviewPostRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	post, err := c.svc.ReviewPost(userID, postID, &req)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+	respondOK(ctx, post)
+}
+
+// ListQueue lists posts waiting for review, longest waiting first.
+func (c *ReviewController) ListQueue(ctx *gin.Context) {
+	req, err := parsePagination(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters"})
+		return
+	}
+
+	responses, total, err := c.svc.ListReviewQueue(req)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+	respondList(ctx, ensureNotNil(responses), total, req.Limit, req.Offset)
+}
+
+func (c *ReviewController) GetHistory(ctx *gin.Context) {
+	userID, ok := requireUserID(ctx)
+	if !ok {
+		return
+	}
+	postID, err := uuid.FromString(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
+		return
+	}
+
+	events, err := c.svc.GetPostHistory(userID, postID)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+	respondOK(ctx, events)
+}
@@ -0,0 +1,137 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pdhoang91/blog/internal/dto"
+	"github.com/pdhoang91/blog/internal/service"
+	uuid "github.com/satori/go.uuid"
+)
+
+type RevisionController struct {
+	svc service.RevisionService
+}
+
+// revisionParams reads the post ID and, when present, the revision number
+// from the path, answering 400 if either is malformed.
+func revisionParams(ctx *gin.Context) (uuid.UUID, int, bool) {
+	postID, err := uuid.FromString(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
+		return uuid.Nil, 0, false
+	}
+	if ctx.Param("rev") == "" {
+		return postID, 0, true
+	}
+	number, err := strconv.Atoi(ctx.Param("rev"))
+	if err != nil || number < 1 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid revision number"})
+		return uuid.Nil, 0, false
+	}
+	return postID, number, true
+}
+
+func (c *RevisionController) ListRevisions(ctx *gin.Context) {
+	userID, ok := requireUserID(ctx)
+	if !ok {
+		return
+	}
+	postID, _, ok := revisionParams(ctx)
+	if !ok {
+		return
+	}
+
+	req, err := parsePagination(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters"})
+		return
+	}
+
+	revisions, total, err := c.svc.ListPostRevisions(userID, postID, req)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+	respondList(ctx, ensureNotNil(revisions), total, req.Limit, req.Offset)
+}
+
+func (c *RevisionController) GetRevision(ctx *gin.Context) {
+	userID, ok := requireUserID(ctx)
+	if !ok {
+		return
+	}
+	postID, number, ok := revisionParams(ctx)
+	if !ok {
+		return
+	}
+
+	revision, err := c.svc.GetPostRevision(userID, postID, number)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+	respondOK(ctx, revision)
+}
+
+// DiffRevision compares a revision with ?from=N, by default the one before it.
+func (c *RevisionController) DiffRevision(ctx *gin.Context) {
+	userID, ok := requireUserID(ctx)
+	if !ok {
+		return
+	}
+	postID, number, ok := revisionParams(ctx)
+	if !ok {
+		return
+	}
+
+	from := number - 1
+	if raw := ctx.Query("from"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters"})
+			return
+		}
+		from = n
+	}
+
+	diff, err := c.svc.DiffPostRevisions(userID, postID, from, number)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+	respondOK(ctx, diff)
+}
+
+func (c *RevisionController) RestoreRevision(ctx *gin.Context) {
+	userID, ok := requireUserID(ctx)
+	if !ok {
+		return
+	}
+	postID, number, ok := revisionParams(ctx)
+	if !ok {
+		return
+	}
+
+	// The version may come in If-Match instead of a body.
+	var req dto.RestoreRevisionRequest
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+			return
+		}
+	}
+	version, ok := requireVersion(ctx, req.Version)
+	if !ok {
+		return
+	}
+
+	post, err := c.svc.RestorePostRevision(userID, postID, number, version)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+	setETag(ctx, post.Version)
+	respondOK(ctx, post)
+}
@@ -0,0 +1,43 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pdhoang91/blog/internal/dto"
+	"github.com/pdhoang91/blog/internal/service"
+	uuid "github.com/satori/go.uuid"
+)
+
+type RoleController struct {
+	svc service.RoleService
+}
+
+func (c *RoleController) ListRoles(ctx *gin.Context) {
+	respondOK(ctx, c.svc.ListRoles())
+}
+
+func (c *RoleController) ChangeUserRole(ctx *gin.Context) {
+	adminID, ok := requireUserID(ctx)
+	if !ok {
+		return
+	}
+
+	userID, err := uuid.FromString(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	var req dto.ChangeRoleRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	user, err := c.svc.ChangeUserRole(adminID, userID, req.Role)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+	respondOK(ctx, user)
+}
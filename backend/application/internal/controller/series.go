@@ -0,0 +1,100 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pdhoang91/blog/internal/dto"
+	"github.com/pdhoang91/blog/internal/service"
+	uuid "github.com/satori/go.uuid"
+)
+
+type SeriesController struct {
+	svc service.SeriesService
+}
+
+func (c *SeriesController) GetSeriesBySlug(ctx *gin.Context) {
+	series, err := c.svc.GetSeriesBySlug(ctx.Param("slug"))
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+	respondOK(ctx, series)
+}
+
+func (c *SeriesController) ListMySeries(ctx *gin.Context) {
+	userID, ok := requireUserID(ctx)
+	if !ok {
+		return
+	}
+
+	series, err := c.svc.ListMySeries(userID)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+	respondOK(ctx, series)
+}
+
+func (c *SeriesController) CreateSeries(ctx *gin.Context) {
+	userID, ok := requireUserID(ctx)
+	if !ok {
+		return
+	}
+
+	var req dto.CreateSeriesRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	series, err := c.svc.CreateSeries(userID, &req)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+	respondCreated(ctx, series)
+}
+
+func (c *SeriesController) UpdateSeries(ctx *gin.Context) {
+	userID, ok := requireUserID(ctx)
+	if !ok {
+		return
+	}
+	id, err := uuid.FromString(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid series ID"})
+		return
+	}
+
+	var req dto.UpdateSeriesRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	series, err := c.svc.UpdateSeries(userID, id, &req)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+	respondOK(ctx, series)
+}
+
+func (c *SeriesController) DeleteSeries(ctx *gin.Context) {
+	userID, ok := requireUserID(ctx)
+	if !ok {
+		return
+	}
+	id, err := uuid.FromString(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid series ID"})
+		return
+	}
+
+	if err := c.svc.DeleteSeries(userID, id); err != nil {
+		respondError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Series deleted"})
+}
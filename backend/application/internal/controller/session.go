@@ -0,0 +1,65 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pdhoang91/blog/internal/service"
+	uuid "github.com/satori/go.uuid"
+)
+
+type SessionController struct {
+	svc service.SessionService
+}
+
+func (c *SessionController) ListSessions(ctx *gin.Context) {
+	userID, ok := requireUserID(ctx)
+	if !ok {
+		return
+	}
+	sessionID, ok := requireSessionID(ctx)
+	if !ok {
+		return
+	}
+
+	sessions, err := c.svc.ListSessions(userID, sessionID)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+	respondOK(ctx, sessions)
+}
+
+func (c *SessionController) RevokeSession(ctx *gin.Context) {
+	userID, ok := requireUserID(ctx)
+	if !ok {
+		return
+	}
+
+	id, err := uuid.FromString(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	if err := c.svc.RevokeSession(userID, id); err != nil {
+		respondError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Session revoked"})
+}
+
+// RevokeAllSessions logs the user out everywhere, including the calling session.
+func (c *SessionController) RevokeAllSessions(ctx *gin.Context) {
+	userID, ok := requireUserID(ctx)
+	if !ok {
+		return
+	}
+
+	revoked, err := c.svc.RevokeAllSessions(userID)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Logged out of all sessions", "revoked": revoked})
+}
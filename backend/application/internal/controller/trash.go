@@ -0,0 +1,91 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pdhoang91/blog/internal/dto"
+	"github.com/pdhoang91/blog/internal/service"
+	uuid "github.com/satori/go.uuid"
+)
+
+type TrashController struct {
+	svc service.TrashService
+}
+
+func (c *TrashController) ListTrash(ctx *gin.Context) {
+	userID, ok := requireUserID(ctx)
+	if !ok {
+		return
+	}
+
+	var req dto.TrashListRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	if req.Page > 0 && req.Limit > 0 {
+		req.Offset = (req.Page - 1) * req.Limit
+	}
+	if req.Limit == 0 {
+		req.Limit = 10
+	}
+
+	items, total, err := c.svc.ListTrash(userID, &req)
+	if err != nil {
+		respondError(ctx, err)
+		return
+	}
+	respondList(ctx, items, total, req.Limit, req.Offset)
+}
+
+// trashItem reads the item type and ID from the path.
+func trashItem(ctx *gin.Context) (string, uuid.UUID, bool) {
+	itemType := ctx.Param("type")
+	switch itemType {
+	case dto.TrashPosts, dto.TrashComments, dto.TrashReplies:
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid trash type"})
+		return "", uuid.Nil, false
+	}
+	id, err := uuid.FromString(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid item ID"})
+		return "", uuid.Nil, false
+	}
+	return itemType, id, true
+}
+
+func (c *TrashController) RestoreItem(ctx *gin.Context) {
+	userID, ok := requireUserID(ctx)
+	if !ok {
+		return
+	}
+	itemType, id, ok := trashItem(ctx)
+	if !ok {
+		return
+	}
+
+	if err := c.svc.RestoreTrashItem(userID, itemType, id); err != nil {
+		respondError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Item restored"})
+}
+
+func (c *TrashController) PurgeItem(ctx *gin.Context) {
+	userID, ok := requireUserID(ctx)
+	if !ok {
+		return
+	}
+	itemType, id, ok := trashItem(ctx)
+	if !ok {
+		return
+	}
+
+	if err := c.svc.PurgeTrashItem(userID, itemType, id); err != nil {
+		respondError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Item deleted permanently"})
+}
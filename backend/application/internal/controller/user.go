@@ -120,11 +120,11 @@ func (c *UserController) DebugJWT(ctx *gin.Context) {
 	} else {
 		user = &entities.User{
 			ID: uuid.NewV4(), Email: "debug@example.com",
-			Name: "Debug User", Role: constants.RoleUser,
+			Name: "Debug User", Role: constants.RoleAuthor,
 		}
 	}
 
-	token, err := pkgjwt.GenerateJWT(user)
+	token, err := pkgjwt.GenerateJWT(user, uuid.NewV4().String())
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "JWT generation failed"})
 		return
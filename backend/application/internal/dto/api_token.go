@@ -0,0 +1,50 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/pdhoang91/blog/internal/entities"
+	uuid "github.com/satori/go.uuid"
+)
+
+type CreateAPITokenRequest struct {
+	Name          string   `json:"name" binding:"required,max=100"`
+	Scopes        []string `json:"scopes" binding:"required,min=1"`
+	ExpiresInDays int      `json:"expires_in_days" binding:"omitempty,min=1,max=365"`
+}
+
+type APITokenResponse struct {
+	ID         uuid.UUID  `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func NewAPITokenResponse(token *entities.APIToken) *APITokenResponse {
+	return &APITokenResponse{
+		ID:         token.ID,
+		Name:       token.Name,
+		Prefix:     token.Prefix,
+		Scopes:     token.ScopeList(),
+		ExpiresAt:  token.ExpiresAt,
+		LastUsedAt: token.LastUsedAt,
+		CreatedAt:  token.CreatedAt,
+	}
+}
+
+// CreateAPITokenResponse carries the plaintext token, which is only ever returned once.
+type CreateAPITokenResponse struct {
+	*APITokenResponse
+	Token string `json:"token"`
+}
+
+// APITokenPrincipal is the identity behind an authenticated API token request.
+type APITokenPrincipal struct {
+	TokenID uuid.UUID
+	UserID  uuid.UUID
+	Role    string
+	Scopes  []string
+}
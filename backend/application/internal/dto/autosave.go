@@ -0,0 +1,53 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/pdhoang91/blog/internal/entities"
+	uuid "github.com/satori/go.uuid"
+)
+
+// AutosaveRequest stores the editor's working copy. Version is the autosave
+// version the editor last saw, 0 for the first autosave; If-Match may carry
+// it instead.
+type AutosaveRequest struct {
+	Title   string          `json:"title"`
+	Excerpt string          `json:"excerpt"`
+	Content json.RawMessage `json:"content"`
+	Version *int            `json:"version,omitempty"`
+}
+
+type AutosaveResponse struct {
+	PostID      uuid.UUID       `json:"post_id"`
+	Title       string          `json:"title"`
+	Excerpt     string          `json:"excerpt"`
+	Content     json.RawMessage `json:"content,omitempty"`
+	Version     int             `json:"version"`
+	PostVersion int             `json:"post_version"`
+	// Stale means the post was saved since this copy was last autosaved.
+	Stale     bool       `json:"stale"`
+	UpdatedBy *uuid.UUID `json:"updated_by,omitempty"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+func NewAutosaveResponse(autosave *entities.PostAutosave, postVersion int) *AutosaveResponse {
+	return &AutosaveResponse{
+		PostID:      autosave.PostID,
+		Title:       autosave.Title,
+		Excerpt:     autosave.Excerpt,
+		Content:     autosave.Content,
+		Version:     autosave.Version,
+		PostVersion: autosave.PostVersion,
+		Stale:       autosave.PostVersion != postVersion,
+		UpdatedBy:   autosave.UpdatedBy,
+		UpdatedAt:   autosave.UpdatedAt,
+	}
+}
+
+// VersionConflictResponse is returned as the details of a 409 so the editor
+// can offer to merge with what is on the server now.
+type VersionConflictResponse struct {
+	CurrentVersion int         `json:"current_version"`
+	Current        interface{} `json:"current,omitempty"`
+}
@@ -0,0 +1,19 @@
+package dto
+
+// UnfurlRequest is a link pasted into the editor.
+type UnfurlRequest struct {
+	URL string `json:"url" binding:"required,max=2048"`
+}
+
+// EmbedResponse is a link preview, with the attributes of the editor's embed
+// node.
+type EmbedResponse struct {
+	URL         string `json:"url"`
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	SiteName    string `json:"site_name,omitempty"`
+	Image       string `json:"image,omitempty"`
+	// Video is the player to embed instead of a card, only for sites whose
+	// players posts may embed.
+	Video string `json:"video,omitempty"`
+}
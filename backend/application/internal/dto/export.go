@@ -0,0 +1,18 @@
+package dto
+
+// Formats a post can be exported in.
+const (
+	ExportMarkdown = "md"
+	ExportHTML     = "html"
+)
+
+type ExportPostRequest struct {
+	Format string `form:"format" binding:"omitempty,oneof=md html"`
+}
+
+// PostExport is a post rendered as a standalone document.
+type PostExport struct {
+	Filename    string
+	ContentType string
+	Body        string
+}
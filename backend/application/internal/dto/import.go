@@ -0,0 +1,97 @@
+package dto
+
+import (
+	"encoding/json"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+// Formats a document can be converted from.
+const (
+	ImportMarkdown = "markdown"
+	ImportHTML     = "html"
+)
+
+// What an import did, or in a dry run would do, with a post.
+const (
+	ImportActionCreate  = "create"
+	ImportActionCreated = "created"
+	ImportActionSkipped = "skipped"
+	ImportActionFailed  = "failed"
+)
+
+// ConvertDocumentRequest is a Markdown or HTML document to turn into editor
+// content. Images are rehosted when a bundle is uploaded along with it.
+type ConvertDocumentRequest struct {
+	Format  string `json:"format" form:"format" binding:"required,oneof=markdown html"`
+	Content string `json:"content" form:"content"`
+}
+
+type ConvertDocumentResponse struct {
+	// Title is taken from a level-one heading opening the document, which is
+	// then left out of Content.
+	Title   string            `json:"title,omitempty"`
+	Content json.RawMessage   `json:"content"`
+	Images  ImportImageCounts `json:"images"`
+}
+
+// ImportImageCounts tells what became of the images in imported content.
+// Remote images without a bundle are left pointing at their old location.
+type ImportImageCounts struct {
+	Rehosted int `json:"rehosted"`
+	Missing  int `json:"missing"`
+	Remote   int `json:"remote"`
+}
+
+func (c *ImportImageCounts) Add(o ImportImageCounts) {
+	c.Rehosted += o.Rehosted
+	c.Missing += o.Missing
+	c.Remote += o.Remote
+}
+
+// ImportReport describes a WordPress import. In a dry run nothing is written
+// and post actions say what would happen.
+type ImportReport struct {
+	DryRun        bool                 `json:"dry_run"`
+	Summary       ImportSummary        `json:"summary"`
+	Authors       []ImportAuthorResult `json:"authors"`
+	NewCategories []string             `json:"new_categories"`
+	NewTags       []string             `json:"new_tags"`
+	Posts         []ImportPostResult   `json:"posts"`
+}
+
+type ImportSummary struct {
+	Posts           int               `json:"posts"`
+	Created         int               `json:"created"`
+	Skipped         int               `json:"skipped"`
+	Failed          int               `json:"failed"`
+	Comments        int               `json:"comments"`
+	CommentsSkipped int               `json:"comments_skipped"`
+	Images          ImportImageCounts `json:"images"`
+}
+
+// ImportAuthorResult maps a WordPress author to an account. Matched is set for
+// authors the request mapped to an account; posts of the others go to the user
+// running the import.
+type ImportAuthorResult struct {
+	Login       string     `json:"login"`
+	Email       string     `json:"email,omitempty"`
+	DisplayName string     `json:"display_name,omitempty"`
+	UserID      *uuid.UUID `json:"user_id,omitempty"`
+	Username    string     `json:"username,omitempty"`
+	Matched     bool       `json:"matched"`
+}
+
+type ImportPostResult struct {
+	SourceID        int64             `json:"source_id"`
+	Title           string            `json:"title"`
+	Slug            string            `json:"slug,omitempty"`
+	Status          string            `json:"status,omitempty"`
+	Author          string            `json:"author,omitempty"`
+	Action          string            `json:"action"`
+	Reason          string            `json:"reason,omitempty"`
+	PostID          *uuid.UUID        `json:"post_id,omitempty"`
+	Comments        int               `json:"comments"`
+	CommentsSkipped int               `json:"comments_skipped"`
+	Images          ImportImageCounts `json:"images"`
+}
@@ -0,0 +1,59 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/pdhoang91/blog/internal/entities"
+	uuid "github.com/satori/go.uuid"
+)
+
+// VerifyMFARequest completes a login that returned mfa_required. Exactly one of
+// Code and RecoveryCode is expected.
+type VerifyMFARequest struct {
+	MFAToken     string `json:"mfa_token" binding:"required"`
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+type MFACodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type UpdateMFAPolicyRequest struct {
+	Required *bool `json:"required" binding:"required"`
+}
+
+type MFAStatusResponse struct {
+	Enabled                bool       `json:"enabled"`
+	EnabledAt              *time.Time `json:"enabled_at,omitempty"`
+	Required               bool       `json:"required"`
+	RecoveryCodesRemaining int64      `json:"recovery_codes_remaining"`
+}
+
+// MFAEnrollResponse carries the secret to load into an authenticator app,
+// either typed in or scanned from OTPAuthURI as a QR code.
+type MFAEnrollResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+// MFARecoveryCodesResponse carries plaintext recovery codes, which are only ever returned once.
+type MFARecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type MFAPolicyResponse struct {
+	Role      string     `json:"role"`
+	Required  bool       `json:"required"`
+	UpdatedBy *uuid.UUID `json:"updated_by,omitempty"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+func NewMFAPolicyResponse(policy *entities.MFARolePolicy) *MFAPolicyResponse {
+	return &MFAPolicyResponse{
+		Role:      policy.Role,
+		Required:  policy.Required,
+		UpdatedBy: policy.UpdatedBy,
+		UpdatedAt: policy.UpdatedAt,
+	}
+}
@@ -10,38 +10,67 @@ import (
 
 // Post requests
 type CreatePostRequest struct {
-	Title      string          `json:"title" validate:"required,min=5,max=200"`
-	CoverImage string          `json:"cover_image,omitempty"`
-	Excerpt    string          `json:"excerpt,omitempty" validate:"omitempty,min=10,max=500"`
-	Content    json.RawMessage `json:"content" validate:"required"`
-	CategoryNames []string     `json:"categories,omitempty"`
-	TagNames      []string     `json:"tags,omitempty"`
+	Title         string          `json:"title" validate:"required,min=5,max=200"`
+	CoverImage    string          `json:"cover_image,omitempty"`
+	Excerpt       string          `json:"excerpt,omitempty" validate:"omitempty,min=10,max=500"`
+	Content       json.RawMessage `json:"content" validate:"required"`
+	CategoryNames []string        `json:"categories,omitempty"`
+	TagNames      []string        `json:"tags,omitempty"`
+	// Slug pins a custom slug; without it the slug is derived from the title.
+	Slug string `json:"slug,omitempty" binding:"omitempty,max=200"`
+	// Status defaults to published for editors and to draft for authors, whose
+	// posts need approval first. Scheduled posts need a future PublishedAt.
+	Status      string     `json:"status,omitempty" binding:"omitempty,oneof=draft in_review scheduled published unlisted archived"`
+	PublishedAt *time.Time `json:"published_at,omitempty"`
 }
 
 type UpdatePostRequest struct {
-	Title      string          `json:"title,omitempty" validate:"omitempty,min=5,max=200"`
-	CoverImage string          `json:"cover_image,omitempty"`
-	Excerpt    string          `json:"excerpt,omitempty" validate:"omitempty,min=10,max=500"`
-	Content    json.RawMessage `json:"content,omitempty"`
-	CategoryNames *[]string    `json:"categories,omitempty"`
-	TagNames      *[]string    `json:"tags,omitempty"`
+	Title         string          `json:"title,omitempty" validate:"omitempty,min=5,max=200"`
+	CoverImage    string          `json:"cover_image,omitempty"`
+	Excerpt       string          `json:"excerpt,omitempty" validate:"omitempty,min=10,max=500"`
+	Content       json.RawMessage `json:"content,omitempty"`
+	CategoryNames *[]string       `json:"categories,omitempty"`
+	TagNames      *[]string       `json:"tags,omitempty"`
+	// Slug pins a custom slug; an empty one makes the slug follow the title again.
+	Slug *string `json:"slug,omitempty" binding:"omitempty,max=200"`
+	// Status and PublishedAt are left unchanged when omitted. Approval and
+	// requested changes go through the review endpoint instead.
+	Status      string     `json:"status,omitempty" binding:"omitempty,oneof=draft in_review scheduled published unlisted archived"`
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+	// Version is the post version the edit is based on; If-Match may carry it instead.
+	Version *int `json:"version,omitempty"`
 }
 
 // Post responses
 type PostResponse struct {
-	ID            uuid.UUID           `json:"id"`
-	Title         string              `json:"title"`
-	Slug          string              `json:"slug"`
-	Excerpt       string              `json:"excerpt"`
-	CoverImage    string              `json:"cover_image"`
-	Content       json.RawMessage     `json:"content,omitempty"`
-	Views         uint64              `json:"views"`
-	CommentsCount uint64              `json:"comments_count"`
-	CreatedAt     time.Time           `json:"created_at"`
-	UpdatedAt     time.Time           `json:"updated_at"`
-	User          *UserResponse       `json:"user,omitempty"`
-	Categories    []*CategoryResponse `json:"categories,omitempty"`
-	Tags          []*TagResponse      `json:"tags,omitempty"`
+	ID          uuid.UUID       `json:"id"`
+	Title       string          `json:"title"`
+	Slug        string          `json:"slug"`
+	SlugPinned  bool            `json:"slug_pinned"`
+	Excerpt     string          `json:"excerpt"`
+	CoverImage  string          `json:"cover_image"`
+	Status      string          `json:"status"`
+	PublishedAt *time.Time      `json:"published_at,omitempty"`
+	Version     int             `json:"version"`
+	Content     json.RawMessage `json:"content,omitempty"`
+	WordCount   int             `json:"word_count"`
+	ReadingTime int             `json:"reading_time"` // minutes
+	// TOC lists the headings as {id, level, text}, in document order.
+	TOC json.RawMessage `json:"toc,omitempty"`
+	// CodeTokens holds the highlighted tokens of each code block, as lists of
+	// {kind, text}, in document order; blocks left plain have null. It is
+	// for display only, and only on single-post responses.
+	CodeTokens    json.RawMessage       `json:"code_tokens,omitempty"`
+	Views         uint64                `json:"views"`
+	CommentsCount uint64                `json:"comments_count"`
+	CreatedAt     time.Time             `json:"created_at"`
+	UpdatedAt     time.Time             `json:"updated_at"`
+	User          *UserResponse         `json:"user,omitempty"`
+	Authors       []*PostAuthorResponse `json:"authors,omitempty"`
+	Categories    []*CategoryResponse   `json:"categories,omitempty"`
+	Tags          []*TagResponse        `json:"tags,omitempty"`
+	// Series is only filled in on single-post responses.
+	Series *SeriesNavResponse `json:"series,omitempty"`
 }
 
 func NewPostResponse(post *entities.Post) *PostResponse {
@@ -49,9 +78,17 @@ func NewPostResponse(post *entities.Post) *PostResponse {
 		ID:            post.ID,
 		Title:         post.Title,
 		Slug:          post.Slug,
+		SlugPinned:    post.SlugPinned,
 		Excerpt:       post.Excerpt,
 		CoverImage:    post.CoverImage,
+		Status:        post.Status,
+		PublishedAt:   post.PublishedAt,
+		Version:       post.Version,
 		Content:       post.Content,
+		WordCount:     post.WordCount,
+		ReadingTime:   post.ReadingTime,
+		TOC:           post.TOC,
+		CodeTokens:    post.CodeTokens,
 		Views:         post.Views,
 		CommentsCount: post.CommentsCount,
 		CreatedAt:     post.CreatedAt,
@@ -62,6 +99,10 @@ func NewPostResponse(post *entities.Post) *PostResponse {
 		response.User = NewUserResponse(&post.User)
 	}
 
+	for i := range post.Authors {
+		response.Authors = append(response.Authors, NewPostAuthorResponse(&post.Authors[i]))
+	}
+
 	for _, category := range post.Categories {
 		response.Categories = append(response.Categories, NewCategoryResponse(&category))
 	}
@@ -0,0 +1,57 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/pdhoang91/blog/internal/entities"
+	uuid "github.com/satori/go.uuid"
+)
+
+// InvitePostAuthorRequest invites a user to a post as co-author or reviewer.
+type InvitePostAuthorRequest struct {
+	Username string `json:"username" binding:"required"`
+	Role     string `json:"role" binding:"required,oneof=co_author reviewer"`
+}
+
+type PostAuthorResponse struct {
+	User        *UserResponse `json:"user,omitempty"`
+	Role        string        `json:"role"`
+	Status      string        `json:"status"`
+	InvitedBy   *uuid.UUID    `json:"invited_by,omitempty"`
+	CreatedAt   time.Time     `json:"created_at"`
+	RespondedAt *time.Time    `json:"responded_at,omitempty"`
+}
+
+func NewPostAuthorResponse(author *entities.PostAuthor) *PostAuthorResponse {
+	resp := &PostAuthorResponse{
+		Role:        author.Role,
+		Status:      author.Status,
+		InvitedBy:   author.InvitedBy,
+		CreatedAt:   author.CreatedAt,
+		RespondedAt: author.RespondedAt,
+	}
+	if author.User.ID != uuid.Nil {
+		resp.User = NewUserResponse(&author.User)
+	}
+	return resp
+}
+
+// PostInvitationResponse is a pending invitation as the invitee sees it.
+type PostInvitationResponse struct {
+	Post      *PostResponse `json:"post"`
+	Role      string        `json:"role"`
+	InvitedBy *uuid.UUID    `json:"invited_by,omitempty"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+func NewPostInvitationResponse(author *entities.PostAuthor) *PostInvitationResponse {
+	resp := &PostInvitationResponse{
+		Role:      author.Role,
+		InvitedBy: author.InvitedBy,
+		CreatedAt: author.CreatedAt,
+	}
+	if author.Post != nil {
+		resp.Post = NewPostResponse(author.Post)
+	}
+	return resp
+}
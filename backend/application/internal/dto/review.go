@@ -0,0 +1,45 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/pdhoang91/blog/internal/entities"
+)
+
+// Review decisions.
+const (
+	ReviewApprove        = "approve"
+	ReviewRequestChanges = "request_changes"
+)
+
+type SubmitForReviewRequest struct {
+	Comment string `json:"comment,omitempty" binding:"max=2000"`
+}
+
+// ReviewPostRequest decides on a post in review. A comment is required when
+// requesting changes.
+type ReviewPostRequest struct {
+	Decision string `json:"decision" binding:"required,oneof=approve request_changes"`
+	Comment  string `json:"comment,omitempty" binding:"max=2000"`
+}
+
+type PostStatusEventResponse struct {
+	FromStatus string        `json:"from_status,omitempty"`
+	ToStatus   string        `json:"to_status"`
+	Actor      *UserResponse `json:"actor,omitempty"`
+	Comment    string        `json:"comment,omitempty"`
+	CreatedAt  time.Time     `json:"created_at"`
+}
+
+func NewPostStatusEventResponse(event *entities.PostStatusEvent) *PostStatusEventResponse {
+	resp := &PostStatusEventResponse{
+		FromStatus: event.FromStatus,
+		ToStatus:   event.ToStatus,
+		Comment:    event.Comment,
+		CreatedAt:  event.CreatedAt,
+	}
+	if event.Actor != nil {
+		resp.Actor = NewUserResponse(event.Actor)
+	}
+	return resp
+}
@@ -0,0 +1,55 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/pdhoang91/blog/internal/entities"
+	"github.com/pdhoang91/blog/pkg/textdiff"
+	uuid "github.com/satori/go.uuid"
+)
+
+type PostRevisionResponse struct {
+	ID             uuid.UUID       `json:"id"`
+	PostID         uuid.UUID       `json:"post_id"`
+	RevisionNumber int             `json:"revision_number"`
+	Title          string          `json:"title"`
+	Excerpt        string          `json:"excerpt"`
+	Content        json.RawMessage `json:"content,omitempty"`
+	Author         *UserResponse   `json:"author,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+func NewPostRevisionResponse(rev *entities.PostRevision) *PostRevisionResponse {
+	resp := &PostRevisionResponse{
+		ID:             rev.ID,
+		PostID:         rev.PostID,
+		RevisionNumber: rev.RevisionNumber,
+		Title:          rev.Title,
+		Excerpt:        rev.Excerpt,
+		Content:        rev.Content,
+		CreatedAt:      rev.CreatedAt,
+	}
+	if rev.Author != nil && rev.Author.ID != uuid.Nil {
+		resp.Author = NewUserResponse(rev.Author)
+	}
+	return resp
+}
+
+// RestoreRevisionRequest is the optional body of a revision restore.
+type RestoreRevisionRequest struct {
+	// Version is the post version the restore replaces; If-Match may carry it instead.
+	Version *int `json:"version,omitempty"`
+}
+
+// RevisionDiffResponse compares two revisions word by word. From 0 stands for
+// an empty post, so diffing revision 1 against it shows the whole first draft.
+type RevisionDiffResponse struct {
+	From         int                `json:"from"`
+	To           int                `json:"to"`
+	Title        []textdiff.Segment `json:"title"`
+	Excerpt      []textdiff.Segment `json:"excerpt"`
+	Content      []textdiff.Segment `json:"content"`
+	WordsAdded   int                `json:"words_added"`
+	WordsRemoved int                `json:"words_removed"`
+}
@@ -0,0 +1,26 @@
+package dto
+
+import "github.com/pdhoang91/blog/constants"
+
+type ChangeRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+type RoleResponse struct {
+	Name        string   `json:"name"`
+	DisplayName string   `json:"display_name"`
+	Permissions []string `json:"permissions"`
+}
+
+func NewRoleResponse(role constants.UserRole) *RoleResponse {
+	perms := constants.RolePermissions(role)
+	names := make([]string, 0, len(perms))
+	for _, perm := range perms {
+		names = append(names, string(perm))
+	}
+	return &RoleResponse{
+		Name:        string(role),
+		DisplayName: constants.GetRoleDisplayName(role),
+		Permissions: names,
+	}
+}
@@ -0,0 +1,72 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/pdhoang91/blog/internal/entities"
+	uuid "github.com/satori/go.uuid"
+)
+
+type CreateSeriesRequest struct {
+	Title       string `json:"title" binding:"required,min=3,max=200"`
+	Description string `json:"description,omitempty" binding:"max=2000"`
+	// PostIDs lists the parts in reading order.
+	PostIDs []uuid.UUID `json:"post_ids,omitempty"`
+}
+
+// UpdateSeriesRequest leaves fields that are omitted unchanged. PostIDs, when
+// given, replaces the parts and their order.
+type UpdateSeriesRequest struct {
+	Title       string       `json:"title,omitempty" binding:"omitempty,min=3,max=200"`
+	Description *string      `json:"description,omitempty" binding:"omitempty,max=2000"`
+	PostIDs     *[]uuid.UUID `json:"post_ids,omitempty"`
+}
+
+type SeriesResponse struct {
+	ID          uuid.UUID       `json:"id"`
+	Title       string          `json:"title"`
+	Slug        string          `json:"slug"`
+	Description string          `json:"description"`
+	User        *UserResponse   `json:"user,omitempty"`
+	Posts       []*PostResponse `json:"posts"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+func NewSeriesResponse(series *entities.Series, posts []*entities.Post) *SeriesResponse {
+	resp := &SeriesResponse{
+		ID:          series.ID,
+		Title:       series.Title,
+		Slug:        series.Slug,
+		Description: series.Description,
+		Posts:       make([]*PostResponse, 0, len(posts)),
+		CreatedAt:   series.CreatedAt,
+		UpdatedAt:   series.UpdatedAt,
+	}
+	if series.User.ID != uuid.Nil {
+		resp.User = NewUserResponse(&series.User)
+	}
+	for _, post := range posts {
+		resp.Posts = append(resp.Posts, NewPostResponse(post))
+	}
+	return resp
+}
+
+// SeriesNavResponse places a post within its series for previous/next links.
+// Position and Total count only the parts readers can see.
+type SeriesNavResponse struct {
+	ID       uuid.UUID           `json:"id"`
+	Title    string              `json:"title"`
+	Slug     string              `json:"slug"`
+	Position int                 `json:"position"`
+	Total    int                 `json:"total"`
+	Previous *SeriesPartResponse `json:"previous,omitempty"`
+	Next     *SeriesPartResponse `json:"next,omitempty"`
+}
+
+type SeriesPartResponse struct {
+	ID       uuid.UUID `json:"id"`
+	Title    string    `json:"title"`
+	Slug     string    `json:"slug"`
+	Position int       `json:"position"`
+}
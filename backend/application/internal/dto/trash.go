@@ -0,0 +1,84 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/pdhoang91/blog/internal/entities"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Kinds of items in the trash, as used in trash URLs.
+const (
+	TrashPosts    = "posts"
+	TrashComments = "comments"
+	TrashReplies  = "replies"
+)
+
+type TrashListRequest struct {
+	PaginationRequest
+	Type string `form:"type" binding:"omitempty,oneof=posts comments replies"`
+	// All lists everyone's deleted items instead of only the caller's. It needs
+	// the permission to manage other people's posts or comments.
+	All bool `form:"all"`
+}
+
+// TrashItemResponse is a deleted post, comment or reply. Posts carry their
+// title, comments and replies their content.
+type TrashItemResponse struct {
+	Type      string        `json:"type"`
+	ID        uuid.UUID     `json:"id"`
+	Title     string        `json:"title,omitempty"`
+	Slug      string        `json:"slug,omitempty"`
+	Content   string        `json:"content,omitempty"`
+	PostID    *uuid.UUID    `json:"post_id,omitempty"`
+	CommentID *uuid.UUID    `json:"comment_id,omitempty"`
+	User      *UserResponse `json:"user,omitempty"`
+	DeletedAt time.Time     `json:"deleted_at"`
+	PurgeAt   time.Time     `json:"purge_at"`
+}
+
+func NewTrashPostResponse(post *entities.Post, retention time.Duration) *TrashItemResponse {
+	resp := &TrashItemResponse{
+		Type:      TrashPosts,
+		ID:        post.ID,
+		Title:     post.Title,
+		Slug:      post.Slug,
+		DeletedAt: post.DeletedAt.Time,
+		PurgeAt:   post.DeletedAt.Time.Add(retention),
+	}
+	if post.User.ID != uuid.Nil {
+		resp.User = NewUserResponse(&post.User)
+	}
+	return resp
+}
+
+func NewTrashCommentResponse(comment *entities.Comment, retention time.Duration) *TrashItemResponse {
+	resp := &TrashItemResponse{
+		Type:      TrashComments,
+		ID:        comment.ID,
+		Content:   comment.Content,
+		PostID:    &comment.PostID,
+		DeletedAt: comment.DeletedAt.Time,
+		PurgeAt:   comment.DeletedAt.Time.Add(retention),
+	}
+	if comment.User.ID != uuid.Nil {
+		resp.User = NewUserResponse(&comment.User)
+	}
+	return resp
+}
+
+func NewTrashReplyResponse(reply *entities.Reply, retention time.Duration) *TrashItemResponse {
+	resp := &TrashItemResponse{
+		Type:      TrashReplies,
+		ID:        reply.ID,
+		Content:   reply.Content,
+		PostID:    &reply.PostID,
+		CommentID: &reply.CommentID,
+		DeletedAt: reply.DeletedAt.Time,
+		PurgeAt:   reply.DeletedAt.Time.Add(retention),
+	}
+	if reply.User.ID != uuid.Nil {
+		resp.User = NewUserResponse(&reply.User)
+	}
+	return resp
+}
@@ -63,8 +63,57 @@ func NewUserResponse(user *entities.User) *UserResponse {
 	}
 }
 
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=6"`
+}
+
 // Auth responses
+
+// OAuthCallbackResult is the outcome of an OAuth callback: either a new login
+// or, for linking flows, the provider that was linked to the current account.
+type OAuthCallbackResult struct {
+	Login          *LoginResponse
+	LinkedProvider string
+}
+
+type IdentityResponse struct {
+	Provider    string     `json:"provider"`
+	DisplayName string     `json:"display_name"`
+	Linked      bool       `json:"linked"`
+	Email       string     `json:"email,omitempty"`
+	LinkedAt    *time.Time `json:"linked_at,omitempty"`
+}
+
+type OAuthProviderResponse struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+}
+
+type IdentitiesResponse struct {
+	HasPassword bool               `json:"has_password"`
+	Identities  []IdentityResponse `json:"identities"`
+}
+
+// LoginResponse is either a token pair or, when the account has two-factor
+// authentication enabled, an MFA challenge to redeem at /auth/mfa/verify.
 type LoginResponse struct {
-	Token string        `json:"token"`
-	User  *UserResponse `json:"user"`
+	Token        string        `json:"token,omitempty"`
+	RefreshToken string        `json:"refresh_token,omitempty"`
+	ExpiresIn    int64         `json:"expires_in,omitempty"` // access token lifetime in seconds
+	User         *UserResponse `json:"user,omitempty"`
+	MFARequired  bool          `json:"mfa_required,omitempty"`
+	MFAToken     string        `json:"mfa_token,omitempty"`
 }
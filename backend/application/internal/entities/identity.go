@@ -0,0 +1,22 @@
+package entities
+
+import (
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+// UserIdentity links a user to an account at an external sign-in provider.
+type UserIdentity struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+	Provider  string    `gorm:"size:50;not null" json:"provider"`
+	Subject   string    `gorm:"size:255;not null" json:"subject"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (UserIdentity) TableName() string {
+	return "user_identities"
+}
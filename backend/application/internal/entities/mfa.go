@@ -0,0 +1,57 @@
+package entities
+
+import (
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+// ActionMFALogin is the purpose of the challenge token handed out between a
+// successful password check and the second factor.
+const ActionMFALogin = "mfa_login"
+
+// UserMFA holds a user's TOTP secret. Enrollment is pending until EnabledAt is set
+// by confirming a first code.
+type UserMFA struct {
+	UserID       uuid.UUID  `gorm:"type:uuid;primaryKey" json:"user_id"`
+	Secret       string     `gorm:"size:64;not null" json:"-"`
+	EnabledAt    *time.Time `json:"enabled_at,omitempty"`
+	LastUsedStep int64      `gorm:"not null;default:0" json:"-"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+func (UserMFA) TableName() string {
+	return "user_mfa"
+}
+
+// IsEnabled reports whether logins must present a second factor.
+func (m *UserMFA) IsEnabled() bool {
+	return m != nil && m.EnabledAt != nil
+}
+
+// MFARecoveryCode is a one-time code that stands in for a TOTP code.
+// Only the SHA-256 hash of the code is stored.
+type MFARecoveryCode struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID    uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	CodeHash  string     `gorm:"size:64;not null" json:"-"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func (MFARecoveryCode) TableName() string {
+	return "mfa_recovery_codes"
+}
+
+// MFARolePolicy records whether users with Role must use two-factor authentication.
+type MFARolePolicy struct {
+	Role      string     `gorm:"size:50;primaryKey" json:"role"`
+	Required  bool       `gorm:"not null;default:false" json:"required"`
+	UpdatedBy *uuid.UUID `gorm:"type:uuid" json:"updated_by,omitempty"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+func (MFARolePolicy) TableName() string {
+	return "mfa_role_policies"
+}
@@ -8,34 +8,85 @@ import (
 	"gorm.io/gorm"
 )
 
+// Post statuses. Only published posts are listed publicly; unlisted posts are
+// reachable by direct link, and scheduled posts go live at PublishedAt. Posts
+// by authors without review rights pass through in_review and approved (or
+// changes_requested) before they can be published.
+const (
+	PostStatusDraft            = "draft"
+	PostStatusInReview         = "in_review"
+	PostStatusChangesRequested = "changes_requested"
+	PostStatusApproved         = "approved"
+	PostStatusScheduled        = "scheduled"
+	PostStatusPublished        = "published"
+	PostStatusUnlisted         = "unlisted"
+	PostStatusArchived         = "archived"
+)
+
+// IsValidPostStatus reports whether status is one of the post statuses.
+func IsValidPostStatus(status string) bool {
+	switch status {
+	case PostStatusDraft, PostStatusInReview, PostStatusChangesRequested, PostStatusApproved,
+		PostStatusScheduled, PostStatusPublished, PostStatusUnlisted, PostStatusArchived:
+		return true
+	}
+	return false
+}
+
+// IsLiveStatus reports whether posts in status are, or are about to be, visible
+// to readers.
+func IsLiveStatus(status string) bool {
+	switch status {
+	case PostStatusScheduled, PostStatusPublished, PostStatusUnlisted:
+		return true
+	}
+	return false
+}
+
 // Post represents a blog post entity in the domain
 type Post struct {
-	ID              uuid.UUID       `gorm:"type:uuid;primaryKey" json:"id"`
-	Title           string          `json:"title"`
-	Slug            string          `json:"slug"`
-	Excerpt         string          `json:"excerpt"`
-	CoverImage      string          `json:"cover_image"`
-	UserID          uuid.UUID       `json:"user_id"`
-	CreatedAt       time.Time       `json:"created_at"`
-	UpdatedAt       time.Time       `json:"updated_at"`
-	DeletedAt       gorm.DeletedAt  `gorm:"index" json:"deleted_at,omitempty"` // Soft delete field
-	Views           uint64          `json:"views"`
-	EngagementScore float64         `gorm:"default:0" json:"-"`
-	Content         json.RawMessage `gorm:"-" json:"content,omitempty"`
-	CommentsCount uint64 `gorm:"column:comment_count;default:0" json:"comments_count"`
+	ID              uuid.UUID      `gorm:"type:uuid;primaryKey" json:"id"`
+	Title           string         `json:"title"`
+	Slug            string         `json:"slug"`
+	SlugPinned      bool           `gorm:"not null;default:false" json:"slug_pinned"` // Slug set by the author, kept on title changes
+	Excerpt         string         `json:"excerpt"`
+	CoverImage      string         `json:"cover_image"`
+	UserID          uuid.UUID      `json:"user_id"`
+	Status          string         `gorm:"size:20;default:draft" json:"status"`
+	PublishedAt     *time.Time     `json:"published_at,omitempty"`
+	Version         int            `gorm:"not null;default:1" json:"version"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"` // Soft delete field
+	Views           uint64         `json:"views"`
+	EngagementScore float64        `gorm:"default:0" json:"-"`
+	// WordCount, ReadingTime (in minutes) and TOC are derived from the
+	// content whenever it is saved.
+	WordCount     int             `gorm:"not null;default:0" json:"word_count"`
+	ReadingTime   int             `gorm:"not null;default:0" json:"reading_time"`
+	TOC           json.RawMessage `gorm:"column:toc;type:jsonb" json:"toc,omitempty"`
+	Content       json.RawMessage `gorm:"-" json:"content,omitempty"`
+	CodeTokens    json.RawMessage `gorm:"-" json:"code_tokens,omitempty"` // highlighted code, for display
+	CommentsCount uint64          `gorm:"column:comment_count;default:0" json:"comments_count"`
 
 	// Relationships
-	User        User        `gorm:"foreignKey:UserID" json:"user"`
-	Comments    []Comment   `gorm:"foreignKey:PostID" json:"comments"`
-	Categories  []Category  `gorm:"many2many:post_categories" json:"categories"`
-	Tags        []Tag       `gorm:"many2many:post_tags" json:"tags"`
-	PostContent PostContent `gorm:"foreignKey:PostID" json:"post_content"`
+	User        User         `gorm:"foreignKey:UserID" json:"user"`
+	Comments    []Comment    `gorm:"foreignKey:PostID" json:"comments"`
+	Categories  []Category   `gorm:"many2many:post_categories" json:"categories"`
+	Tags        []Tag        `gorm:"many2many:post_tags" json:"tags"`
+	Authors     []PostAuthor `gorm:"foreignKey:PostID" json:"authors,omitempty"`
+	PostContent PostContent  `gorm:"foreignKey:PostID" json:"post_content"`
 }
 
 func (Post) TableName() string {
 	return "posts"
 }
 
+// IsPubliclyVisible reports whether anonymous readers may open the post.
+func (p *Post) IsPubliclyVisible() bool {
+	return p.Status == PostStatusPublished || p.Status == PostStatusUnlisted
+}
+
 // PostCategory represents the many-to-many relationship between posts and categories
 type PostCategory struct {
 	PostID     uuid.UUID `gorm:"type:uuid;primaryKey"`
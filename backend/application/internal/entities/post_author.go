@@ -0,0 +1,45 @@
+package entities
+
+import (
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+// Roles a user can hold on a post. The owner is the post's creator; co-authors
+// edit alongside them and reviewers may read unpublished versions.
+const (
+	PostAuthorOwner    = "owner"
+	PostAuthorCoAuthor = "co_author"
+	PostAuthorReviewer = "reviewer"
+)
+
+// Invitation states of a post author. The owner is always accepted.
+const (
+	InvitationPending  = "pending"
+	InvitationAccepted = "accepted"
+	InvitationDeclined = "declined"
+)
+
+// PostAuthor links a user to a post they write or review.
+type PostAuthor struct {
+	PostID      uuid.UUID  `gorm:"type:uuid;primaryKey" json:"post_id"`
+	UserID      uuid.UUID  `gorm:"type:uuid;primaryKey" json:"user_id"`
+	Role        string     `gorm:"size:20;not null" json:"role"`
+	Status      string     `gorm:"size:20;not null;default:pending" json:"status"`
+	InvitedBy   *uuid.UUID `gorm:"type:uuid" json:"invited_by,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	RespondedAt *time.Time `json:"responded_at,omitempty"`
+
+	User User  `gorm:"foreignKey:UserID" json:"user"`
+	Post *Post `gorm:"foreignKey:PostID" json:"post,omitempty"`
+}
+
+func (PostAuthor) TableName() string {
+	return "post_authors"
+}
+
+// IsByline reports whether the author is credited publicly on the post.
+func (a *PostAuthor) IsByline() bool {
+	return a.Status == InvitationAccepted && (a.Role == PostAuthorOwner || a.Role == PostAuthorCoAuthor)
+}
@@ -0,0 +1,26 @@
+package entities
+
+import (
+	"encoding/json"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+// PostAutosave is the editor's working copy of a post, kept apart from the
+// saved content until the post is updated. Version counts autosaves;
+// PostVersion is the post version the copy was last saved against.
+type PostAutosave struct {
+	PostID      uuid.UUID       `gorm:"type:uuid;primaryKey" json:"post_id"`
+	Title       string          `json:"title"`
+	Excerpt     string          `json:"excerpt"`
+	Content     json.RawMessage `gorm:"type:jsonb" json:"content,omitempty"`
+	Version     int             `gorm:"not null;default:1" json:"version"`
+	PostVersion int             `gorm:"not null" json:"post_version"`
+	UpdatedBy   *uuid.UUID      `gorm:"type:uuid" json:"updated_by,omitempty"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+func (PostAutosave) TableName() string {
+	return "post_autosaves"
+}
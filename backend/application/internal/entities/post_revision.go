@@ -0,0 +1,27 @@
+package entities
+
+import (
+	"encoding/json"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+// PostRevision is a snapshot of a post's title, excerpt and content taken each
+// time a save changed them. Revisions are numbered per post starting at 1.
+type PostRevision struct {
+	ID             uuid.UUID       `gorm:"type:uuid;primaryKey" json:"id"`
+	PostID         uuid.UUID       `gorm:"type:uuid;not null" json:"post_id"`
+	RevisionNumber int             `gorm:"not null" json:"revision_number"`
+	Title          string          `json:"title"`
+	Excerpt        string          `json:"excerpt"`
+	Content        json.RawMessage `gorm:"type:jsonb" json:"content,omitempty"`
+	AuthorID       *uuid.UUID      `gorm:"type:uuid" json:"author_id,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+
+	Author *User `gorm:"foreignKey:AuthorID" json:"author,omitempty"`
+}
+
+func (PostRevision) TableName() string {
+	return "post_revisions"
+}
@@ -0,0 +1,19 @@
+package entities
+
+import (
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+// PostSlugHistory remembers a slug a post used to have, so links to it can be
+// redirected to the current one.
+type PostSlugHistory struct {
+	Slug      string    `gorm:"primaryKey;size:255" json:"slug"`
+	PostID    uuid.UUID `gorm:"type:uuid;not null" json:"post_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (PostSlugHistory) TableName() string {
+	return "post_slug_history"
+}
@@ -0,0 +1,26 @@
+package entities
+
+import (
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+// PostStatusEvent records one status change of a post. FromStatus is empty for
+// the status a post was created with; ActorID is nil for changes made by the
+// scheduler.
+type PostStatusEvent struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	PostID     uuid.UUID  `gorm:"type:uuid;not null" json:"post_id"`
+	FromStatus string     `gorm:"size:20;not null" json:"from_status"`
+	ToStatus   string     `gorm:"size:20;not null" json:"to_status"`
+	ActorID    *uuid.UUID `gorm:"type:uuid" json:"actor_id,omitempty"`
+	Comment    string     `json:"comment"`
+	CreatedAt  time.Time  `json:"created_at"`
+
+	Actor *User `gorm:"foreignKey:ActorID" json:"actor,omitempty"`
+}
+
+func (PostStatusEvent) TableName() string {
+	return "post_status_events"
+}
@@ -0,0 +1,35 @@
+package entities
+
+import (
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+// Series is an ordered collection of posts, such as a multi-part tutorial.
+type Series struct {
+	ID          uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID      uuid.UUID `gorm:"type:uuid;not null" json:"user_id"`
+	Title       string    `gorm:"size:200;not null" json:"title"`
+	Slug        string    `gorm:"size:255;uniqueIndex;not null" json:"slug"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	User User `gorm:"foreignKey:UserID" json:"user"`
+}
+
+func (Series) TableName() string {
+	return "series"
+}
+
+// SeriesPost places a post in a series. A post belongs to at most one series.
+type SeriesPost struct {
+	PostID   uuid.UUID `gorm:"type:uuid;primaryKey" json:"post_id"`
+	SeriesID uuid.UUID `gorm:"type:uuid;not null" json:"series_id"`
+	Position int       `gorm:"not null" json:"position"`
+}
+
+func (SeriesPost) TableName() string {
+	return "series_posts"
+}
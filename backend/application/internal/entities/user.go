@@ -17,14 +17,15 @@ type User struct {
 	Name                   string    `json:"name"`
 	Username               string    `json:"username"`
 	Password               string    `json:"-"`
-	GoogleID               string    `json:"google_id"`
 	GooglePictureURL       string    `json:"google_picture_url"`
 	AvatarURL              string    `json:"avatar_url"`
 	Bio                    string    `json:"bio"`
 	Phone                  string    `json:"phone"`
 	Dob                    string    `json:"dob"`
-	Role                   UserRole  `json:"role" gorm:"default:'user'"`
+	Role                   UserRole  `json:"role" gorm:"default:'author'"`
 	EmailVerified bool      `json:"email_verified" gorm:"default:false"`
+	FailedLoginCount int        `json:"-" gorm:"default:0"`
+	LockedUntil      *time.Time `json:"-"`
 	CreatedAt     time.Time `json:"created_at"`
 	UpdatedAt              time.Time `json:"updated_at"`
 }
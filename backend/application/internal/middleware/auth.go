@@ -6,12 +6,40 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/pdhoang91/blog/constants"
+	"github.com/pdhoang91/blog/internal/apperror"
 	"github.com/pdhoang91/blog/internal/dto"
+	"github.com/pdhoang91/blog/internal/entities"
 	pkgjwt "github.com/pdhoang91/blog/pkg/jwt"
 )
 
-// AuthMiddleware validates JWT tokens
-func AuthMiddleware() gin.HandlerFunc {
+// SessionValidator reports whether the login session behind an access token is
+// still active and returns the user's current role, so role changes apply
+// before the access token expires.
+type SessionValidator interface {
+	ValidateSession(sessionID string) (string, error)
+}
+
+// APITokenAuthenticator resolves personal access tokens.
+type APITokenAuthenticator interface {
+	AuthenticateAPIToken(token string) (*dto.APITokenPrincipal, error)
+}
+
+// MFAEnforcer decides whether a session satisfies the two-factor policy of its role.
+type MFAEnforcer interface {
+	CheckMFA(sessionID, role string) error
+}
+
+// Authenticator is everything AuthMiddleware needs to accept both credential types.
+type Authenticator interface {
+	SessionValidator
+	APITokenAuthenticator
+}
+
+// AuthMiddleware validates JWT tokens and personal access tokens, rejecting
+// JWTs whose session has been revoked. API token requests carry "scopes" in
+// the context; see RequireScope and SessionOnly.
+func AuthMiddleware(auth Authenticator) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		tokenString := c.GetHeader("Authorization")
 		if tokenString == "" {
@@ -27,6 +55,26 @@ func AuthMiddleware() gin.HandlerFunc {
 		// Bearer token format
 		tokenString = strings.TrimPrefix(tokenString, "Bearer ")
 
+		if strings.HasPrefix(tokenString, entities.APITokenPrefix) {
+			principal, err := auth.AuthenticateAPIToken(tokenString)
+			if err != nil {
+				c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
+					Status:  "error",
+					Code:    http.StatusUnauthorized,
+					Message: "Invalid API token",
+				})
+				c.Abort()
+				return
+			}
+
+			c.Set("userID", principal.UserID.String())
+			c.Set("role", principal.Role)
+			c.Set("apiTokenID", principal.TokenID.String())
+			c.Set("scopes", principal.Scopes)
+			c.Next()
+			return
+		}
+
 		token, err := pkgjwt.VerifyJWT(tokenString)
 		if err != nil || !token.Valid {
 			c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
@@ -41,8 +89,8 @@ func AuthMiddleware() gin.HandlerFunc {
 		// Extract claims
 		if claims, ok := token.Claims.(jwt.MapClaims); ok {
 			userID, userIDExists := claims["user_id"]
-			role, roleExists := claims["role"]
-			if !userIDExists || !roleExists {
+			sessionID, _ := claims["sid"].(string)
+			if !userIDExists || sessionID == "" {
 				c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
 					Status:  "error",
 					Code:    http.StatusUnauthorized,
@@ -52,7 +100,19 @@ func AuthMiddleware() gin.HandlerFunc {
 				return
 			}
 
+			role, err := auth.ValidateSession(sessionID)
+			if err != nil {
+				c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
+					Status:  "error",
+					Code:    http.StatusUnauthorized,
+					Message: "Session has been revoked",
+				})
+				c.Abort()
+				return
+			}
+
 			c.Set("userID", userID)
+			c.Set("sessionID", sessionID)
 			c.Set("role", role)
 			c.Set("exp", claims["exp"])
 			c.Set("iat", claims["iat"])
@@ -70,30 +130,82 @@ func AuthMiddleware() gin.HandlerFunc {
 	}
 }
 
-// AdminMiddleware checks if user has admin role
-func AdminMiddleware() gin.HandlerFunc {
+// RequirePermission rejects callers whose role does not grant perm.
+// It must run after AuthMiddleware.
+func RequirePermission(perm constants.Permission) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		role, exists := c.Get("role")
-		if !exists {
-			c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
+		role, _ := c.Get("role")
+		roleName, _ := role.(string)
+		if !constants.HasPermission(constants.UserRole(roleName), perm) {
+			c.JSON(http.StatusForbidden, dto.ErrorResponse{
 				Status:  "error",
-				Code:    http.StatusUnauthorized,
-				Message: "User role not found",
+				Code:    http.StatusForbidden,
+				Message: "Your role does not allow this action",
 			})
 			c.Abort()
 			return
 		}
+		c.Next()
+	}
+}
 
-		if role != "admin" {
+// RequireMFA rejects sessions that have not completed two-factor authentication
+// when the caller's role requires it. It must run after AuthMiddleware and is
+// meant for session-only routes.
+func RequireMFA(enforcer MFAEnforcer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		sessionID, _ := c.Get("sessionID")
+		role, _ := c.Get("role")
+		sid, _ := sessionID.(string)
+		roleName, _ := role.(string)
+
+		if err := enforcer.CheckMFA(sid, roleName); err != nil {
+			c.JSON(apperror.HTTPCode(err), dto.ErrorResponse{
+				Status:  "error",
+				Code:    apperror.HTTPCode(err),
+				Message: apperror.UserMessage(err),
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireScope restricts API token requests to tokens granted scope.
+// Requests authenticated with a login session are not affected.
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if scopes, ok := c.Get("scopes"); ok {
+			granted, _ := scopes.([]string)
+			if !constants.ScopeSatisfies(granted, scope) {
+				c.JSON(http.StatusForbidden, dto.ErrorResponse{
+					Status:  "error",
+					Code:    http.StatusForbidden,
+					Message: "API token is missing the " + scope + " scope",
+				})
+				c.Abort()
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+// SessionOnly rejects API token requests, for account and security settings
+// that should only be changed by a signed-in user.
+func SessionOnly() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := c.Get("apiTokenID"); ok {
 			c.JSON(http.StatusForbidden, dto.ErrorResponse{
 				Status:  "error",
 				Code:    http.StatusForbidden,
-				Message: "Admin access required",
+				Message: "This endpoint cannot be used with an API token",
 			})
 			c.Abort()
 			return
 		}
-
 		c.Next()
 	}
 }
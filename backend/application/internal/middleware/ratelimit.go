@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pdhoang91/blog/internal/dto"
+	"github.com/pdhoang91/blog/pkg/ratelimit"
+)
+
+// KeyFunc picks the identity a rate limit is counted against.
+type KeyFunc func(c *gin.Context) string
+
+// KeyByIP counts requests per client IP.
+func KeyByIP(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// KeyByUser counts requests per authenticated user, falling back to the client
+// IP on routes where authentication is optional.
+func KeyByUser(c *gin.Context) string {
+	if userID, ok := c.Get("userID"); ok {
+		if id, ok := userID.(string); ok && id != "" {
+			return "user:" + id
+		}
+	}
+	return KeyByIP(c)
+}
+
+// RateLimit allows limit requests per key within the named group; routes that
+// share a name share buckets. Limiter failures are logged and the request is let
+// through, so an unavailable Redis does not take the API down.
+func RateLimit(limiter ratelimit.Limiter, name string, limit ratelimit.Limit, keyFn KeyFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		res, err := limiter.Allow(c.Request.Context(), "rl:"+name+":"+keyFn(c), limit)
+		if err != nil {
+			log.Printf("ratelimit: %s: %v", name, err)
+			c.Next()
+			return
+		}
+
+		c.Header("X-RateLimit-Limit", strconv.Itoa(res.Limit))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		if !res.Allowed {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(res.RetryAfter.Seconds()))))
+			c.JSON(http.StatusTooManyRequests, dto.ErrorResponse{
+				Status:  "error",
+				Code:    http.StatusTooManyRequests,
+				Message: "Too many requests, please try again later",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
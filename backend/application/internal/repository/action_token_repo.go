@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/pdhoang91/blog/internal/entities"
+	uuid "github.com/satori/go.uuid"
+	"gorm.io/gorm"
+)
+
+type actionTokenRepo struct{ db *gorm.DB }
+
+func NewActionTokenRepository(db *gorm.DB) ActionTokenRepository { return &actionTokenRepo{db: db} }
+
+func (r *actionTokenRepo) WithTx(tx *gorm.DB) ActionTokenRepository { return &actionTokenRepo{db: tx} }
+
+func (r *actionTokenRepo) Create(token *entities.ActionToken) error {
+	return r.db.Create(token).Error
+}
+
+// Consume atomically marks an unused, unexpired token as used. It returns false
+// when the token is unknown, expired or was already redeemed.
+func (r *actionTokenRepo) Consume(id uuid.UUID, purpose string) (bool, error) {
+	now := time.Now()
+	res := r.db.Model(&entities.ActionToken{}).
+		Where("id = ? AND purpose = ? AND used_at IS NULL AND expires_at > ?", id, purpose, now).
+		Update("used_at", now)
+	return res.RowsAffected == 1, res.Error
+}
+
+// InvalidateForUser marks every outstanding token of the given purpose as used,
+// so only the most recently issued link stays valid.
+func (r *actionTokenRepo) InvalidateForUser(userID uuid.UUID, purpose string) error {
+	return r.db.Model(&entities.ActionToken{}).
+		Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).
+		Update("used_at", time.Now()).Error
+}
+
+func (r *actionTokenRepo) DeleteExpired(before time.Time) (int64, error) {
+	res := r.db.Where("expires_at < ?", before).Delete(&entities.ActionToken{})
+	return res.RowsAffected, res.Error
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/pdhoang91/blog/internal/entities"
+	uuid "github.com/satori/go.uuid"
+	"gorm.io/gorm"
+)
+
+type apiTokenRepo struct{ db *gorm.DB }
+
+func NewAPITokenRepository(db *gorm.DB) APITokenRepository { return &apiTokenRepo{db: db} }
+
+func (r *apiTokenRepo) Create(token *entities.APIToken) error {
+	return r.db.Create(token).Error
+}
+
+func (r *apiTokenRepo) FindByID(id uuid.UUID) (*entities.APIToken, error) {
+	var token entities.APIToken
+	err := r.db.Where("id = ?", id).First(&token).Error
+	return &token, err
+}
+
+func (r *apiTokenRepo) FindByHash(hash string) (*entities.APIToken, error) {
+	var token entities.APIToken
+	err := r.db.Where("token_hash = ?", hash).First(&token).Error
+	return &token, err
+}
+
+// FindByUserID lists the user's tokens that have not been revoked, newest first.
+func (r *apiTokenRepo) FindByUserID(userID uuid.UUID) ([]*entities.APIToken, error) {
+	var tokens []*entities.APIToken
+	err := r.db.Where("user_id = ? AND revoked_at IS NULL", userID).
+		Order("created_at DESC").
+		Find(&tokens).Error
+	return tokens, err
+}
+
+func (r *apiTokenRepo) CountActiveByUserID(userID uuid.UUID, now time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&entities.APIToken{}).
+		Where("user_id = ? AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?)", userID, now).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *apiTokenRepo) Revoke(id uuid.UUID) error {
+	return r.db.Model(&entities.APIToken{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RevokeAllByUserID revokes every active token of the user and returns how many.
+func (r *apiTokenRepo) RevokeAllByUserID(userID uuid.UUID) (int64, error) {
+	result := r.db.Model(&entities.APIToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now())
+	return result.RowsAffected, result.Error
+}
+
+func (r *apiTokenRepo) TouchLastUsed(id uuid.UUID, at time.Time) error {
+	return r.db.Model(&entities.APIToken{}).
+		Where("id = ?", id).
+		UpdateColumn("last_used_at", at).Error
+}
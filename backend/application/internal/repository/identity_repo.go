@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"github.com/pdhoang91/blog/internal/entities"
+	uuid "github.com/satori/go.uuid"
+	"gorm.io/gorm"
+)
+
+type identityRepo struct{ db *gorm.DB }
+
+func NewIdentityRepository(db *gorm.DB) IdentityRepository { return &identityRepo{db: db} }
+
+func (r *identityRepo) WithTx(tx *gorm.DB) IdentityRepository { return &identityRepo{db: tx} }
+
+func (r *identityRepo) Create(identity *entities.UserIdentity) error {
+	return r.db.Create(identity).Error
+}
+
+func (r *identityRepo) Update(identity *entities.UserIdentity) error {
+	return r.db.Save(identity).Error
+}
+
+func (r *identityRepo) Delete(userID uuid.UUID, provider string) error {
+	return r.db.Where("user_id = ? AND provider = ?", userID, provider).Delete(&entities.UserIdentity{}).Error
+}
+
+func (r *identityRepo) FindByProviderSubject(provider, subject string) (*entities.UserIdentity, error) {
+	var identity entities.UserIdentity
+	err := r.db.Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error
+	return &identity, err
+}
+
+func (r *identityRepo) FindByUserID(userID uuid.UUID) ([]*entities.UserIdentity, error) {
+	var identities []*entities.UserIdentity
+	err := r.db.Where("user_id = ?", userID).Order("created_at ASC").Find(&identities).Error
+	return identities, err
+}
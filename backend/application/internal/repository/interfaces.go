@@ -16,8 +16,11 @@ type UserRepository interface {
 	FindByID(id uuid.UUID) (*entities.User, error)
 	FindByEmail(email string) (*entities.User, error)
 	FindByUsername(username string) (*entities.User, error)
-	FindByGoogleID(googleID string) (*entities.User, error)
 	List(limit, offset int) ([]*entities.User, error)
+	RecordFailedLogin(id uuid.UUID, maxFailures int, lockUntil time.Time) (*time.Time, error)
+	ResetFailedLogins(id uuid.UUID) error
+	UpdateRole(id uuid.UUID, role string) error
+	CountByRole(role string) (int64, error)
 }
 
 type PostRepository interface {
@@ -25,8 +28,14 @@ type PostRepository interface {
 	Update(post *entities.Post) error
 	Delete(post *entities.Post) error
 	FindByID(id uuid.UUID) (*entities.Post, error)
+	FindByIDForUpdate(id uuid.UUID) (*entities.Post, error)
 	FindBySlug(slug string) (*entities.Post, error)
+	// FindByUserID lists the published posts credited to a user, co-authored ones included.
 	FindByUserID(userID uuid.UUID, limit, offset int) ([]*entities.Post, error)
+	// FindByUserIDAndStatus lists every post a user works on, in any status; an
+	// empty status matches all of them.
+	FindByUserIDAndStatus(userID uuid.UUID, status string, limit, offset int) ([]*entities.Post, error)
+	CountByUserIDAndStatus(userID uuid.UUID, status string) (int64, error)
 	FindAll(limit, offset int) ([]*entities.Post, error)
 	Count() (int64, error)
 	CountByUserID(userID uuid.UUID) (int64, error)
@@ -52,6 +61,14 @@ type PostRepository interface {
 	LoadRelationships(post *entities.Post) error
 	ExistsBySlugExcluding(slug string, excludeID uuid.UUID) bool
 	RecalculateAllEngagementScores() error
+	FindDueScheduled(now time.Time, limit int) ([]*entities.Post, error)
+	MarkPublished(id uuid.UUID, now time.Time) (bool, error)
+	// UpdateContentStats saves what was derived from the content (word count,
+	// reading time, table of contents and cover) without bumping updated_at.
+	UpdateContentStats(post *entities.Post) error
+	// FindByStatus lists posts in one status, least recently updated first.
+	FindByStatus(status string, limit, offset int) ([]*entities.Post, error)
+	CountByStatus(status string) (int64, error)
 	WithTx(tx *gorm.DB) PostRepository
 }
 
@@ -115,6 +132,8 @@ type PostContentRepository interface {
 	Create(pc *entities.PostContent) error
 	Update(pc *entities.PostContent) error
 	FindByPostID(postID uuid.UUID) (*entities.PostContent, error)
+	// FindAfter pages through all contents in ID order, starting after afterID.
+	FindAfter(afterID uuid.UUID, limit int) ([]*entities.PostContent, error)
 	DeleteByPostID(postID uuid.UUID) error
 	// WithTx returns a new repository instance that uses the given transaction.
 	WithTx(tx *gorm.DB) PostContentRepository
@@ -128,4 +147,138 @@ type ImageRepository interface {
 	FindReference(imageID, postID uuid.UUID, refType string) (*entities.ImageReference, error)
 }
 
+type SessionRepository interface {
+	Create(session *entities.UserSession) error
+	Update(session *entities.UserSession) error
+	FindByID(id uuid.UUID) (*entities.UserSession, error)
+	ListActiveByUserID(userID uuid.UUID, now time.Time) ([]*entities.UserSession, error)
+	TouchLastSeen(id uuid.UUID, at time.Time) error
+	Revoke(id uuid.UUID) error
+	RevokeAllByUserID(userID uuid.UUID) ([]uuid.UUID, error)
+	DeleteExpired(before time.Time) (int64, error)
+	CreateRefreshToken(token *entities.RefreshToken) error
+	FindRefreshTokenByHash(hash string) (*entities.RefreshToken, error)
+	MarkRefreshTokenUsed(id uuid.UUID) (bool, error)
+	MarkMFAVerified(id uuid.UUID) error
+	WithTx(tx *gorm.DB) SessionRepository
+}
+
+type ActionTokenRepository interface {
+	Create(token *entities.ActionToken) error
+	Consume(id uuid.UUID, purpose string) (bool, error)
+	InvalidateForUser(userID uuid.UUID, purpose string) error
+	DeleteExpired(before time.Time) (int64, error)
+	WithTx(tx *gorm.DB) ActionTokenRepository
+}
+
+type IdentityRepository interface {
+	Create(identity *entities.UserIdentity) error
+	Update(identity *entities.UserIdentity) error
+	Delete(userID uuid.UUID, provider string) error
+	FindByProviderSubject(provider, subject string) (*entities.UserIdentity, error)
+	FindByUserID(userID uuid.UUID) ([]*entities.UserIdentity, error)
+	WithTx(tx *gorm.DB) IdentityRepository
+}
+
+type APITokenRepository interface {
+	Create(token *entities.APIToken) error
+	FindByID(id uuid.UUID) (*entities.APIToken, error)
+	FindByHash(hash string) (*entities.APIToken, error)
+	FindByUserID(userID uuid.UUID) ([]*entities.APIToken, error)
+	CountActiveByUserID(userID uuid.UUID, now time.Time) (int64, error)
+	Revoke(id uuid.UUID) error
+	RevokeAllByUserID(userID uuid.UUID) (int64, error)
+	TouchLastUsed(id uuid.UUID, at time.Time) error
+}
+
+type MFARepository interface {
+	FindByUserID(userID uuid.UUID) (*entities.UserMFA, error)
+	Save(mfa *entities.UserMFA) error
+	Delete(userID uuid.UUID) error
+	AdvanceLastUsedStep(userID uuid.UUID, step int64) (bool, error)
+	ReplaceRecoveryCodes(userID uuid.UUID, codes []*entities.MFARecoveryCode) error
+	ConsumeRecoveryCode(userID uuid.UUID, codeHash string) (bool, error)
+	CountUnusedRecoveryCodes(userID uuid.UUID) (int64, error)
+	ListPolicies() ([]*entities.MFARolePolicy, error)
+	FindPolicy(role string) (*entities.MFARolePolicy, error)
+	SavePolicy(policy *entities.MFARolePolicy) error
+	WithTx(tx *gorm.DB) MFARepository
+}
+
+type PostRevisionRepository interface {
+	Create(rev *entities.PostRevision) error
+	FindByPostID(postID uuid.UUID, limit, offset int) ([]*entities.PostRevision, error)
+	CountByPostID(postID uuid.UUID) (int64, error)
+	FindByNumber(postID uuid.UUID, number int) (*entities.PostRevision, error)
+	FindLatest(postID uuid.UUID) (*entities.PostRevision, error)
+	Prune(postID uuid.UUID, keep int) (int64, error)
+	WithTx(tx *gorm.DB) PostRevisionRepository
+}
+
+type PostAutosaveRepository interface {
+	FindByPostID(postID uuid.UUID) (*entities.PostAutosave, error)
+	// Save stores a working copy if the current one is still at expectedVersion
+	// (0 when there is none yet) and reports false otherwise.
+	Save(autosave *entities.PostAutosave, expectedVersion int) (bool, error)
+	DeleteByPostID(postID uuid.UUID) error
+	WithTx(tx *gorm.DB) PostAutosaveRepository
+}
+
+type PostAuthorRepository interface {
+	Save(author *entities.PostAuthor) error
+	Find(postID, userID uuid.UUID) (*entities.PostAuthor, error)
+	FindByPostID(postID uuid.UUID) ([]*entities.PostAuthor, error)
+	FindPendingByUserID(userID uuid.UUID) ([]*entities.PostAuthor, error)
+	Respond(postID, userID uuid.UUID, status string, at time.Time) (bool, error)
+	Delete(postID, userID uuid.UUID) error
+	WithTx(tx *gorm.DB) PostAuthorRepository
+}
+
+type PostStatusEventRepository interface {
+	Create(event *entities.PostStatusEvent) error
+	FindByPostID(postID uuid.UUID) ([]*entities.PostStatusEvent, error)
+	WithTx(tx *gorm.DB) PostStatusEventRepository
+}
+
+type SeriesRepository interface {
+	Create(series *entities.Series) error
+	Update(series *entities.Series) error
+	Delete(id uuid.UUID) error
+	FindByID(id uuid.UUID) (*entities.Series, error)
+	FindBySlug(slug string) (*entities.Series, error)
+	FindByUserID(userID uuid.UUID) ([]*entities.Series, error)
+	FindByPostID(postID uuid.UUID) (*entities.Series, error)
+	ExistsBySlug(slug string) bool
+	FindPosts(seriesID uuid.UUID, publishedOnly bool) ([]*entities.Post, error)
+	FindParts(seriesID uuid.UUID) ([]*entities.Post, error)
+	SetPosts(seriesID uuid.UUID, postIDs []uuid.UUID) error
+	WithTx(tx *gorm.DB) SeriesRepository
+}
+
+type PostSlugHistoryRepository interface {
+	FindBySlug(slug string) (*entities.PostSlugHistory, error)
+	Save(entry *entities.PostSlugHistory) error
+	Delete(slug string, postID uuid.UUID) error
+	WithTx(tx *gorm.DB) PostSlugHistoryRepository
+}
+
+type TrashRepository interface {
+	FindDeletedPosts(ownerID uuid.UUID, limit, offset int) ([]*entities.Post, int64, error)
+	FindDeletedComments(ownerID uuid.UUID, limit, offset int) ([]*entities.Comment, int64, error)
+	FindDeletedReplies(ownerID uuid.UUID, limit, offset int) ([]*entities.Reply, int64, error)
+	FindDeletedPost(id uuid.UUID) (*entities.Post, error)
+	FindDeletedComment(id uuid.UUID) (*entities.Comment, error)
+	FindDeletedReply(id uuid.UUID) (*entities.Reply, error)
+	RestorePost(post *entities.Post) error
+	RestoreComment(comment *entities.Comment) error
+	RestoreReply(reply *entities.Reply) error
+	PurgePost(id uuid.UUID) error
+	PurgeComment(id uuid.UUID) error
+	PurgeReply(id uuid.UUID) error
+	FindExpiredPostIDs(before time.Time, limit int) ([]uuid.UUID, error)
+	FindExpiredCommentIDs(before time.Time, limit int) ([]uuid.UUID, error)
+	FindExpiredReplyIDs(before time.Time, limit int) ([]uuid.UUID, error)
+	WithTx(tx *gorm.DB) TrashRepository
+}
+
 // SearchRepository is defined in search_repo.go.
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/pdhoang91/blog/internal/entities"
+	uuid "github.com/satori/go.uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type mfaRepo struct{ db *gorm.DB }
+
+func NewMFARepository(db *gorm.DB) MFARepository { return &mfaRepo{db: db} }
+
+func (r *mfaRepo) WithTx(tx *gorm.DB) MFARepository { return &mfaRepo{db: tx} }
+
+func (r *mfaRepo) FindByUserID(userID uuid.UUID) (*entities.UserMFA, error) {
+	var mfa entities.UserMFA
+	err := r.db.Where("user_id = ?", userID).First(&mfa).Error
+	return &mfa, err
+}
+
+func (r *mfaRepo) Save(mfa *entities.UserMFA) error {
+	return r.db.Save(mfa).Error
+}
+
+// Delete removes the user's TOTP secret together with their recovery codes.
+func (r *mfaRepo) Delete(userID uuid.UUID) error {
+	if err := r.db.Where("user_id = ?", userID).Delete(&entities.MFARecoveryCode{}).Error; err != nil {
+		return err
+	}
+	return r.db.Where("user_id = ?", userID).Delete(&entities.UserMFA{}).Error
+}
+
+// AdvanceLastUsedStep records step as the latest accepted TOTP step. It returns
+// false when a code for the same or a later step was already accepted, which
+// signals a replay.
+func (r *mfaRepo) AdvanceLastUsedStep(userID uuid.UUID, step int64) (bool, error) {
+	res := r.db.Model(&entities.UserMFA{}).
+		Where("user_id = ? AND last_used_step < ?", userID, step).
+		UpdateColumn("last_used_step", step)
+	return res.RowsAffected == 1, res.Error
+}
+
+// ReplaceRecoveryCodes discards the user's recovery codes and stores a new set.
+func (r *mfaRepo) ReplaceRecoveryCodes(userID uuid.UUID, codes []*entities.MFARecoveryCode) error {
+	if err := r.db.Where("user_id = ?", userID).Delete(&entities.MFARecoveryCode{}).Error; err != nil {
+		return err
+	}
+	if len(codes) == 0 {
+		return nil
+	}
+	return r.db.Create(&codes).Error
+}
+
+// ConsumeRecoveryCode atomically marks an unused code as used. It returns false
+// when no unused code with that hash exists.
+func (r *mfaRepo) ConsumeRecoveryCode(userID uuid.UUID, codeHash string) (bool, error) {
+	res := r.db.Model(&entities.MFARecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	return res.RowsAffected == 1, res.Error
+}
+
+func (r *mfaRepo) CountUnusedRecoveryCodes(userID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.Model(&entities.MFARecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *mfaRepo) ListPolicies() ([]*entities.MFARolePolicy, error) {
+	var policies []*entities.MFARolePolicy
+	err := r.db.Order("role ASC").Find(&policies).Error
+	return policies, err
+}
+
+func (r *mfaRepo) FindPolicy(role string) (*entities.MFARolePolicy, error) {
+	var policy entities.MFARolePolicy
+	err := r.db.Where("role = ?", role).First(&policy).Error
+	return &policy, err
+}
+
+// SavePolicy inserts or updates the policy for its role.
+func (r *mfaRepo) SavePolicy(policy *entities.MFARolePolicy) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "role"}},
+		DoUpdates: clause.AssignmentColumns([]string{"required", "updated_by", "updated_at"}),
+	}).Create(policy).Error
+}
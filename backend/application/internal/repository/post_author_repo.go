@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/pdhoang91/blog/internal/entities"
+	uuid "github.com/satori/go.uuid"
+	"gorm.io/gorm"
+)
+
+type postAuthorRepo struct{ db *gorm.DB }
+
+func NewPostAuthorRepository(db *gorm.DB) PostAuthorRepository { return &postAuthorRepo{db: db} }
+
+func (r *postAuthorRepo) WithTx(tx *gorm.DB) PostAuthorRepository { return &postAuthorRepo{db: tx} }
+
+// Save inserts the author or, for a re-invitation, overwrites the earlier row.
+func (r *postAuthorRepo) Save(author *entities.PostAuthor) error {
+	return r.db.Omit("User", "Post").Save(author).Error
+}
+
+func (r *postAuthorRepo) Find(postID, userID uuid.UUID) (*entities.PostAuthor, error) {
+	var author entities.PostAuthor
+	err := r.db.Where("post_id = ? AND user_id = ?", postID, userID).First(&author).Error
+	return &author, err
+}
+
+// FindByPostID lists everyone on a post, invitations included, owner first.
+func (r *postAuthorRepo) FindByPostID(postID uuid.UUID) ([]*entities.PostAuthor, error) {
+	var authors []*entities.PostAuthor
+	err := r.db.Preload("User").
+		Where("post_id = ?", postID).
+		Order("role = 'owner' DESC, created_at ASC").
+		Find(&authors).Error
+	return authors, err
+}
+
+// FindPendingByUserID lists the invitations waiting for a user's answer.
+func (r *postAuthorRepo) FindPendingByUserID(userID uuid.UUID) ([]*entities.PostAuthor, error) {
+	var authors []*entities.PostAuthor
+	err := r.db.Preload("Post").Preload("Post.User").
+		Where("user_id = ? AND status = ?", userID, entities.InvitationPending).
+		Order("created_at DESC").
+		Find(&authors).Error
+	return authors, err
+}
+
+// Respond records the answer to a pending invitation and reports false if
+// there was none.
+func (r *postAuthorRepo) Respond(postID, userID uuid.UUID, status string, at time.Time) (bool, error) {
+	res := r.db.Model(&entities.PostAuthor{}).
+		Where("post_id = ? AND user_id = ? AND status = ?", postID, userID, entities.InvitationPending).
+		Updates(map[string]interface{}{"status": status, "responded_at": at})
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r *postAuthorRepo) Delete(postID, userID uuid.UUID) error {
+	return r.db.Where("post_id = ? AND user_id = ?", postID, userID).Delete(&entities.PostAuthor{}).Error
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"github.com/pdhoang91/blog/internal/entities"
+	uuid "github.com/satori/go.uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type postAutosaveRepo struct{ db *gorm.DB }
+
+func NewPostAutosaveRepository(db *gorm.DB) PostAutosaveRepository {
+	return &postAutosaveRepo{db: db}
+}
+
+func (r *postAutosaveRepo) WithTx(tx *gorm.DB) PostAutosaveRepository {
+	return &postAutosaveRepo{db: tx}
+}
+
+func (r *postAutosaveRepo) FindByPostID(postID uuid.UUID) (*entities.PostAutosave, error) {
+	var autosave entities.PostAutosave
+	err := r.db.Where("post_id = ?", postID).First(&autosave).Error
+	return &autosave, err
+}
+
+// Save inserts the first working copy or advances an existing one by one
+// version, both conditionally so that only one of two racing saves wins.
+func (r *postAutosaveRepo) Save(autosave *entities.PostAutosave, expectedVersion int) (bool, error) {
+	if expectedVersion == 0 {
+		autosave.Version = 1
+		res := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(autosave)
+		return res.RowsAffected > 0, res.Error
+	}
+
+	res := r.db.Model(&entities.PostAutosave{}).
+		Where("post_id = ? AND version = ?", autosave.PostID, expectedVersion).
+		Updates(map[string]interface{}{
+			"title":        autosave.Title,
+			"excerpt":      autosave.Excerpt,
+			"content":      autosave.Content,
+			"version":      expectedVersion + 1,
+			"post_version": autosave.PostVersion,
+			"updated_by":   autosave.UpdatedBy,
+			"updated_at":   autosave.UpdatedAt,
+		})
+	if res.Error != nil || res.RowsAffected == 0 {
+		return false, res.Error
+	}
+	autosave.Version = expectedVersion + 1
+	return true, nil
+}
+
+func (r *postAutosaveRepo) DeleteByPostID(postID uuid.UUID) error {
+	return r.db.Where("post_id = ?", postID).Delete(&entities.PostAutosave{}).Error
+}
@@ -29,6 +29,12 @@ func (r *postContentRepo) FindByPostID(postID uuid.UUID) (*entities.PostContent,
 	return &pc, err
 }
 
+func (r *postContentRepo) FindAfter(afterID uuid.UUID, limit int) ([]*entities.PostContent, error) {
+	var contents []*entities.PostContent
+	err := r.db.Where("id > ?", afterID).Order("id ASC").Limit(limit).Find(&contents).Error
+	return contents, err
+}
+
 func (r *postContentRepo) DeleteByPostID(postID uuid.UUID) error {
 	return r.db.Where("post_id = ?", postID).Delete(&entities.PostContent{}).Error
 }
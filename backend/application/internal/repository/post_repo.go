@@ -7,6 +7,7 @@ import (
 	"github.com/pdhoang91/blog/internal/entities"
 	uuid "github.com/satori/go.uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // Engagement score formula weights
@@ -17,6 +18,37 @@ const (
 
 type postRepo struct{ db *gorm.DB }
 
+// withPostRelations preloads what a post response shows: the owner, taxonomy
+// and the authors credited in the byline, owner first.
+func withPostRelations(db *gorm.DB) *gorm.DB {
+	return db.Preload("User").Preload("Categories").Preload("Tags").
+		Preload("Authors", func(db *gorm.DB) *gorm.DB {
+			return db.Where("status = ? AND role IN ?", entities.InvitationAccepted,
+				[]string{entities.PostAuthorOwner, entities.PostAuthorCoAuthor}).
+				Order("role = 'owner' DESC, created_at ASC")
+		}).
+		Preload("Authors.User")
+}
+
+// creditedTo matches posts the user owns or has accepted one of roles on.
+func creditedTo(userID uuid.UUID, roles ...string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(`(posts.user_id = ? OR posts.id IN (
+			SELECT post_id FROM post_authors WHERE user_id = ? AND status = ? AND role IN ?))`,
+			userID, userID, entities.InvitationAccepted, roles)
+	}
+}
+
+// bylineOf matches posts publicly credited to the user.
+func bylineOf(userID uuid.UUID) func(*gorm.DB) *gorm.DB {
+	return creditedTo(userID, entities.PostAuthorOwner, entities.PostAuthorCoAuthor)
+}
+
+// publishedOnly restricts a query to posts that belong in public listings.
+func publishedOnly(db *gorm.DB) *gorm.DB {
+	return db.Where("posts.status = ?", entities.PostStatusPublished)
+}
+
 func NewPostRepository(db *gorm.DB) PostRepository { return &postRepo{db: db} }
 
 func (r *postRepo) WithTx(tx *gorm.DB) PostRepository { return &postRepo{db: tx} }
@@ -35,7 +67,15 @@ func (r *postRepo) Delete(post *entities.Post) error {
 
 func (r *postRepo) FindByID(id uuid.UUID) (*entities.Post, error) {
 	var post entities.Post
-	err := r.db.Preload("User").Preload("Categories").Preload("Tags").Where("id = ?", id).First(&post).Error
+	err := r.db.Scopes(withPostRelations).Where("id = ?", id).First(&post).Error
+	return &post, err
+}
+
+// FindByIDForUpdate loads a post and locks its row until the transaction ends,
+// so concurrent saves are applied one after the other.
+func (r *postRepo) FindByIDForUpdate(id uuid.UUID) (*entities.Post, error) {
+	var post entities.Post
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&post).Error
 	return &post, err
 }
 
@@ -47,17 +87,39 @@ func (r *postRepo) FindBySlug(slug string) (*entities.Post, error) {
 
 func (r *postRepo) FindByUserID(userID uuid.UUID, limit, offset int) ([]*entities.Post, error) {
 	var posts []*entities.Post
-	err := r.db.Preload("User").Preload("Categories").Preload("Tags").
-		Where("user_id = ?", userID).
+	err := r.db.Scopes(withPostRelations, publishedOnly, bylineOf(userID)).
+		Order("published_at DESC").
+		Limit(limit).Offset(offset).
+		Find(&posts).Error
+	return posts, err
+}
+
+func (r *postRepo) FindByUserIDAndStatus(userID uuid.UUID, status string, limit, offset int) ([]*entities.Post, error) {
+	var posts []*entities.Post
+	q := r.db.Scopes(withPostRelations, creditedTo(userID, entities.PostAuthorOwner, entities.PostAuthorCoAuthor, entities.PostAuthorReviewer))
+	if status != "" {
+		q = q.Where("status = ?", status)
+	}
+	err := q.Order("updated_at DESC").
 		Limit(limit).Offset(offset).
 		Find(&posts).Error
 	return posts, err
 }
 
+func (r *postRepo) CountByUserIDAndStatus(userID uuid.UUID, status string) (int64, error) {
+	var count int64
+	q := r.db.Model(&entities.Post{}).Scopes(creditedTo(userID, entities.PostAuthorOwner, entities.PostAuthorCoAuthor, entities.PostAuthorReviewer))
+	if status != "" {
+		q = q.Where("status = ?", status)
+	}
+	err := q.Count(&count).Error
+	return count, err
+}
+
 func (r *postRepo) FindAll(limit, offset int) ([]*entities.Post, error) {
 	var posts []*entities.Post
-	err := r.db.Preload("User").Preload("Categories").Preload("Tags").
-		Order("created_at DESC").
+	err := r.db.Scopes(withPostRelations, publishedOnly).
+		Order("published_at DESC").
 		Limit(limit).Offset(offset).
 		Find(&posts).Error
 	return posts, err
@@ -65,19 +127,19 @@ func (r *postRepo) FindAll(limit, offset int) ([]*entities.Post, error) {
 
 func (r *postRepo) Count() (int64, error) {
 	var count int64
-	err := r.db.Model(&entities.Post{}).Count(&count).Error
+	err := r.db.Model(&entities.Post{}).Scopes(publishedOnly).Count(&count).Error
 	return count, err
 }
 
 func (r *postRepo) CountByUserID(userID uuid.UUID) (int64, error) {
 	var count int64
-	err := r.db.Model(&entities.Post{}).Where("user_id = ?", userID).Count(&count).Error
+	err := r.db.Model(&entities.Post{}).Scopes(publishedOnly, bylineOf(userID)).Count(&count).Error
 	return count, err
 }
 
 func (r *postRepo) CountSearch(query string) (int64, error) {
 	var count int64
-	err := r.db.Model(&entities.Post{}).
+	err := r.db.Model(&entities.Post{}).Scopes(publishedOnly).
 		Where("document @@ plainto_tsquery('english', ?) OR document @@ plainto_tsquery('simple', ?)", query, query).
 		Count(&count).Error
 	return count, err
@@ -85,7 +147,7 @@ func (r *postRepo) CountSearch(query string) (int64, error) {
 
 func (r *postRepo) Search(query string, limit, offset int) ([]*entities.Post, error) {
 	var posts []*entities.Post
-	err := r.db.Preload("User").Preload("Categories").Preload("Tags").
+	err := r.db.Scopes(withPostRelations, publishedOnly).
 		Where("document @@ plainto_tsquery('english', ?) OR document @@ plainto_tsquery('simple', ?)", query, query).
 		Limit(limit).Offset(offset).
 		Find(&posts).Error
@@ -94,7 +156,7 @@ func (r *postRepo) Search(query string, limit, offset int) ([]*entities.Post, er
 
 func (r *postRepo) GetPopular(limit int) ([]*entities.Post, error) {
 	var posts []*entities.Post
-	err := r.db.Preload("User").Preload("Categories").Preload("Tags").
+	err := r.db.Scopes(withPostRelations, publishedOnly).
 		Order("engagement_score DESC").
 		Limit(limit).
 		Find(&posts).Error
@@ -111,10 +173,11 @@ func (r *postRepo) RecalculateAllEngagementScores() error {
 
 func (r *postRepo) FindByCategory(categoryID uuid.UUID, limit, offset int) ([]*entities.Post, error) {
 	var posts []*entities.Post
-	err := r.db.Preload("User").Preload("Categories").Preload("Tags").
+	err := r.db.Scopes(withPostRelations).
 		Joins("JOIN post_categories ON posts.id = post_categories.post_id").
+		Scopes(publishedOnly).
 		Where("post_categories.category_id = ?", categoryID).
-		Order("posts.created_at DESC").
+		Order("posts.published_at DESC").
 		Limit(limit).Offset(offset).
 		Find(&posts).Error
 	return posts, err
@@ -124,6 +187,7 @@ func (r *postRepo) CountByCategory(categoryID uuid.UUID) (int64, error) {
 	var count int64
 	err := r.db.Model(&entities.Post{}).
 		Joins("JOIN post_categories ON posts.id = post_categories.post_id").
+		Scopes(publishedOnly).
 		Where("post_categories.category_id = ?", categoryID).
 		Count(&count).Error
 	return count, err
@@ -131,10 +195,11 @@ func (r *postRepo) CountByCategory(categoryID uuid.UUID) (int64, error) {
 
 func (r *postRepo) FindByTag(tagID uuid.UUID, limit, offset int) ([]*entities.Post, error) {
 	var posts []*entities.Post
-	err := r.db.Preload("User").Preload("Categories").Preload("Tags").
+	err := r.db.Scopes(withPostRelations).
 		Joins("JOIN post_tags ON posts.id = post_tags.post_id").
+		Scopes(publishedOnly).
 		Where("post_tags.tag_id = ?", tagID).
-		Order("posts.created_at DESC").
+		Order("posts.published_at DESC").
 		Limit(limit).Offset(offset).
 		Find(&posts).Error
 	return posts, err
@@ -144,6 +209,7 @@ func (r *postRepo) CountByTag(tagID uuid.UUID) (int64, error) {
 	var count int64
 	err := r.db.Model(&entities.Post{}).
 		Joins("JOIN post_tags ON posts.id = post_tags.post_id").
+		Scopes(publishedOnly).
 		Where("post_tags.tag_id = ?", tagID).
 		Count(&count).Error
 	return count, err
@@ -153,9 +219,9 @@ func (r *postRepo) FindByYearMonth(year, month int, limit, offset int) ([]*entit
 	start := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
 	end := start.AddDate(0, 1, 0)
 	var posts []*entities.Post
-	err := r.db.Preload("User").Preload("Categories").Preload("Tags").
-		Where("created_at >= ? AND created_at < ?", start, end).
-		Order("created_at DESC").
+	err := r.db.Scopes(withPostRelations, publishedOnly).
+		Where("published_at >= ? AND published_at < ?", start, end).
+		Order("published_at DESC").
 		Limit(limit).Offset(offset).
 		Find(&posts).Error
 	return posts, err
@@ -165,8 +231,8 @@ func (r *postRepo) CountByYearMonth(year, month int) (int64, error) {
 	start := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
 	end := start.AddDate(0, 1, 0)
 	var count int64
-	err := r.db.Model(&entities.Post{}).
-		Where("created_at >= ? AND created_at < ?", start, end).
+	err := r.db.Model(&entities.Post{}).Scopes(publishedOnly).
+		Where("published_at >= ? AND published_at < ?", start, end).
 		Count(&count).Error
 	return count, err
 }
@@ -175,14 +241,14 @@ func (r *postRepo) GetArchiveSummary() ([]*dto.ArchiveSummaryItem, error) {
 	var items []*dto.ArchiveSummaryItem
 	err := r.db.Raw(`
 		SELECT
-			EXTRACT(YEAR FROM created_at)::int  AS year,
-			EXTRACT(MONTH FROM created_at)::int AS month,
-			COUNT(*)                            AS count
+			EXTRACT(YEAR FROM published_at)::int  AS year,
+			EXTRACT(MONTH FROM published_at)::int AS month,
+			COUNT(*)                              AS count
 		FROM posts
-		WHERE deleted_at IS NULL
-		GROUP BY EXTRACT(YEAR FROM created_at), EXTRACT(MONTH FROM created_at)
+		WHERE deleted_at IS NULL AND status = ?
+		GROUP BY EXTRACT(YEAR FROM published_at), EXTRACT(MONTH FROM published_at)
 		ORDER BY year DESC, month DESC
-	`).Scan(&items).Error
+	`, entities.PostStatusPublished).Scan(&items).Error
 	if err != nil {
 		return nil, err
 	}
@@ -192,6 +258,51 @@ func (r *postRepo) GetArchiveSummary() ([]*dto.ArchiveSummaryItem, error) {
 	return items, nil
 }
 
+// FindDueScheduled returns scheduled posts whose publish time has passed.
+func (r *postRepo) FindDueScheduled(now time.Time, limit int) ([]*entities.Post, error) {
+	var posts []*entities.Post
+	err := r.db.Where("status = ? AND published_at <= ?", entities.PostStatusScheduled, now).
+		Order("published_at ASC").
+		Limit(limit).
+		Find(&posts).Error
+	return posts, err
+}
+
+// MarkPublished flips a scheduled post to published. It reports false when the
+// post was rescheduled or published by someone else in the meantime.
+func (r *postRepo) MarkPublished(id uuid.UUID, now time.Time) (bool, error) {
+	res := r.db.Model(&entities.Post{}).
+		Where("id = ? AND status = ? AND published_at <= ?", id, entities.PostStatusScheduled, now).
+		Updates(map[string]interface{}{"status": entities.PostStatusPublished, "updated_at": now})
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r *postRepo) UpdateContentStats(post *entities.Post) error {
+	return r.db.Model(&entities.Post{}).Where("id = ?", post.ID).
+		UpdateColumns(map[string]interface{}{
+			"word_count":   post.WordCount,
+			"reading_time": post.ReadingTime,
+			"toc":          post.TOC,
+			"cover_image":  post.CoverImage,
+		}).Error
+}
+
+func (r *postRepo) FindByStatus(status string, limit, offset int) ([]*entities.Post, error) {
+	var posts []*entities.Post
+	err := r.db.Scopes(withPostRelations).
+		Where("status = ?", status).
+		Order("updated_at ASC").
+		Limit(limit).Offset(offset).
+		Find(&posts).Error
+	return posts, err
+}
+
+func (r *postRepo) CountByStatus(status string) (int64, error) {
+	var count int64
+	err := r.db.Model(&entities.Post{}).Where("status = ?", status).Count(&count).Error
+	return count, err
+}
+
 func (r *postRepo) IncrementViews(post *entities.Post) error {
 	return r.db.Model(post).UpdateColumn("views", gorm.Expr("views + ?", 1)).Error
 }
@@ -232,12 +343,14 @@ func (r *postRepo) ReplaceTags(post *entities.Post, tags []entities.Tag) error {
 }
 
 func (r *postRepo) LoadRelationships(post *entities.Post) error {
-	return r.db.Preload("User").Preload("Categories").Preload("Tags").First(post, post.ID).Error
+	return r.db.Scopes(withPostRelations).First(post, post.ID).Error
 }
 
+// ExistsBySlugExcluding also counts posts in the trash, which keep their slug
+// until they are purged.
 func (r *postRepo) ExistsBySlugExcluding(slug string, excludeID uuid.UUID) bool {
 	var count int64
-	r.db.Model(&entities.Post{}).Where("slug = ? AND id != ?", slug, excludeID).Count(&count)
+	r.db.Unscoped().Model(&entities.Post{}).Where("slug = ? AND id != ?", slug, excludeID).Count(&count)
 	return count > 0
 }
 
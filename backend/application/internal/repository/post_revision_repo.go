@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"github.com/pdhoang91/blog/internal/entities"
+	uuid "github.com/satori/go.uuid"
+	"gorm.io/gorm"
+)
+
+type postRevisionRepo struct{ db *gorm.DB }
+
+func NewPostRevisionRepository(db *gorm.DB) PostRevisionRepository {
+	return &postRevisionRepo{db: db}
+}
+
+func (r *postRevisionRepo) WithTx(tx *gorm.DB) PostRevisionRepository {
+	return &postRevisionRepo{db: tx}
+}
+
+func (r *postRevisionRepo) Create(rev *entities.PostRevision) error {
+	return r.db.Create(rev).Error
+}
+
+// FindByPostID lists a post's revisions newest first, without their content.
+func (r *postRevisionRepo) FindByPostID(postID uuid.UUID, limit, offset int) ([]*entities.PostRevision, error) {
+	var revs []*entities.PostRevision
+	err := r.db.Preload("Author").Omit("content").
+		Where("post_id = ?", postID).
+		Order("revision_number DESC").
+		Limit(limit).Offset(offset).
+		Find(&revs).Error
+	return revs, err
+}
+
+func (r *postRevisionRepo) CountByPostID(postID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.Model(&entities.PostRevision{}).Where("post_id = ?", postID).Count(&count).Error
+	return count, err
+}
+
+func (r *postRevisionRepo) FindByNumber(postID uuid.UUID, number int) (*entities.PostRevision, error) {
+	var rev entities.PostRevision
+	err := r.db.Preload("Author").
+		Where("post_id = ? AND revision_number = ?", postID, number).
+		First(&rev).Error
+	return &rev, err
+}
+
+func (r *postRevisionRepo) FindLatest(postID uuid.UUID) (*entities.PostRevision, error) {
+	var rev entities.PostRevision
+	err := r.db.Where("post_id = ?", postID).Order("revision_number DESC").First(&rev).Error
+	return &rev, err
+}
+
+// Prune deletes all but the newest keep revisions of a post.
+func (r *postRevisionRepo) Prune(postID uuid.UUID, keep int) (int64, error) {
+	res := r.db.Exec(`
+		DELETE FROM post_revisions
+		WHERE post_id = ?
+		  AND revision_number <= (SELECT MAX(revision_number) FROM post_revisions WHERE post_id = ?) - ?
+	`, postID, postID, keep)
+	return res.RowsAffected, res.Error
+}
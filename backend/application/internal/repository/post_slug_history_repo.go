@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"github.com/pdhoang91/blog/internal/entities"
+	uuid "github.com/satori/go.uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type postSlugHistoryRepo struct{ db *gorm.DB }
+
+func NewPostSlugHistoryRepository(db *gorm.DB) PostSlugHistoryRepository {
+	return &postSlugHistoryRepo{db: db}
+}
+
+func (r *postSlugHistoryRepo) WithTx(tx *gorm.DB) PostSlugHistoryRepository {
+	return &postSlugHistoryRepo{db: tx}
+}
+
+func (r *postSlugHistoryRepo) FindBySlug(slug string) (*entities.PostSlugHistory, error) {
+	var entry entities.PostSlugHistory
+	err := r.db.Where("slug = ?", slug).First(&entry).Error
+	return &entry, err
+}
+
+// Save records an old slug, taking it over if it was recorded before.
+func (r *postSlugHistoryRepo) Save(entry *entities.PostSlugHistory) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "slug"}},
+		DoUpdates: clause.AssignmentColumns([]string{"post_id", "created_at"}),
+	}).Create(entry).Error
+}
+
+// Delete forgets an old slug of the post, e.g. because it is current again.
+func (r *postSlugHistoryRepo) Delete(slug string, postID uuid.UUID) error {
+	return r.db.Where("slug = ? AND post_id = ?", slug, postID).Delete(&entities.PostSlugHistory{}).Error
+}
@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"github.com/pdhoang91/blog/internal/entities"
+	uuid "github.com/satori/go.uuid"
+	"gorm.io/gorm"
+)
+
+type postStatusEventRepo struct{ db *gorm.DB }
+
+func NewPostStatusEventRepository(db *gorm.DB) PostStatusEventRepository {
+	return &postStatusEventRepo{db: db}
+}
+
+func (r *postStatusEventRepo) WithTx(tx *gorm.DB) PostStatusEventRepository {
+	return &postStatusEventRepo{db: tx}
+}
+
+func (r *postStatusEventRepo) Create(event *entities.PostStatusEvent) error {
+	return r.db.Omit("Actor").Create(event).Error
+}
+
+// FindByPostID lists a post's status changes, oldest first.
+func (r *postStatusEventRepo) FindByPostID(postID uuid.UUID) ([]*entities.PostStatusEvent, error) {
+	var events []*entities.PostStatusEvent
+	err := r.db.Preload("Actor").
+		Where("post_id = ?", postID).
+		Order("created_at ASC").
+		Find(&events).Error
+	return events, err
+}
@@ -95,7 +95,8 @@ func (r *pgSearchRepository) InitializeIndexes() error {
 		 END;
 		 $$ LANGUAGE plpgsql IMMUTABLE;`,
 
-		// Recursively extracts plain text from a TipTap / ProseMirror JSON tree.
+		// Recursively extracts plain text from a TipTap / ProseMirror JSON tree,
+		// with the LaTeX source of math nodes.
 		`CREATE OR REPLACE FUNCTION extract_text_from_json_doc(doc jsonb)
 		 RETURNS text AS $$
 		 DECLARE
@@ -106,6 +107,9 @@ func (r *pgSearchRepository) InitializeIndexes() error {
 		   IF doc->>'text' IS NOT NULL THEN
 		     result := result || ' ' || (doc->>'text');
 		   END IF;
+		   IF doc->'attrs'->>'latex' IS NOT NULL THEN
+		     result := result || ' ' || (doc->'attrs'->>'latex');
+		   END IF;
 		   IF doc->'content' IS NOT NULL AND jsonb_typeof(doc->'content') = 'array' THEN
 		     FOR child IN SELECT jsonb_array_elements(doc->'content')
 		     LOOP
@@ -157,9 +161,9 @@ type rawSearchRow struct {
 func (r *pgSearchRepository) Search(normalizedQuery, rawQuery string, limit, offset int) ([]SearchPostRow, int64, error) {
 	base := r.db.Table("posts").
 		Joins("LEFT JOIN post_contents ON post_contents.post_id = posts.id AND post_contents.deleted_at IS NULL").
-		Select("DISTINCT posts.id, posts.title, posts.slug, posts.excerpt, posts.user_id, posts.created_at, posts.views").
-		Where("posts.deleted_at IS NULL").
-		Order("posts.created_at DESC")
+		Select("DISTINCT posts.id, posts.title, posts.slug, posts.excerpt, posts.user_id, posts.created_at, posts.published_at, posts.views").
+		Where("posts.deleted_at IS NULL AND posts.status = ?", entities.PostStatusPublished).
+		Order("posts.published_at DESC")
 
 	if normalizedQuery != "" {
 		base = base.Where(`
@@ -249,12 +253,14 @@ func (r *pgSearchRepository) GetSuggestions(normalizedQuery, rawQuery string, li
 		SELECT DISTINCT title,
 		       ts_rank(to_tsvector('simple', lower(title)), plainto_tsquery('simple', ?)) AS score
 		FROM posts
-		WHERE lower(immutable_unaccent(title)) LIKE ?
+		WHERE deleted_at IS NULL AND status = ?
+		  AND (lower(immutable_unaccent(title)) LIKE ?
 		   OR title ILIKE ?
-		   OR to_tsvector('simple', lower(title)) @@ plainto_tsquery('simple', ?)
+		   OR to_tsvector('simple', lower(title)) @@ plainto_tsquery('simple', ?))
 		ORDER BY score DESC, title
 		LIMIT ?`,
 		normalizedQuery,
+		entities.PostStatusPublished,
 		"%"+normalizedQuery+"%",
 		"%"+rawQuery+"%",
 		normalizedQuery,
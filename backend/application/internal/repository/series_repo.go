@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"github.com/pdhoang91/blog/internal/entities"
+	uuid "github.com/satori/go.uuid"
+	"gorm.io/gorm"
+)
+
+type seriesRepo struct{ db *gorm.DB }
+
+func NewSeriesRepository(db *gorm.DB) SeriesRepository { return &seriesRepo{db: db} }
+
+func (r *seriesRepo) WithTx(tx *gorm.DB) SeriesRepository { return &seriesRepo{db: tx} }
+
+func (r *seriesRepo) Create(series *entities.Series) error {
+	return r.db.Omit("User").Create(series).Error
+}
+
+func (r *seriesRepo) Update(series *entities.Series) error {
+	return r.db.Omit("User").Save(series).Error
+}
+
+func (r *seriesRepo) Delete(id uuid.UUID) error {
+	return r.db.Delete(&entities.Series{}, "id = ?", id).Error
+}
+
+func (r *seriesRepo) FindByID(id uuid.UUID) (*entities.Series, error) {
+	var series entities.Series
+	err := r.db.Preload("User").Where("id = ?", id).First(&series).Error
+	return &series, err
+}
+
+func (r *seriesRepo) FindBySlug(slug string) (*entities.Series, error) {
+	var series entities.Series
+	err := r.db.Preload("User").Where("slug = ?", slug).First(&series).Error
+	return &series, err
+}
+
+func (r *seriesRepo) FindByUserID(userID uuid.UUID) ([]*entities.Series, error) {
+	var series []*entities.Series
+	err := r.db.Preload("User").
+		Where("user_id = ?", userID).
+		Order("updated_at DESC").
+		Find(&series).Error
+	return series, err
+}
+
+// FindByPostID returns the series a post belongs to.
+func (r *seriesRepo) FindByPostID(postID uuid.UUID) (*entities.Series, error) {
+	var series entities.Series
+	err := r.db.Joins("JOIN series_posts ON series_posts.series_id = series.id").
+		Where("series_posts.post_id = ?", postID).
+		First(&series).Error
+	return &series, err
+}
+
+func (r *seriesRepo) ExistsBySlug(slug string) bool {
+	var count int64
+	r.db.Model(&entities.Series{}).Where("slug = ?", slug).Count(&count)
+	return count > 0
+}
+
+// FindPosts lists a series' posts in order with what a post response shows.
+func (r *seriesRepo) FindPosts(seriesID uuid.UUID, publishedOnlyPosts bool) ([]*entities.Post, error) {
+	var posts []*entities.Post
+	q := r.db.Scopes(withPostRelations).
+		Joins("JOIN series_posts ON series_posts.post_id = posts.id").
+		Where("series_posts.series_id = ?", seriesID)
+	if publishedOnlyPosts {
+		q = q.Scopes(publishedOnly)
+	}
+	err := q.Order("series_posts.position ASC").Find(&posts).Error
+	return posts, err
+}
+
+// FindParts lists a series' posts in order with only their ID, title, slug
+// and status, enough to link between parts.
+func (r *seriesRepo) FindParts(seriesID uuid.UUID) ([]*entities.Post, error) {
+	var posts []*entities.Post
+	err := r.db.Select("posts.id", "posts.title", "posts.slug", "posts.status").
+		Joins("JOIN series_posts ON series_posts.post_id = posts.id").
+		Where("series_posts.series_id = ?", seriesID).
+		Order("series_posts.position ASC").
+		Find(&posts).Error
+	return posts, err
+}
+
+// SetPosts replaces a series' posts with postIDs, in that order.
+func (r *seriesRepo) SetPosts(seriesID uuid.UUID, postIDs []uuid.UUID) error {
+	if err := r.db.Where("series_id = ?", seriesID).Delete(&entities.SeriesPost{}).Error; err != nil {
+		return err
+	}
+	if len(postIDs) == 0 {
+		return nil
+	}
+	parts := make([]entities.SeriesPost, len(postIDs))
+	for i, id := range postIDs {
+		parts[i] = entities.SeriesPost{PostID: id, SeriesID: seriesID, Position: i + 1}
+	}
+	return r.db.Create(&parts).Error
+}
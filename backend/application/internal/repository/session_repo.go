@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/pdhoang91/blog/internal/entities"
+	uuid "github.com/satori/go.uuid"
+	"gorm.io/gorm"
+)
+
+type sessionRepo struct{ db *gorm.DB }
+
+func NewSessionRepository(db *gorm.DB) SessionRepository { return &sessionRepo{db: db} }
+
+func (r *sessionRepo) WithTx(tx *gorm.DB) SessionRepository { return &sessionRepo{db: tx} }
+
+func (r *sessionRepo) Create(session *entities.UserSession) error {
+	return r.db.Create(session).Error
+}
+
+func (r *sessionRepo) Update(session *entities.UserSession) error {
+	return r.db.Save(session).Error
+}
+
+func (r *sessionRepo) FindByID(id uuid.UUID) (*entities.UserSession, error) {
+	var session entities.UserSession
+	err := r.db.Where("id = ?", id).First(&session).Error
+	return &session, err
+}
+
+// ListActiveByUserID returns the user's unexpired, unrevoked sessions, most recently used first.
+func (r *sessionRepo) ListActiveByUserID(userID uuid.UUID, now time.Time) ([]*entities.UserSession, error) {
+	var sessions []*entities.UserSession
+	err := r.db.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, now).
+		Order("last_seen_at DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+// TouchLastSeen records activity on the session without bumping updated_at.
+func (r *sessionRepo) TouchLastSeen(id uuid.UUID, at time.Time) error {
+	return r.db.Model(&entities.UserSession{}).
+		Where("id = ?", id).
+		UpdateColumn("last_seen_at", at).Error
+}
+
+// Revoke marks the session and every refresh token in its family as revoked.
+func (r *sessionRepo) Revoke(id uuid.UUID) error {
+	now := time.Now()
+	if err := r.db.Model(&entities.UserSession{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", now).Error; err != nil {
+		return err
+	}
+	return r.db.Model(&entities.RefreshToken{}).
+		Where("session_id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", now).Error
+}
+
+// RevokeAllByUserID revokes every active session of the user and returns their ids.
+func (r *sessionRepo) RevokeAllByUserID(userID uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	if err := r.db.Model(&entities.UserSession{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return ids, nil
+	}
+
+	now := time.Now()
+	if err := r.db.Model(&entities.UserSession{}).
+		Where("id IN ?", ids).
+		Update("revoked_at", now).Error; err != nil {
+		return nil, err
+	}
+	if err := r.db.Model(&entities.RefreshToken{}).
+		Where("session_id IN ? AND revoked_at IS NULL", ids).
+		Update("revoked_at", now).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// DeleteExpired hard-deletes sessions (and, by cascade, their refresh tokens)
+// that expired or were revoked before the given time.
+func (r *sessionRepo) DeleteExpired(before time.Time) (int64, error) {
+	res := r.db.Where("expires_at < ? OR revoked_at < ?", before, before).Delete(&entities.UserSession{})
+	return res.RowsAffected, res.Error
+}
+
+func (r *sessionRepo) CreateRefreshToken(token *entities.RefreshToken) error {
+	return r.db.Create(token).Error
+}
+
+func (r *sessionRepo) FindRefreshTokenByHash(hash string) (*entities.RefreshToken, error) {
+	var token entities.RefreshToken
+	err := r.db.Where("token_hash = ?", hash).First(&token).Error
+	return &token, err
+}
+
+// MarkRefreshTokenUsed atomically consumes a refresh token. It returns false when
+// the token had already been used, which signals a replay.
+func (r *sessionRepo) MarkRefreshTokenUsed(id uuid.UUID) (bool, error) {
+	res := r.db.Model(&entities.RefreshToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	return res.RowsAffected == 1, res.Error
+}
+
+// MarkMFAVerified records that the session completed two-factor authentication.
+func (r *sessionRepo) MarkMFAVerified(id uuid.UUID) error {
+	return r.db.Model(&entities.UserSession{}).
+		Where("id = ?", id).
+		UpdateColumn("mfa_verified", true).Error
+}
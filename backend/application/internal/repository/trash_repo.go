@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/pdhoang91/blog/internal/entities"
+	uuid "github.com/satori/go.uuid"
+	"gorm.io/gorm"
+)
+
+// trashRepo works on soft-deleted rows only. An owner of uuid.Nil matches
+// every owner.
+type trashRepo struct{ db *gorm.DB }
+
+func NewTrashRepository(db *gorm.DB) TrashRepository { return &trashRepo{db: db} }
+
+func (r *trashRepo) WithTx(tx *gorm.DB) TrashRepository { return &trashRepo{db: tx} }
+
+func ownedBy(table string, ownerID uuid.UUID) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if ownerID == uuid.Nil {
+			return db
+		}
+		return db.Where(table+".user_id = ?", ownerID)
+	}
+}
+
+func (r *trashRepo) FindDeletedPosts(ownerID uuid.UUID, limit, offset int) ([]*entities.Post, int64, error) {
+	query := r.db.Unscoped().Model(&entities.Post{}).
+		Where("posts.deleted_at IS NOT NULL").Scopes(ownedBy("posts", ownerID))
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var posts []*entities.Post
+	err := query.Preload("User").Order("posts.deleted_at DESC").Limit(limit).Offset(offset).Find(&posts).Error
+	return posts, total, err
+}
+
+// FindDeletedComments lists comments deleted on their own. Comments deleted
+// together with their post are restored and purged with it.
+func (r *trashRepo) FindDeletedComments(ownerID uuid.UUID, limit, offset int) ([]*entities.Comment, int64, error) {
+	query := r.db.Unscoped().Model(&entities.Comment{}).
+		Joins("JOIN posts ON posts.id = comments.post_id AND posts.deleted_at IS NULL").
+		Where("comments.deleted_at IS NOT NULL").Scopes(ownedBy("comments", ownerID))
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var comments []*entities.Comment
+	err := query.Preload("User").Order("comments.deleted_at DESC").Limit(limit).Offset(offset).Find(&comments).Error
+	return comments, total, err
+}
+
+// FindDeletedReplies lists replies deleted on their own, leaving out those
+// whose comment or post is in the trash as well.
+func (r *trashRepo) FindDeletedReplies(ownerID uuid.UUID, limit, offset int) ([]*entities.Reply, int64, error) {
+	query := r.db.Unscoped().Model(&entities.Reply{}).
+		Joins("JOIN comments ON comments.id = replies.comment_id AND comments.deleted_at IS NULL").
+		Joins("JOIN posts ON posts.id = replies.post_id AND posts.deleted_at IS NULL").
+		Where("replies.deleted_at IS NOT NULL").Scopes(ownedBy("replies", ownerID))
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var replies []*entities.Reply
+	err := query.Preload("User").Order("replies.deleted_at DESC").Limit(limit).Offset(offset).Find(&replies).Error
+	return replies, total, err
+}
+
+func (r *trashRepo) FindDeletedPost(id uuid.UUID) (*entities.Post, error) {
+	var post entities.Post
+	err := r.db.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).First(&post).Error
+	return &post, err
+}
+
+func (r *trashRepo) FindDeletedComment(id uuid.UUID) (*entities.Comment, error) {
+	var comment entities.Comment
+	err := r.db.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).First(&comment).Error
+	return &comment, err
+}
+
+func (r *trashRepo) FindDeletedReply(id uuid.UUID) (*entities.Reply, error) {
+	var reply entities.Reply
+	err := r.db.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).First(&reply).Error
+	return &reply, err
+}
+
+// restore clears deleted_at on the rows of model matching query that were
+// deleted at or after since, i.e. in the same deletion or later.
+func (r *trashRepo) restore(model interface{}, since time.Time, query string, args ...interface{}) error {
+	return r.db.Unscoped().Model(model).Where(query, args...).
+		Where("deleted_at >= ?", since).Update("deleted_at", nil).Error
+}
+
+// RestorePost brings back the post with the content, comments and replies
+// deleted along with it.
+func (r *trashRepo) RestorePost(post *entities.Post) error {
+	since := post.DeletedAt.Time
+	if err := r.restore(&entities.Post{}, since, "id = ?", post.ID); err != nil {
+		return err
+	}
+	if err := r.restore(&entities.PostContent{}, since, "post_id = ?", post.ID); err != nil {
+		return err
+	}
+	if err := r.restore(&entities.Comment{}, since, "post_id = ?", post.ID); err != nil {
+		return err
+	}
+	return r.restore(&entities.Reply{}, since, "post_id = ?", post.ID)
+}
+
+// RestoreComment brings back the comment with the replies deleted along with it.
+func (r *trashRepo) RestoreComment(comment *entities.Comment) error {
+	since := comment.DeletedAt.Time
+	if err := r.restore(&entities.Comment{}, since, "id = ?", comment.ID); err != nil {
+		return err
+	}
+	return r.restore(&entities.Reply{}, since, "comment_id = ?", comment.ID)
+}
+
+func (r *trashRepo) RestoreReply(reply *entities.Reply) error {
+	return r.restore(&entities.Reply{}, reply.DeletedAt.Time, "id = ?", reply.ID)
+}
+
+// PurgePost removes the post and everything stored for it for good.
+func (r *trashRepo) PurgePost(id uuid.UUID) error {
+	db := r.db.Unscoped()
+	if err := db.Where("post_id = ?", id).Delete(&entities.PostContent{}).Error; err != nil {
+		return err
+	}
+	if err := db.Where("post_id = ?", id).Delete(&entities.Reply{}).Error; err != nil {
+		return err
+	}
+	if err := db.Where("post_id = ?", id).Delete(&entities.Comment{}).Error; err != nil {
+		return err
+	}
+	if err := db.Where("post_id = ?", id).Delete(&entities.ImageReference{}).Error; err != nil {
+		return err
+	}
+	return db.Where("id = ?", id).Delete(&entities.Post{}).Error
+}
+
+func (r *trashRepo) PurgeComment(id uuid.UUID) error {
+	db := r.db.Unscoped()
+	if err := db.Where("comment_id = ?", id).Delete(&entities.Reply{}).Error; err != nil {
+		return err
+	}
+	return db.Where("id = ?", id).Delete(&entities.Comment{}).Error
+}
+
+func (r *trashRepo) PurgeReply(id uuid.UUID) error {
+	return r.db.Unscoped().Where("id = ?", id).Delete(&entities.Reply{}).Error
+}
+
+func (r *trashRepo) expiredIDs(model interface{}, before time.Time, limit int) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.Unscoped().Model(model).
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+		Order("deleted_at ASC").Limit(limit).Pluck("id", &ids).Error
+	return ids, err
+}
+
+func (r *trashRepo) FindExpiredPostIDs(before time.Time, limit int) ([]uuid.UUID, error) {
+	return r.expiredIDs(&entities.Post{}, before, limit)
+}
+
+func (r *trashRepo) FindExpiredCommentIDs(before time.Time, limit int) ([]uuid.UUID, error) {
+	return r.expiredIDs(&entities.Comment{}, before, limit)
+}
+
+func (r *trashRepo) FindExpiredReplyIDs(before time.Time, limit int) ([]uuid.UUID, error) {
+	return r.expiredIDs(&entities.Reply{}, before, limit)
+}
@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"time"
+
 	"github.com/pdhoang91/blog/internal/entities"
 	uuid "github.com/satori/go.uuid"
 	"gorm.io/gorm"
@@ -40,14 +42,43 @@ func (r *userRepo) FindByUsername(username string) (*entities.User, error) {
 	return &user, err
 }
 
-func (r *userRepo) FindByGoogleID(googleID string) (*entities.User, error) {
-	var user entities.User
-	err := r.db.Where("google_id = ?", googleID).First(&user).Error
-	return &user, err
-}
-
 func (r *userRepo) List(limit, offset int) ([]*entities.User, error) {
 	var users []*entities.User
 	err := r.db.Limit(limit).Offset(offset).Find(&users).Error
 	return users, err
 }
+
+// RecordFailedLogin counts a failed sign-in. On the maxFailures-th consecutive
+// failure the account is locked until lockUntil and the count starts over.
+// It returns the account's lock expiry, if any.
+func (r *userRepo) RecordFailedLogin(id uuid.UUID, maxFailures int, lockUntil time.Time) (*time.Time, error) {
+	var lockedUntil *time.Time
+	err := r.db.Raw(`
+		UPDATE users SET
+			failed_login_count = CASE WHEN failed_login_count + 1 >= ? THEN 0 ELSE failed_login_count + 1 END,
+			locked_until = CASE WHEN failed_login_count + 1 >= ? THEN ? ELSE locked_until END
+		WHERE id = ?
+		RETURNING locked_until`, maxFailures, maxFailures, lockUntil, id).
+		Row().Scan(&lockedUntil)
+	return lockedUntil, err
+}
+
+// ResetFailedLogins clears the failure count and any lock after a successful sign-in.
+func (r *userRepo) ResetFailedLogins(id uuid.UUID) error {
+	return r.db.Model(&entities.User{}).
+		Where("id = ? AND (failed_login_count > 0 OR locked_until IS NOT NULL)", id).
+		UpdateColumns(map[string]interface{}{"failed_login_count": 0, "locked_until": nil}).Error
+}
+
+// UpdateRole changes only the user's role, leaving other columns untouched.
+func (r *userRepo) UpdateRole(id uuid.UUID, role string) error {
+	return r.db.Model(&entities.User{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{"role": role, "updated_at": time.Now()}).Error
+}
+
+func (r *userRepo) CountByRole(role string) (int64, error) {
+	var count int64
+	err := r.db.Model(&entities.User{}).Where("role = ?", role).Count(&count).Error
+	return count, err
+}
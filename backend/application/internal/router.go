@@ -2,24 +2,73 @@ package internal
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/pdhoang91/blog/constants"
 	"github.com/pdhoang91/blog/internal/controller"
 	"github.com/pdhoang91/blog/internal/middleware"
+	"github.com/pdhoang91/blog/pkg/ratelimit"
+)
+
+// Rate limits per route group. Anonymous endpoints are keyed by client IP,
+// authenticated writes by user.
+var (
+	loginLimit    = ratelimit.PerMinute(10)
+	registerLimit = ratelimit.PerHour(10)
+	accountLimit  = ratelimit.PerHour(20) // emails and one-time links
+	refreshLimit  = ratelimit.PerMinute(30)
+	trackLimit    = ratelimit.PerMinute(30)
+	commentLimit  = ratelimit.PerMinute(10)
+	writeLimit    = ratelimit.PerMinute(60)
+	autosaveLimit = ratelimit.PerMinute(120) // editors save every few seconds
 )
 
 // DefineAPIRoutes sets up all API routes using domain-specific controllers.
-func DefineAPIRoutes(r *gin.Engine, ctrl *controller.Controller) {
+// auth is consulted by AuthMiddleware to reject revoked sessions and to resolve API tokens;
+// mfa enforces the two-factor policy on admin routes; limiter backs the rate limits.
+func DefineAPIRoutes(r *gin.Engine, ctrl *controller.Controller, auth middleware.Authenticator, mfa middleware.MFAEnforcer, limiter ratelimit.Limiter) {
 	v1 := r.Group("")
+	authRequired := middleware.AuthMiddleware(auth)
+
+	limitLogin := middleware.RateLimit(limiter, "login", loginLimit, middleware.KeyByIP)
+	limitRegister := middleware.RateLimit(limiter, "register", registerLimit, middleware.KeyByIP)
+	limitAccount := middleware.RateLimit(limiter, "account", accountLimit, middleware.KeyByIP)
+	limitRefresh := middleware.RateLimit(limiter, "refresh", refreshLimit, middleware.KeyByIP)
+	limitTrack := middleware.RateLimit(limiter, "search-track", trackLimit, middleware.KeyByIP)
+	limitComments := middleware.RateLimit(limiter, "comment", commentLimit, middleware.KeyByUser)
+	limitWrites := middleware.RateLimit(limiter, "write", writeLimit, middleware.KeyByUser)
+	limitAutosave := middleware.RateLimit(limiter, "autosave", autosaveLimit, middleware.KeyByUser)
+
+	// API tokens only reach routes guarded by a scope; account and security
+	// settings are limited to login sessions.
+	sessionOnly := middleware.SessionOnly()
+	canRead := middleware.RequireScope(constants.ScopeRead)
+	canWritePosts := middleware.RequireScope(constants.ScopePostsWrite)
+	canWriteImages := middleware.RequireScope(constants.ScopeImagesWrite)
+
+	// Role permissions; services additionally let privileged roles act on
+	// content owned by others.
+	canPublish := middleware.RequirePermission(constants.PermPublish)
+	canEditAnyPost := middleware.RequirePermission(constants.PermEditAnyPost)
+	canReviewPosts := middleware.RequirePermission(constants.PermReviewPosts)
+	canManageTaxonomy := middleware.RequirePermission(constants.PermManageTaxonomy)
+	canManageUsers := middleware.RequirePermission(constants.PermManageUsers)
+
+	r.GET("/.well-known/jwks.json", ctrl.Auth.JWKS)
 
 	// --- Public routes (no authentication required) ---
 	public := v1.Group("")
 	{
 		// Auth
-		public.POST("/auth/register", ctrl.Auth.Register)
-		public.POST("/auth/login", ctrl.Auth.Login)
-		public.GET("/auth/google", ctrl.Auth.GoogleLogin)
-		public.GET("/auth/google/callback", ctrl.Auth.GoogleCallback)
-		public.POST("/auth/logout", ctrl.Auth.Logout)
-		public.POST("/auth/refresh", ctrl.Auth.RefreshToken)
+		public.POST("/auth/register", limitRegister, ctrl.Auth.Register)
+		public.POST("/auth/login", limitLogin, ctrl.Auth.Login)
+		public.POST("/auth/mfa/verify", limitLogin, ctrl.Auth.VerifyMFA)
+		public.GET("/auth/providers", ctrl.Auth.ListProviders)
+		public.GET("/auth/:provider", ctrl.Auth.OAuthLogin)
+		public.GET("/auth/:provider/callback", ctrl.Auth.OAuthCallback)
+		public.POST("/auth/logout", authRequired, sessionOnly, ctrl.Auth.Logout)
+		public.POST("/auth/refresh", limitRefresh, ctrl.Auth.RefreshToken)
+		public.POST("/auth/verify-email", limitAccount, ctrl.Auth.VerifyEmail)
+		public.POST("/auth/forgot-password", limitAccount, ctrl.Auth.ForgotPassword)
+		public.POST("/auth/reset-password", limitAccount, ctrl.Auth.ResetPassword)
 
 		public.GET("/home", ctrl.Home.GetHomeData)
 
@@ -28,6 +77,7 @@ func DefineAPIRoutes(r *gin.Engine, ctrl *controller.Controller) {
 		public.GET("/posts/popular", ctrl.Post.GetPopularPosts)
 		public.GET("/posts/:id", ctrl.Post.GetPost)
 		public.GET("/posts/:id/comments", ctrl.Comment.GetPostComments)
+		public.GET("/posts/:id/export", ctrl.Post.ExportPost)
 		public.GET("/p/:titleName", ctrl.Post.GetPostByTitleName)
 
 		// Archive
@@ -38,7 +88,7 @@ func DefineAPIRoutes(r *gin.Engine, ctrl *controller.Controller) {
 		public.GET("/search/posts", ctrl.Search.SearchPosts)
 		public.GET("/search/suggestions", ctrl.Search.GetSearchSuggestions)
 		public.GET("/search/popular", ctrl.Search.GetPopularSearches)
-		public.POST("/search/track", ctrl.Search.TrackSearch)
+		public.POST("/search/track", limitTrack, ctrl.Search.TrackSearch)
 
 		// Categories
 		public.GET("/categories", ctrl.Category.ListCategories)
@@ -58,6 +108,9 @@ func DefineAPIRoutes(r *gin.Engine, ctrl *controller.Controller) {
 		public.GET("/public/:username/posts", ctrl.Post.GetUserPostsByUsername)
 		public.GET("/public/:username/profile", ctrl.User.GetUserProfileByUsername)
 
+		// Series
+		public.GET("/series/:slug", ctrl.Series.GetSeriesBySlug)
+
 		// Replies (public read)
 		public.GET("/comments/:id/replies", ctrl.Comment.GetCommentReplies)
 
@@ -71,50 +124,111 @@ func DefineAPIRoutes(r *gin.Engine, ctrl *controller.Controller) {
 
 	// --- Protected routes (authentication required) ---
 	protected := v1.Group("/api")
-	protected.Use(middleware.AuthMiddleware())
+	protected.Use(authRequired)
 	{
 		// Users
-		protected.GET("/profile", ctrl.User.GetProfile)
-		protected.GET("/me", ctrl.User.GetProfile)
-		protected.PUT("/users/:id", ctrl.User.UpdateProfile)
-		protected.PUT("/profile", ctrl.User.UpdateProfile)
-		protected.DELETE("/profile", ctrl.User.DeleteProfile)
+		protected.GET("/profile", canRead, ctrl.User.GetProfile)
+		protected.GET("/me", canRead, ctrl.User.GetProfile)
+		protected.PUT("/users/:id", sessionOnly, ctrl.User.UpdateProfile)
+		protected.PUT("/profile", sessionOnly, ctrl.User.UpdateProfile)
+		protected.DELETE("/profile", sessionOnly, ctrl.User.DeleteProfile)
+		protected.POST("/auth/resend-verification", sessionOnly, limitAccount, ctrl.Auth.ResendVerification)
+		protected.GET("/auth/identities", sessionOnly, ctrl.Auth.ListIdentities)
+		protected.POST("/auth/identities/:provider", sessionOnly, ctrl.Auth.LinkIdentity)
+		protected.DELETE("/auth/identities/:provider", sessionOnly, ctrl.Auth.UnlinkIdentity)
+
+		// Two-factor authentication
+		protected.GET("/auth/mfa", sessionOnly, ctrl.MFA.GetStatus)
+		protected.POST("/auth/mfa/enroll", sessionOnly, ctrl.MFA.Enroll)
+		protected.POST("/auth/mfa/activate", sessionOnly, ctrl.MFA.Activate)
+		protected.POST("/auth/mfa/disable", sessionOnly, ctrl.MFA.Disable)
+		protected.POST("/auth/mfa/recovery-codes", sessionOnly, ctrl.MFA.RegenerateRecoveryCodes)
+
+		// Sessions
+		protected.GET("/sessions", sessionOnly, ctrl.Session.ListSessions)
+		protected.DELETE("/sessions", sessionOnly, ctrl.Session.RevokeAllSessions)
+		protected.DELETE("/sessions/:id", sessionOnly, ctrl.Session.RevokeSession)
+
+		// API tokens
+		protected.GET("/tokens", sessionOnly, ctrl.APIToken.ListAPITokens)
+		protected.POST("/tokens", sessionOnly, ctrl.APIToken.CreateAPIToken)
+		protected.DELETE("/tokens/:id", sessionOnly, ctrl.APIToken.RevokeAPIToken)
 
 		// Posts
-		protected.POST("/posts", ctrl.Post.CreatePost)
-		protected.PUT("/posts/:id", ctrl.Post.UpdatePost)
-		protected.DELETE("/posts/:id", ctrl.Post.DeletePost)
+		protected.GET("/me/posts", canRead, ctrl.Post.ListMyPosts)
+		protected.GET("/me/posts/:id", canRead, ctrl.Post.GetMyPost)
+		protected.GET("/me/posts/:id/export", canRead, ctrl.Post.ExportMyPost)
+		protected.POST("/posts", canWritePosts, canPublish, limitWrites, ctrl.Post.CreatePost)
+		protected.PUT("/posts/:id", canWritePosts, canPublish, limitWrites, ctrl.Post.UpdatePost)
+		protected.DELETE("/posts/:id", canWritePosts, canPublish, limitWrites, ctrl.Post.DeletePost)
+		protected.GET("/posts/:id/autosave", canRead, ctrl.Autosave.GetAutosave)
+		protected.PUT("/posts/:id/autosave", canWritePosts, canPublish, limitAutosave, ctrl.Autosave.SaveAutosave)
+		protected.DELETE("/posts/:id/autosave", canWritePosts, canPublish, limitWrites, ctrl.Autosave.DiscardAutosave)
+		protected.GET("/posts/:id/revisions", canRead, ctrl.Revision.ListRevisions)
+		protected.GET("/posts/:id/revisions/:rev", canRead, ctrl.Revision.GetRevision)
+		protected.GET("/posts/:id/revisions/:rev/diff", canRead, ctrl.Revision.DiffRevision)
+		protected.POST("/posts/:id/revisions/:rev/restore", canWritePosts, canPublish, limitWrites, ctrl.Revision.RestoreRevision)
+		protected.POST("/posts/:id/submit", canWritePosts, canPublish, limitWrites, ctrl.Review.SubmitForReview)
+		protected.POST("/posts/:id/review", canWritePosts, canReviewPosts, limitWrites, ctrl.Review.ReviewPost)
+		protected.GET("/posts/:id/history", canRead, ctrl.Review.GetHistory)
+		protected.GET("/reviews", canRead, canReviewPosts, ctrl.Review.ListQueue)
+		protected.GET("/me/series", canRead, ctrl.Series.ListMySeries)
+		protected.POST("/series", canWritePosts, canPublish, limitWrites, ctrl.Series.CreateSeries)
+		protected.PUT("/series/:id", canWritePosts, canPublish, limitWrites, ctrl.Series.UpdateSeries)
+		protected.DELETE("/series/:id", canWritePosts, canPublish, limitWrites, ctrl.Series.DeleteSeries)
+		protected.POST("/import/convert", canWritePosts, canPublish, limitWrites, ctrl.Import.ConvertDocument)
+		protected.POST("/embeds/unfurl", canWritePosts, canPublish, limitWrites, ctrl.Embed.Unfurl)
+		protected.GET("/trash", canRead, ctrl.Trash.ListTrash)
+		protected.POST("/trash/:type/:id/restore", sessionOnly, limitWrites, ctrl.Trash.RestoreItem)
+		protected.DELETE("/trash/:type/:id", sessionOnly, limitWrites, ctrl.Trash.PurgeItem)
+		protected.GET("/posts/:id/authors", canRead, ctrl.PostAuthor.ListAuthors)
+		protected.POST("/posts/:id/authors", canWritePosts, canPublish, limitWrites, ctrl.PostAuthor.InviteAuthor)
+		protected.DELETE("/posts/:id/authors/:userId", sessionOnly, ctrl.PostAuthor.RemoveAuthor)
+		protected.GET("/me/invitations", canRead, ctrl.PostAuthor.ListInvitations)
+		protected.POST("/me/invitations/:id/accept", sessionOnly, ctrl.PostAuthor.AcceptInvitation)
+		protected.POST("/me/invitations/:id/decline", sessionOnly, ctrl.PostAuthor.DeclineInvitation)
 		// Comments
-		protected.POST("/comments", ctrl.Comment.CreateComment)
-		protected.POST("/posts/:id/comments", ctrl.Comment.CreateCommentForPost)
-		protected.PUT("/comments/:id", ctrl.Comment.UpdateComment)
-		protected.DELETE("/comments/:id", ctrl.Comment.DeleteComment)
-		protected.GET("/posts/:id/comments", ctrl.Comment.GetPostComments)
+		protected.POST("/comments", sessionOnly, limitComments, ctrl.Comment.CreateComment)
+		protected.POST("/posts/:id/comments", sessionOnly, limitComments, ctrl.Comment.CreateCommentForPost)
+		protected.PUT("/comments/:id", sessionOnly, limitComments, ctrl.Comment.UpdateComment)
+		protected.DELETE("/comments/:id", sessionOnly, ctrl.Comment.DeleteComment)
+		protected.GET("/posts/:id/comments", canRead, ctrl.Comment.GetPostComments)
 		// Tags
-		protected.POST("/tags", ctrl.Tag.CreateTag)
-		protected.PUT("/tags/:id", ctrl.Tag.UpdateTag)
-		protected.DELETE("/tags/:id", ctrl.Tag.DeleteTag)
+		protected.POST("/tags", canWritePosts, canManageTaxonomy, limitWrites, ctrl.Tag.CreateTag)
+		protected.PUT("/tags/:id", canWritePosts, canManageTaxonomy, limitWrites, ctrl.Tag.UpdateTag)
+		protected.DELETE("/tags/:id", canWritePosts, canManageTaxonomy, limitWrites, ctrl.Tag.DeleteTag)
 
 		// Replies
-		protected.POST("/replies", ctrl.Comment.CreateReply)
-		protected.POST("/comments/:id/replies", ctrl.Engagement.CreateReplyForComment)
-		protected.DELETE("/replies/:id", ctrl.Comment.DeleteReply)
-		protected.GET("/comments/:id/replies", ctrl.Comment.GetCommentReplies)
+		protected.POST("/replies", sessionOnly, limitComments, ctrl.Comment.CreateReply)
+		protected.POST("/comments/:id/replies", sessionOnly, limitComments, ctrl.Engagement.CreateReplyForComment)
+		protected.DELETE("/replies/:id", sessionOnly, ctrl.Comment.DeleteReply)
+		protected.GET("/comments/:id/replies", canRead, ctrl.Comment.GetCommentReplies)
 		// Images
-		protected.POST("/images/upload/v2/:type", ctrl.Image.UploadImageV2)
-		protected.DELETE("/images/v2/:id", ctrl.Image.DeleteImageV2)
-		protected.GET("/images/my", ctrl.Image.ListUserImages)
+		protected.POST("/images/upload/v2/:type", canWriteImages, limitWrites, ctrl.Image.UploadImageV2)
+		protected.DELETE("/images/v2/:id", canWriteImages, limitWrites, ctrl.Image.DeleteImageV2)
+		protected.GET("/images/my", canRead, ctrl.Image.ListUserImages)
 	}
 
-	// --- Admin routes ---
+	// --- Admin routes (each route requires its own permission) ---
 	admin := v1.Group("/admin")
-	admin.Use(middleware.AuthMiddleware(), middleware.AdminMiddleware())
+	admin.Use(authRequired, sessionOnly, middleware.RequireMFA(mfa))
 	{
-		admin.DELETE("/posts/:id", ctrl.Post.DeletePost)
-		
-		// Categories (Admin only)
-		admin.POST("/categories", ctrl.Category.CreateCategory)
-		admin.PUT("/categories/id/:id", ctrl.Category.UpdateCategory)
-		admin.DELETE("/categories/id/:id", ctrl.Category.DeleteCategory)
+		// Other people's posts; authors use the routes above.
+		admin.PUT("/posts/:id", canEditAnyPost, limitWrites, ctrl.Post.UpdateAnyPost)
+		admin.DELETE("/posts/:id", canEditAnyPost, ctrl.Post.DeleteAnyPost)
+
+		// Roles and two-factor policy per role
+		admin.GET("/roles", canManageUsers, ctrl.Role.ListRoles)
+		admin.PUT("/users/:id/role", canManageUsers, ctrl.Role.ChangeUserRole)
+		admin.GET("/mfa/policies", canManageUsers, ctrl.MFA.ListPolicies)
+		admin.PUT("/mfa/policies/:role", canManageUsers, ctrl.MFA.UpdatePolicy)
+
+		// Categories
+		admin.POST("/categories", canManageTaxonomy, ctrl.Category.CreateCategory)
+		admin.PUT("/categories/id/:id", canManageTaxonomy, ctrl.Category.UpdateCategory)
+		admin.DELETE("/categories/id/:id", canManageTaxonomy, ctrl.Category.DeleteCategory)
+
+		// WordPress import
+		admin.POST("/import/wordpress", canEditAnyPost, canManageTaxonomy, ctrl.Import.ImportWordPress)
 	}
 }
@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/pdhoang91/blog/internal/apperror"
+	"github.com/pdhoang91/blog/internal/entities"
+	jwtUtil "github.com/pdhoang91/blog/pkg/jwt"
+	"github.com/pdhoang91/blog/pkg/mailer"
+	uuid "github.com/satori/go.uuid"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const (
+	verifyEmailTokenTTL   = 48 * time.Hour
+	resetPasswordTokenTTL = time.Hour
+	mailSendTimeout       = 10 * time.Second
+)
+
+// issueActionToken signs a one-time token for purpose and records its jti.
+// Older unused tokens of the same purpose are invalidated so only the latest link works.
+func (s *InsightService) issueActionToken(user *entities.User, purpose string, ttl time.Duration) (string, error) {
+	token, jti, err := jwtUtil.GenerateActionToken(user, purpose, ttl)
+	if err != nil {
+		return "", apperror.NewInternal("failed to generate token", err)
+	}
+
+	now := time.Now()
+	record := &entities.ActionToken{
+		ID:        uuid.FromStringOrNil(jti),
+		UserID:    user.ID,
+		Purpose:   purpose,
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}
+	if err := withTx(s.db, func(tx *gorm.DB) error {
+		txRepo := s.actionTokenRepo.WithTx(tx)
+		if err := txRepo.InvalidateForUser(user.ID, purpose); err != nil {
+			return err
+		}
+		return txRepo.Create(record)
+	}); err != nil {
+		return "", apperror.NewInternal("failed to store token", err)
+	}
+	return token, nil
+}
+
+// redeemActionToken verifies an action token, consumes it and returns its user.
+// The token is rejected when the user's email changed after it was issued.
+func (s *InsightService) redeemActionToken(token, purpose string) (*entities.User, error) {
+	claims, err := jwtUtil.VerifyActionToken(token, purpose)
+	if err != nil {
+		return nil, apperror.NewBadRequest("invalid or expired token")
+	}
+	jti, err := uuid.FromString(claims.ID)
+	if err != nil {
+		return nil, apperror.NewBadRequest("invalid or expired token")
+	}
+	userID, err := uuid.FromString(claims.Subject)
+	if err != nil {
+		return nil, apperror.NewBadRequest("invalid or expired token")
+	}
+
+	consumed, err := s.actionTokenRepo.Consume(jti, purpose)
+	if err != nil {
+		return nil, apperror.NewInternal("failed to redeem token", err)
+	}
+	if !consumed {
+		return nil, apperror.NewBadRequest("invalid or expired token")
+	}
+
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.NewBadRequest("invalid or expired token")
+		}
+		return nil, apperror.NewInternal("failed to find user", err)
+	}
+	if user.Email != claims.Email {
+		return nil, apperror.NewBadRequest("invalid or expired token")
+	}
+	return user, nil
+}
+
+func (s *InsightService) sendMail(to, subject, body string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), mailSendTimeout)
+	defer cancel()
+	return s.mailer.Send(ctx, &mailer.Message{To: to, Subject: subject, Body: body})
+}
+
+func (s *InsightService) sendVerificationEmail(user *entities.User) error {
+	token, err := s.issueActionToken(user, entities.ActionVerifyEmail, verifyEmailTokenTTL)
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/verify-email?token=%s", os.Getenv("BASE_FE_URL"), token)
+	body := fmt.Sprintf("Hi %s,\n\nPlease confirm your email address by opening the link below:\n\n%s\n\nThe link expires in 48 hours. If you did not create an account, you can ignore this email.\n", user.Name, link)
+	if err := s.sendMail(user.Email, "Verify your email address", body); err != nil {
+		return apperror.NewInternal("failed to send verification email", err)
+	}
+	return nil
+}
+
+// VerifyEmail marks the user's email as verified using a token from a verification email.
+func (s *InsightService) VerifyEmail(token string) error {
+	user, err := s.redeemActionToken(token, entities.ActionVerifyEmail)
+	if err != nil {
+		return err
+	}
+	if user.EmailVerified {
+		return nil
+	}
+
+	user.EmailVerified = true
+	user.UpdatedAt = time.Now()
+	if err := s.userRepo.Update(user); err != nil {
+		return apperror.NewInternal("failed to update user", err)
+	}
+	return nil
+}
+
+// ResendVerification sends a fresh verification email, invalidating earlier links.
+func (s *InsightService) ResendVerification(userID uuid.UUID) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperror.NewNotFound("user not found")
+		}
+		return apperror.NewInternal("failed to find user", err)
+	}
+	if user.EmailVerified {
+		return apperror.NewBadRequest("email already verified")
+	}
+	return s.sendVerificationEmail(user)
+}
+
+// ForgotPassword emails a password reset link. It succeeds for unknown addresses
+// too, so the endpoint cannot be used to discover registered emails.
+func (s *InsightService) ForgotPassword(email string) error {
+	user, err := s.userRepo.FindByEmail(email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return apperror.NewInternal("failed to find user", err)
+	}
+
+	token, err := s.issueActionToken(user, entities.ActionResetPassword, resetPasswordTokenTTL)
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/reset-password?token=%s", os.Getenv("BASE_FE_URL"), token)
+	body := fmt.Sprintf("Hi %s,\n\nWe received a request to reset your password. Open the link below to choose a new one:\n\n%s\n\nThe link expires in 1 hour. If you did not request a reset, you can ignore this email.\n", user.Name, link)
+	if err := s.sendMail(user.Email, "Reset your password", body); err != nil {
+		log.Printf("auth: failed to send password reset email to user %s: %v", user.ID, err)
+	}
+	return nil
+}
+
+// ResetPassword sets a new password using a reset token and signs the user out
+// everywhere. Personal API tokens are revoked too: a reset means the account
+// may have been taken over, and tokens minted meanwhile must not outlive it.
+func (s *InsightService) ResetPassword(token, newPassword string) error {
+	user, err := s.redeemActionToken(token, entities.ActionResetPassword)
+	if err != nil {
+		return err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return apperror.NewInternal("failed to hash password", err)
+	}
+
+	user.Password = string(hashedPassword)
+	// Following the emailed link proves ownership of the address.
+	user.EmailVerified = true
+	// A successful reset is the way out of a lockout.
+	user.FailedLoginCount = 0
+	user.LockedUntil = nil
+	user.UpdatedAt = time.Now()
+	if err := s.userRepo.Update(user); err != nil {
+		return apperror.NewInternal("failed to update password", err)
+	}
+
+	if _, err := s.RevokeAllSessions(user.ID); err != nil {
+		return err
+	}
+	if _, err := s.apiTokenRepo.RevokeAllByUserID(user.ID); err != nil {
+		return apperror.NewInternal("failed to revoke API tokens", err)
+	}
+	return nil
+}
+
+// PurgeExpiredActionTokens deletes action tokens that expired more than a day ago.
+func (s *InsightService) PurgeExpiredActionTokens() {
+	n, err := s.actionTokenRepo.DeleteExpired(time.Now().Add(-24 * time.Hour))
+	if err != nil {
+		log.Printf("auth: failed to purge expired action tokens: %v", err)
+		return
+	}
+	if n > 0 {
+		log.Printf("auth: purged %d expired action tokens", n)
+	}
+}
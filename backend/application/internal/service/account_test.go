@@ -0,0 +1,43 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pdhoang91/blog/constants"
+	"github.com/pdhoang91/blog/internal/entities"
+	jwtUtil "github.com/pdhoang91/blog/pkg/jwt"
+	uuid "github.com/satori/go.uuid"
+)
+
+func TestResetPasswordRevokesSessionsAndAPITokens(t *testing.T) {
+	s, f := newTestService()
+	userID := f.addUser(constants.RoleAuthor)
+	token, _, err := jwtUtil.GenerateActionToken(f.users.users[userID], entities.ActionResetPassword, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.ResetPassword(token, "a new password"); err != nil {
+		t.Fatalf("ResetPassword: %v", err)
+	}
+
+	if !revoked(f.sessions.revokedFor, userID) {
+		t.Error("sessions survived the reset")
+	}
+	if !revoked(f.apiTokens.revokedFor, userID) {
+		t.Error("API tokens survived the reset")
+	}
+	if f.users.users[userID].Password == "" {
+		t.Error("password was not changed")
+	}
+}
+
+func revoked(users []uuid.UUID, id uuid.UUID) bool {
+	for _, u := range users {
+		if u == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"errors"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pdhoang91/blog/constants"
+	"github.com/pdhoang91/blog/internal/apperror"
+	"github.com/pdhoang91/blog/internal/dto"
+	"github.com/pdhoang91/blog/internal/entities"
+	uuid "github.com/satori/go.uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultAPITokenExpiryDays = 90
+	maxAPITokensPerUser       = 25
+	// apiTokenLastUsedInterval throttles last_used_at writes for busy tokens.
+	apiTokenLastUsedInterval = time.Minute
+)
+
+// CreateAPIToken mints a personal access token. The plaintext token is only returned here.
+func (s *InsightService) CreateAPIToken(userID uuid.UUID, req *dto.CreateAPITokenRequest) (*dto.CreateAPITokenResponse, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, apperror.NewBadRequest("name is required")
+	}
+
+	scopeSet := make(map[string]bool)
+	for _, scope := range req.Scopes {
+		if !constants.IsValidScope(scope) {
+			return nil, apperror.NewBadRequest("unknown scope: " + scope)
+		}
+		scopeSet[scope] = true
+	}
+	scopes := make([]string, 0, len(scopeSet))
+	for scope := range scopeSet {
+		scopes = append(scopes, scope)
+	}
+	sort.Strings(scopes)
+
+	now := time.Now()
+	count, err := s.apiTokenRepo.CountActiveByUserID(userID, now)
+	if err != nil {
+		return nil, apperror.NewInternal("failed to count API tokens", err)
+	}
+	if count >= maxAPITokensPerUser {
+		return nil, apperror.NewBadRequest("too many active API tokens; revoke one first")
+	}
+
+	secret, _, err := newOpaqueToken()
+	if err != nil {
+		return nil, apperror.NewInternal("failed to generate API token", err)
+	}
+	raw := entities.APITokenPrefix + secret
+
+	days := req.ExpiresInDays
+	if days == 0 {
+		days = defaultAPITokenExpiryDays
+	}
+	expiresAt := now.AddDate(0, 0, days)
+
+	token := &entities.APIToken{
+		ID:        uuid.NewV4(),
+		UserID:    userID,
+		Name:      name,
+		Prefix:    raw[:len(entities.APITokenPrefix)+4],
+		TokenHash: hashToken(raw),
+		Scopes:    strings.Join(scopes, ","),
+		ExpiresAt: &expiresAt,
+		CreatedAt: now,
+	}
+	if err := s.apiTokenRepo.Create(token); err != nil {
+		return nil, apperror.NewInternal("failed to create API token", err)
+	}
+
+	return &dto.CreateAPITokenResponse{
+		APITokenResponse: dto.NewAPITokenResponse(token),
+		Token:            raw,
+	}, nil
+}
+
+// ListAPITokens returns the user's tokens that have not been revoked.
+func (s *InsightService) ListAPITokens(userID uuid.UUID) ([]*dto.APITokenResponse, error) {
+	tokens, err := s.apiTokenRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, apperror.NewInternal("failed to list API tokens", err)
+	}
+	responses := make([]*dto.APITokenResponse, 0, len(tokens))
+	for _, token := range tokens {
+		responses = append(responses, dto.NewAPITokenResponse(token))
+	}
+	return responses, nil
+}
+
+// RevokeAPIToken revokes one of the user's tokens.
+func (s *InsightService) RevokeAPIToken(userID, tokenID uuid.UUID) error {
+	token, err := s.apiTokenRepo.FindByID(tokenID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperror.NewNotFound("API token not found")
+		}
+		return apperror.NewInternal("failed to find API token", err)
+	}
+	if token.UserID != userID || token.RevokedAt != nil {
+		return apperror.NewNotFound("API token not found")
+	}
+	if err := s.apiTokenRepo.Revoke(tokenID); err != nil {
+		return apperror.NewInternal("failed to revoke API token", err)
+	}
+	return nil
+}
+
+// AuthenticateAPIToken resolves a personal access token to its owner and scopes.
+func (s *InsightService) AuthenticateAPIToken(raw string) (*dto.APITokenPrincipal, error) {
+	token, err := s.apiTokenRepo.FindByHash(hashToken(raw))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.NewUnauthorized("invalid API token")
+		}
+		return nil, apperror.NewInternal("failed to find API token", err)
+	}
+
+	now := time.Now()
+	if !token.IsActive(now) {
+		return nil, apperror.NewUnauthorized("API token expired or revoked")
+	}
+
+	user, err := s.userRepo.FindByID(token.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.NewUnauthorized("invalid API token")
+		}
+		return nil, apperror.NewInternal("failed to find user", err)
+	}
+
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) > apiTokenLastUsedInterval {
+		if err := s.apiTokenRepo.TouchLastUsed(token.ID, now); err != nil {
+			log.Printf("auth: failed to update last used for API token %s: %v", token.ID, err)
+		}
+	}
+
+	return &dto.APITokenPrincipal{
+		TokenID: token.ID,
+		UserID:  user.ID,
+		Role:    string(constants.NormalizeRole(user.Role)),
+		Scopes:  token.ScopeList(),
+	}, nil
+}
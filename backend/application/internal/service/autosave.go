@@ -0,0 +1,109 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/pdhoang91/blog/internal/apperror"
+	"github.com/pdhoang91/blog/internal/dto"
+	"github.com/pdhoang91/blog/internal/entities"
+	uuid "github.com/satori/go.uuid"
+	"gorm.io/gorm"
+)
+
+// postVersionConflict reports a save based on an outdated version, with the
+// post as it is now so the editor can merge.
+func (s *InsightService) postVersionConflict(post *entities.Post) error {
+	s.loadPostContent(post)
+	return apperror.NewVersionConflict("post was changed since you loaded it", &dto.VersionConflictResponse{
+		CurrentVersion: post.Version,
+		Current:        dto.NewPostResponse(post),
+	})
+}
+
+// GetAutosave returns the post's working copy, if the editor left one.
+func (s *InsightService) GetAutosave(userID, postID uuid.UUID) (*dto.AutosaveResponse, error) {
+	post, err := s.findAuthorizedPost(userID, postID, postAccessRead)
+	if err != nil {
+		return nil, err
+	}
+
+	autosave, err := s.autosaveRepo.FindByPostID(postID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.NewNotFound("no autosave for this post")
+		}
+		return nil, apperror.NewInternal("failed to get autosave", err)
+	}
+	if len(autosave.Content) > 0 {
+		autosave.Content = s.storageManager.ProcessJSONContentForDisplay(autosave.Content)
+	}
+	return dto.NewAutosaveResponse(autosave, post.Version), nil
+}
+
+// SaveAutosave replaces the working copy if it is still at expectedVersion (0
+// for the first autosave). A newer copy from another tab or editor is returned
+// in a conflict instead of being overwritten. The response leaves out the
+// content the caller just sent.
+func (s *InsightService) SaveAutosave(userID, postID uuid.UUID, expectedVersion int, req *dto.AutosaveRequest) (*dto.AutosaveResponse, error) {
+	if expectedVersion < 0 {
+		return nil, apperror.NewBadRequest("invalid version")
+	}
+	if err := validateContent(req.Content); err != nil {
+		return nil, err
+	}
+	post, err := s.findAuthorizedPost(userID, postID, postAccessEdit)
+	if err != nil {
+		return nil, err
+	}
+
+	autosave := &entities.PostAutosave{
+		PostID:      postID,
+		Title:       req.Title,
+		Excerpt:     req.Excerpt,
+		PostVersion: post.Version,
+		UpdatedBy:   &userID,
+		UpdatedAt:   time.Now(),
+	}
+	if len(req.Content) > 0 {
+		autosave.Content = s.storageManager.ProcessJSONContent(req.Content)
+	}
+
+	saved, err := s.autosaveRepo.Save(autosave, expectedVersion)
+	if err != nil {
+		return nil, apperror.NewInternal("failed to save autosave", err)
+	}
+	if !saved {
+		return nil, s.autosaveConflict(postID, post.Version)
+	}
+
+	autosave.Content = nil
+	return dto.NewAutosaveResponse(autosave, post.Version), nil
+}
+
+func (s *InsightService) autosaveConflict(postID uuid.UUID, postVersion int) error {
+	details := &dto.VersionConflictResponse{}
+	current, err := s.autosaveRepo.FindByPostID(postID)
+	switch {
+	case err == nil:
+		if len(current.Content) > 0 {
+			current.Content = s.storageManager.ProcessJSONContentForDisplay(current.Content)
+		}
+		details.CurrentVersion = current.Version
+		details.Current = dto.NewAutosaveResponse(current, postVersion)
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return apperror.NewInternal("failed to get autosave", err)
+	}
+	return apperror.NewVersionConflict("autosave was changed in another editor", details)
+}
+
+// DiscardAutosave drops the working copy without touching the post.
+func (s *InsightService) DiscardAutosave(userID, postID uuid.UUID) error {
+	if _, err := s.findAuthorizedPost(userID, postID, postAccessEdit); err != nil {
+		return err
+	}
+	if err := s.autosaveRepo.DeleteByPostID(postID); err != nil {
+		return apperror.NewInternal("failed to discard autosave", err)
+	}
+	return nil
+}
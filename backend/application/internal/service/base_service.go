@@ -7,19 +7,23 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/pdhoang91/blog/internal/repository"
 	"github.com/pdhoang91/blog/pkg/cache"
+	"github.com/pdhoang91/blog/pkg/mailer"
+	"github.com/pdhoang91/blog/pkg/oauth"
 	"github.com/pdhoang91/blog/pkg/storage"
+	"github.com/pdhoang91/blog/pkg/unfurl"
 	uuid "github.com/satori/go.uuid"
-	"golang.org/x/oauth2"
 	"gorm.io/gorm"
 )
 
 type BaseService struct {
 	db *gorm.DB
 
-	cache             cache.Cache
-	googleOauthConfig *oauth2.Config
-	s3Client          *s3.Client
-	storageManager    *storage.Manager
+	cache          cache.Cache
+	oauthProviders *oauth.Registry
+	s3Client       *s3.Client
+	storageManager *storage.Manager
+	mailer         mailer.Mailer
+	unfurler       *unfurl.Unfurler
 
 	userRepo        repository.UserRepository
 	postRepo        repository.PostRepository
@@ -29,6 +33,18 @@ type BaseService struct {
 	tagRepo         repository.TagRepository
 	postContentRepo repository.PostContentRepository
 	imageRepo       repository.ImageRepository
+	sessionRepo     repository.SessionRepository
+	identityRepo    repository.IdentityRepository
+	apiTokenRepo    repository.APITokenRepository
+	actionTokenRepo repository.ActionTokenRepository
+	mfaRepo         repository.MFARepository
+	revisionRepo    repository.PostRevisionRepository
+	autosaveRepo    repository.PostAutosaveRepository
+	postAuthorRepo  repository.PostAuthorRepository
+	statusEventRepo repository.PostStatusEventRepository
+	seriesRepo      repository.SeriesRepository
+	slugHistoryRepo repository.PostSlugHistoryRepository
+	trashRepo       repository.TrashRepository
 
 	viewBuffer sync.Map // map[uuid.UUID]*int64
 }
@@ -56,9 +72,11 @@ func (s *BaseService) FlushViewCounts() {
 func NewBaseService(
 	db *gorm.DB,
 	appCache cache.Cache,
-	googleOauthConfig *oauth2.Config,
+	oauthProviders *oauth.Registry,
 	s3Client *s3.Client,
 	storageManager *storage.Manager,
+	appMailer mailer.Mailer,
+	unfurler *unfurl.Unfurler,
 	userRepo repository.UserRepository,
 	postRepo repository.PostRepository,
 	commentRepo repository.CommentRepository,
@@ -67,21 +85,47 @@ func NewBaseService(
 	tagRepo repository.TagRepository,
 	postContentRepo repository.PostContentRepository,
 	imageRepo repository.ImageRepository,
+	sessionRepo repository.SessionRepository,
+	identityRepo repository.IdentityRepository,
+	apiTokenRepo repository.APITokenRepository,
+	actionTokenRepo repository.ActionTokenRepository,
+	mfaRepo repository.MFARepository,
+	revisionRepo repository.PostRevisionRepository,
+	autosaveRepo repository.PostAutosaveRepository,
+	postAuthorRepo repository.PostAuthorRepository,
+	statusEventRepo repository.PostStatusEventRepository,
+	seriesRepo repository.SeriesRepository,
+	slugHistoryRepo repository.PostSlugHistoryRepository,
+	trashRepo repository.TrashRepository,
 ) *BaseService {
 	return &BaseService{
-		db:                db,
-		cache:             appCache,
-		googleOauthConfig: googleOauthConfig,
-		s3Client:          s3Client,
-		storageManager:    storageManager,
-		userRepo:          userRepo,
-		postRepo:          postRepo,
-		commentRepo:       commentRepo,
-		replyRepo:         replyRepo,
-		categoryRepo:      categoryRepo,
-		tagRepo:           tagRepo,
-		postContentRepo:   postContentRepo,
-		imageRepo:         imageRepo,
+		db:              db,
+		cache:           appCache,
+		oauthProviders:  oauthProviders,
+		s3Client:        s3Client,
+		storageManager:  storageManager,
+		mailer:          appMailer,
+		unfurler:        unfurler,
+		userRepo:        userRepo,
+		postRepo:        postRepo,
+		commentRepo:     commentRepo,
+		replyRepo:       replyRepo,
+		categoryRepo:    categoryRepo,
+		tagRepo:         tagRepo,
+		postContentRepo: postContentRepo,
+		imageRepo:       imageRepo,
+		sessionRepo:     sessionRepo,
+		identityRepo:    identityRepo,
+		apiTokenRepo:    apiTokenRepo,
+		actionTokenRepo: actionTokenRepo,
+		mfaRepo:         mfaRepo,
+		revisionRepo:    revisionRepo,
+		autosaveRepo:    autosaveRepo,
+		postAuthorRepo:  postAuthorRepo,
+		statusEventRepo: statusEventRepo,
+		seriesRepo:      seriesRepo,
+		slugHistoryRepo: slugHistoryRepo,
+		trashRepo:       trashRepo,
 	}
 }
 
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"time"
 
+	"github.com/pdhoang91/blog/constants"
 	"github.com/pdhoang91/blog/internal/apperror"
 	"github.com/pdhoang91/blog/internal/dto"
 	"github.com/pdhoang91/blog/internal/entities"
@@ -73,12 +74,16 @@ func (s *InsightService) CreateComment(userID uuid.UUID, req *dto.CreateCommentR
 		return nil, apperror.NewBadRequest("invalid post ID")
 	}
 
-	if _, err := s.postRepo.FindByID(postID); err != nil {
+	post, err := s.postRepo.FindByID(postID)
+	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, apperror.NewNotFound("post not found")
 		}
 		return nil, apperror.NewInternal("failed to verify post", err)
 	}
+	if !post.IsPubliclyVisible() {
+		return nil, apperror.NewNotFound("post not found")
+	}
 
 	comment := &entities.Comment{
 		ID: uuid.NewV4(), PostID: postID, UserID: userID,
@@ -137,8 +142,8 @@ func (s *InsightService) DeleteComment(userID uuid.UUID, commentID uuid.UUID) er
 		return apperror.NewInternal("failed to find comment", err)
 	}
 
-	if comment.UserID != userID {
-		return apperror.NewForbidden("you do not own this comment")
+	if err := s.authorizeOwnerOr(comment.UserID, userID, constants.PermModerateComments, "you do not own this comment"); err != nil {
+		return err
 	}
 
 	if err := withTx(s.db, func(tx *gorm.DB) error {
@@ -203,8 +208,8 @@ func (s *InsightService) DeleteReply(userID uuid.UUID, replyID uuid.UUID) error
 		return apperror.NewInternal("failed to find reply", err)
 	}
 
-	if reply.UserID != userID {
-		return apperror.NewForbidden("you do not own this reply")
+	if err := s.authorizeOwnerOr(reply.UserID, userID, constants.PermModerateComments, "you do not own this reply"); err != nil {
+		return err
 	}
 
 	if err := s.replyRepo.Delete(reply); err != nil {
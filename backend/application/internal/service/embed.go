@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/pdhoang91/blog/internal/apperror"
+	"github.com/pdhoang91/blog/internal/dto"
+	"github.com/pdhoang91/blog/pkg/tiptap"
+	"github.com/pdhoang91/blog/pkg/unfurl"
+)
+
+const (
+	unfurlTimeout  = 10 * time.Second
+	unfurlCacheTTL = 24 * time.Hour
+)
+
+// UnfurlLink fetches the preview of a link for an embed node. Previews are
+// shared between authors for a day, as pages rarely change their metadata.
+func (s *InsightService) UnfurlLink(ctx context.Context, req *dto.UnfurlRequest) (*dto.EmbedResponse, error) {
+	cacheKey := "unfurl:" + req.URL
+	if cached, ok := s.cache.Get(cacheKey); ok {
+		if resp, ok := cached.(*dto.EmbedResponse); ok {
+			return resp, nil
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, unfurlTimeout)
+	defer cancel()
+	preview, err := s.unfurler.Unfurl(ctx, req.URL)
+	switch {
+	case errors.Is(err, unfurl.ErrInvalidURL):
+		return nil, apperror.NewBadRequest("url must be an http or https link")
+	case errors.Is(err, unfurl.ErrBlockedAddress):
+		return nil, apperror.NewBadRequest("links to private addresses cannot be previewed")
+	case err != nil:
+		log.Printf("unfurl: %s: %v", req.URL, err)
+		return nil, apperror.NewBadRequest("could not fetch a preview of this link")
+	}
+
+	resp := &dto.EmbedResponse{
+		URL:         preview.URL,
+		Title:       preview.Title,
+		Description: preview.Description,
+		SiteName:    preview.SiteName,
+		Image:       preview.Image,
+	}
+	// Video pages name their player; a link straight to one is a player too.
+	for _, src := range []string{preview.Video, preview.URL, req.URL} {
+		if player := tiptap.PlayerURL(src); player != "" {
+			resp.Video = player
+			break
+		}
+	}
+
+	s.cache.Set(cacheKey, resp, unfurlCacheTTL)
+	return resp, nil
+}
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pdhoang91/blog/internal/apperror"
+	"github.com/pdhoang91/blog/internal/dto"
+	"github.com/pdhoang91/blog/internal/entities"
+	"github.com/pdhoang91/blog/pkg/tiptap"
+	uuid "github.com/satori/go.uuid"
+	"gorm.io/gorm"
+)
+
+const exportCacheTTL = 10 * time.Minute
+
+// ExportPost renders a published post as a Markdown or HTML document.
+func (s *InsightService) ExportPost(id uuid.UUID, format string) (*dto.PostExport, error) {
+	post, err := s.postRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.NewNotFound("post not found")
+		}
+		return nil, apperror.NewInternal("failed to get post", err)
+	}
+	if !post.IsPubliclyVisible() {
+		return nil, apperror.NewNotFound("post not found")
+	}
+	return s.exportPost(post, format)
+}
+
+// ExportMyPost is ExportPost for the post's authors, whatever its status.
+func (s *InsightService) ExportMyPost(userID, id uuid.UUID, format string) (*dto.PostExport, error) {
+	post, err := s.findAuthorizedPost(userID, id, postAccessRead)
+	if err != nil {
+		return nil, err
+	}
+	return s.exportPost(post, format)
+}
+
+func (s *InsightService) exportPost(post *entities.Post, format string) (*dto.PostExport, error) {
+	if format == "" {
+		format = dto.ExportMarkdown
+	}
+	if format != dto.ExportMarkdown && format != dto.ExportHTML {
+		return nil, apperror.NewBadRequest("format must be md or html")
+	}
+
+	// Any change to the post moves its version or update time, so a cached
+	// export is never served for an older state.
+	cacheKey := fmt.Sprintf("post_export:%s:%d:%d:%s", post.ID, post.Version, post.UpdatedAt.UnixNano(), format)
+	if cached, ok := s.cache.Get(cacheKey); ok {
+		if export, ok := cached.(*dto.PostExport); ok {
+			return export, nil
+		}
+	}
+
+	var content json.RawMessage
+	postContent, err := s.postContentRepo.FindByPostID(post.ID)
+	switch {
+	case err == nil && postContent != nil:
+		content = postContent.Content
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, apperror.NewInternal("failed to load post content", err)
+	}
+	if err := s.postRepo.LoadRelationships(post); err != nil {
+		return nil, apperror.NewInternal("failed to load post relationships", err)
+	}
+
+	body, err := s.renderPostContent(content, format)
+	if err != nil {
+		return nil, err
+	}
+	export := &dto.PostExport{Filename: post.Slug + "." + format}
+	if format == dto.ExportHTML {
+		export.ContentType = "text/html; charset=utf-8"
+		export.Body = htmlDocument(post, body)
+	} else {
+		export.ContentType = "text/markdown; charset=utf-8"
+		export.Body = frontMatter(post) + body
+	}
+
+	s.cache.Set(cacheKey, export, exportCacheTTL)
+	return export, nil
+}
+
+// renderPostContent renders stored content as an HTML fragment or Markdown,
+// with image IDs resolved to their direct URLs, for exports, feeds and email.
+func (s *InsightService) renderPostContent(content json.RawMessage, format string) (string, error) {
+	if len(content) == 0 {
+		return "", nil
+	}
+	doc, err := tiptap.Parse(s.storageManager.ProcessJSONContentForDisplay(content))
+	if err != nil {
+		return "", apperror.NewInternal("failed to read post content", err)
+	}
+	if format == dto.ExportHTML {
+		return doc.HTML(), nil
+	}
+	return doc.Markdown(), nil
+}
+
+// publishedDate is when the post went live, or was created if it has not.
+func publishedDate(post *entities.Post) time.Time {
+	if post.PublishedAt != nil {
+		return *post.PublishedAt
+	}
+	return post.CreatedAt
+}
+
+// postURL is the post's page on the frontend, "" when BASE_FE_URL is unset.
+func postURL(post *entities.Post) string {
+	feURL := os.Getenv("BASE_FE_URL")
+	if feURL == "" {
+		return ""
+	}
+	return strings.TrimRight(feURL, "/") + "/p/" + post.Slug
+}
+
+// frontMatter describes the post in a YAML block, as static site generators
+// read it. Values are written as JSON, which YAML accepts.
+func frontMatter(post *entities.Post) string {
+	quote := func(v interface{}) string {
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+	categories := make([]string, 0, len(post.Categories))
+	for _, c := range post.Categories {
+		categories = append(categories, c.Name)
+	}
+	tags := make([]string, 0, len(post.Tags))
+	for _, t := range post.Tags {
+		tags = append(tags, t.Name)
+	}
+
+	var b strings.Builder
+	b.WriteString("---\n")
+	b.WriteString("title: " + quote(post.Title) + "\n")
+	b.WriteString("slug: " + quote(post.Slug) + "\n")
+	b.WriteString("date: " + publishedDate(post).UTC().Format(time.RFC3339) + "\n")
+	if post.User.Name != "" {
+		b.WriteString("author: " + quote(post.User.Name) + "\n")
+	}
+	if post.Excerpt != "" {
+		b.WriteString("description: " + quote(post.Excerpt) + "\n")
+	}
+	if post.CoverImage != "" {
+		b.WriteString("cover_image: " + quote(post.CoverImage) + "\n")
+	}
+	b.WriteString("categories: " + quote(categories) + "\n")
+	b.WriteString("tags: " + quote(tags) + "\n")
+	if url := postURL(post); url != "" {
+		b.WriteString("canonical_url: " + quote(url) + "\n")
+	}
+	b.WriteString("---\n\n")
+	return b.String()
+}
+
+func htmlDocument(post *entities.Post, content string) string {
+	title := html.EscapeString(post.Title)
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+	b.WriteString("<title>" + title + "</title>\n")
+	if post.Excerpt != "" {
+		b.WriteString("<meta name=\"description\" content=\"" + html.EscapeString(post.Excerpt) + "\">\n")
+	}
+	if url := postURL(post); url != "" {
+		b.WriteString("<link rel=\"canonical\" href=\"" + html.EscapeString(url) + "\">\n")
+	}
+	b.WriteString("</head>\n<body>\n<article>\n<header>\n")
+	b.WriteString("<h1>" + title + "</h1>\n<p>")
+	if post.User.Name != "" {
+		b.WriteString(html.EscapeString(post.User.Name) + " · ")
+	}
+	date := publishedDate(post)
+	b.WriteString("<time datetime=\"" + date.UTC().Format(time.RFC3339) + "\">" + date.Format("January 2, 2006") + "</time></p>\n")
+	b.WriteString("</header>\n")
+	b.WriteString(content)
+	b.WriteString("</article>\n</body>\n</html>\n")
+	return b.String()
+}
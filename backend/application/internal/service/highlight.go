@@ -0,0 +1,47 @@
+package service
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/pdhoang91/blog/pkg/tiptap"
+)
+
+const codeHighlightCacheTTL = 24 * time.Hour
+
+// codeTokens highlights the code blocks of display content, so the frontend
+// renders colored code without a highlighter. It returns the tokens of each
+// block in document order, or nil when there is nothing to color; content
+// itself is left alone, as it must go back unchanged when edited. The result
+// is cached by the content's hash: the same content always highlights the
+// same, and any edit makes a new key.
+func (s *InsightService) codeTokens(content json.RawMessage) json.RawMessage {
+	if !bytes.Contains(content, []byte(`"codeBlock"`)) {
+		return nil
+	}
+	sum := sha256.Sum256(content)
+	cacheKey := "code_tokens:" + hex.EncodeToString(sum[:])
+	if cached, ok := s.cache.Get(cacheKey); ok {
+		if tokens, ok := cached.(string); ok {
+			return json.RawMessage(tokens)
+		}
+	}
+
+	doc, err := tiptap.Parse(content)
+	if err != nil {
+		return nil
+	}
+	var tokens json.RawMessage
+	if blocks := doc.CodeTokens(); blocks != nil {
+		if tokens, err = json.Marshal(blocks); err != nil {
+			log.Printf("highlight: failed to encode code tokens: %v", err)
+			return nil
+		}
+	}
+	s.cache.Set(cacheKey, string(tokens), codeHighlightCacheTTL)
+	return tokens
+}
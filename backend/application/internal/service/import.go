@@ -0,0 +1,736 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pdhoang91/blog/constants"
+	"github.com/pdhoang91/blog/internal/apperror"
+	"github.com/pdhoang91/blog/internal/dto"
+	"github.com/pdhoang91/blog/internal/entities"
+	"github.com/pdhoang91/blog/pkg/storage"
+	"github.com/pdhoang91/blog/pkg/tiptap"
+	"github.com/pdhoang91/blog/pkg/utils"
+	"github.com/pdhoang91/blog/pkg/wxr"
+	uuid "github.com/satori/go.uuid"
+	"gorm.io/gorm"
+)
+
+// wordPressDefaultCategory is the category WordPress files posts under when
+// the author picked none; it carries no meaning worth importing.
+const wordPressDefaultCategory = "uncategorized"
+
+// imageRehoster uploads the images imported content points at from a bundle
+// and rewrites the content to use the copies. A file used several times is
+// uploaded once per owner.
+type imageRehoster struct {
+	storage  *storage.Manager
+	ctx      context.Context
+	bundle   *storage.Bundle
+	dryRun   bool
+	uploaded map[string]*storage.UploadResponse
+}
+
+func (s *InsightService) newImageRehoster(ctx context.Context, bundle *storage.Bundle, dryRun bool) *imageRehoster {
+	return &imageRehoster{
+		storage:  s.storageManager,
+		ctx:      ctx,
+		bundle:   bundle,
+		dryRun:   dryRun,
+		uploaded: make(map[string]*storage.UploadResponse),
+	}
+}
+
+// rehost points the images of doc at uploaded copies owned by ownerID. Images
+// missing from the bundle, or every remote image when there is no bundle,
+// keep their source.
+func (r *imageRehoster) rehost(doc *tiptap.Node, ownerID uuid.UUID) dto.ImportImageCounts {
+	var counts dto.ImportImageCounts
+	for _, img := range doc.Images() {
+		src, _ := img.Attrs["src"].(string)
+		upload, found := r.copy(src, ownerID, "content")
+		switch {
+		case upload != nil:
+			img.Attrs["src"] = upload.URL
+			img.Attrs["dataImageId"] = upload.ImageID.String()
+			counts.Rehosted++
+		case found:
+			counts.Rehosted++ // dry run
+		case r.bundle == nil && isRemoteURL(src):
+			counts.Remote++
+		default:
+			counts.Missing++
+		}
+	}
+	return counts
+}
+
+// cover returns the URL to use for a cover image found at src.
+func (r *imageRehoster) cover(src string, ownerID uuid.UUID) (string, dto.ImportImageCounts) {
+	var counts dto.ImportImageCounts
+	upload, found := r.copy(src, ownerID, "title")
+	switch {
+	case upload != nil:
+		counts.Rehosted++
+		return upload.URL, counts
+	case found:
+		counts.Rehosted++
+	case isRemoteURL(src):
+		counts.Remote++
+	default:
+		counts.Missing++
+		return "", counts
+	}
+	return src, counts
+}
+
+// copy uploads the bundle file src refers to. found reports whether the
+// bundle has it; in a dry run nothing is uploaded and upload stays nil.
+func (r *imageRehoster) copy(src string, ownerID uuid.UUID, imageType string) (upload *storage.UploadResponse, found bool) {
+	if r.bundle == nil || src == "" {
+		return nil, false
+	}
+	f, ok := r.bundle.Find(src)
+	if !ok {
+		return nil, false
+	}
+	if r.dryRun {
+		return nil, true
+	}
+	key := ownerID.String() + "|" + imageType + "|" + f.Name
+	if upload, ok := r.uploaded[key]; ok {
+		return upload, true
+	}
+	upload, err := r.upload(f, ownerID, imageType)
+	if err != nil {
+		log.Printf("import: failed to rehost %s: %v", f.Name, err)
+		return nil, false
+	}
+	r.uploaded[key] = upload
+	return upload, true
+}
+
+func (r *imageRehoster) upload(f *zip.File, ownerID uuid.UUID, imageType string) (*storage.UploadResponse, error) {
+	header, err := r.bundle.FileHeader(f)
+	if err != nil {
+		return nil, err
+	}
+	return r.storage.UploadImage(r.ctx, &storage.UploadRequest{File: header, UserID: ownerID, Type: imageType})
+}
+
+func isRemoteURL(src string) bool {
+	u, err := url.Parse(src)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// ConvertDocument turns a Markdown or HTML document into editor content for
+// the client to open as a new post. Nothing is saved apart from the images
+// rehosted from the bundle.
+func (s *InsightService) ConvertDocument(ctx context.Context, userID uuid.UUID, req *dto.ConvertDocumentRequest, bundle *storage.Bundle) (*dto.ConvertDocumentResponse, error) {
+	allowed, err := s.userCan(userID, constants.PermPublish)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, apperror.NewForbidden("your role does not allow publishing posts")
+	}
+
+	var doc *tiptap.Node
+	switch req.Format {
+	case dto.ImportMarkdown:
+		doc = tiptap.FromMarkdown(req.Content)
+	case dto.ImportHTML:
+		doc = tiptap.FromHTML(req.Content)
+	default:
+		return nil, apperror.NewBadRequest("format must be markdown or html")
+	}
+
+	title := takeTitle(doc)
+	counts := s.newImageRehoster(ctx, bundle, false).rehost(doc, userID)
+	content, err := doc.JSON()
+	if err != nil {
+		return nil, apperror.NewInternal("failed to encode content", err)
+	}
+	return &dto.ConvertDocumentResponse{Title: title, Content: content, Images: counts}, nil
+}
+
+// takeTitle removes a level-one heading opening doc and returns its text.
+func takeTitle(doc *tiptap.Node) string {
+	if len(doc.Content) == 0 {
+		return ""
+	}
+	first := doc.Content[0]
+	if first.Type != "heading" || first.Attrs["level"] != 1 {
+		return ""
+	}
+	doc.Content = tiptap.Doc(doc.Content[1:]).Content
+	return strings.TrimSpace(first.PlainText())
+}
+
+// wordPressImport is the state shared by the posts of one WXR import.
+type wordPressImport struct {
+	userID      uuid.UUID
+	dryRun      bool
+	export      *wxr.Export
+	now         time.Time
+	images      *imageRehoster
+	attachments map[int64]string
+	authorMap   map[string]*entities.User // accounts chosen for author logins
+	authors     map[string]uuid.UUID      // account of each author login
+	commenters  map[string]*uuid.UUID     // by lowercase email, nil without an account
+	slugs       map[string]bool           // claimed by earlier posts of the import
+	categories  map[string]bool           // names looked up, true if they exist
+	tags        map[string]bool
+	report      *dto.ImportReport
+}
+
+// ImportWordPress creates posts, with their taxonomy and comments, from a
+// WordPress export. authorMap gives the username each author login is to be
+// imported as; posts of other authors go to the user running the import.
+// Posts whose slug is already in use are taken to be imported before and
+// skipped, so an import can be run again after a partial failure. Each post is
+// imported in its own transaction.
+func (s *InsightService) ImportWordPress(ctx context.Context, userID uuid.UUID, export *wxr.Export, bundle *storage.Bundle, authorMap map[string]string, dryRun bool) (*dto.ImportReport, error) {
+	allowed, err := s.userCan(userID, constants.PermEditAnyPost)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, apperror.NewForbidden("your role does not allow importing posts")
+	}
+	accounts, err := s.importAuthorAccounts(authorMap)
+	if err != nil {
+		return nil, err
+	}
+
+	imp := &wordPressImport{
+		userID:      userID,
+		dryRun:      dryRun,
+		export:      export,
+		now:         time.Now(),
+		images:      s.newImageRehoster(ctx, bundle, dryRun),
+		attachments: export.AttachmentURLs(),
+		authorMap:   accounts,
+		authors:     make(map[string]uuid.UUID),
+		commenters:  make(map[string]*uuid.UUID),
+		slugs:       make(map[string]bool),
+		categories:  make(map[string]bool),
+		tags:        make(map[string]bool),
+		report: &dto.ImportReport{
+			DryRun:        dryRun,
+			Authors:       []dto.ImportAuthorResult{},
+			NewCategories: []string{},
+			NewTags:       []string{},
+			Posts:         []dto.ImportPostResult{},
+		},
+	}
+	for _, author := range export.Authors {
+		if _, err := s.importAuthor(imp, author.Login); err != nil {
+			return nil, err
+		}
+	}
+
+	report := imp.report
+	for _, item := range export.Posts() {
+		result := s.importWordPressPost(imp, item)
+		report.Posts = append(report.Posts, result)
+		report.Summary.Posts++
+		switch result.Action {
+		case dto.ImportActionCreate, dto.ImportActionCreated:
+			report.Summary.Created++
+			report.Summary.Comments += result.Comments
+			report.Summary.CommentsSkipped += result.CommentsSkipped
+			report.Summary.Images.Add(result.Images)
+		case dto.ImportActionSkipped:
+			report.Summary.Skipped++
+		case dto.ImportActionFailed:
+			report.Summary.Failed++
+		}
+	}
+
+	if !dryRun && report.Summary.Created > 0 {
+		s.invalidatePostListCaches()
+		log.Printf("import: %d posts imported from WordPress by %s", report.Summary.Created, userID)
+	}
+	return report, nil
+}
+
+// importAuthorAccounts looks up the accounts of an author mapping. Naming
+// an account that does not exist fails the whole import, before anything is
+// created.
+func (s *InsightService) importAuthorAccounts(authorMap map[string]string) (map[string]*entities.User, error) {
+	accounts := make(map[string]*entities.User, len(authorMap))
+	unknown := map[string]string{}
+	for login, username := range authorMap {
+		username = strings.TrimSpace(username)
+		if username == "" {
+			continue
+		}
+		user, err := s.userRepo.FindByUsername(username)
+		switch {
+		case err == nil:
+			accounts[login] = user
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			unknown[login] = username
+		default:
+			return nil, apperror.NewInternal("failed to look up author account", err)
+		}
+	}
+	if len(unknown) > 0 {
+		return nil, apperror.NewInvalid("author mapping names accounts that do not exist", unknown)
+	}
+	return accounts, nil
+}
+
+// importAuthor returns the account posts by login go to: the one the author
+// mapping gives or, without one, the user running the import. Authors are
+// never matched to accounts by email or username on their own, as that would
+// hand posts to whoever registered with the same name.
+func (s *InsightService) importAuthor(imp *wordPressImport, login string) (uuid.UUID, error) {
+	if id, ok := imp.authors[login]; ok {
+		return id, nil
+	}
+	author, ok := imp.export.Author(login)
+	if !ok {
+		author = wxr.Author{Login: login}
+	}
+	result := dto.ImportAuthorResult{Login: author.Login, Email: author.Email, DisplayName: author.DisplayName}
+
+	id := imp.userID
+	if user := imp.authorMap[login]; user != nil {
+		id = user.ID
+		result.UserID = &user.ID
+		result.Username = user.Username
+		result.Matched = true
+	}
+	imp.authors[login] = id
+	imp.report.Authors = append(imp.report.Authors, result)
+	return id, nil
+}
+
+// importCommenter returns the account with the commenter's email, or nil.
+func (s *InsightService) importCommenter(imp *wordPressImport, email string) (*uuid.UUID, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" {
+		return nil, nil
+	}
+	if id, ok := imp.commenters[email]; ok {
+		return id, nil
+	}
+	user, err := s.userRepo.FindByEmail(email)
+	switch {
+	case err == nil:
+		imp.commenters[email] = &user.ID
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		imp.commenters[email] = nil
+	default:
+		return nil, apperror.NewInternal("failed to look up commenter", err)
+	}
+	return imp.commenters[email], nil
+}
+
+// importSlug picks the slug for item: its WordPress slug when it has one,
+// transliterated like titles are, otherwise one derived from the title.
+// existing reports that the WordPress slug is already taken.
+func (s *InsightService) importSlug(imp *wordPressImport, item wxr.Item, title string) (slug string, pinned, existing bool, err error) {
+	wpSlug, err := url.PathUnescape(item.Slug)
+	if err != nil {
+		wpSlug = item.Slug
+	}
+	if slug = utils.CreateSlug(wpSlug); slug != "" && len(slug) <= maxSlugLength {
+		if imp.slugs[slug] {
+			return slug, true, true, nil
+		}
+		taken, err := s.slugTaken(slug, uuid.Nil)
+		if err != nil {
+			return "", false, false, err
+		}
+		return slug, true, taken, nil
+	}
+	slug, err = s.derivedSlug(title, uuid.Nil)
+	return slug, false, false, err
+}
+
+// importStatus maps a WordPress status. Private posts become drafts, since
+// nothing here is visible to its author alone, and pending ones go to review.
+func importStatus(item wxr.Item, now time.Time) (string, *time.Time) {
+	publishedAt := item.PostedAt
+	if publishedAt.IsZero() || (item.Status == "publish" && publishedAt.After(now)) {
+		publishedAt = now
+	}
+	switch item.Status {
+	case "publish":
+		return entities.PostStatusPublished, &publishedAt
+	case "future":
+		if publishedAt.After(now) {
+			return entities.PostStatusScheduled, &publishedAt
+		}
+		return entities.PostStatusPublished, &publishedAt
+	case "pending":
+		return entities.PostStatusInReview, nil
+	}
+	return entities.PostStatusDraft, nil
+}
+
+// importTermNames lists the names of terms, leaving out WordPress' default
+// category, and records the ones that do not exist yet.
+func importTermNames(terms []wxr.Term, known map[string]bool, exists func(string) (bool, error), newNames *[]string) ([]string, error) {
+	var names []string
+	seen := make(map[string]bool)
+	for _, term := range terms {
+		if term.Name == "" || term.Slug == wordPressDefaultCategory || seen[term.Name] {
+			continue
+		}
+		seen[term.Name] = true
+		names = append(names, term.Name)
+		if _, ok := known[term.Name]; ok {
+			continue
+		}
+		found, err := exists(term.Name)
+		if err != nil {
+			return nil, err
+		}
+		known[term.Name] = found
+		if !found {
+			*newNames = append(*newNames, term.Name)
+		}
+	}
+	return names, nil
+}
+
+func findResult(err error) (bool, error) {
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return false, nil
+	default:
+		return false, apperror.NewInternal("failed to look up taxonomy", err)
+	}
+}
+
+// plannedComment is a WordPress comment that will be imported. Replies hang
+// off the top-level comment of their thread, as replies here do not nest.
+type plannedComment struct {
+	comment  wxr.Comment
+	userID   uuid.UUID
+	content  string
+	threadID int64 // WordPress ID of the top-level comment, 0 for one itself
+}
+
+// planComments picks the comments of item that can be imported: approved
+// comments, not pingbacks, written by someone with an account.
+func (s *InsightService) planComments(imp *wordPressImport, item wxr.Item) ([]plannedComment, int, error) {
+	byID := make(map[int64]wxr.Comment)
+	for _, c := range item.Comments {
+		if c.Approved && (c.Type == "" || c.Type == "comment") {
+			byID[c.ID] = c
+		}
+	}
+	thread := func(c wxr.Comment) (int64, bool) {
+		for hops := 0; c.ParentID != 0; hops++ {
+			parent, ok := byID[c.ParentID]
+			if !ok || hops > len(byID) {
+				return 0, false
+			}
+			c = parent
+		}
+		return c.ID, true
+	}
+
+	var planned []plannedComment
+	imported := make(map[int64]bool)
+	for _, c := range item.Comments {
+		if _, ok := byID[c.ID]; !ok || c.ParentID != 0 {
+			continue
+		}
+		p, err := s.planComment(imp, c, 0)
+		if err != nil {
+			return nil, 0, err
+		}
+		if p != nil {
+			planned = append(planned, *p)
+			imported[c.ID] = true
+		}
+	}
+	for _, c := range item.Comments {
+		if _, ok := byID[c.ID]; !ok || c.ParentID == 0 {
+			continue
+		}
+		root, ok := thread(c)
+		if !ok || !imported[root] {
+			continue
+		}
+		p, err := s.planComment(imp, c, root)
+		if err != nil {
+			return nil, 0, err
+		}
+		if p != nil {
+			planned = append(planned, *p)
+		}
+	}
+	sort.SliceStable(planned, func(i, j int) bool {
+		if (planned[i].threadID == 0) != (planned[j].threadID == 0) {
+			return planned[i].threadID == 0
+		}
+		return planned[i].comment.PostedAt.Before(planned[j].comment.PostedAt)
+	})
+	return planned, len(item.Comments) - len(planned), nil
+}
+
+func (s *InsightService) planComment(imp *wordPressImport, c wxr.Comment, threadID int64) (*plannedComment, error) {
+	userID, err := s.importCommenter(imp, c.AuthorEmail)
+	if err != nil || userID == nil {
+		return nil, err
+	}
+	content := strings.TrimSpace(tiptap.FromHTML(wxr.AutoParagraph(c.Content)).PlainText())
+	if content == "" {
+		return nil, nil
+	}
+	return &plannedComment{comment: c, userID: *userID, content: content, threadID: threadID}, nil
+}
+
+func (s *InsightService) importWordPressPost(imp *wordPressImport, item wxr.Item) dto.ImportPostResult {
+	result := dto.ImportPostResult{SourceID: item.ID, Title: item.Title, Author: item.Creator}
+	fail := func(err error) dto.ImportPostResult {
+		log.Printf("import: failed to import WordPress post %d: %v", item.ID, err)
+		result.Action = dto.ImportActionFailed
+		result.Reason = apperror.UserMessage(err)
+		return result
+	}
+
+	title := item.Title
+	if title == "" {
+		title = "Untitled"
+	}
+	ownerID, err := s.importAuthor(imp, item.Creator)
+	if err != nil {
+		return fail(err)
+	}
+
+	// The content gets the checks of content saved from the editor, before
+	// anything is claimed or copied for it.
+	doc := tiptap.FromHTML(item.HTML())
+	content, err := doc.JSON()
+	if err != nil {
+		return fail(apperror.NewInternal("failed to encode content", err))
+	}
+	if err := validateContent(content); err != nil {
+		return fail(err)
+	}
+
+	slug, pinned, existing, err := s.importSlug(imp, item, title)
+	if err != nil {
+		return fail(err)
+	}
+	result.Slug = slug
+	if existing {
+		result.Action = dto.ImportActionSkipped
+		result.Reason = fmt.Sprintf("a post with slug %q already exists", slug)
+		return result
+	}
+	imp.slugs[slug] = true
+
+	status, publishedAt := importStatus(item, imp.now)
+	result.Status = status
+
+	categoryNames, err := importTermNames(item.Categories, imp.categories, func(name string) (bool, error) {
+		_, err := s.categoryRepo.FindByName(name)
+		return findResult(err)
+	}, &imp.report.NewCategories)
+	if err != nil {
+		return fail(err)
+	}
+	tagNames, err := importTermNames(item.Tags, imp.tags, func(name string) (bool, error) {
+		_, err := s.tagRepo.FindByName(name)
+		return findResult(err)
+	}, &imp.report.NewTags)
+	if err != nil {
+		return fail(err)
+	}
+
+	comments, skipped, err := s.planComments(imp, item)
+	if err != nil {
+		return fail(err)
+	}
+	result.Comments = len(comments)
+	result.CommentsSkipped = skipped
+
+	result.Images = imp.images.rehost(doc, ownerID)
+	coverImage := ""
+	if src := imp.attachments[item.ThumbnailID]; src != "" {
+		var counts dto.ImportImageCounts
+		coverImage, counts = imp.images.cover(src, ownerID)
+		result.Images.Add(counts)
+	}
+	if coverImage == "" {
+		coverImage = defaultCoverImage()
+	}
+
+	if imp.dryRun {
+		result.Action = dto.ImportActionCreate
+		return result
+	}
+
+	content, err = doc.JSON()
+	if err != nil {
+		return fail(apperror.NewInternal("failed to encode content", err))
+	}
+	processedJSON := s.storageManager.ProcessJSONContent(content)
+
+	excerpt := ""
+	if item.Excerpt != "" {
+		excerpt = strings.TrimSpace(tiptap.FromHTML(item.Excerpt).PlainText())
+	}
+	if excerpt == "" {
+		excerpt = s.extractExcerpt(processedJSON)
+	}
+
+	createdAt := item.PostedAt
+	if createdAt.IsZero() || createdAt.After(imp.now) {
+		createdAt = imp.now
+	}
+	post := &entities.Post{
+		ID:         uuid.NewV4(),
+		UserID:     ownerID,
+		Title:      title,
+		CoverImage: coverImage,
+		Slug:       slug,
+		SlugPinned: pinned,
+		Excerpt:    excerpt,
+		Version:    1,
+		CreatedAt:  createdAt,
+		UpdatedAt:  imp.now,
+	}
+	s.applyContentStats(post, processedJSON)
+	if err := applyPostStatus(post, status, publishedAt, imp.now); err != nil {
+		return fail(err)
+	}
+	for _, c := range comments {
+		if c.threadID == 0 {
+			post.CommentsCount++
+		}
+	}
+
+	err = withTx(s.db, func(tx *gorm.DB) error {
+		txPostRepo := s.postRepo.WithTx(tx)
+		if err := txPostRepo.Create(post); err != nil {
+			return apperror.NewInternal("failed to create post", err)
+		}
+		postContent := &entities.PostContent{
+			ID:        uuid.NewV4(),
+			PostID:    post.ID,
+			Content:   processedJSON,
+			CreatedAt: createdAt,
+			UpdatedAt: imp.now,
+		}
+		if err := s.postContentRepo.WithTx(tx).Create(postContent); err != nil {
+			return apperror.NewInternal("failed to create post content", err)
+		}
+
+		if len(categoryNames) > 0 {
+			categories, err := s.findOrCreateCategories(categoryNames, s.categoryRepo.WithTx(tx))
+			if err != nil {
+				return err
+			}
+			if err := txPostRepo.AppendCategories(post, categories); err != nil {
+				return apperror.NewInternal("failed to associate categories", err)
+			}
+		}
+		if len(tagNames) > 0 {
+			tags, err := s.findOrCreateTags(tagNames, s.tagRepo.WithTx(tx))
+			if err != nil {
+				return err
+			}
+			if err := txPostRepo.AppendTags(post, tags); err != nil {
+				return apperror.NewInternal("failed to associate tags", err)
+			}
+		}
+
+		owner := &entities.PostAuthor{
+			PostID:      post.ID,
+			UserID:      ownerID,
+			Role:        entities.PostAuthorOwner,
+			Status:      entities.InvitationAccepted,
+			CreatedAt:   post.CreatedAt,
+			RespondedAt: &post.CreatedAt,
+		}
+		if err := s.postAuthorRepo.WithTx(tx).Save(owner); err != nil {
+			return apperror.NewInternal("failed to add post owner", err)
+		}
+
+		if err := s.importComments(tx, post.ID, comments, imp.now); err != nil {
+			return err
+		}
+		if _, err := s.recordRevision(tx, post, processedJSON, ownerID); err != nil {
+			return err
+		}
+		return s.recordStatusEvent(tx, post.ID, "", post.Status, &imp.userID, "imported from WordPress")
+	})
+	if err != nil {
+		imp.slugs[slug] = false
+		return fail(err)
+	}
+
+	// Create image references after commit (best-effort)
+	for _, imgID := range s.storageManager.ExtractImageIDsFromJSON(processedJSON) {
+		_ = s.createImageReference(imgID, post.ID, "content")
+	}
+
+	result.Action = dto.ImportActionCreated
+	result.PostID = &post.ID
+	return result
+}
+
+// importComments saves planned comments within tx. Top-level comments come
+// first, so every reply's thread is saved before it.
+func (s *InsightService) importComments(tx *gorm.DB, postID uuid.UUID, comments []plannedComment, now time.Time) error {
+	commentRepo := s.commentRepo.WithTx(tx)
+	replyRepo := s.replyRepo.WithTx(tx)
+	threads := make(map[int64]uuid.UUID)
+	for _, c := range comments {
+		postedAt := c.comment.PostedAt
+		if postedAt.IsZero() {
+			postedAt = now
+		}
+		if c.threadID == 0 {
+			comment := &entities.Comment{
+				ID:        uuid.NewV4(),
+				PostID:    postID,
+				UserID:    c.userID,
+				Content:   c.content,
+				CreatedAt: postedAt,
+				UpdatedAt: postedAt,
+			}
+			if err := commentRepo.Create(comment); err != nil {
+				return apperror.NewInternal("failed to create comment", err)
+			}
+			threads[c.comment.ID] = comment.ID
+			continue
+		}
+		commentID, ok := threads[c.threadID]
+		if !ok {
+			continue
+		}
+		reply := &entities.Reply{
+			ID:        uuid.NewV4(),
+			CommentID: commentID,
+			PostID:    postID,
+			UserID:    c.userID,
+			Content:   c.content,
+			CreatedAt: postedAt,
+			UpdatedAt: postedAt,
+		}
+		if err := replyRepo.Create(reply); err != nil {
+			return apperror.NewInternal("failed to create reply", err)
+		}
+	}
+	return nil
+}
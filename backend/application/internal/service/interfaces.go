@@ -7,18 +7,109 @@ import (
 	"github.com/pdhoang91/blog/internal/dto"
 	"github.com/pdhoang91/blog/internal/entities"
 	"github.com/pdhoang91/blog/pkg/storage"
+	"github.com/pdhoang91/blog/pkg/wxr"
 	uuid "github.com/satori/go.uuid"
 )
 
 // --- Domain-specific service interfaces (ISP-compliant) ---
 
 type AuthService interface {
-	Register(req *dto.CreateUserRequest) (*dto.LoginResponse, error)
-	Login(req *dto.LoginRequest) (*dto.LoginResponse, error)
-	GoogleLogin() (string, error)
-	GoogleCallback(code string) (*dto.LoginResponse, error)
-	Logout() error
-	RefreshToken() error
+	Register(req *dto.CreateUserRequest, client *dto.ClientInfo) (*dto.LoginResponse, error)
+	Login(req *dto.LoginRequest, client *dto.ClientInfo) (*dto.LoginResponse, error)
+	VerifyMFA(req *dto.VerifyMFARequest, client *dto.ClientInfo) (*dto.LoginResponse, error)
+	ListOAuthProviders() []dto.OAuthProviderResponse
+	OAuthLogin(provider, linkTicket string) (string, string, error)
+	OAuthCallback(provider, code, state string, client *dto.ClientInfo) (*dto.OAuthCallbackResult, error)
+	Logout(sessionID uuid.UUID) error
+	RefreshToken(refreshToken string, client *dto.ClientInfo) (*dto.LoginResponse, error)
+	ValidateSession(sessionID string) (string, error)
+	VerifyEmail(token string) error
+	ResendVerification(userID uuid.UUID) error
+	ForgotPassword(email string) error
+	ResetPassword(token, newPassword string) error
+	ListIdentities(userID uuid.UUID) (*dto.IdentitiesResponse, error)
+	StartIdentityLink(userID uuid.UUID, provider string) (string, error)
+	UnlinkIdentity(userID uuid.UUID, provider string) error
+}
+
+type SessionService interface {
+	ListSessions(userID, currentSessionID uuid.UUID) ([]*dto.SessionResponse, error)
+	RevokeSession(userID, sessionID uuid.UUID) error
+	RevokeAllSessions(userID uuid.UUID) (int, error)
+}
+
+type MFAService interface {
+	GetMFAStatus(userID uuid.UUID, role string) (*dto.MFAStatusResponse, error)
+	EnrollMFA(userID uuid.UUID) (*dto.MFAEnrollResponse, error)
+	ActivateMFA(userID, sessionID uuid.UUID, code string) (*dto.MFARecoveryCodesResponse, error)
+	DisableMFA(userID uuid.UUID, role, code string) error
+	RegenerateRecoveryCodes(userID uuid.UUID, code string) (*dto.MFARecoveryCodesResponse, error)
+	CheckMFA(sessionID, role string) error
+	ListMFAPolicies() ([]*dto.MFAPolicyResponse, error)
+	SetMFAPolicy(adminID uuid.UUID, role string, required bool) (*dto.MFAPolicyResponse, error)
+}
+
+type RoleService interface {
+	ListRoles() []*dto.RoleResponse
+	ChangeUserRole(adminID, userID uuid.UUID, role string) (*dto.UserResponse, error)
+}
+
+type RevisionService interface {
+	ListPostRevisions(userID, postID uuid.UUID, req *dto.PaginationRequest) ([]*dto.PostRevisionResponse, int64, error)
+	GetPostRevision(userID, postID uuid.UUID, number int) (*dto.PostRevisionResponse, error)
+	DiffPostRevisions(userID, postID uuid.UUID, from, to int) (*dto.RevisionDiffResponse, error)
+	RestorePostRevision(userID, postID uuid.UUID, number, version int) (*dto.PostResponse, error)
+}
+
+type AutosaveService interface {
+	GetAutosave(userID, postID uuid.UUID) (*dto.AutosaveResponse, error)
+	SaveAutosave(userID, postID uuid.UUID, expectedVersion int, req *dto.AutosaveRequest) (*dto.AutosaveResponse, error)
+	DiscardAutosave(userID, postID uuid.UUID) error
+}
+
+type ReviewService interface {
+	SubmitPostForReview(userID, postID uuid.UUID, req *dto.SubmitForReviewRequest) (*dto.PostResponse, error)
+	ReviewPost(userID, postID uuid.UUID, req *dto.ReviewPostRequest) (*dto.PostResponse, error)
+	ListReviewQueue(req *dto.PaginationRequest) ([]*dto.PostResponse, int64, error)
+	GetPostHistory(userID, postID uuid.UUID) ([]*dto.PostStatusEventResponse, error)
+}
+
+type SeriesService interface {
+	GetSeriesBySlug(slug string) (*dto.SeriesResponse, error)
+	ListMySeries(userID uuid.UUID) ([]*dto.SeriesResponse, error)
+	CreateSeries(userID uuid.UUID, req *dto.CreateSeriesRequest) (*dto.SeriesResponse, error)
+	UpdateSeries(userID, id uuid.UUID, req *dto.UpdateSeriesRequest) (*dto.SeriesResponse, error)
+	DeleteSeries(userID, id uuid.UUID) error
+}
+
+type TrashService interface {
+	ListTrash(userID uuid.UUID, req *dto.TrashListRequest) ([]*dto.TrashItemResponse, int64, error)
+	RestoreTrashItem(userID uuid.UUID, itemType string, id uuid.UUID) error
+	PurgeTrashItem(userID uuid.UUID, itemType string, id uuid.UUID) error
+}
+
+type ImportService interface {
+	ConvertDocument(ctx context.Context, userID uuid.UUID, req *dto.ConvertDocumentRequest, bundle *storage.Bundle) (*dto.ConvertDocumentResponse, error)
+	ImportWordPress(ctx context.Context, userID uuid.UUID, export *wxr.Export, bundle *storage.Bundle, authorMap map[string]string, dryRun bool) (*dto.ImportReport, error)
+}
+
+type EmbedService interface {
+	UnfurlLink(ctx context.Context, req *dto.UnfurlRequest) (*dto.EmbedResponse, error)
+}
+
+type PostAuthorService interface {
+	ListPostAuthors(userID, postID uuid.UUID) ([]*dto.PostAuthorResponse, error)
+	InvitePostAuthor(userID, postID uuid.UUID, req *dto.InvitePostAuthorRequest) (*dto.PostAuthorResponse, error)
+	RemovePostAuthor(userID, postID, authorID uuid.UUID) error
+	ListMyInvitations(userID uuid.UUID) ([]*dto.PostInvitationResponse, error)
+	RespondToInvitation(userID, postID uuid.UUID, accept bool) error
+}
+
+type APITokenService interface {
+	CreateAPIToken(userID uuid.UUID, req *dto.CreateAPITokenRequest) (*dto.CreateAPITokenResponse, error)
+	ListAPITokens(userID uuid.UUID) ([]*dto.APITokenResponse, error)
+	RevokeAPIToken(userID, tokenID uuid.UUID) error
+	AuthenticateAPIToken(token string) (*dto.APITokenPrincipal, error)
 }
 
 type UserService interface {
@@ -35,9 +126,11 @@ type PostService interface {
 	CreatePost(userID uuid.UUID, req *dto.CreatePostRequest) (*dto.PostResponse, error)
 	GetPost(id uuid.UUID) (*dto.PostResponse, error)
 	GetPostEntity(id uuid.UUID) (*entities.Post, error)
-	GetPostBySlug(slug string) (*dto.PostResponse, error)
+	GetPostBySlug(slug string) (*dto.PostResponse, string, error)
 	UpdatePost(userID uuid.UUID, id uuid.UUID, req *dto.UpdatePostRequest) (*dto.PostResponse, error)
+	UpdateAnyPost(userID uuid.UUID, id uuid.UUID, req *dto.UpdatePostRequest) (*dto.PostResponse, error)
 	DeletePost(userID uuid.UUID, id uuid.UUID) error
+	DeleteAnyPost(userID uuid.UUID, id uuid.UUID) error
 	ListPosts(req *dto.PaginationRequest) ([]*dto.PostResponse, int64, error)
 	GetUserPosts(userID uuid.UUID, req *dto.PaginationRequest) ([]*dto.PostResponse, int64, error)
 	GetLatestPosts(limit int) ([]*dto.PostResponse, error)
@@ -47,6 +140,13 @@ type PostService interface {
 	GetPostsByTag(tagName string, req *dto.PaginationRequest) ([]*dto.PostResponse, int64, error)
 	GetHomeData() (*dto.HomeResponse, error)
 	GetArchiveSummary() ([]*dto.ArchiveSummaryItem, error)
+	// ListMyPosts and GetMyPost cover the author's own posts in any status.
+	ListMyPosts(userID uuid.UUID, status string, req *dto.PaginationRequest) ([]*dto.PostResponse, int64, error)
+	GetMyPost(userID, id uuid.UUID) (*dto.PostResponse, error)
+	// ExportPost renders a published post as Markdown or HTML; ExportMyPost
+	// does so for its authors in any status.
+	ExportPost(id uuid.UUID, format string) (*dto.PostExport, error)
+	ExportMyPost(userID, id uuid.UUID, format string) (*dto.PostExport, error)
 }
 
 type CommentService interface {
@@ -103,8 +203,20 @@ type SearchService interface {
 // Kept for backward compatibility during migration.
 type Service interface {
 	AuthService
+	SessionService
+	MFAService
+	APITokenService
+	RoleService
 	UserService
 	PostService
+	RevisionService
+	AutosaveService
+	PostAuthorService
+	ReviewService
+	SeriesService
+	TrashService
+	ImportService
+	EmbedService
 	CommentService
 	CategoryService
 	TagService
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/pdhoang91/blog/internal/apperror"
+	"github.com/pdhoang91/blog/internal/entities"
+)
+
+const (
+	defaultLoginMaxFailures = 5
+	defaultLoginLockout     = 15 * time.Minute
+)
+
+// loginMaxFailures is how many consecutive failed sign-ins (wrong password or
+// second factor) lock an account, configurable through LOGIN_MAX_FAILURES.
+func loginMaxFailures() int {
+	if n, err := strconv.Atoi(os.Getenv("LOGIN_MAX_FAILURES")); err == nil && n > 0 {
+		return n
+	}
+	return defaultLoginMaxFailures
+}
+
+// loginLockoutDuration is how long a locked account stays locked, configurable
+// through LOGIN_LOCKOUT_DURATION as a Go duration (e.g. "30m").
+func loginLockoutDuration() time.Duration {
+	if d, err := time.ParseDuration(os.Getenv("LOGIN_LOCKOUT_DURATION")); err == nil && d > 0 {
+		return d
+	}
+	return defaultLoginLockout
+}
+
+func accountLockedError(until time.Time) error {
+	return apperror.NewTooManyRequests("account temporarily locked after too many failed sign-in attempts", time.Until(until))
+}
+
+// checkAccountLock rejects sign-ins while the account is locked, before any
+// credential is checked.
+func checkAccountLock(user *entities.User) error {
+	if user.LockedUntil != nil && time.Now().Before(*user.LockedUntil) {
+		return accountLockedError(*user.LockedUntil)
+	}
+	return nil
+}
+
+// recordFailedLogin counts a failed sign-in and returns the error to report:
+// invalid credentials, or a lockout once the limit is reached.
+func (s *InsightService) recordFailedLogin(user *entities.User, failure error) error {
+	now := time.Now()
+	lockedUntil, err := s.userRepo.RecordFailedLogin(user.ID, loginMaxFailures(), now.Add(loginLockoutDuration()))
+	if err != nil {
+		log.Printf("auth: failed to record failed login for user %s: %v", user.ID, err)
+		return failure
+	}
+	if lockedUntil != nil && now.Before(*lockedUntil) {
+		log.Printf("auth: user %s locked until %s after repeated failed logins", user.ID, lockedUntil.Format(time.RFC3339))
+		return accountLockedError(*lockedUntil)
+	}
+	return failure
+}
+
+func (s *InsightService) resetFailedLogins(user *entities.User) {
+	if user.FailedLoginCount == 0 && user.LockedUntil == nil {
+		return
+	}
+	if err := s.userRepo.ResetFailedLogins(user.ID); err != nil {
+		log.Printf("auth: failed to reset failed logins for user %s: %v", user.ID, err)
+	}
+}
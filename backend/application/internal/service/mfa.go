@@ -0,0 +1,493 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pdhoang91/blog/constants"
+	"github.com/pdhoang91/blog/internal/apperror"
+	"github.com/pdhoang91/blog/internal/dto"
+	"github.com/pdhoang91/blog/internal/entities"
+	jwtUtil "github.com/pdhoang91/blog/pkg/jwt"
+	"github.com/pdhoang91/blog/pkg/totp"
+	uuid "github.com/satori/go.uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	mfaChallengeTTL      = 5 * time.Minute
+	mfaMaxAttempts       = 5
+	mfaPolicyCacheTTL    = time.Minute
+	mfaRecoveryCodeCount = 10
+	// totpSkew accepts codes from one step either side to tolerate clock drift.
+	totpSkew = 1
+)
+
+func mfaAttemptsCacheKey(jti string) string {
+	return "mfa_attempts:" + jti
+}
+
+func mfaPolicyCacheKey(role string) string {
+	return "mfa_policy:" + role
+}
+
+func sessionMFACacheKey(sessionID string) string {
+	return "session_mfa:" + sessionID
+}
+
+// mfaIssuer is the account label shown in authenticator apps.
+func mfaIssuer() string {
+	if issuer := os.Getenv("MFA_ISSUER"); issuer != "" {
+		return issuer
+	}
+	return "Insight"
+}
+
+// newRecoveryCodes returns plaintext codes formatted as "xxxxx-xxxxx".
+func newRecoveryCodes(n int) ([]string, error) {
+	enc := base32.StdEncoding.WithPadding(base32.NoPadding)
+	codes := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		b := make([]byte, 7)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		raw := strings.ToLower(enc.EncodeToString(b))[:10]
+		codes = append(codes, raw[:5]+"-"+raw[5:])
+	}
+	return codes, nil
+}
+
+// hashRecoveryCode normalises user input so dashes, spaces and case do not matter.
+func hashRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	code = strings.NewReplacer("-", "", " ", "").Replace(code)
+	return hashToken(code)
+}
+
+func (s *InsightService) findMFA(userID uuid.UUID) (*entities.UserMFA, error) {
+	mfa, err := s.mfaRepo.FindByUserID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, apperror.NewInternal("failed to load two-factor settings", err)
+	}
+	return mfa, nil
+}
+
+// checkTOTP validates a TOTP code and records its step so the same code cannot be replayed.
+func (s *InsightService) checkTOTP(mfa *entities.UserMFA, code string) (bool, error) {
+	step, ok := totp.Validate(mfa.Secret, code, time.Now(), totpSkew)
+	if !ok {
+		return false, nil
+	}
+	advanced, err := s.mfaRepo.AdvanceLastUsedStep(mfa.UserID, step)
+	if err != nil {
+		return false, apperror.NewInternal("failed to verify code", err)
+	}
+	return advanced, nil
+}
+
+// checkSecondFactor accepts either a TOTP code or an unused recovery code.
+func (s *InsightService) checkSecondFactor(mfa *entities.UserMFA, code, recoveryCode string) (bool, error) {
+	if recoveryCode != "" {
+		consumed, err := s.mfaRepo.ConsumeRecoveryCode(mfa.UserID, hashRecoveryCode(recoveryCode))
+		if err != nil {
+			return false, apperror.NewInternal("failed to verify recovery code", err)
+		}
+		if consumed {
+			log.Printf("auth: recovery code used by user %s", mfa.UserID)
+		}
+		return consumed, nil
+	}
+	return s.checkTOTP(mfa, code)
+}
+
+// completeLogin finishes a first-factor login. Accounts with two-factor
+// authentication get an MFA challenge instead of a session.
+func (s *InsightService) completeLogin(user *entities.User, client *dto.ClientInfo) (*dto.LoginResponse, error) {
+	mfa, err := s.findMFA(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !mfa.IsEnabled() {
+		s.resetFailedLogins(user)
+		return s.issueSession(user, client, false)
+	}
+
+	token, err := s.issueActionToken(user, entities.ActionMFALogin, mfaChallengeTTL)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.LoginResponse{MFARequired: true, MFAToken: token}, nil
+}
+
+// VerifyMFA redeems an MFA challenge with a TOTP or recovery code and starts the session.
+// A challenge allows a handful of attempts before the user has to sign in again.
+func (s *InsightService) VerifyMFA(req *dto.VerifyMFARequest, client *dto.ClientInfo) (*dto.LoginResponse, error) {
+	if (req.Code == "") == (req.RecoveryCode == "") {
+		return nil, apperror.NewBadRequest("provide either a code or a recovery code")
+	}
+
+	claims, err := jwtUtil.VerifyActionToken(req.MFAToken, entities.ActionMFALogin)
+	if err != nil {
+		return nil, apperror.NewUnauthorized("invalid or expired MFA challenge")
+	}
+	jti, err := uuid.FromString(claims.ID)
+	if err != nil {
+		return nil, apperror.NewUnauthorized("invalid or expired MFA challenge")
+	}
+	userID, err := uuid.FromString(claims.Subject)
+	if err != nil {
+		return nil, apperror.NewUnauthorized("invalid or expired MFA challenge")
+	}
+
+	attemptsKey := mfaAttemptsCacheKey(claims.ID)
+	var attempts int64
+	if cached, ok := s.cache.Get(attemptsKey); ok {
+		attempts, _ = cached.(int64)
+	}
+	if attempts >= mfaMaxAttempts {
+		return nil, apperror.NewUnauthorized("too many attempts, please sign in again")
+	}
+
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.NewUnauthorized("invalid or expired MFA challenge")
+		}
+		return nil, apperror.NewInternal("failed to find user", err)
+	}
+	if user.Email != claims.Email {
+		return nil, apperror.NewUnauthorized("invalid or expired MFA challenge")
+	}
+	if err := checkAccountLock(user); err != nil {
+		return nil, err
+	}
+	mfa, err := s.findMFA(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !mfa.IsEnabled() {
+		return nil, apperror.NewUnauthorized("invalid or expired MFA challenge")
+	}
+
+	ok, err := s.checkSecondFactor(mfa, req.Code, req.RecoveryCode)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		s.cache.Set(attemptsKey, attempts+1, mfaChallengeTTL)
+		return nil, s.recordFailedLogin(user, apperror.NewUnauthorized("invalid code"))
+	}
+
+	consumed, err := s.actionTokenRepo.Consume(jti, entities.ActionMFALogin)
+	if err != nil {
+		return nil, apperror.NewInternal("failed to redeem MFA challenge", err)
+	}
+	if !consumed {
+		return nil, apperror.NewUnauthorized("invalid or expired MFA challenge")
+	}
+	s.cache.Delete(attemptsKey)
+	s.resetFailedLogins(user)
+
+	return s.issueSession(user, client, true)
+}
+
+// GetMFAStatus reports the user's two-factor settings and whether their role requires it.
+func (s *InsightService) GetMFAStatus(userID uuid.UUID, role string) (*dto.MFAStatusResponse, error) {
+	required, err := s.isMFARequired(role)
+	if err != nil {
+		return nil, err
+	}
+	mfa, err := s.findMFA(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &dto.MFAStatusResponse{Required: required}
+	if mfa.IsEnabled() {
+		remaining, err := s.mfaRepo.CountUnusedRecoveryCodes(userID)
+		if err != nil {
+			return nil, apperror.NewInternal("failed to count recovery codes", err)
+		}
+		status.Enabled = true
+		status.EnabledAt = mfa.EnabledAt
+		status.RecoveryCodesRemaining = remaining
+	}
+	return status, nil
+}
+
+// EnrollMFA generates a new TOTP secret. It takes effect once ActivateMFA confirms
+// a code from the authenticator app; enrolling again replaces a pending secret.
+func (s *InsightService) EnrollMFA(userID uuid.UUID) (*dto.MFAEnrollResponse, error) {
+	user, err := s.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	mfa, err := s.findMFA(userID)
+	if err != nil {
+		return nil, err
+	}
+	if mfa.IsEnabled() {
+		return nil, apperror.NewConflict("two-factor authentication is already enabled")
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, apperror.NewInternal("failed to generate secret", err)
+	}
+	now := time.Now()
+	if err := s.mfaRepo.Save(&entities.UserMFA{
+		UserID:    userID,
+		Secret:    secret,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}); err != nil {
+		return nil, apperror.NewInternal("failed to save two-factor settings", err)
+	}
+
+	return &dto.MFAEnrollResponse{
+		Secret:     secret,
+		OTPAuthURI: totp.URI(mfaIssuer(), user.Email, secret),
+	}, nil
+}
+
+// ActivateMFA confirms enrollment with a first code and returns the recovery codes.
+// The calling session counts as having passed the second factor.
+func (s *InsightService) ActivateMFA(userID, sessionID uuid.UUID, code string) (*dto.MFARecoveryCodesResponse, error) {
+	mfa, err := s.findMFA(userID)
+	if err != nil {
+		return nil, err
+	}
+	if mfa == nil {
+		return nil, apperror.NewBadRequest("start two-factor enrollment first")
+	}
+	if mfa.IsEnabled() {
+		return nil, apperror.NewConflict("two-factor authentication is already enabled")
+	}
+
+	ok, err := s.checkTOTP(mfa, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, apperror.NewBadRequest("invalid code")
+	}
+
+	now := time.Now()
+	codes, records, err := s.buildRecoveryCodes(userID, now)
+	if err != nil {
+		return nil, err
+	}
+	if err := withTx(s.db, func(tx *gorm.DB) error {
+		txRepo := s.mfaRepo.WithTx(tx)
+		// Reload so the last used step recorded by checkTOTP is not overwritten.
+		current, err := txRepo.FindByUserID(userID)
+		if err != nil {
+			return err
+		}
+		current.EnabledAt = &now
+		current.UpdatedAt = now
+		if err := txRepo.Save(current); err != nil {
+			return err
+		}
+		if err := txRepo.ReplaceRecoveryCodes(userID, records); err != nil {
+			return err
+		}
+		return s.sessionRepo.WithTx(tx).MarkMFAVerified(sessionID)
+	}); err != nil {
+		return nil, apperror.NewInternal("failed to enable two-factor authentication", err)
+	}
+	s.cache.Delete(sessionMFACacheKey(sessionID.String()))
+
+	return &dto.MFARecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// DisableMFA turns two-factor authentication off after checking a current code.
+// It is refused while the user's role requires MFA.
+func (s *InsightService) DisableMFA(userID uuid.UUID, role, code string) error {
+	required, err := s.isMFARequired(role)
+	if err != nil {
+		return err
+	}
+	if required {
+		return apperror.NewForbidden("two-factor authentication is required for your role")
+	}
+
+	mfa, err := s.requireEnabledMFA(userID, code)
+	if err != nil {
+		return err
+	}
+	if err := withTx(s.db, func(tx *gorm.DB) error {
+		return s.mfaRepo.WithTx(tx).Delete(mfa.UserID)
+	}); err != nil {
+		return apperror.NewInternal("failed to disable two-factor authentication", err)
+	}
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes after checking a current code.
+func (s *InsightService) RegenerateRecoveryCodes(userID uuid.UUID, code string) (*dto.MFARecoveryCodesResponse, error) {
+	if _, err := s.requireEnabledMFA(userID, code); err != nil {
+		return nil, err
+	}
+
+	codes, records, err := s.buildRecoveryCodes(userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if err := withTx(s.db, func(tx *gorm.DB) error {
+		return s.mfaRepo.WithTx(tx).ReplaceRecoveryCodes(userID, records)
+	}); err != nil {
+		return nil, apperror.NewInternal("failed to store recovery codes", err)
+	}
+	return &dto.MFARecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// requireEnabledMFA loads the user's MFA settings and checks code against them.
+// Either a TOTP code or a recovery code is accepted.
+func (s *InsightService) requireEnabledMFA(userID uuid.UUID, code string) (*entities.UserMFA, error) {
+	mfa, err := s.findMFA(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !mfa.IsEnabled() {
+		return nil, apperror.NewBadRequest("two-factor authentication is not enabled")
+	}
+
+	recoveryCode := ""
+	if len(strings.TrimSpace(code)) != totp.Digits {
+		recoveryCode, code = code, ""
+	}
+	ok, err := s.checkSecondFactor(mfa, code, recoveryCode)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, apperror.NewBadRequest("invalid code")
+	}
+	return mfa, nil
+}
+
+func (s *InsightService) buildRecoveryCodes(userID uuid.UUID, now time.Time) ([]string, []*entities.MFARecoveryCode, error) {
+	codes, err := newRecoveryCodes(mfaRecoveryCodeCount)
+	if err != nil {
+		return nil, nil, apperror.NewInternal("failed to generate recovery codes", err)
+	}
+	records := make([]*entities.MFARecoveryCode, 0, len(codes))
+	for _, code := range codes {
+		records = append(records, &entities.MFARecoveryCode{
+			ID:        uuid.NewV4(),
+			UserID:    userID,
+			CodeHash:  hashRecoveryCode(code),
+			CreatedAt: now,
+		})
+	}
+	return codes, records, nil
+}
+
+func (s *InsightService) isMFARequired(role string) (bool, error) {
+	cacheKey := mfaPolicyCacheKey(role)
+	if cached, ok := s.cache.Get(cacheKey); ok {
+		if required, ok := cached.(bool); ok {
+			return required, nil
+		}
+	}
+
+	required := false
+	policy, err := s.mfaRepo.FindPolicy(role)
+	switch {
+	case err == nil:
+		required = policy.Required
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return false, apperror.NewInternal("failed to load MFA policy", err)
+	}
+	s.cache.Set(cacheKey, required, mfaPolicyCacheTTL)
+	return required, nil
+}
+
+// CheckMFA returns an error when role requires two-factor authentication and
+// the session did not complete it.
+func (s *InsightService) CheckMFA(sessionID, role string) error {
+	required, err := s.isMFARequired(role)
+	if err != nil {
+		return err
+	}
+	if !required {
+		return nil
+	}
+
+	cacheKey := sessionMFACacheKey(sessionID)
+	if cached, ok := s.cache.Get(cacheKey); ok {
+		if verified, ok := cached.(bool); ok && verified {
+			return nil
+		}
+	}
+
+	id, err := uuid.FromString(sessionID)
+	if err != nil {
+		return apperror.NewUnauthorized("invalid session")
+	}
+	session, err := s.sessionRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperror.NewUnauthorized("invalid session")
+		}
+		return apperror.NewInternal("failed to find session", err)
+	}
+	if !session.MFAVerified {
+		return apperror.NewForbidden("two-factor authentication is required")
+	}
+	s.cache.Set(cacheKey, true, sessionCacheTTL)
+	return nil
+}
+
+// ListMFAPolicies returns the MFA policy of every role, including roles that
+// were never configured.
+func (s *InsightService) ListMFAPolicies() ([]*dto.MFAPolicyResponse, error) {
+	policies, err := s.mfaRepo.ListPolicies()
+	if err != nil {
+		return nil, apperror.NewInternal("failed to list MFA policies", err)
+	}
+	byRole := make(map[string]*entities.MFARolePolicy, len(policies))
+	for _, policy := range policies {
+		byRole[policy.Role] = policy
+	}
+
+	roles := constants.AllRoles()
+	responses := make([]*dto.MFAPolicyResponse, 0, len(roles))
+	for _, role := range roles {
+		policy, ok := byRole[string(role)]
+		if !ok {
+			policy = &entities.MFARolePolicy{Role: string(role)}
+		}
+		responses = append(responses, dto.NewMFAPolicyResponse(policy))
+	}
+	return responses, nil
+}
+
+// SetMFAPolicy requires or stops requiring two-factor authentication for role.
+func (s *InsightService) SetMFAPolicy(adminID uuid.UUID, role string, required bool) (*dto.MFAPolicyResponse, error) {
+	if !constants.IsValidRole(role) {
+		return nil, apperror.NewBadRequest("unknown role")
+	}
+
+	policy := &entities.MFARolePolicy{
+		Role:      role,
+		Required:  required,
+		UpdatedBy: &adminID,
+		UpdatedAt: time.Now(),
+	}
+	if err := s.mfaRepo.SavePolicy(policy); err != nil {
+		return nil, apperror.NewInternal("failed to save MFA policy", err)
+	}
+	s.cache.Delete(mfaPolicyCacheKey(role))
+	return dto.NewMFAPolicyResponse(policy), nil
+}
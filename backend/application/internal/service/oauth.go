@@ -0,0 +1,362 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/pdhoang91/blog/constants"
+	"github.com/pdhoang91/blog/internal/apperror"
+	"github.com/pdhoang91/blog/internal/dto"
+	"github.com/pdhoang91/blog/internal/entities"
+	"github.com/pdhoang91/blog/pkg/oauth"
+	uuid "github.com/satori/go.uuid"
+	"golang.org/x/oauth2"
+	"gorm.io/gorm"
+)
+
+const (
+	oauthStateTTL      = 10 * time.Minute
+	oauthLinkTicketTTL = 2 * time.Minute
+)
+
+// OAuthState is kept server-side for each authorization request, keyed by the
+// state parameter, and consumed on callback.
+type OAuthState struct {
+	Provider   string
+	Verifier   string // PKCE code verifier
+	LinkUserID string // set when the flow links the provider to an existing account
+}
+
+func oauthStateCacheKey(state string) string {
+	return "oauth_state:" + state
+}
+
+func oauthLinkTicketCacheKey(provider, ticket string) string {
+	return "oauth_link:" + provider + ":" + ticket
+}
+
+func newRandomString() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func (s *InsightService) oauthProvider(name string) (*oauth.Provider, error) {
+	provider, ok := s.oauthProviders.Get(name)
+	if !ok {
+		return nil, apperror.NewNotFound("unknown sign-in provider")
+	}
+	return provider, nil
+}
+
+// ListOAuthProviders returns the sign-in providers enabled on this server.
+func (s *InsightService) ListOAuthProviders() []dto.OAuthProviderResponse {
+	providers := s.oauthProviders.Providers()
+	responses := make([]dto.OAuthProviderResponse, 0, len(providers))
+	for _, p := range providers {
+		responses = append(responses, dto.OAuthProviderResponse{Name: p.Name(), DisplayName: p.DisplayName()})
+	}
+	return responses
+}
+
+// OAuthLogin starts an authorization request and returns the provider URL
+// together with the state the caller must bind to the browser.
+// A non-empty linkTicket (from StartIdentityLink) turns the flow into account linking.
+func (s *InsightService) OAuthLogin(providerName, linkTicket string) (string, string, error) {
+	provider, err := s.oauthProvider(providerName)
+	if err != nil {
+		return "", "", err
+	}
+
+	oauthState := &OAuthState{Provider: providerName, Verifier: oauth2.GenerateVerifier()}
+	if linkTicket != "" {
+		cacheKey := oauthLinkTicketCacheKey(providerName, linkTicket)
+		cached, ok := s.cache.Get(cacheKey)
+		userID, _ := cached.(string)
+		if !ok || userID == "" {
+			return "", "", apperror.NewBadRequest("link request expired, please try again")
+		}
+		s.cache.Delete(cacheKey)
+		oauthState.LinkUserID = userID
+	}
+
+	state, err := newRandomString()
+	if err != nil {
+		return "", "", apperror.NewInternal("failed to generate state", err)
+	}
+
+	url, err := provider.AuthCodeURL(context.Background(), state, oauthState.Verifier)
+	if err != nil {
+		return "", "", apperror.NewInternal("sign-in provider unavailable", err)
+	}
+	s.cache.Set(oauthStateCacheKey(state), oauthState, oauthStateTTL)
+	return url, state, nil
+}
+
+// consumeOAuthState returns and invalidates the server-side state of an authorization request.
+func (s *InsightService) consumeOAuthState(providerName, state string) (*OAuthState, error) {
+	if state == "" {
+		return nil, apperror.NewBadRequest("invalid OAuth state")
+	}
+	cacheKey := oauthStateCacheKey(state)
+	cached, ok := s.cache.Get(cacheKey)
+	if !ok {
+		return nil, apperror.NewBadRequest("invalid or expired OAuth state")
+	}
+	s.cache.Delete(cacheKey)
+
+	oauthState, ok := cached.(*OAuthState)
+	if !ok || oauthState.Verifier == "" || oauthState.Provider != providerName {
+		return nil, apperror.NewBadRequest("invalid or expired OAuth state")
+	}
+	return oauthState, nil
+}
+
+// OAuthCallback completes an authorization request started by OAuthLogin.
+// Depending on the stored state it either signs the user in or links the identity to their account.
+func (s *InsightService) OAuthCallback(providerName, code, state string, client *dto.ClientInfo) (*dto.OAuthCallbackResult, error) {
+	provider, err := s.oauthProvider(providerName)
+	if err != nil {
+		return nil, err
+	}
+
+	oauthState, err := s.consumeOAuthState(providerName, state)
+	if err != nil {
+		return nil, err
+	}
+
+	profile, err := provider.Exchange(context.Background(), code, oauthState.Verifier)
+	if err != nil {
+		if errors.Is(err, oauth.ErrExchange) {
+			return nil, apperror.NewUnauthorized("failed to exchange authorization code")
+		}
+		return nil, apperror.NewInternal("failed to fetch user info", err)
+	}
+
+	if oauthState.LinkUserID != "" {
+		userID, err := uuid.FromString(oauthState.LinkUserID)
+		if err != nil {
+			return nil, apperror.NewBadRequest("invalid OAuth state")
+		}
+		if err := s.linkIdentity(userID, providerName, profile); err != nil {
+			return nil, err
+		}
+		return &dto.OAuthCallbackResult{LinkedProvider: providerName}, nil
+	}
+
+	user, err := s.findOrCreateOAuthUser(providerName, profile)
+	if err != nil {
+		return nil, err
+	}
+	login, err := s.completeLogin(user, client)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.OAuthCallbackResult{Login: login}, nil
+}
+
+// findOrCreateOAuthUser resolves the account for an external identity. An existing
+// account with the same email is only adopted when the provider verified the address.
+func (s *InsightService) findOrCreateOAuthUser(providerName string, profile *oauth.Profile) (*entities.User, error) {
+	identity, err := s.identityRepo.FindByProviderSubject(providerName, profile.Subject)
+	if err == nil {
+		user, err := s.GetUserByID(identity.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if providerName == oauth.TypeGoogle && profile.Picture != "" && user.GooglePictureURL != profile.Picture {
+			// Keep Google picture URL up to date for existing users
+			user.GooglePictureURL = profile.Picture
+			if err := s.userRepo.Update(user); err != nil {
+				return nil, apperror.NewInternal("failed to update user", err)
+			}
+		}
+		return user, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, apperror.NewInternal("failed to find identity", err)
+	}
+
+	if profile.Email == "" {
+		return nil, apperror.NewBadRequest("the sign-in provider did not share an email address")
+	}
+
+	user, err := s.userRepo.FindByEmail(profile.Email)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, apperror.NewInternal("failed to find user", err)
+	}
+
+	if err == nil {
+		if !profile.EmailVerified {
+			return nil, apperror.NewConflict("an account with this email already exists; sign in and link this provider from your account settings")
+		}
+		if err := s.linkIdentity(user.ID, providerName, profile); err != nil {
+			return nil, err
+		}
+		if !user.EmailVerified {
+			user.EmailVerified = true
+			user.UpdatedAt = time.Now()
+			if err := s.userRepo.Update(user); err != nil {
+				return nil, apperror.NewInternal("failed to update user", err)
+			}
+		}
+		return user, nil
+	}
+
+	now := time.Now()
+	avatarURL := profile.Picture
+	if avatarURL == "" {
+		avatarURL = "https://www.w3schools.com/w3images/avatar2.png"
+	}
+	user = &entities.User{
+		ID:            uuid.NewV4(),
+		Email:         profile.Email,
+		Username:      "@" + strings.Split(profile.Email, "@")[0],
+		Name:          profile.Name,
+		AvatarURL:     avatarURL,
+		Role:          constants.GetDefaultRole(),
+		EmailVerified: profile.EmailVerified,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if providerName == oauth.TypeGoogle {
+		user.GooglePictureURL = profile.Picture
+	}
+	identity = &entities.UserIdentity{
+		ID:        uuid.NewV4(),
+		UserID:    user.ID,
+		Provider:  providerName,
+		Subject:   profile.Subject,
+		Email:     profile.Email,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := withTx(s.db, func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		return s.identityRepo.WithTx(tx).Create(identity)
+	}); err != nil {
+		return nil, apperror.NewInternal("failed to create user", err)
+	}
+	return user, nil
+}
+
+func (s *InsightService) linkIdentity(userID uuid.UUID, providerName string, profile *oauth.Profile) error {
+	existing, err := s.identityRepo.FindByProviderSubject(providerName, profile.Subject)
+	if err == nil {
+		if existing.UserID != userID {
+			return apperror.NewConflict("this account is already linked to another user")
+		}
+		return nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return apperror.NewInternal("failed to find identity", err)
+	}
+
+	identities, err := s.identityRepo.FindByUserID(userID)
+	if err != nil {
+		return apperror.NewInternal("failed to list identities", err)
+	}
+	for _, identity := range identities {
+		if identity.Provider == providerName {
+			return apperror.NewConflict("a different account from this provider is already linked")
+		}
+	}
+
+	now := time.Now()
+	identity := &entities.UserIdentity{
+		ID:        uuid.NewV4(),
+		UserID:    userID,
+		Provider:  providerName,
+		Subject:   profile.Subject,
+		Email:     profile.Email,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.identityRepo.Create(identity); err != nil {
+		return apperror.NewInternal("failed to link identity", err)
+	}
+	return nil
+}
+
+// ListIdentities reports, for every enabled provider, whether it is linked to the user.
+func (s *InsightService) ListIdentities(userID uuid.UUID) (*dto.IdentitiesResponse, error) {
+	user, err := s.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	identities, err := s.identityRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, apperror.NewInternal("failed to list identities", err)
+	}
+
+	linked := make(map[string]*entities.UserIdentity, len(identities))
+	for _, identity := range identities {
+		linked[identity.Provider] = identity
+	}
+
+	response := &dto.IdentitiesResponse{HasPassword: user.Password != ""}
+	for _, p := range s.oauthProviders.Providers() {
+		item := dto.IdentityResponse{Provider: p.Name(), DisplayName: p.DisplayName()}
+		if identity, ok := linked[p.Name()]; ok {
+			item.Linked = true
+			item.Email = identity.Email
+			item.LinkedAt = &identity.CreatedAt
+		}
+		response.Identities = append(response.Identities, item)
+	}
+	return response, nil
+}
+
+// StartIdentityLink issues a short-lived, single-use ticket that lets the browser
+// start a linking flow without sending its access token in a URL. The returned
+// path is relative to the API base URL.
+func (s *InsightService) StartIdentityLink(userID uuid.UUID, providerName string) (string, error) {
+	if _, err := s.oauthProvider(providerName); err != nil {
+		return "", err
+	}
+	ticket, err := newRandomString()
+	if err != nil {
+		return "", apperror.NewInternal("failed to generate link ticket", err)
+	}
+	s.cache.Set(oauthLinkTicketCacheKey(providerName, ticket), userID.String(), oauthLinkTicketTTL)
+	return "/auth/" + providerName + "?link=" + ticket, nil
+}
+
+// UnlinkIdentity detaches an external provider. The last sign-in method cannot be removed.
+func (s *InsightService) UnlinkIdentity(userID uuid.UUID, providerName string) error {
+	user, err := s.GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+	identities, err := s.identityRepo.FindByUserID(userID)
+	if err != nil {
+		return apperror.NewInternal("failed to list identities", err)
+	}
+
+	found := false
+	for _, identity := range identities {
+		if identity.Provider == providerName {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return apperror.NewNotFound("identity not linked")
+	}
+	if user.Password == "" && len(identities) == 1 {
+		return apperror.NewBadRequest("set a password before unlinking your only sign-in method")
+	}
+
+	if err := s.identityRepo.Delete(userID, providerName); err != nil {
+		return apperror.NewInternal("failed to unlink identity", err)
+	}
+	return nil
+}
@@ -10,11 +10,13 @@ import (
 	"sync"
 	"time"
 
+	"github.com/pdhoang91/blog/constants"
 	"github.com/pdhoang91/blog/internal/apperror"
 	"github.com/pdhoang91/blog/internal/dto"
 	"github.com/pdhoang91/blog/internal/entities"
 	"github.com/pdhoang91/blog/internal/repository"
 	"github.com/pdhoang91/blog/pkg/revalidation"
+	"github.com/pdhoang91/blog/pkg/tiptap"
 	"github.com/pdhoang91/blog/pkg/utils"
 	uuid "github.com/satori/go.uuid"
 	"gorm.io/gorm"
@@ -22,6 +24,17 @@ import (
 
 // CreatePost creates a new post
 func (s *InsightService) CreatePost(userID uuid.UUID, req *dto.CreatePostRequest) (*dto.PostResponse, error) {
+	if err := validateContent(req.Content); err != nil {
+		return nil, err
+	}
+	allowed, err := s.userCan(userID, constants.PermPublish)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, apperror.NewForbidden("your role does not allow publishing posts")
+	}
+
 	tx := s.db.Begin()
 	if tx.Error != nil {
 		return nil, apperror.NewInternal("failed to start transaction", tx.Error)
@@ -35,16 +48,17 @@ func (s *InsightService) CreatePost(userID uuid.UUID, req *dto.CreatePostRequest
 
 	coverImage := req.CoverImage
 	if coverImage == "" {
-		feURL := os.Getenv("BASE_FE_URL")
-		if feURL == "" {
-			feURL = "http://localhost:3000"
-		}
-		coverImage = feURL + "/images/insight.jpg"
+		coverImage = defaultCoverImage()
 	}
 
-	slug := utils.CreateSlug(req.Title)
-	if _, err := s.postRepo.FindBySlug(slug); err == nil {
-		slug = fmt.Sprintf("%s-%s", slug, utils.GetUniquePrefix())
+	var slug string
+	if req.Slug != "" {
+		slug, err = s.pinnedSlug(req.Slug, uuid.Nil)
+	} else {
+		slug, err = s.derivedSlug(req.Title, uuid.Nil)
+	}
+	if err != nil {
+		return nil, err
 	}
 
 	excerpt := req.Excerpt
@@ -58,18 +72,42 @@ func (s *InsightService) CreatePost(userID uuid.UUID, req *dto.CreatePostRequest
 		Title:      req.Title,
 		CoverImage: coverImage,
 		Slug:       slug,
+		SlugPinned: req.Slug != "",
 		Excerpt:    excerpt,
+		Version:    1,
 		CreatedAt:  time.Now(),
 		UpdatedAt:  time.Now(),
 	}
 
-	if err := txPostRepo.Create(post); err != nil {
-		return nil, apperror.NewInternal("failed to create post", err)
+	reviewer, err := s.userCan(userID, constants.PermReviewPosts)
+	if err != nil {
+		return nil, err
+	}
+	status := req.Status
+	if status == "" {
+		status = entities.PostStatusDraft
+		if reviewer {
+			status = entities.PostStatusPublished
+		}
+	}
+	if err := checkStatusTransition("", status, reviewer); err != nil {
+		return nil, err
+	}
+	if err := applyPostStatus(post, status, req.PublishedAt, time.Now()); err != nil {
+		return nil, err
 	}
 
 	var processedJSON json.RawMessage
 	if len(req.Content) > 0 {
 		processedJSON = s.storageManager.ProcessJSONContent(req.Content)
+		s.applyContentStats(post, processedJSON)
+	}
+
+	if err := txPostRepo.Create(post); err != nil {
+		return nil, apperror.NewInternal("failed to create post", err)
+	}
+
+	if len(processedJSON) > 0 {
 		postContent := &entities.PostContent{
 			ID:        uuid.NewV4(),
 			PostID:    post.ID,
@@ -102,6 +140,25 @@ func (s *InsightService) CreatePost(userID uuid.UUID, req *dto.CreatePostRequest
 		}
 	}
 
+	owner := &entities.PostAuthor{
+		PostID:      post.ID,
+		UserID:      userID,
+		Role:        entities.PostAuthorOwner,
+		Status:      entities.InvitationAccepted,
+		CreatedAt:   post.CreatedAt,
+		RespondedAt: &post.CreatedAt,
+	}
+	if err := s.postAuthorRepo.WithTx(tx).Save(owner); err != nil {
+		return nil, apperror.NewInternal("failed to add post owner", err)
+	}
+
+	if _, err := s.recordRevision(tx, post, processedJSON, userID); err != nil {
+		return nil, err
+	}
+	if err := s.recordStatusEvent(tx, post.ID, "", post.Status, &userID, ""); err != nil {
+		return nil, err
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		return nil, apperror.NewInternal("failed to commit transaction", err)
 	}
@@ -124,14 +181,34 @@ func (s *InsightService) CreatePost(userID uuid.UUID, req *dto.CreatePostRequest
 	return dto.NewPostResponse(post), nil
 }
 
+// defaultCoverImage is the cover of posts created without one.
+func defaultCoverImage() string {
+	feURL := os.Getenv("BASE_FE_URL")
+	if feURL == "" {
+		feURL = "http://localhost:3000"
+	}
+	return feURL + "/images/insight.jpg"
+}
+
 func (s *InsightService) invalidatePostListCaches() {
 	s.cache.DeletePrefix("list_posts:")
 	s.cache.DeletePrefix("latest_posts:")
 	s.cache.DeletePrefix("popular_posts:")
+	s.cache.DeletePrefix("series:")
 	s.cache.Delete("home_data")
+	s.cache.Delete("archive_summary")
 }
 
+// invalidatePostDetailCaches drops the post's cached pages, and those of its
+// series neighbours, whose previous/next links show its title and slug.
 func (s *InsightService) invalidatePostDetailCaches(slug string, id uuid.UUID) {
+	s.dropPostDetailCache(slug, id)
+	if series, err := s.seriesRepo.FindByPostID(id); err == nil {
+		s.invalidateSeriesCaches(series.ID)
+	}
+}
+
+func (s *InsightService) dropPostDetailCache(slug string, id uuid.UUID) {
 	s.cache.Delete("post_slug:" + slug)
 	s.cache.Delete(fmt.Sprintf("post_id:%s", id.String()))
 }
@@ -185,6 +262,9 @@ func (s *InsightService) GetPost(id uuid.UUID) (*dto.PostResponse, error) {
 		}
 		return nil, apperror.NewInternal("failed to get post", err)
 	}
+	if !post.IsPubliclyVisible() {
+		return nil, apperror.NewNotFound("post not found")
+	}
 
 	s.BufferViewIncrement(post.ID)
 	if err := s.loadPostRelationsParallel(post); err != nil {
@@ -192,39 +272,49 @@ func (s *InsightService) GetPost(id uuid.UUID) (*dto.PostResponse, error) {
 	}
 
 	resp := dto.NewPostResponse(post)
+	resp.Series = s.seriesNav(post)
 	s.cache.Set(cacheKey, resp, 60*time.Second)
 	return resp, nil
 }
 
-// GetPostBySlug retrieves a post by slug
-func (s *InsightService) GetPostBySlug(slug string) (*dto.PostResponse, error) {
+// GetPostBySlug retrieves a post by slug. For a slug the post had before it
+// was renamed, it returns only the current slug to redirect to.
+func (s *InsightService) GetPostBySlug(slug string) (*dto.PostResponse, string, error) {
 	cacheKey := "post_slug:" + slug
 	if cached, ok := s.cache.Get(cacheKey); ok {
 		if resp, ok := cached.(*dto.PostResponse); ok {
 			s.BufferViewIncrement(resp.ID)
-			return resp, nil
+			return resp, "", nil
 		}
 	}
 
 	post, err := s.postRepo.FindBySlug(slug)
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, apperror.NewNotFound("post not found")
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		if post, err := s.findPostBySlugHistory(slug); err == nil && post.IsPubliclyVisible() {
+			return nil, post.Slug, nil
 		}
-		return nil, apperror.NewInternal("failed to get post by slug", err)
+		return nil, "", apperror.NewNotFound("post not found")
+	}
+	if err != nil {
+		return nil, "", apperror.NewInternal("failed to get post by slug", err)
+	}
+	if !post.IsPubliclyVisible() {
+		return nil, "", apperror.NewNotFound("post not found")
 	}
 
 	s.BufferViewIncrement(post.ID)
 	if err := s.loadPostRelationsParallel(post); err != nil {
-		return nil, apperror.NewInternal("failed to load post relations", err)
+		return nil, "", apperror.NewInternal("failed to load post relations", err)
 	}
 
 	resp := dto.NewPostResponse(post)
+	resp.Series = s.seriesNav(post)
 	s.cache.Set(cacheKey, resp, 60*time.Second)
-	return resp, nil
+	return resp, "", nil
 }
 
-// loadPostRelationsParallel loads post content and relationships concurrently.
+// loadPostRelationsParallel loads post content, with the highlighted tokens
+// of its code for readers, and relationships concurrently.
 func (s *InsightService) loadPostRelationsParallel(post *entities.Post) error {
 	var relErr error
 	var wg sync.WaitGroup
@@ -232,6 +322,7 @@ func (s *InsightService) loadPostRelationsParallel(post *entities.Post) error {
 	go func() {
 		defer wg.Done()
 		s.loadPostContent(post)
+		post.CodeTokens = s.codeTokens(post.Content)
 	}()
 	go func() {
 		defer wg.Done()
@@ -262,8 +353,26 @@ func (s *InsightService) GetPostEntity(id uuid.UUID) (*entities.Post, error) {
 	return post, nil
 }
 
-// UpdatePost updates a post by ID
+// UpdatePost updates a post by ID for one of its authors.
 func (s *InsightService) UpdatePost(userID uuid.UUID, id uuid.UUID, req *dto.UpdatePostRequest) (*dto.PostResponse, error) {
+	return s.updatePost(userID, id, req, func(post *entities.Post) error {
+		return s.authorizePost(post, userID, postAccessEdit)
+	})
+}
+
+// UpdateAnyPost updates a post for someone allowed to edit other people's
+// posts, from the admin routes.
+func (s *InsightService) UpdateAnyPost(userID uuid.UUID, id uuid.UUID, req *dto.UpdatePostRequest) (*dto.PostResponse, error) {
+	return s.updatePost(userID, id, req, func(post *entities.Post) error {
+		return s.authorizeAnyPost(post, userID)
+	})
+}
+
+// updatePost applies req to the post once authorize allows it.
+func (s *InsightService) updatePost(userID uuid.UUID, id uuid.UUID, req *dto.UpdatePostRequest, authorize func(*entities.Post) error) (*dto.PostResponse, error) {
+	if err := validateContent(req.Content); err != nil {
+		return nil, err
+	}
 	tx := s.db.Begin()
 	if tx.Error != nil {
 		return nil, apperror.NewInternal("failed to start transaction", tx.Error)
@@ -275,7 +384,7 @@ func (s *InsightService) UpdatePost(userID uuid.UUID, id uuid.UUID, req *dto.Upd
 	txCategoryRepo := s.categoryRepo.WithTx(tx)
 	txTagRepo := s.tagRepo.WithTx(tx)
 
-	post, err := txPostRepo.FindByID(id)
+	post, err := txPostRepo.FindByIDForUpdate(id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, apperror.NewNotFound("post not found")
@@ -283,18 +392,50 @@ func (s *InsightService) UpdatePost(userID uuid.UUID, id uuid.UUID, req *dto.Upd
 		return nil, apperror.NewInternal("failed to find post", err)
 	}
 
-	if post.UserID != userID {
-		return nil, apperror.NewForbidden("you do not own this post")
+	if err := authorize(post); err != nil {
+		return nil, err
+	}
+
+	if req.Version != nil && *req.Version != post.Version {
+		return nil, s.postVersionConflict(post)
 	}
+	post.Version++
 
-	if req.Title != "" {
+	reviewer, err := s.userCan(userID, constants.PermReviewPosts)
+	if err != nil {
+		return nil, err
+	}
+	prevStatus, prevPublishedAt := post.Status, post.PublishedAt
+	backToReview := false
+
+	// The slug follows the title unless the author pinned one; an empty slug
+	// unpins it.
+	oldSlug, rederive := post.Slug, false
+	if req.Title != "" && req.Title != post.Title {
 		post.Title = req.Title
-		newSlug := utils.CreateSlug(req.Title)
-		if newSlug != post.Slug {
-			if txPostRepo.ExistsBySlugExcluding(newSlug, post.ID) {
-				newSlug = fmt.Sprintf("%s-%s", newSlug, utils.GetUniquePrefix())
+		rederive = true
+	}
+	if req.Slug != nil {
+		if *req.Slug != "" {
+			slug, err := s.pinnedSlug(*req.Slug, post.ID)
+			if err != nil {
+				return nil, err
 			}
-			post.Slug = newSlug
+			post.Slug, post.SlugPinned = slug, true
+		} else {
+			post.SlugPinned, rederive = false, true
+		}
+	}
+	if rederive && !post.SlugPinned && utils.CreateSlug(post.Title) != post.Slug {
+		slug, err := s.derivedSlug(post.Title, post.ID)
+		if err != nil {
+			return nil, err
+		}
+		post.Slug = slug
+	}
+	if post.Slug != oldSlug {
+		if err := s.recordSlugChange(tx, post, oldSlug); err != nil {
+			return nil, err
 		}
 	}
 	if req.CoverImage != "" {
@@ -306,12 +447,30 @@ func (s *InsightService) UpdatePost(userID uuid.UUID, id uuid.UUID, req *dto.Upd
 	if len(req.Content) > 0 && req.Excerpt == "" {
 		post.Excerpt = s.extractExcerpt(req.Content)
 	}
+	var processedJSON json.RawMessage
+	if len(req.Content) > 0 {
+		processedJSON = s.storageManager.ProcessJSONContent(req.Content)
+		s.applyContentStats(post, processedJSON)
+	}
+	if req.Status != "" || req.PublishedAt != nil {
+		status := req.Status
+		if status == "" {
+			status = post.Status
+		}
+		if err := checkStatusTransition(post.Status, status, reviewer); err != nil {
+			return nil, err
+		}
+		if err := applyPostStatus(post, status, req.PublishedAt, time.Now()); err != nil {
+			return nil, err
+		}
+	}
 
 	post.UpdatedAt = time.Now()
 	if err := txPostRepo.Update(post); err != nil {
 		return nil, apperror.NewInternal("failed to update post", err)
 	}
 
+	var currentContent json.RawMessage
 	if len(req.Content) > 0 {
 		postContent, err := txPostContentRepo.FindByPostID(post.ID)
 		if err != nil {
@@ -327,7 +486,6 @@ func (s *InsightService) UpdatePost(userID uuid.UUID, id uuid.UUID, req *dto.Upd
 		}
 
 		oldContent := postContent.Content
-		processedJSON := s.storageManager.ProcessJSONContent(req.Content)
 		postContent.Content = processedJSON
 		postContent.UpdatedAt = time.Now()
 
@@ -344,6 +502,33 @@ func (s *InsightService) UpdatePost(userID uuid.UUID, id uuid.UUID, req *dto.Upd
 
 		// Update image references (best-effort)
 		_ = s.storageManager.UpdateJSONImageReferences(context.Background(), post.ID, oldContent, postContent.Content)
+		currentContent = postContent.Content
+	}
+
+	if req.Title != "" || req.Excerpt != "" || len(req.Content) > 0 {
+		if currentContent == nil {
+			if postContent, err := txPostContentRepo.FindByPostID(post.ID); err == nil {
+				currentContent = postContent.Content
+			}
+		}
+		changed, err := s.recordRevision(tx, post, currentContent, userID)
+		if err != nil {
+			return nil, err
+		}
+		// Approval covers the text the editor saw; changing it sends the
+		// post back to the review queue, even when it was being published
+		// or is already live.
+		if changed && contentChangeNeedsReview(prevStatus, reviewer) {
+			post.Status, post.PublishedAt = entities.PostStatusInReview, prevPublishedAt
+			backToReview = true
+			if err := txPostRepo.Update(post); err != nil {
+				return nil, apperror.NewInternal("failed to update post", err)
+			}
+		}
+		// The working copy has been saved for real now.
+		if err := s.autosaveRepo.WithTx(tx).DeleteByPostID(post.ID); err != nil {
+			return nil, apperror.NewInternal("failed to clear autosave", err)
+		}
 	}
 
 	if req.CategoryNames != nil {
@@ -374,6 +559,16 @@ func (s *InsightService) UpdatePost(userID uuid.UUID, id uuid.UUID, req *dto.Upd
 		}
 	}
 
+	if post.Status != prevStatus {
+		comment := ""
+		if backToReview {
+			comment = "changed after approval"
+		}
+		if err := s.recordStatusEvent(tx, post.ID, prevStatus, post.Status, &userID, comment); err != nil {
+			return nil, err
+		}
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		return nil, apperror.NewInternal("failed to commit transaction", err)
 	}
@@ -385,11 +580,30 @@ func (s *InsightService) UpdatePost(userID uuid.UUID, id uuid.UUID, req *dto.Upd
 	revalidation.TriggerPostRevalidation(post.Slug)
 	s.invalidatePostListCaches()
 	s.invalidatePostDetailCaches(post.Slug, post.ID)
+	if post.Slug != oldSlug {
+		revalidation.TriggerPostRevalidation(oldSlug)
+		s.dropPostDetailCache(oldSlug, post.ID)
+	}
 
 	return dto.NewPostResponse(post), nil
 }
 
+// DeletePost deletes a post for its owner.
 func (s *InsightService) DeletePost(userID uuid.UUID, id uuid.UUID) error {
+	return s.deletePost(id, func(post *entities.Post) error {
+		return s.authorizePost(post, userID, postAccessManage)
+	})
+}
+
+// DeleteAnyPost deletes a post for someone allowed to edit other people's
+// posts, from the admin routes.
+func (s *InsightService) DeleteAnyPost(userID uuid.UUID, id uuid.UUID) error {
+	return s.deletePost(id, func(post *entities.Post) error {
+		return s.authorizeAnyPost(post, userID)
+	})
+}
+
+func (s *InsightService) deletePost(id uuid.UUID, authorize func(*entities.Post) error) error {
 	tx := s.db.Begin()
 	if tx.Error != nil {
 		return apperror.NewInternal("failed to start transaction", tx.Error)
@@ -409,8 +623,8 @@ func (s *InsightService) DeletePost(userID uuid.UUID, id uuid.UUID) error {
 		return apperror.NewInternal("failed to find post", err)
 	}
 
-	if post.UserID != userID {
-		return apperror.NewForbidden("you do not own this post")
+	if err := authorize(post); err != nil {
+		return err
 	}
 
 	if err := txPostRepo.Delete(post); err != nil {
@@ -770,3 +984,19 @@ func (s *InsightService) extractExcerpt(content json.RawMessage) string {
 	}
 	return plainText
 }
+
+// validateContent rejects documents the editor could not have produced, so
+// nothing unsafe is stored for the renderers and exports to trip over.
+func validateContent(content json.RawMessage) error {
+	if len(content) == 0 || string(content) == "null" {
+		return nil
+	}
+	if err := tiptap.Validate(content, tiptap.DefaultLimits); err != nil {
+		var verr *tiptap.ValidationError
+		if errors.As(err, &verr) {
+			return apperror.NewInvalid("invalid content: "+verr.Error(), verr)
+		}
+		return apperror.NewBadRequest("invalid content")
+	}
+	return nil
+}
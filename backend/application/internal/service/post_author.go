@@ -0,0 +1,242 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/pdhoang91/blog/constants"
+	"github.com/pdhoang91/blog/internal/apperror"
+	"github.com/pdhoang91/blog/internal/dto"
+	"github.com/pdhoang91/blog/internal/entities"
+	uuid "github.com/satori/go.uuid"
+	"gorm.io/gorm"
+)
+
+// postAccess is what a caller wants to do with a post. Each level includes the
+// ones below it.
+type postAccess int
+
+const (
+	postAccessRead   postAccess = iota // unpublished versions and revisions
+	postAccessEdit                     // content, autosave, restoring revisions
+	postAccessManage                   // deleting, inviting and removing authors
+)
+
+// authorAccess is the access an accepted author role grants.
+func authorAccess(role string) postAccess {
+	switch role {
+	case entities.PostAuthorOwner:
+		return postAccessManage
+	case entities.PostAuthorCoAuthor:
+		return postAccessEdit
+	default:
+		return postAccessRead
+	}
+}
+
+// authorizePost allows the post's owner and authors whose role grants need.
+// Anyone allowed to edit other people's posts may read them, as reviewers
+// do; editing and deleting them goes through UpdateAnyPost and DeleteAnyPost
+// instead, which the router keeps behind a signed-in session and its second
+// factor.
+func (s *InsightService) authorizePost(post *entities.Post, userID uuid.UUID, need postAccess) error {
+	if post.UserID == userID {
+		return nil
+	}
+
+	author, err := s.postAuthorRepo.Find(post.ID, userID)
+	switch {
+	case err == nil:
+		if author.Status == entities.InvitationAccepted && authorAccess(author.Role) >= need {
+			return nil
+		}
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return apperror.NewInternal("failed to check post authors", err)
+	}
+
+	if need > postAccessRead {
+		return apperror.NewForbidden("you are not allowed to do this on this post")
+	}
+	allowed, err := s.userCan(userID, constants.PermEditAnyPost)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return apperror.NewForbidden("you are not allowed to do this on this post")
+	}
+	return nil
+}
+
+// authorizeAnyPost allows anyone whose role lets them edit other people's
+// posts, whoever the post's authors are.
+func (s *InsightService) authorizeAnyPost(post *entities.Post, userID uuid.UUID) error {
+	return s.authorizeOwnerOr(post.UserID, userID, constants.PermEditAnyPost, "you are not allowed to do this on this post")
+}
+
+// findAuthorizedPost loads a post in any status if the user may access it as need says.
+func (s *InsightService) findAuthorizedPost(userID, postID uuid.UUID, need postAccess) (*entities.Post, error) {
+	post, err := s.postRepo.FindByID(postID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.NewNotFound("post not found")
+		}
+		return nil, apperror.NewInternal("failed to find post", err)
+	}
+	if err := s.authorizePost(post, userID, need); err != nil {
+		return nil, err
+	}
+	return post, nil
+}
+
+// ListPostAuthors lists everyone on a post, pending and declined invitations included.
+func (s *InsightService) ListPostAuthors(userID, postID uuid.UUID) ([]*dto.PostAuthorResponse, error) {
+	if _, err := s.findAuthorizedPost(userID, postID, postAccessRead); err != nil {
+		return nil, err
+	}
+
+	authors, err := s.postAuthorRepo.FindByPostID(postID)
+	if err != nil {
+		return nil, apperror.NewInternal("failed to list post authors", err)
+	}
+	responses := make([]*dto.PostAuthorResponse, 0, len(authors))
+	for _, author := range authors {
+		responses = append(responses, dto.NewPostAuthorResponse(author))
+	}
+	return responses, nil
+}
+
+// InvitePostAuthor invites a user to a post. Declined invitations may be
+// repeated; anything else already on file is a conflict.
+func (s *InsightService) InvitePostAuthor(userID, postID uuid.UUID, req *dto.InvitePostAuthorRequest) (*dto.PostAuthorResponse, error) {
+	post, err := s.findAuthorizedPost(userID, postID, postAccessManage)
+	if err != nil {
+		return nil, err
+	}
+
+	invitee, err := s.userRepo.FindByUsername(req.Username)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.NewNotFound("user not found")
+		}
+		return nil, apperror.NewInternal("failed to find user", err)
+	}
+	if invitee.ID == post.UserID {
+		return nil, apperror.NewBadRequest("the owner is already an author of this post")
+	}
+	if req.Role == entities.PostAuthorCoAuthor && !constants.HasPermission(invitee.Role, constants.PermPublish) {
+		return nil, apperror.NewBadRequest("this user's role does not allow writing posts")
+	}
+
+	existing, err := s.postAuthorRepo.Find(postID, invitee.ID)
+	switch {
+	case err == nil:
+		if existing.Status != entities.InvitationDeclined {
+			return nil, apperror.NewConflict("user is already invited to this post")
+		}
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, apperror.NewInternal("failed to check post authors", err)
+	}
+
+	author := &entities.PostAuthor{
+		PostID:    postID,
+		UserID:    invitee.ID,
+		Role:      req.Role,
+		Status:    entities.InvitationPending,
+		InvitedBy: &userID,
+		CreatedAt: time.Now(),
+	}
+	if err := s.postAuthorRepo.Save(author); err != nil {
+		return nil, apperror.NewInternal("failed to invite author", err)
+	}
+
+	if err := s.sendPostInvitation(invitee, post, req.Role); err != nil {
+		log.Printf("posts: failed to send invitation for post %s to user %s: %v", post.ID, invitee.ID, err)
+	}
+
+	author.User = *invitee
+	return dto.NewPostAuthorResponse(author), nil
+}
+
+func (s *InsightService) sendPostInvitation(invitee *entities.User, post *entities.Post, role string) error {
+	what := "co-author"
+	if role == entities.PostAuthorReviewer {
+		what = "review"
+	}
+	link := fmt.Sprintf("%s/invitations", os.Getenv("BASE_FE_URL"))
+	body := fmt.Sprintf("Hi %s,\n\n%s invited you to %s the post \"%s\".\n\nAccept or decline the invitation here:\n\n%s\n", invitee.Name, post.User.Name, what, post.Title, link)
+	return s.sendMail(invitee.Email, "Invitation to "+what+" a post", body)
+}
+
+// RemovePostAuthor takes someone off a post. Authors may always remove
+// themselves; removing others needs manage access. The owner cannot be removed.
+func (s *InsightService) RemovePostAuthor(userID, postID, authorID uuid.UUID) error {
+	need := postAccessManage
+	if authorID == userID {
+		need = postAccessRead
+	}
+	post, err := s.findAuthorizedPost(userID, postID, need)
+	if err != nil {
+		return err
+	}
+	if authorID == post.UserID {
+		return apperror.NewBadRequest("the owner cannot be removed from a post")
+	}
+
+	if _, err := s.postAuthorRepo.Find(postID, authorID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperror.NewNotFound("author not found")
+		}
+		return apperror.NewInternal("failed to find post author", err)
+	}
+	if err := s.postAuthorRepo.Delete(postID, authorID); err != nil {
+		return apperror.NewInternal("failed to remove post author", err)
+	}
+
+	s.invalidateBylineCaches(post)
+	return nil
+}
+
+// ListMyInvitations lists the invitations waiting for the user's answer.
+func (s *InsightService) ListMyInvitations(userID uuid.UUID) ([]*dto.PostInvitationResponse, error) {
+	invitations, err := s.postAuthorRepo.FindPendingByUserID(userID)
+	if err != nil {
+		return nil, apperror.NewInternal("failed to list invitations", err)
+	}
+	responses := make([]*dto.PostInvitationResponse, 0, len(invitations))
+	for _, invitation := range invitations {
+		responses = append(responses, dto.NewPostInvitationResponse(invitation))
+	}
+	return responses, nil
+}
+
+// RespondToInvitation accepts or declines a pending invitation.
+func (s *InsightService) RespondToInvitation(userID, postID uuid.UUID, accept bool) error {
+	status := entities.InvitationDeclined
+	if accept {
+		status = entities.InvitationAccepted
+	}
+
+	ok, err := s.postAuthorRepo.Respond(postID, userID, status, time.Now())
+	if err != nil {
+		return apperror.NewInternal("failed to answer invitation", err)
+	}
+	if !ok {
+		return apperror.NewNotFound("invitation not found")
+	}
+
+	if accept {
+		if post, err := s.postRepo.FindByID(postID); err == nil {
+			s.invalidateBylineCaches(post)
+		}
+	}
+	return nil
+}
+
+// invalidateBylineCaches drops cached responses that list the post's authors.
+func (s *InsightService) invalidateBylineCaches(post *entities.Post) {
+	s.invalidatePostDetailCaches(post.Slug, post.ID)
+	s.invalidatePostListCaches()
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/pdhoang91/blog/constants"
+	"github.com/pdhoang91/blog/internal/apperror"
+	"github.com/pdhoang91/blog/internal/entities"
+	uuid "github.com/satori/go.uuid"
+)
+
+func TestAuthorizePost(t *testing.T) {
+	s, f := newTestService()
+	owner := f.addUser(constants.RoleAuthor)
+	coAuthor := f.addUser(constants.RoleAuthor)
+	invited := f.addUser(constants.RoleAuthor)
+	editor := f.addUser(constants.RoleEditor)
+	admin := f.addUser(constants.RoleAdmin)
+	stranger := f.addUser(constants.RoleAuthor)
+
+	post := &entities.Post{ID: uuid.NewV4(), UserID: owner}
+	f.postAuthors.authors = []*entities.PostAuthor{
+		{PostID: post.ID, UserID: coAuthor, Role: entities.PostAuthorCoAuthor, Status: entities.InvitationAccepted},
+		{PostID: post.ID, UserID: invited, Role: entities.PostAuthorCoAuthor, Status: entities.InvitationPending},
+	}
+
+	tests := []struct {
+		name    string
+		userID  uuid.UUID
+		need    postAccess
+		allowed bool
+	}{
+		{name: "owner manages", userID: owner, need: postAccessManage, allowed: true},
+		{name: "co-author edits", userID: coAuthor, need: postAccessEdit, allowed: true},
+		{name: "co-author cannot delete", userID: coAuthor, need: postAccessManage},
+		{name: "pending invitation cannot read", userID: invited, need: postAccessRead},
+		{name: "editor reads", userID: editor, need: postAccessRead, allowed: true},
+		{name: "editor cannot edit here", userID: editor, need: postAccessEdit},
+		{name: "admin cannot delete here", userID: admin, need: postAccessManage},
+		{name: "stranger cannot read", userID: stranger, need: postAccessRead},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.authorizePost(post, tt.userID, tt.need)
+			checkAllowed(t, err, tt.allowed)
+		})
+	}
+
+	anyPost := []struct {
+		name    string
+		userID  uuid.UUID
+		allowed bool
+	}{
+		{name: "editor", userID: editor, allowed: true},
+		{name: "admin", userID: admin, allowed: true},
+		{name: "co-author", userID: coAuthor},
+		{name: "stranger", userID: stranger},
+	}
+	for _, tt := range anyPost {
+		t.Run("any post/"+tt.name, func(t *testing.T) {
+			checkAllowed(t, s.authorizeAnyPost(post, tt.userID), tt.allowed)
+		})
+	}
+}
+
+// checkAllowed fails unless err is nil when allowed, or a forbidden error
+// when not.
+func checkAllowed(t *testing.T, err error, allowed bool) {
+	t.Helper()
+	if allowed {
+		if err != nil {
+			t.Errorf("got %v, want access", err)
+		}
+		return
+	}
+	if err == nil || apperror.HTTPCode(err) != http.StatusForbidden {
+		t.Errorf("got %v, want a forbidden error", err)
+	}
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"encoding/json"
+	"log"
+
+	"github.com/pdhoang91/blog/internal/entities"
+	"github.com/pdhoang91/blog/pkg/tiptap"
+	uuid "github.com/satori/go.uuid"
+)
+
+const postStatsBatchSize = 200
+
+// applyContentStats sets what the post derives from its content: word count,
+// reading time and table of contents, and the first image as cover while the
+// author has not picked one. content is the stored form, with image IDs.
+func (s *InsightService) applyContentStats(post *entities.Post, content json.RawMessage) {
+	post.WordCount, post.ReadingTime, post.TOC = 0, 0, nil
+	if len(content) == 0 {
+		return
+	}
+	doc, err := tiptap.Parse(s.storageManager.ProcessJSONContentForDisplay(content))
+	if err != nil {
+		return
+	}
+	stats := doc.Stats()
+	post.WordCount, post.ReadingTime = stats.Words, stats.ReadingTime
+	if len(stats.Headings) > 0 {
+		if toc, err := json.Marshal(stats.Headings); err == nil {
+			post.TOC = toc
+		}
+	}
+	if stats.FirstImage != "" && (post.CoverImage == "" || post.CoverImage == defaultCoverImage()) {
+		post.CoverImage = stats.FirstImage
+	}
+}
+
+// BackfillPostStats computes the content statistics of every post with
+// content, for posts saved before they existed. Posts in the trash are left
+// for when they are edited again.
+func (s *InsightService) BackfillPostStats() (int, error) {
+	updated, skipped := 0, 0
+	after := uuid.Nil
+	for {
+		contents, err := s.postContentRepo.FindAfter(after, postStatsBatchSize)
+		if err != nil {
+			return updated, err
+		}
+		if len(contents) == 0 {
+			break
+		}
+		for _, pc := range contents {
+			after = pc.ID
+			post, err := s.postRepo.FindByID(pc.PostID)
+			if err != nil {
+				skipped++
+				continue
+			}
+			s.applyContentStats(post, pc.Content)
+			if err := s.postRepo.UpdateContentStats(post); err != nil {
+				log.Printf("post stats: failed to update post %s: %v", post.ID, err)
+				skipped++
+				continue
+			}
+			s.dropPostDetailCache(post.Slug, post.ID)
+			updated++
+		}
+	}
+
+	if updated > 0 {
+		s.invalidatePostListCaches()
+	}
+	log.Printf("post stats: updated %d posts, skipped %d", updated, skipped)
+	return updated, nil
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"log"
+	"time"
+
+	"github.com/pdhoang91/blog/internal/apperror"
+	"github.com/pdhoang91/blog/internal/dto"
+	"github.com/pdhoang91/blog/internal/entities"
+	"github.com/pdhoang91/blog/pkg/revalidation"
+	uuid "github.com/satori/go.uuid"
+)
+
+// scheduledPublishBatch caps how many scheduled posts one scheduler tick
+// publishes; the rest are picked up on the next tick.
+const scheduledPublishBatch = 100
+
+// applyPostStatus moves post to status. publishAt sets an explicit publish
+// time: it must lie in the future for scheduled posts and may backdate
+// published ones. Without it, a post gets its publish time the first time it
+// goes live.
+func applyPostStatus(post *entities.Post, status string, publishAt *time.Time, now time.Time) error {
+	if !entities.IsValidPostStatus(status) {
+		return apperror.NewBadRequest("invalid post status")
+	}
+
+	switch status {
+	case entities.PostStatusScheduled:
+		at := publishAt
+		if at == nil {
+			at = post.PublishedAt
+		}
+		if at == nil || !at.After(now) {
+			return apperror.NewBadRequest("scheduled posts need a published_at in the future")
+		}
+		post.PublishedAt = at
+	case entities.PostStatusPublished, entities.PostStatusUnlisted:
+		if publishAt != nil {
+			if publishAt.After(now) {
+				return apperror.NewBadRequest("use the scheduled status to publish in the future")
+			}
+			post.PublishedAt = publishAt
+		} else if post.PublishedAt == nil || post.Status == entities.PostStatusScheduled {
+			post.PublishedAt = &now
+		}
+	}
+
+	post.Status = status
+	return nil
+}
+
+// checkStatusTransition enforces the review workflow on a status change. from
+// is empty for new posts. Reviewers may publish without approval; everyone else
+// needs the post approved (or already live) first. Moving a live post between
+// live statuses changes who can see it, not what it says; text changes are
+// held back by contentChangeNeedsReview.
+func checkStatusTransition(from, to string, reviewer bool) error {
+	if from == to {
+		return nil
+	}
+	switch to {
+	case entities.PostStatusApproved, entities.PostStatusChangesRequested:
+		return apperror.NewBadRequest("posts are approved or sent back through review")
+	case entities.PostStatusInReview:
+		if from != "" && from != entities.PostStatusDraft && from != entities.PostStatusChangesRequested {
+			return apperror.NewBadRequest("only drafts can be submitted for review")
+		}
+	case entities.PostStatusScheduled, entities.PostStatusPublished, entities.PostStatusUnlisted:
+		if !reviewer && from != entities.PostStatusApproved && !entities.IsLiveStatus(from) {
+			return apperror.NewForbidden("posts need an editor's approval before they can be published")
+		}
+	}
+	return nil
+}
+
+// contentChangeNeedsReview reports whether changing the title, excerpt or
+// content of a post in status sends it back to the review queue. Approval
+// covers the text the editor saw, so that holds for approved posts and for
+// live ones alike; only reviewers may change what readers see directly.
+func contentChangeNeedsReview(status string, reviewer bool) bool {
+	if reviewer {
+		return false
+	}
+	return status == entities.PostStatusApproved || entities.IsLiveStatus(status)
+}
+
+// PublishScheduledPosts publishes every scheduled post whose time has come.
+// It is run periodically from main.
+func (s *InsightService) PublishScheduledPosts() {
+	now := time.Now()
+	posts, err := s.postRepo.FindDueScheduled(now, scheduledPublishBatch)
+	if err != nil {
+		log.Printf("posts: failed to load scheduled posts: %v", err)
+		return
+	}
+
+	published := 0
+	for _, post := range posts {
+		ok, err := s.postRepo.MarkPublished(post.ID, now)
+		if err != nil {
+			log.Printf("posts: failed to publish scheduled post %s: %v", post.ID, err)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		published++
+		if err := s.recordStatusEvent(s.db, post.ID, entities.PostStatusScheduled, entities.PostStatusPublished, nil, ""); err != nil {
+			log.Printf("posts: failed to record publication of scheduled post %s: %v", post.ID, err)
+		}
+		s.invalidatePostDetailCaches(post.Slug, post.ID)
+		revalidation.TriggerPostRevalidation(post.Slug)
+	}
+
+	if published > 0 {
+		s.invalidatePostListCaches()
+		log.Printf("posts: published %d scheduled posts", published)
+	}
+}
+
+// ListMyPosts lists the caller's own posts in every status, optionally
+// filtered to one.
+func (s *InsightService) ListMyPosts(userID uuid.UUID, status string, req *dto.PaginationRequest) ([]*dto.PostResponse, int64, error) {
+	if status != "" && !entities.IsValidPostStatus(status) {
+		return nil, 0, apperror.NewBadRequest("invalid post status")
+	}
+	if req.Limit == 0 {
+		req.Limit = 20
+	}
+
+	posts, err := s.postRepo.FindByUserIDAndStatus(userID, status, req.Limit, req.Offset)
+	if err != nil {
+		return nil, 0, apperror.NewInternal("failed to list posts", err)
+	}
+	total, err := s.postRepo.CountByUserIDAndStatus(userID, status)
+	if err != nil {
+		return nil, 0, apperror.NewInternal("failed to count posts", err)
+	}
+
+	responses := make([]*dto.PostResponse, 0, len(posts))
+	for _, post := range posts {
+		responses = append(responses, dto.NewPostResponse(post))
+	}
+	return responses, total, nil
+}
+
+// GetMyPost returns a post with its content regardless of status, for its
+// author or anyone allowed to edit other people's posts. Views are not counted.
+func (s *InsightService) GetMyPost(userID, id uuid.UUID) (*dto.PostResponse, error) {
+	post, err := s.findAuthorizedPost(userID, id, postAccessRead)
+	if err != nil {
+		return nil, err
+	}
+
+	s.loadPostContent(post)
+	return dto.NewPostResponse(post), nil
+}
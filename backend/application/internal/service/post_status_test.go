@@ -0,0 +1,43 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/pdhoang91/blog/internal/entities"
+)
+
+func TestContentChangeNeedsReview(t *testing.T) {
+	tests := []struct {
+		status   string
+		reviewer bool
+		want     bool
+	}{
+		{entities.PostStatusDraft, false, false},
+		{entities.PostStatusChangesRequested, false, false},
+		{entities.PostStatusInReview, false, false},
+		{entities.PostStatusApproved, false, true},
+		{entities.PostStatusScheduled, false, true},
+		{entities.PostStatusPublished, false, true},
+		{entities.PostStatusUnlisted, false, true},
+		{entities.PostStatusArchived, false, false},
+		{entities.PostStatusApproved, true, false},
+		{entities.PostStatusPublished, true, false},
+	}
+	for _, tt := range tests {
+		if got := contentChangeNeedsReview(tt.status, tt.reviewer); got != tt.want {
+			t.Errorf("contentChangeNeedsReview(%q, reviewer=%v) = %v, want %v", tt.status, tt.reviewer, got, tt.want)
+		}
+	}
+}
+
+func TestCheckStatusTransitionLivePosts(t *testing.T) {
+	// Authors may move their live posts between live statuses; the text
+	// itself is held back by contentChangeNeedsReview.
+	if err := checkStatusTransition(entities.PostStatusPublished, entities.PostStatusUnlisted, false); err != nil {
+		t.Errorf("unlisting a published post: %v", err)
+	}
+	// A live post sent back to review needs approval again.
+	if err := checkStatusTransition(entities.PostStatusInReview, entities.PostStatusPublished, false); err == nil {
+		t.Error("an author republished a post in review without approval")
+	}
+}
@@ -0,0 +1,183 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pdhoang91/blog/constants"
+	"github.com/pdhoang91/blog/internal/apperror"
+	"github.com/pdhoang91/blog/internal/dto"
+	"github.com/pdhoang91/blog/internal/entities"
+	uuid "github.com/satori/go.uuid"
+	"gorm.io/gorm"
+)
+
+// recordStatusEvent adds a status change to the post's history within tx.
+// actorID is nil for changes nobody made by hand, like scheduled publishing.
+func (s *InsightService) recordStatusEvent(tx *gorm.DB, postID uuid.UUID, from, to string, actorID *uuid.UUID, comment string) error {
+	event := &entities.PostStatusEvent{
+		ID:         uuid.NewV4(),
+		PostID:     postID,
+		FromStatus: from,
+		ToStatus:   to,
+		ActorID:    actorID,
+		Comment:    comment,
+		CreatedAt:  time.Now(),
+	}
+	if err := s.statusEventRepo.WithTx(tx).Create(event); err != nil {
+		return apperror.NewInternal("failed to record status change", err)
+	}
+	return nil
+}
+
+// transitionPost locks the post, lets apply check and change its status, then
+// saves it together with the history entry.
+func (s *InsightService) transitionPost(userID, postID uuid.UUID, comment string, apply func(post *entities.Post) error) (*entities.Post, error) {
+	tx := s.db.Begin()
+	if tx.Error != nil {
+		return nil, apperror.NewInternal("failed to start transaction", tx.Error)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	txPostRepo := s.postRepo.WithTx(tx)
+	post, err := txPostRepo.FindByIDForUpdate(postID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.NewNotFound("post not found")
+		}
+		return nil, apperror.NewInternal("failed to find post", err)
+	}
+
+	from := post.Status
+	if err := apply(post); err != nil {
+		return nil, err
+	}
+	post.UpdatedAt = time.Now()
+	if err := txPostRepo.Update(post); err != nil {
+		return nil, apperror.NewInternal("failed to update post", err)
+	}
+	if err := s.recordStatusEvent(tx, post.ID, from, post.Status, &userID, comment); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, apperror.NewInternal("failed to commit transaction", err)
+	}
+	if err := s.postRepo.LoadRelationships(post); err != nil {
+		return nil, apperror.NewInternal("failed to load post relationships", err)
+	}
+	return post, nil
+}
+
+// SubmitPostForReview puts a draft, or a post sent back with requested
+// changes, in the editors' review queue.
+func (s *InsightService) SubmitPostForReview(userID, postID uuid.UUID, req *dto.SubmitForReviewRequest) (*dto.PostResponse, error) {
+	post, err := s.transitionPost(userID, postID, strings.TrimSpace(req.Comment), func(post *entities.Post) error {
+		if err := s.authorizePost(post, userID, postAccessEdit); err != nil {
+			return err
+		}
+		if post.Status == entities.PostStatusInReview {
+			return apperror.NewConflict("post is already waiting for review")
+		}
+		if err := checkStatusTransition(post.Status, entities.PostStatusInReview, false); err != nil {
+			return err
+		}
+		post.Status = entities.PostStatusInReview
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dto.NewPostResponse(post), nil
+}
+
+// ReviewPost approves a post waiting for review or sends it back to its
+// authors with requested changes.
+func (s *InsightService) ReviewPost(userID, postID uuid.UUID, req *dto.ReviewPostRequest) (*dto.PostResponse, error) {
+	allowed, err := s.userCan(userID, constants.PermReviewPosts)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, apperror.NewForbidden("your role does not allow reviewing posts")
+	}
+
+	comment := strings.TrimSpace(req.Comment)
+	status := entities.PostStatusApproved
+	if req.Decision == dto.ReviewRequestChanges {
+		if comment == "" {
+			return nil, apperror.NewBadRequest("say what should change when requesting changes")
+		}
+		status = entities.PostStatusChangesRequested
+	}
+
+	post, err := s.transitionPost(userID, postID, comment, func(post *entities.Post) error {
+		if post.Status != entities.PostStatusInReview {
+			return apperror.NewConflict("post is not waiting for review")
+		}
+		post.Status = status
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.sendReviewDecision(post, status, comment); err != nil {
+		log.Printf("posts: failed to send review decision for post %s: %v", post.ID, err)
+	}
+	return dto.NewPostResponse(post), nil
+}
+
+func (s *InsightService) sendReviewDecision(post *entities.Post, status, comment string) error {
+	subject := "Your post was approved"
+	body := fmt.Sprintf("Hi %s,\n\n\"%s\" was approved and can be published now.\n", post.User.Name, post.Title)
+	if status == entities.PostStatusChangesRequested {
+		subject = "Changes requested on your post"
+		body = fmt.Sprintf("Hi %s,\n\nAn editor asked for changes to \"%s\" before it can be published:\n\n%s\n", post.User.Name, post.Title, comment)
+	}
+	body += fmt.Sprintf("\n%s/edit/%s\n", os.Getenv("BASE_FE_URL"), post.ID)
+	return s.sendMail(post.User.Email, subject, body)
+}
+
+// ListReviewQueue lists posts waiting for review, longest waiting first.
+func (s *InsightService) ListReviewQueue(req *dto.PaginationRequest) ([]*dto.PostResponse, int64, error) {
+	if req.Limit == 0 {
+		req.Limit = 20
+	}
+
+	posts, err := s.postRepo.FindByStatus(entities.PostStatusInReview, req.Limit, req.Offset)
+	if err != nil {
+		return nil, 0, apperror.NewInternal("failed to list review queue", err)
+	}
+	total, err := s.postRepo.CountByStatus(entities.PostStatusInReview)
+	if err != nil {
+		return nil, 0, apperror.NewInternal("failed to count review queue", err)
+	}
+
+	responses := make([]*dto.PostResponse, 0, len(posts))
+	for _, post := range posts {
+		responses = append(responses, dto.NewPostResponse(post))
+	}
+	return responses, total, nil
+}
+
+// GetPostHistory lists who moved the post between statuses and when.
+func (s *InsightService) GetPostHistory(userID, postID uuid.UUID) ([]*dto.PostStatusEventResponse, error) {
+	if _, err := s.findAuthorizedPost(userID, postID, postAccessRead); err != nil {
+		return nil, err
+	}
+
+	events, err := s.statusEventRepo.FindByPostID(postID)
+	if err != nil {
+		return nil, apperror.NewInternal("failed to get post history", err)
+	}
+	responses := make([]*dto.PostStatusEventResponse, 0, len(events))
+	for _, event := range events {
+		responses = append(responses, dto.NewPostStatusEventResponse(event))
+	}
+	return responses, nil
+}
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/pdhoang91/blog/internal/apperror"
+	"github.com/pdhoang91/blog/internal/dto"
+	"github.com/pdhoang91/blog/internal/entities"
+	"github.com/pdhoang91/blog/pkg/textdiff"
+	uuid "github.com/satori/go.uuid"
+	"gorm.io/gorm"
+)
+
+const defaultRevisionRetention = 50
+
+// revisionRetention is how many revisions are kept per post, configurable
+// through POST_REVISION_RETENTION. Older ones are pruned on save.
+func revisionRetention() int {
+	if n, err := strconv.Atoi(os.Getenv("POST_REVISION_RETENTION")); err == nil && n > 0 {
+		return n
+	}
+	return defaultRevisionRetention
+}
+
+// recordRevision snapshots post and content as the next revision within tx,
+// unless they match the latest revision already. It reports whether a revision
+// was written, i.e. whether the post's text changed.
+func (s *InsightService) recordRevision(tx *gorm.DB, post *entities.Post, content json.RawMessage, authorID uuid.UUID) (bool, error) {
+	repo := s.revisionRepo.WithTx(tx)
+
+	number := 1
+	latest, err := repo.FindLatest(post.ID)
+	switch {
+	case err == nil:
+		if latest.Title == post.Title && latest.Excerpt == post.Excerpt && sameJSON(latest.Content, content) {
+			return false, nil
+		}
+		number = latest.RevisionNumber + 1
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return false, apperror.NewInternal("failed to load latest revision", err)
+	}
+
+	rev := &entities.PostRevision{
+		ID:             uuid.NewV4(),
+		PostID:         post.ID,
+		RevisionNumber: number,
+		Title:          post.Title,
+		Excerpt:        post.Excerpt,
+		Content:        content,
+		AuthorID:       &authorID,
+		CreatedAt:      time.Now(),
+	}
+	if err := repo.Create(rev); err != nil {
+		return false, apperror.NewInternal("failed to save revision", err)
+	}
+	if _, err := repo.Prune(post.ID, revisionRetention()); err != nil {
+		return false, apperror.NewInternal("failed to prune revisions", err)
+	}
+	return true, nil
+}
+
+// sameJSON compares documents by value; jsonb does not preserve key order or
+// whitespace, so the stored bytes differ from what was submitted.
+func sameJSON(a, b json.RawMessage) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return len(a) == len(b)
+	}
+	var va, vb interface{}
+	if json.Unmarshal(a, &va) != nil || json.Unmarshal(b, &vb) != nil {
+		return string(a) == string(b)
+	}
+	return reflect.DeepEqual(va, vb)
+}
+
+func (s *InsightService) findRevision(postID uuid.UUID, number int) (*entities.PostRevision, error) {
+	rev, err := s.revisionRepo.FindByNumber(postID, number)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.NewNotFound("revision not found")
+		}
+		return nil, apperror.NewInternal("failed to find revision", err)
+	}
+	return rev, nil
+}
+
+// ListPostRevisions lists a post's revisions newest first, without content.
+func (s *InsightService) ListPostRevisions(userID, postID uuid.UUID, req *dto.PaginationRequest) ([]*dto.PostRevisionResponse, int64, error) {
+	if _, err := s.findAuthorizedPost(userID, postID, postAccessRead); err != nil {
+		return nil, 0, err
+	}
+	if req.Limit == 0 {
+		req.Limit = 20
+	}
+
+	revs, err := s.revisionRepo.FindByPostID(postID, req.Limit, req.Offset)
+	if err != nil {
+		return nil, 0, apperror.NewInternal("failed to list revisions", err)
+	}
+	total, err := s.revisionRepo.CountByPostID(postID)
+	if err != nil {
+		return nil, 0, apperror.NewInternal("failed to count revisions", err)
+	}
+
+	responses := make([]*dto.PostRevisionResponse, 0, len(revs))
+	for _, rev := range revs {
+		responses = append(responses, dto.NewPostRevisionResponse(rev))
+	}
+	return responses, total, nil
+}
+
+// GetPostRevision returns one revision with its content ready for display.
+func (s *InsightService) GetPostRevision(userID, postID uuid.UUID, number int) (*dto.PostRevisionResponse, error) {
+	if _, err := s.findAuthorizedPost(userID, postID, postAccessRead); err != nil {
+		return nil, err
+	}
+	rev, err := s.findRevision(postID, number)
+	if err != nil {
+		return nil, err
+	}
+	if len(rev.Content) > 0 {
+		rev.Content = s.storageManager.ProcessJSONContentForDisplay(rev.Content)
+	}
+	return dto.NewPostRevisionResponse(rev), nil
+}
+
+// DiffPostRevisions compares the plain text of two revisions. from may be 0
+// to diff against an empty post.
+func (s *InsightService) DiffPostRevisions(userID, postID uuid.UUID, from, to int) (*dto.RevisionDiffResponse, error) {
+	if from < 0 || to < 1 {
+		return nil, apperror.NewBadRequest("invalid revision number")
+	}
+	if _, err := s.findAuthorizedPost(userID, postID, postAccessRead); err != nil {
+		return nil, err
+	}
+
+	newer, err := s.findRevision(postID, to)
+	if err != nil {
+		return nil, err
+	}
+	older := &entities.PostRevision{}
+	if from > 0 {
+		if older, err = s.findRevision(postID, from); err != nil {
+			return nil, err
+		}
+	}
+
+	resp := &dto.RevisionDiffResponse{
+		From:    from,
+		To:      to,
+		Title:   textdiff.Words(older.Title, newer.Title),
+		Excerpt: textdiff.Words(older.Excerpt, newer.Excerpt),
+		Content: textdiff.Words(
+			s.storageManager.ExtractPlainTextFromJSON(older.Content),
+			s.storageManager.ExtractPlainTextFromJSON(newer.Content),
+		),
+	}
+	resp.WordsAdded, resp.WordsRemoved = textdiff.Counts(resp.Content)
+	return resp, nil
+}
+
+// RestorePostRevision makes a revision's title, excerpt and content current
+// again. The restore is saved like any other edit, so it becomes the newest
+// revision and can itself be undone, and like any other edit it fails with a
+// conflict unless version is the post's current one.
+func (s *InsightService) RestorePostRevision(userID, postID uuid.UUID, number, version int) (*dto.PostResponse, error) {
+	post, err := s.findAuthorizedPost(userID, postID, postAccessEdit)
+	if err != nil {
+		return nil, err
+	}
+	if post.Version != version {
+		return nil, s.postVersionConflict(post)
+	}
+	rev, err := s.findRevision(postID, number)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.UpdatePost(userID, postID, &dto.UpdatePostRequest{
+		Title:   rev.Title,
+		Excerpt: rev.Excerpt,
+		Content: rev.Content,
+		Version: &version,
+	})
+}
@@ -0,0 +1,36 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/pdhoang91/blog/constants"
+	"github.com/pdhoang91/blog/internal/apperror"
+	"github.com/pdhoang91/blog/internal/dto"
+	"github.com/pdhoang91/blog/internal/entities"
+	uuid "github.com/satori/go.uuid"
+)
+
+func TestRestorePostRevisionStaleVersion(t *testing.T) {
+	s, f := newTestService()
+	owner := f.addUser(constants.RoleAuthor)
+	post := &entities.Post{ID: uuid.NewV4(), UserID: owner, Title: "Edited meanwhile", Version: 4}
+	f.posts.posts[post.ID] = post
+
+	// The author loaded version 3; someone saved version 4 since.
+	_, err := s.RestorePostRevision(owner, post.ID, 1, 3)
+
+	var appErr *apperror.AppError
+	if !errors.As(err, &appErr) || appErr.Code != http.StatusConflict {
+		t.Fatalf("got %v, want a version conflict", err)
+	}
+	conflict, ok := appErr.Details.(*dto.VersionConflictResponse)
+	if !ok {
+		t.Fatalf("conflict details are %T, want the current post", appErr.Details)
+	}
+	current, ok := conflict.Current.(*dto.PostResponse)
+	if conflict.CurrentVersion != 4 || !ok || current.Title != "Edited meanwhile" {
+		t.Errorf("conflict carries version %d and %+v, want the post as saved at version 4", conflict.CurrentVersion, conflict.Current)
+	}
+}
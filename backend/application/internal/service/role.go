@@ -0,0 +1,92 @@
+package service
+
+import (
+	"errors"
+	"log"
+
+	"github.com/pdhoang91/blog/constants"
+	"github.com/pdhoang91/blog/internal/apperror"
+	"github.com/pdhoang91/blog/internal/dto"
+	uuid "github.com/satori/go.uuid"
+	"gorm.io/gorm"
+)
+
+// ListRoles describes every assignable role and its permissions.
+func (s *InsightService) ListRoles() []*dto.RoleResponse {
+	roles := constants.AllRoles()
+	responses := make([]*dto.RoleResponse, 0, len(roles))
+	for _, role := range roles {
+		responses = append(responses, dto.NewRoleResponse(role))
+	}
+	return responses
+}
+
+// ChangeUserRole assigns a new role to a user. The change applies to the
+// user's existing sessions on their next request. Admins cannot change their
+// own role, and the last admin cannot be demoted.
+func (s *InsightService) ChangeUserRole(adminID, userID uuid.UUID, role string) (*dto.UserResponse, error) {
+	if !constants.IsValidRole(role) {
+		return nil, apperror.NewBadRequest("unknown role")
+	}
+	if adminID == userID {
+		return nil, apperror.NewForbidden("you cannot change your own role")
+	}
+
+	user, err := s.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	current := constants.NormalizeRole(user.Role)
+	if string(current) == role {
+		return dto.NewUserResponse(user), nil
+	}
+	if current == constants.RoleAdmin {
+		admins, err := s.userRepo.CountByRole(string(constants.RoleAdmin))
+		if err != nil {
+			return nil, apperror.NewInternal("failed to count admins", err)
+		}
+		if admins <= 1 {
+			return nil, apperror.NewConflict("cannot demote the last admin")
+		}
+	}
+
+	if err := s.userRepo.UpdateRole(userID, role); err != nil {
+		return nil, apperror.NewInternal("failed to update role", err)
+	}
+	if err := s.forgetUserSessions(userID); err != nil {
+		log.Printf("auth: failed to refresh sessions of user %s after role change: %v", userID, err)
+	}
+	log.Printf("auth: user %s changed role of user %s from %s to %s", adminID, userID, current, role)
+
+	user.Role = constants.UserRole(role)
+	return dto.NewUserResponse(user), nil
+}
+
+// userCan reports whether the user's current role grants perm. Services use it
+// to let privileged roles act on content owned by others.
+func (s *InsightService) userCan(userID uuid.UUID, perm constants.Permission) (bool, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, apperror.NewInternal("failed to find user", err)
+	}
+	return constants.HasPermission(user.Role, perm), nil
+}
+
+// authorizeOwnerOr allows the owner of a resource, or anyone whose role grants
+// perm, and returns a forbidden error with msg otherwise.
+func (s *InsightService) authorizeOwnerOr(ownerID, userID uuid.UUID, perm constants.Permission, msg string) error {
+	if ownerID == userID {
+		return nil
+	}
+	allowed, err := s.userCan(userID, perm)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return apperror.NewForbidden(msg)
+	}
+	return nil
+}
@@ -0,0 +1,288 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/pdhoang91/blog/constants"
+	"github.com/pdhoang91/blog/internal/apperror"
+	"github.com/pdhoang91/blog/internal/dto"
+	"github.com/pdhoang91/blog/internal/entities"
+	"github.com/pdhoang91/blog/pkg/utils"
+	uuid "github.com/satori/go.uuid"
+	"gorm.io/gorm"
+)
+
+// seriesNav places post within its series, counting only the parts readers can
+// see. Posts outside any series get nil. Failures are logged and leave the
+// post without navigation rather than failing the request.
+func (s *InsightService) seriesNav(post *entities.Post) *dto.SeriesNavResponse {
+	series, err := s.seriesRepo.FindByPostID(post.ID)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Printf("posts: failed to find series of post %s: %v", post.ID, err)
+		}
+		return nil
+	}
+	parts, err := s.seriesRepo.FindParts(series.ID)
+	if err != nil {
+		log.Printf("posts: failed to load parts of series %s: %v", series.ID, err)
+		return nil
+	}
+
+	var visible []*entities.Post
+	for _, part := range parts {
+		if part.ID == post.ID || part.Status == entities.PostStatusPublished {
+			visible = append(visible, part)
+		}
+	}
+
+	nav := &dto.SeriesNavResponse{
+		ID:    series.ID,
+		Title: series.Title,
+		Slug:  series.Slug,
+		Total: len(visible),
+	}
+	link := func(i int) *dto.SeriesPartResponse {
+		return &dto.SeriesPartResponse{ID: visible[i].ID, Title: visible[i].Title, Slug: visible[i].Slug, Position: i + 1}
+	}
+	for i, part := range visible {
+		if part.ID != post.ID {
+			continue
+		}
+		nav.Position = i + 1
+		if i > 0 {
+			nav.Previous = link(i - 1)
+		}
+		if i < len(visible)-1 {
+			nav.Next = link(i + 1)
+		}
+	}
+	return nav
+}
+
+// invalidateSeriesCaches drops the cached pages of every part of a series, as
+// each links to its neighbours.
+func (s *InsightService) invalidateSeriesCaches(seriesID uuid.UUID) {
+	parts, err := s.seriesRepo.FindParts(seriesID)
+	if err != nil {
+		log.Printf("posts: failed to load parts of series %s: %v", seriesID, err)
+		return
+	}
+	for _, part := range parts {
+		s.dropPostDetailCache(part.Slug, part.ID)
+	}
+}
+
+func (s *InsightService) findSeries(id uuid.UUID) (*entities.Series, error) {
+	series, err := s.seriesRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.NewNotFound("series not found")
+		}
+		return nil, apperror.NewInternal("failed to find series", err)
+	}
+	return series, nil
+}
+
+// checkSeriesPosts makes sure the user may edit every post and that none of
+// them is already part of a different series.
+func (s *InsightService) checkSeriesPosts(userID, seriesID uuid.UUID, postIDs []uuid.UUID) error {
+	seen := make(map[uuid.UUID]bool, len(postIDs))
+	for _, postID := range postIDs {
+		if seen[postID] {
+			return apperror.NewBadRequest("a post can appear only once in a series")
+		}
+		seen[postID] = true
+
+		if _, err := s.findAuthorizedPost(userID, postID, postAccessEdit); err != nil {
+			return err
+		}
+		current, err := s.seriesRepo.FindByPostID(postID)
+		switch {
+		case err == nil:
+			if current.ID != seriesID {
+				return apperror.NewConflict(fmt.Sprintf("post %s is already part of the series %q", postID, current.Title))
+			}
+		case !errors.Is(err, gorm.ErrRecordNotFound):
+			return apperror.NewInternal("failed to check series", err)
+		}
+	}
+	return nil
+}
+
+// GetSeriesBySlug returns a series with its published parts in order.
+func (s *InsightService) GetSeriesBySlug(slug string) (*dto.SeriesResponse, error) {
+	cacheKey := "series:" + slug
+	if cached, ok := s.cache.Get(cacheKey); ok {
+		if resp, ok := cached.(*dto.SeriesResponse); ok {
+			return resp, nil
+		}
+	}
+
+	series, err := s.seriesRepo.FindBySlug(slug)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.NewNotFound("series not found")
+		}
+		return nil, apperror.NewInternal("failed to get series", err)
+	}
+	posts, err := s.seriesRepo.FindPosts(series.ID, true)
+	if err != nil {
+		return nil, apperror.NewInternal("failed to get series posts", err)
+	}
+
+	resp := dto.NewSeriesResponse(series, posts)
+	s.cache.Set(cacheKey, resp, 60*time.Second)
+	return resp, nil
+}
+
+// ListMySeries lists the user's series with all their parts, drafts included.
+func (s *InsightService) ListMySeries(userID uuid.UUID) ([]*dto.SeriesResponse, error) {
+	list, err := s.seriesRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, apperror.NewInternal("failed to list series", err)
+	}
+
+	responses := make([]*dto.SeriesResponse, 0, len(list))
+	for _, series := range list {
+		posts, err := s.seriesRepo.FindPosts(series.ID, false)
+		if err != nil {
+			return nil, apperror.NewInternal("failed to get series posts", err)
+		}
+		responses = append(responses, dto.NewSeriesResponse(series, posts))
+	}
+	return responses, nil
+}
+
+func (s *InsightService) CreateSeries(userID uuid.UUID, req *dto.CreateSeriesRequest) (*dto.SeriesResponse, error) {
+	allowed, err := s.userCan(userID, constants.PermPublish)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, apperror.NewForbidden("your role does not allow publishing posts")
+	}
+
+	series := &entities.Series{
+		ID:          uuid.NewV4(),
+		UserID:      userID,
+		Title:       strings.TrimSpace(req.Title),
+		Description: req.Description,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	if err := s.checkSeriesPosts(userID, series.ID, req.PostIDs); err != nil {
+		return nil, err
+	}
+
+	series.Slug = utils.CreateSlug(series.Title)
+	if s.seriesRepo.ExistsBySlug(series.Slug) {
+		series.Slug = fmt.Sprintf("%s-%s", series.Slug, utils.GetUniquePrefix())
+	}
+
+	tx := s.db.Begin()
+	if tx.Error != nil {
+		return nil, apperror.NewInternal("failed to start transaction", tx.Error)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	txSeriesRepo := s.seriesRepo.WithTx(tx)
+	if err := txSeriesRepo.Create(series); err != nil {
+		return nil, apperror.NewInternal("failed to create series", err)
+	}
+	if err := txSeriesRepo.SetPosts(series.ID, req.PostIDs); err != nil {
+		return nil, apperror.NewInternal("failed to add series posts", err)
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, apperror.NewInternal("failed to commit transaction", err)
+	}
+
+	s.invalidateSeriesCaches(series.ID)
+	s.invalidatePostListCaches()
+	return s.mySeriesResponse(series.ID)
+}
+
+// UpdateSeries changes a series' title, description or parts. The slug stays
+// as it was created so links to the series keep working.
+func (s *InsightService) UpdateSeries(userID, id uuid.UUID, req *dto.UpdateSeriesRequest) (*dto.SeriesResponse, error) {
+	series, err := s.findSeries(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorizeOwnerOr(series.UserID, userID, constants.PermEditAnyPost, "you can only change your own series"); err != nil {
+		return nil, err
+	}
+	if req.PostIDs != nil {
+		if err := s.checkSeriesPosts(userID, series.ID, *req.PostIDs); err != nil {
+			return nil, err
+		}
+	}
+
+	if req.Title != "" {
+		series.Title = strings.TrimSpace(req.Title)
+	}
+	if req.Description != nil {
+		series.Description = *req.Description
+	}
+	series.UpdatedAt = time.Now()
+
+	// Parts dropped from the series link to their old neighbours until now.
+	s.invalidateSeriesCaches(series.ID)
+
+	tx := s.db.Begin()
+	if tx.Error != nil {
+		return nil, apperror.NewInternal("failed to start transaction", tx.Error)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	txSeriesRepo := s.seriesRepo.WithTx(tx)
+	if err := txSeriesRepo.Update(series); err != nil {
+		return nil, apperror.NewInternal("failed to update series", err)
+	}
+	if req.PostIDs != nil {
+		if err := txSeriesRepo.SetPosts(series.ID, *req.PostIDs); err != nil {
+			return nil, apperror.NewInternal("failed to update series posts", err)
+		}
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, apperror.NewInternal("failed to commit transaction", err)
+	}
+
+	s.invalidateSeriesCaches(series.ID)
+	s.invalidatePostListCaches()
+	return s.mySeriesResponse(series.ID)
+}
+
+// DeleteSeries removes a series; its posts stay as they are.
+func (s *InsightService) DeleteSeries(userID, id uuid.UUID) error {
+	series, err := s.findSeries(id)
+	if err != nil {
+		return err
+	}
+	if err := s.authorizeOwnerOr(series.UserID, userID, constants.PermEditAnyPost, "you can only delete your own series"); err != nil {
+		return err
+	}
+
+	s.invalidateSeriesCaches(series.ID)
+	if err := s.seriesRepo.Delete(series.ID); err != nil {
+		return apperror.NewInternal("failed to delete series", err)
+	}
+	s.invalidatePostListCaches()
+	return nil
+}
+
+func (s *InsightService) mySeriesResponse(id uuid.UUID) (*dto.SeriesResponse, error) {
+	series, err := s.findSeries(id)
+	if err != nil {
+		return nil, err
+	}
+	posts, err := s.seriesRepo.FindPosts(series.ID, false)
+	if err != nil {
+		return nil, apperror.NewInternal("failed to get series posts", err)
+	}
+	return dto.NewSeriesResponse(series, posts), nil
+}
@@ -0,0 +1,396 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pdhoang91/blog/constants"
+	"github.com/pdhoang91/blog/internal/apperror"
+	"github.com/pdhoang91/blog/internal/dto"
+	"github.com/pdhoang91/blog/internal/entities"
+	"github.com/pdhoang91/blog/internal/repository"
+	jwtUtil "github.com/pdhoang91/blog/pkg/jwt"
+	uuid "github.com/satori/go.uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultRefreshTokenTTL = 30 * 24 * time.Hour
+	// sessionCacheTTL bounds how long a revoked session can keep passing
+	// AuthMiddleware on instances that only see the in-memory cache.
+	sessionCacheTTL = 30 * time.Second
+)
+
+// refreshTokenTTL returns the sliding lifetime of a session, configurable
+// through REFRESH_TOKEN_TTL as a Go duration (e.g. "720h").
+func refreshTokenTTL() time.Duration {
+	if d, err := time.ParseDuration(os.Getenv("REFRESH_TOKEN_TTL")); err == nil && d > 0 {
+		return d
+	}
+	return defaultRefreshTokenTTL
+}
+
+// newOpaqueToken returns a random URL-safe token and the SHA-256 hash that is persisted for it.
+func newOpaqueToken() (raw string, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	raw = base64.RawURLEncoding.EncodeToString(b)
+	return raw, hashToken(raw), nil
+}
+
+func hashToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+func sessionCacheKey(sessionID string) string {
+	return "session_active:" + sessionID
+}
+
+// describeDevice turns a User-Agent header into a short label such as "Chrome on macOS".
+func describeDevice(userAgent string) string {
+	if userAgent == "" {
+		return "Unknown device"
+	}
+	ua := strings.ToLower(userAgent)
+
+	browser := "Unknown browser"
+	switch {
+	case strings.Contains(ua, "edg/"):
+		browser = "Edge"
+	case strings.Contains(ua, "opr/") || strings.Contains(ua, "opera"):
+		browser = "Opera"
+	case strings.Contains(ua, "firefox/"):
+		browser = "Firefox"
+	case strings.Contains(ua, "chrome/") || strings.Contains(ua, "crios/"):
+		browser = "Chrome"
+	case strings.Contains(ua, "safari/"):
+		browser = "Safari"
+	case strings.Contains(ua, "curl/"):
+		browser = "curl"
+	}
+
+	platform := ""
+	switch {
+	case strings.Contains(ua, "iphone") || strings.Contains(ua, "ipad"):
+		platform = "iOS"
+	case strings.Contains(ua, "android"):
+		platform = "Android"
+	case strings.Contains(ua, "windows"):
+		platform = "Windows"
+	case strings.Contains(ua, "mac os x") || strings.Contains(ua, "macintosh"):
+		platform = "macOS"
+	case strings.Contains(ua, "linux"):
+		platform = "Linux"
+	}
+	if platform == "" {
+		return browser
+	}
+	return browser + " on " + platform
+}
+
+// applyClientInfo records where the session is being used from.
+func applyClientInfo(session *entities.UserSession, client *dto.ClientInfo) {
+	if client == nil {
+		return
+	}
+	session.IPAddress = client.IPAddress
+	session.UserAgent = client.UserAgent
+	session.Device = describeDevice(client.UserAgent)
+}
+
+// issueSession starts a new login session for user and returns the access/refresh token pair.
+// mfaVerified records whether the login passed a second factor.
+func (s *InsightService) issueSession(user *entities.User, client *dto.ClientInfo, mfaVerified bool) (*dto.LoginResponse, error) {
+	now := time.Now()
+	session := &entities.UserSession{
+		ID:          uuid.NewV4(),
+		UserID:      user.ID,
+		MFAVerified: mfaVerified,
+		LastSeenAt:  now,
+		ExpiresAt:   now.Add(refreshTokenTTL()),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	applyClientInfo(session, client)
+
+	var refreshToken string
+	if err := withTx(s.db, func(tx *gorm.DB) error {
+		txSessionRepo := s.sessionRepo.WithTx(tx)
+		if err := txSessionRepo.Create(session); err != nil {
+			return apperror.NewInternal("failed to create session", err)
+		}
+		raw, err := s.createRefreshToken(txSessionRepo, session, nil)
+		if err != nil {
+			return err
+		}
+		refreshToken = raw
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return s.buildLoginResponse(user, session, refreshToken)
+}
+
+// createRefreshToken mints a refresh token in the session's family.
+func (s *InsightService) createRefreshToken(repo repository.SessionRepository, session *entities.UserSession, parentID *uuid.UUID) (string, error) {
+	raw, hash, err := newOpaqueToken()
+	if err != nil {
+		return "", apperror.NewInternal("failed to generate refresh token", err)
+	}
+	token := &entities.RefreshToken{
+		ID:        uuid.NewV4(),
+		SessionID: session.ID,
+		UserID:    session.UserID,
+		TokenHash: hash,
+		ParentID:  parentID,
+		ExpiresAt: session.ExpiresAt,
+		CreatedAt: time.Now(),
+	}
+	if err := repo.CreateRefreshToken(token); err != nil {
+		return "", apperror.NewInternal("failed to store refresh token", err)
+	}
+	return raw, nil
+}
+
+func (s *InsightService) buildLoginResponse(user *entities.User, session *entities.UserSession, refreshToken string) (*dto.LoginResponse, error) {
+	accessToken, err := jwtUtil.GenerateJWT(user, session.ID.String())
+	if err != nil {
+		return nil, apperror.NewInternal("failed to generate token", err)
+	}
+	return &dto.LoginResponse{
+		Token:        accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(jwtUtil.AccessTokenTTL().Seconds()),
+		User:         dto.NewUserResponse(user),
+	}, nil
+}
+
+// RefreshToken exchanges a single-use refresh token for a new token pair.
+// Presenting a token that was already exchanged revokes the whole session.
+func (s *InsightService) RefreshToken(refreshToken string, client *dto.ClientInfo) (*dto.LoginResponse, error) {
+	token, err := s.sessionRepo.FindRefreshTokenByHash(hashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.NewUnauthorized("invalid refresh token")
+		}
+		return nil, apperror.NewInternal("failed to find refresh token", err)
+	}
+
+	session, err := s.sessionRepo.FindByID(token.SessionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.NewUnauthorized("invalid refresh token")
+		}
+		return nil, apperror.NewInternal("failed to find session", err)
+	}
+
+	now := time.Now()
+	if token.RevokedAt != nil || session.RevokedAt != nil {
+		return nil, apperror.NewUnauthorized("session has been revoked")
+	}
+	if token.UsedAt != nil {
+		s.revokeReusedFamily(session)
+		return nil, apperror.NewUnauthorized("refresh token reuse detected")
+	}
+	if !now.Before(token.ExpiresAt) || !session.IsActive(now) {
+		return nil, apperror.NewUnauthorized("refresh token expired")
+	}
+
+	user, err := s.userRepo.FindByID(session.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.NewUnauthorized("user no longer exists")
+		}
+		return nil, apperror.NewInternal("failed to find user", err)
+	}
+
+	var newRefreshToken string
+	reused := false
+	if err := withTx(s.db, func(tx *gorm.DB) error {
+		txSessionRepo := s.sessionRepo.WithTx(tx)
+		consumed, err := txSessionRepo.MarkRefreshTokenUsed(token.ID)
+		if err != nil {
+			return apperror.NewInternal("failed to consume refresh token", err)
+		}
+		if !consumed {
+			// Lost a race against another exchange of the same token.
+			reused = true
+			return nil
+		}
+
+		session.ExpiresAt = now.Add(refreshTokenTTL())
+		session.LastSeenAt = now
+		session.UpdatedAt = now
+		applyClientInfo(session, client)
+		if err := txSessionRepo.Update(session); err != nil {
+			return apperror.NewInternal("failed to extend session", err)
+		}
+
+		raw, err := s.createRefreshToken(txSessionRepo, session, &token.ID)
+		if err != nil {
+			return err
+		}
+		newRefreshToken = raw
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	if reused {
+		s.revokeReusedFamily(session)
+		return nil, apperror.NewUnauthorized("refresh token reuse detected")
+	}
+
+	return s.buildLoginResponse(user, session, newRefreshToken)
+}
+
+func (s *InsightService) revokeReusedFamily(session *entities.UserSession) {
+	log.Printf("auth: refresh token reuse detected for session %s (user %s); revoking session", session.ID, session.UserID)
+	if err := s.revokeSession(session.ID); err != nil {
+		log.Printf("auth: failed to revoke session %s: %v", session.ID, err)
+	}
+}
+
+// Logout revokes the session the caller's access token belongs to.
+func (s *InsightService) Logout(sessionID uuid.UUID) error {
+	if err := s.revokeSession(sessionID); err != nil {
+		return apperror.NewInternal("failed to revoke session", err)
+	}
+	return nil
+}
+
+func (s *InsightService) revokeSession(sessionID uuid.UUID) error {
+	if err := s.sessionRepo.Revoke(sessionID); err != nil {
+		return err
+	}
+	s.cache.Delete(sessionCacheKey(sessionID.String()))
+	return nil
+}
+
+// ValidateSession returns an error when the session is unknown, expired or revoked,
+// and otherwise the current role of its user. Results are cached briefly so
+// AuthMiddleware does not hit the database on every request; each cache miss also
+// records the session's last-seen time, which throttles those writes.
+func (s *InsightService) ValidateSession(sessionID string) (string, error) {
+	cacheKey := sessionCacheKey(sessionID)
+	if cached, ok := s.cache.Get(cacheKey); ok {
+		if role, ok := cached.(string); ok && role != "" {
+			return role, nil
+		}
+	}
+
+	id, err := uuid.FromString(sessionID)
+	if err != nil {
+		return "", apperror.NewUnauthorized("invalid session")
+	}
+
+	session, err := s.sessionRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", apperror.NewUnauthorized("invalid session")
+		}
+		return "", apperror.NewInternal("failed to find session", err)
+	}
+	now := time.Now()
+	if !session.IsActive(now) {
+		return "", apperror.NewUnauthorized("session has been revoked")
+	}
+
+	user, err := s.userRepo.FindByID(session.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", apperror.NewUnauthorized("user no longer exists")
+		}
+		return "", apperror.NewInternal("failed to find user", err)
+	}
+	role := string(constants.NormalizeRole(user.Role))
+
+	if err := s.sessionRepo.TouchLastSeen(id, now); err != nil {
+		log.Printf("auth: failed to update last seen for session %s: %v", id, err)
+	}
+	s.cache.Set(cacheKey, role, sessionCacheTTL)
+	return role, nil
+}
+
+// forgetUserSessions drops cached session state for all of the user's sessions,
+// so changes to the account are picked up on their next request.
+func (s *InsightService) forgetUserSessions(userID uuid.UUID) error {
+	sessions, err := s.sessionRepo.ListActiveByUserID(userID, time.Now())
+	if err != nil {
+		return err
+	}
+	for _, session := range sessions {
+		s.cache.Delete(sessionCacheKey(session.ID.String()))
+		s.cache.Delete(sessionMFACacheKey(session.ID.String()))
+	}
+	return nil
+}
+
+// ListSessions returns the user's active sessions, flagging the one making the request.
+func (s *InsightService) ListSessions(userID, currentSessionID uuid.UUID) ([]*dto.SessionResponse, error) {
+	sessions, err := s.sessionRepo.ListActiveByUserID(userID, time.Now())
+	if err != nil {
+		return nil, apperror.NewInternal("failed to list sessions", err)
+	}
+
+	responses := make([]*dto.SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		responses = append(responses, dto.NewSessionResponse(session, currentSessionID))
+	}
+	return responses, nil
+}
+
+// RevokeSession signs out one of the user's sessions.
+func (s *InsightService) RevokeSession(userID, sessionID uuid.UUID) error {
+	session, err := s.sessionRepo.FindByID(sessionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperror.NewNotFound("session not found")
+		}
+		return apperror.NewInternal("failed to find session", err)
+	}
+	// Sessions of other users are reported as missing so ids cannot be probed.
+	if session.UserID != userID || !session.IsActive(time.Now()) {
+		return apperror.NewNotFound("session not found")
+	}
+
+	if err := s.revokeSession(sessionID); err != nil {
+		return apperror.NewInternal("failed to revoke session", err)
+	}
+	return nil
+}
+
+// RevokeAllSessions signs the user out everywhere, including the current session.
+func (s *InsightService) RevokeAllSessions(userID uuid.UUID) (int, error) {
+	ids, err := s.sessionRepo.RevokeAllByUserID(userID)
+	if err != nil {
+		return 0, apperror.NewInternal("failed to revoke sessions", err)
+	}
+	for _, id := range ids {
+		s.cache.Delete(sessionCacheKey(id.String()))
+	}
+	return len(ids), nil
+}
+
+// PurgeExpiredSessions deletes sessions that expired or were revoked more than a day ago.
+func (s *InsightService) PurgeExpiredSessions() {
+	n, err := s.sessionRepo.DeleteExpired(time.Now().Add(-24 * time.Hour))
+	if err != nil {
+		log.Printf("auth: failed to purge expired sessions: %v", err)
+		return
+	}
+	if n > 0 {
+		log.Printf("auth: purged %d expired sessions", n)
+	}
+}
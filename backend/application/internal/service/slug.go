@@ -0,0 +1,96 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/pdhoang91/blog/internal/apperror"
+	"github.com/pdhoang91/blog/internal/entities"
+	"github.com/pdhoang91/blog/pkg/utils"
+	uuid "github.com/satori/go.uuid"
+	"gorm.io/gorm"
+)
+
+const maxSlugLength = 200
+
+// customSlugPattern is what utils.CreateSlug produces: lowercase letters and
+// digits in hyphen-separated runs.
+var customSlugPattern = regexp.MustCompile(`^[a-z0-9]+(?:-[a-z0-9]+)*$`)
+
+// slugTaken reports whether another post uses slug now or used it before; old
+// slugs stay reserved so their redirects keep working.
+func (s *InsightService) slugTaken(slug string, postID uuid.UUID) (bool, error) {
+	if s.postRepo.ExistsBySlugExcluding(slug, postID) {
+		return true, nil
+	}
+	entry, err := s.slugHistoryRepo.FindBySlug(slug)
+	switch {
+	case err == nil:
+		return entry.PostID != postID, nil
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return false, nil
+	default:
+		return false, apperror.NewInternal("failed to check slug", err)
+	}
+}
+
+// derivedSlug builds a slug from title, adding a random suffix when the plain
+// one is taken.
+func (s *InsightService) derivedSlug(title string, postID uuid.UUID) (string, error) {
+	slug := utils.CreateSlug(title)
+	if slug == "" {
+		return utils.GetUniquePrefix(), nil
+	}
+	taken, err := s.slugTaken(slug, postID)
+	if err != nil {
+		return "", err
+	}
+	if taken {
+		slug = fmt.Sprintf("%s-%s", slug, utils.GetUniquePrefix())
+	}
+	return slug, nil
+}
+
+// pinnedSlug validates a slug chosen by the author. Unlike derived slugs it is
+// used as given, so a taken one is a conflict.
+func (s *InsightService) pinnedSlug(slug string, postID uuid.UUID) (string, error) {
+	if len(slug) > maxSlugLength || !customSlugPattern.MatchString(slug) {
+		return "", apperror.NewBadRequest("slug may only contain lowercase letters, digits and single hyphens")
+	}
+	taken, err := s.slugTaken(slug, postID)
+	if err != nil {
+		return "", err
+	}
+	if taken {
+		return "", apperror.NewConflict("slug is already used by another post")
+	}
+	return slug, nil
+}
+
+// recordSlugChange keeps oldSlug as a redirect to the post within tx. If the
+// post takes back one of its old slugs, that entry is dropped.
+func (s *InsightService) recordSlugChange(tx *gorm.DB, post *entities.Post, oldSlug string) error {
+	repo := s.slugHistoryRepo.WithTx(tx)
+	if err := repo.Delete(post.Slug, post.ID); err != nil {
+		return apperror.NewInternal("failed to update slug history", err)
+	}
+	if oldSlug == "" {
+		return nil
+	}
+	entry := &entities.PostSlugHistory{Slug: oldSlug, PostID: post.ID, CreatedAt: time.Now()}
+	if err := repo.Save(entry); err != nil {
+		return apperror.NewInternal("failed to update slug history", err)
+	}
+	return nil
+}
+
+// findPostBySlugHistory resolves an old slug to the post that had it.
+func (s *InsightService) findPostBySlugHistory(slug string) (*entities.Post, error) {
+	entry, err := s.slugHistoryRepo.FindBySlug(slug)
+	if err != nil {
+		return nil, err
+	}
+	return s.postRepo.FindByID(entry.PostID)
+}
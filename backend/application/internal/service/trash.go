@@ -0,0 +1,321 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/pdhoang91/blog/constants"
+	"github.com/pdhoang91/blog/internal/apperror"
+	"github.com/pdhoang91/blog/internal/dto"
+	"github.com/pdhoang91/blog/internal/entities"
+	"github.com/pdhoang91/blog/pkg/revalidation"
+	uuid "github.com/satori/go.uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultTrashRetentionDays = 30
+	trashPurgeBatchSize       = 100
+)
+
+// trashRetention is how long deleted items can be restored before the purge
+// job removes them, configurable in days through TRASH_RETENTION_DAYS.
+func trashRetention() time.Duration {
+	days := defaultTrashRetentionDays
+	if n, err := strconv.Atoi(os.Getenv("TRASH_RETENTION_DAYS")); err == nil && n > 0 {
+		days = n
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// trashPermission is what it takes to handle other people's deleted items of
+// the given type.
+func trashPermission(itemType string) constants.Permission {
+	if itemType == dto.TrashPosts {
+		return constants.PermEditAnyPost
+	}
+	return constants.PermModerateComments
+}
+
+// ListTrash lists the user's deleted items of one type, newest first. With
+// req.All set it lists everyone's, for those allowed to manage them.
+func (s *InsightService) ListTrash(userID uuid.UUID, req *dto.TrashListRequest) ([]*dto.TrashItemResponse, int64, error) {
+	if req.Type == "" {
+		req.Type = dto.TrashPosts
+	}
+	if req.Limit == 0 {
+		req.Limit = 20
+	}
+
+	ownerID := userID
+	if req.All {
+		allowed, err := s.userCan(userID, trashPermission(req.Type))
+		if err != nil {
+			return nil, 0, err
+		}
+		if !allowed {
+			return nil, 0, apperror.NewForbidden("your role does not allow seeing other people's deleted " + req.Type)
+		}
+		ownerID = uuid.Nil
+	}
+
+	retention := trashRetention()
+	var responses []*dto.TrashItemResponse
+	var total int64
+	switch req.Type {
+	case dto.TrashPosts:
+		posts, n, err := s.trashRepo.FindDeletedPosts(ownerID, req.Limit, req.Offset)
+		if err != nil {
+			return nil, 0, apperror.NewInternal("failed to list deleted posts", err)
+		}
+		responses = make([]*dto.TrashItemResponse, 0, len(posts))
+		for _, post := range posts {
+			responses = append(responses, dto.NewTrashPostResponse(post, retention))
+		}
+		total = n
+	case dto.TrashComments:
+		comments, n, err := s.trashRepo.FindDeletedComments(ownerID, req.Limit, req.Offset)
+		if err != nil {
+			return nil, 0, apperror.NewInternal("failed to list deleted comments", err)
+		}
+		responses = make([]*dto.TrashItemResponse, 0, len(comments))
+		for _, comment := range comments {
+			responses = append(responses, dto.NewTrashCommentResponse(comment, retention))
+		}
+		total = n
+	case dto.TrashReplies:
+		replies, n, err := s.trashRepo.FindDeletedReplies(ownerID, req.Limit, req.Offset)
+		if err != nil {
+			return nil, 0, apperror.NewInternal("failed to list deleted replies", err)
+		}
+		responses = make([]*dto.TrashItemResponse, 0, len(replies))
+		for _, reply := range replies {
+			responses = append(responses, dto.NewTrashReplyResponse(reply, retention))
+		}
+		total = n
+	default:
+		return nil, 0, apperror.NewBadRequest("unknown trash type")
+	}
+	return responses, total, nil
+}
+
+// trashOwner finds a deleted item and returns who it belongs to.
+func (s *InsightService) trashOwner(itemType string, id uuid.UUID) (uuid.UUID, error) {
+	var ownerID uuid.UUID
+	var err error
+	switch itemType {
+	case dto.TrashPosts:
+		var post *entities.Post
+		if post, err = s.trashRepo.FindDeletedPost(id); err == nil {
+			ownerID = post.UserID
+		}
+	case dto.TrashComments:
+		var comment *entities.Comment
+		if comment, err = s.trashRepo.FindDeletedComment(id); err == nil {
+			ownerID = comment.UserID
+		}
+	case dto.TrashReplies:
+		var reply *entities.Reply
+		if reply, err = s.trashRepo.FindDeletedReply(id); err == nil {
+			ownerID = reply.UserID
+		}
+	default:
+		return uuid.Nil, apperror.NewBadRequest("unknown trash type")
+	}
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return uuid.Nil, apperror.NewNotFound("item not found in trash")
+		}
+		return uuid.Nil, apperror.NewInternal("failed to find deleted item", err)
+	}
+	return ownerID, nil
+}
+
+func (s *InsightService) authorizeTrashItem(userID uuid.UUID, itemType string, id uuid.UUID) error {
+	ownerID, err := s.trashOwner(itemType, id)
+	if err != nil {
+		return err
+	}
+	return s.authorizeOwnerOr(ownerID, userID, trashPermission(itemType), "you can only manage your own deleted "+itemType)
+}
+
+// RestoreTrashItem brings a deleted item back, together with whatever was
+// deleted along with it. Comments and replies can only come back under a
+// post, or comment, that is not in the trash itself.
+func (s *InsightService) RestoreTrashItem(userID uuid.UUID, itemType string, id uuid.UUID) error {
+	if err := s.authorizeTrashItem(userID, itemType, id); err != nil {
+		return err
+	}
+
+	switch itemType {
+	case dto.TrashPosts:
+		return s.restorePost(id)
+	case dto.TrashComments:
+		return s.restoreComment(id)
+	default:
+		return s.restoreReply(id)
+	}
+}
+
+func (s *InsightService) restorePost(id uuid.UUID) error {
+	post, err := s.trashRepo.FindDeletedPost(id)
+	if err != nil {
+		return apperror.NewInternal("failed to find deleted post", err)
+	}
+	if err := withTx(s.db, func(tx *gorm.DB) error {
+		if err := s.trashRepo.WithTx(tx).RestorePost(post); err != nil {
+			return apperror.NewInternal("failed to restore post", err)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if entities.IsLiveStatus(post.Status) {
+		revalidation.TriggerPostRevalidation(post.Slug)
+	}
+	s.invalidatePostListCaches()
+	s.invalidatePostDetailCaches(post.Slug, post.ID)
+	return nil
+}
+
+func (s *InsightService) restoreComment(id uuid.UUID) error {
+	comment, err := s.trashRepo.FindDeletedComment(id)
+	if err != nil {
+		return apperror.NewInternal("failed to find deleted comment", err)
+	}
+	if _, err := s.postRepo.FindByID(comment.PostID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperror.NewConflict("restore the post of this comment first")
+		}
+		return apperror.NewInternal("failed to find post", err)
+	}
+
+	if err := withTx(s.db, func(tx *gorm.DB) error {
+		if err := s.trashRepo.WithTx(tx).RestoreComment(comment); err != nil {
+			return apperror.NewInternal("failed to restore comment", err)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	// Increment denormalized count (best-effort)
+	_ = s.postRepo.IncrementCommentCount(comment.PostID)
+	return nil
+}
+
+func (s *InsightService) restoreReply(id uuid.UUID) error {
+	reply, err := s.trashRepo.FindDeletedReply(id)
+	if err != nil {
+		return apperror.NewInternal("failed to find deleted reply", err)
+	}
+	if _, err := s.commentRepo.FindByID(reply.CommentID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperror.NewConflict("restore the comment of this reply first")
+		}
+		return apperror.NewInternal("failed to find comment", err)
+	}
+
+	if err := s.trashRepo.RestoreReply(reply); err != nil {
+		return apperror.NewInternal("failed to restore reply", err)
+	}
+	return nil
+}
+
+// PurgeTrashItem removes a deleted item for good without waiting for the
+// retention period to end.
+func (s *InsightService) PurgeTrashItem(userID uuid.UUID, itemType string, id uuid.UUID) error {
+	if err := s.authorizeTrashItem(userID, itemType, id); err != nil {
+		return err
+	}
+	return s.purgeTrashItem(context.Background(), itemType, id)
+}
+
+// purgeTrashItem hard-deletes an item. For posts, the images they used are
+// removed from storage as well once nothing else references them.
+func (s *InsightService) purgeTrashItem(ctx context.Context, itemType string, id uuid.UUID) error {
+	switch itemType {
+	case dto.TrashComments:
+		if err := s.trashRepo.PurgeComment(id); err != nil {
+			return apperror.NewInternal("failed to purge comment", err)
+		}
+		return nil
+	case dto.TrashReplies:
+		if err := s.trashRepo.PurgeReply(id); err != nil {
+			return apperror.NewInternal("failed to purge reply", err)
+		}
+		return nil
+	}
+
+	refs, err := s.imageRepo.FindReferencesByPostID(id)
+	if err != nil {
+		return apperror.NewInternal("failed to find post images", err)
+	}
+	if err := withTx(s.db, func(tx *gorm.DB) error {
+		if err := s.trashRepo.WithTx(tx).PurgePost(id); err != nil {
+			return apperror.NewInternal("failed to purge post", err)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	imageIDs := make([]uuid.UUID, 0, len(refs))
+	for _, ref := range refs {
+		imageIDs = append(imageIDs, ref.ImageID)
+	}
+	if _, err := s.storageManager.DeleteUnreferencedImages(ctx, imageIDs); err != nil {
+		log.Printf("posts: failed to delete images of purged post %s: %v", id, err)
+	}
+	s.invalidatePostListCaches()
+	return nil
+}
+
+// PurgeTrash hard-deletes posts, comments and replies that have been in the
+// trash longer than the retention period. Posts go first, taking their
+// comments and replies with them.
+func (s *InsightService) PurgeTrash() {
+	before := time.Now().Add(-trashRetention())
+	ctx := context.Background()
+
+	kinds := []struct {
+		itemType string
+		find     func(time.Time, int) ([]uuid.UUID, error)
+	}{
+		{dto.TrashPosts, s.trashRepo.FindExpiredPostIDs},
+		{dto.TrashComments, s.trashRepo.FindExpiredCommentIDs},
+		{dto.TrashReplies, s.trashRepo.FindExpiredReplyIDs},
+	}
+	for _, kind := range kinds {
+		purged := 0
+		for {
+			ids, err := kind.find(before, trashPurgeBatchSize)
+			if err != nil {
+				log.Printf("trash: failed to find expired %s: %v", kind.itemType, err)
+				break
+			}
+			failed := 0
+			for _, id := range ids {
+				if err := s.purgeTrashItem(ctx, kind.itemType, id); err != nil {
+					log.Printf("trash: failed to purge %s %s: %v", kind.itemType, id, err)
+					failed++
+					continue
+				}
+				purged++
+			}
+			// Stop on a short batch, or when nothing in it could be purged and
+			// the next query would only return the same rows again.
+			if len(ids) < trashPurgeBatchSize || failed == len(ids) {
+				break
+			}
+		}
+		if purged > 0 {
+			log.Printf("trash: purged %d expired %s", purged, kind.itemType)
+		}
+	}
+}
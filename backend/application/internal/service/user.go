@@ -2,11 +2,10 @@ package service
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
+	"log"
 	"mime/multipart"
 	"regexp"
-	"strings"
 	"time"
 
 	"github.com/pdhoang91/blog/constants"
@@ -15,15 +14,13 @@ import (
 	"github.com/pdhoang91/blog/internal/entities"
 	"github.com/pdhoang91/blog/pkg/storage"
 
-	jwtUtil "github.com/pdhoang91/blog/pkg/jwt"
 	uuid "github.com/satori/go.uuid"
 	"golang.org/x/crypto/bcrypt"
-	"golang.org/x/oauth2"
 	"gorm.io/gorm"
 )
 
 // Register creates a new user account
-func (s *InsightService) Register(req *dto.CreateUserRequest) (*dto.LoginResponse, error) {
+func (s *InsightService) Register(req *dto.CreateUserRequest, client *dto.ClientInfo) (*dto.LoginResponse, error) {
 	existing, err := s.userRepo.FindByEmail(req.Email)
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, apperror.NewInternal("failed to check email", err)
@@ -48,7 +45,7 @@ func (s *InsightService) Register(req *dto.CreateUserRequest) (*dto.LoginRespons
 	user := &entities.User{
 		ID: uuid.NewV4(), Name: req.Name, Email: req.Email,
 		Username: req.Username, Password: string(hashedPassword),
-		Role: constants.RoleUser, EmailVerified: false,
+		Role: constants.GetDefaultRole(), EmailVerified: false,
 		CreatedAt: time.Now(), UpdatedAt: time.Now(),
 	}
 
@@ -56,16 +53,16 @@ func (s *InsightService) Register(req *dto.CreateUserRequest) (*dto.LoginRespons
 		return nil, apperror.NewInternal("failed to create user", err)
 	}
 
-	jwtToken, err := jwtUtil.GenerateJWT(user)
-	if err != nil {
-		return nil, apperror.NewInternal("failed to generate token", err)
+	// A failed verification email must not fail the registration; the user can resend it.
+	if err := s.sendVerificationEmail(user); err != nil {
+		log.Printf("auth: verification email for user %s: %v", user.ID, err)
 	}
 
-	return &dto.LoginResponse{Token: jwtToken, User: dto.NewUserResponse(user)}, nil
+	return s.issueSession(user, client, false)
 }
 
 // Login authenticates a user
-func (s *InsightService) Login(req *dto.LoginRequest) (*dto.LoginResponse, error) {
+func (s *InsightService) Login(req *dto.LoginRequest, client *dto.ClientInfo) (*dto.LoginResponse, error) {
 	user, err := s.userRepo.FindByEmail(req.Email)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -74,92 +71,14 @@ func (s *InsightService) Login(req *dto.LoginRequest) (*dto.LoginResponse, error
 		return nil, apperror.NewInternal("failed to find user", err)
 	}
 
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
-		return nil, apperror.NewUnauthorized("invalid credentials")
-	}
-
-	jwtToken, err := jwtUtil.GenerateJWT(user)
-	if err != nil {
-		return nil, apperror.NewInternal("failed to generate token", err)
-	}
-
-	return &dto.LoginResponse{Token: jwtToken, User: dto.NewUserResponse(user)}, nil
-}
-
-// GoogleLogin initiates Google OAuth login
-func (s *InsightService) GoogleLogin() (string, error) {
-	if s.googleOauthConfig == nil {
-		return "", apperror.NewInternal("OAuth configuration not available", nil)
-	}
-	return s.googleOauthConfig.AuthCodeURL("state", oauth2.AccessTypeOffline), nil
-}
-
-// GoogleCallback handles Google OAuth callback
-func (s *InsightService) GoogleCallback(code string) (*dto.LoginResponse, error) {
-	if s.googleOauthConfig == nil {
-		return nil, apperror.NewInternal("OAuth configuration not available", nil)
-	}
-
-	token, err := s.googleOauthConfig.Exchange(context.Background(), code)
-	if err != nil {
-		return nil, apperror.NewInternal("failed to exchange token", err)
-	}
-
-	client := s.googleOauthConfig.Client(context.Background(), token)
-	resp, err := client.Get("https://www.googleapis.com/oauth2/v3/userinfo")
-	if err != nil {
-		return nil, apperror.NewInternal("failed to fetch user info", err)
-	}
-	defer resp.Body.Close()
-
-	var userInfo struct {
-		Email   string `json:"email"`
-		Name    string `json:"name"`
-		Picture string `json:"picture"`
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&userInfo); err != nil {
-		return nil, apperror.NewInternal("failed to decode user info", err)
-	}
-
-	user, err := s.userRepo.FindByEmail(userInfo.Email)
-	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-		return nil, apperror.NewInternal("failed to find user", err)
-	}
-
-	if errors.Is(err, gorm.ErrRecordNotFound) {
-		avatarURL := userInfo.Picture
-		if avatarURL == "" {
-			avatarURL = "https://www.w3schools.com/w3images/avatar2.png"
-		}
-		user = &entities.User{
-			ID:               uuid.NewV4(),
-			Email:            userInfo.Email,
-			Username:         "@" + strings.Split(userInfo.Email, "@")[0],
-			Name:             userInfo.Name,
-			AvatarURL:        avatarURL,
-			GooglePictureURL: userInfo.Picture,
-			Role:             constants.RoleUser,
-			EmailVerified:    true,
-			CreatedAt:        time.Now(),
-			UpdatedAt:        time.Now(),
-		}
-		if err := s.userRepo.Create(user); err != nil {
-			return nil, apperror.NewInternal("failed to create user", err)
-		}
-	} else if userInfo.Picture != "" && user.GooglePictureURL != userInfo.Picture {
-		// Keep Google picture URL up to date for existing users
-		user.GooglePictureURL = userInfo.Picture
-		if err := s.userRepo.Update(user); err != nil {
-			return nil, apperror.NewInternal("failed to update user", err)
-		}
+	if err := checkAccountLock(user); err != nil {
+		return nil, err
 	}
-
-	jwtToken, err := jwtUtil.GenerateJWT(user)
-	if err != nil {
-		return nil, apperror.NewInternal("failed to generate token", err)
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+		return nil, s.recordFailedLogin(user, apperror.NewUnauthorized("invalid credentials"))
 	}
 
-	return &dto.LoginResponse{Token: jwtToken, User: dto.NewUserResponse(user)}, nil
+	return s.completeLogin(user, client)
 }
 
 // GetUserByUsername gets a user by username
@@ -174,14 +93,6 @@ func (s *InsightService) GetUserByUsername(username string) (*entities.User, err
 	return user, nil
 }
 
-// Logout handles user logout
-func (s *InsightService) Logout() error { return nil }
-
-// RefreshToken handles token refresh
-func (s *InsightService) RefreshToken() error {
-	return apperror.NewBadRequest("not implemented")
-}
-
 // GetUser retrieves a user by ID
 func (s *InsightService) GetUser(id uuid.UUID) (*dto.UserResponse, error) {
 	user, err := s.userRepo.FindByID(id)
@@ -17,7 +17,12 @@ import (
 	"github.com/pdhoang91/blog/internal/repository"
 	"github.com/pdhoang91/blog/internal/service"
 	"github.com/pdhoang91/blog/pkg/cache"
+	pkgjwt "github.com/pdhoang91/blog/pkg/jwt"
+	"github.com/pdhoang91/blog/pkg/mailer"
+	"github.com/pdhoang91/blog/pkg/oauth"
+	"github.com/pdhoang91/blog/pkg/ratelimit"
 	"github.com/pdhoang91/blog/pkg/storage"
+	"github.com/pdhoang91/blog/pkg/unfurl"
 )
 
 func init() {
@@ -30,13 +35,28 @@ func init() {
 	gob.Register(&dto.TagResponse{})
 	gob.Register([]*dto.CategoryResponse{})
 	gob.Register(&dto.CategoryResponse{})
+	gob.Register(&dto.SeriesResponse{})
+	gob.Register(&dto.PostExport{})
+	gob.Register(&dto.EmbedResponse{})
+	gob.Register(&service.OAuthState{})
 	gob.Register(int64(0))
+	gob.Register(false)
 	gob.Register("")
 }
 
 func main() {
 	cfg := config.NewConfig()
 
+	if err := pkgjwt.Init(pkgjwt.Options{
+		Algorithm:   cfg.JWT_ALGORITHM,
+		Secret:      cfg.JWT_SECRET,
+		KeysDir:     cfg.JWT_KEYS_DIR,
+		ActiveKID:   cfg.JWT_ACTIVE_KID,
+		Development: cfg.IsDevelopment(),
+	}); err != nil {
+		log.Fatalf("Invalid JWT configuration: %v", err)
+	}
+
 	db, err := config.InitDBConnection(cfg)
 	if err != nil {
 		fmt.Println("Failed to connect to the database:", err)
@@ -45,6 +65,10 @@ func main() {
 	defer config.CloseDBConnection(db)
 
 	r := gin.New()
+	// Client IPs key rate limits, so only honour X-Forwarded-For from known proxies.
+	if err := r.SetTrustedProxies(cfg.TRUSTED_PROXIES); err != nil {
+		log.Fatalf("invalid TRUSTED_PROXIES: %v", err)
+	}
 	r.Use(gin.Logger())
 	r.Use(gin.Recovery())
 	ConfigureCORS(r)
@@ -56,6 +80,7 @@ func main() {
 
 	memCache := cache.New()
 	var appCache cache.Cache = memCache
+	var limiter ratelimit.Limiter = ratelimit.NewMemoryLimiter()
 	if redisURL := os.Getenv("REDIS_URL"); redisURL != "" {
 		redisCache := cache.NewRedisCache(redisURL, "insight")
 		if err := redisCache.Ping(); err != nil {
@@ -63,9 +88,20 @@ func main() {
 		} else {
 			log.Printf("Redis connected at %s — using two-tier cache", redisURL)
 			appCache = cache.NewTwoTierCache(memCache, redisCache)
+			limiter = ratelimit.NewRedisLimiter(redisCache.Client(), "insight:ratelimit")
 		}
 	}
 
+	appMailer := mailer.New(mailer.Config{
+		Driver:       cfg.MAILER,
+		From:         cfg.MAIL_FROM,
+		OutboxDir:    cfg.MAIL_OUTBOX_DIR,
+		SMTPHost:     cfg.SMTP_HOST,
+		SMTPPort:     cfg.SMTP_PORT,
+		SMTPUsername: cfg.SMTP_USERNAME,
+		SMTPPassword: cfg.SMTP_PASSWORD,
+	})
+
 	userRepo := repository.NewUserRepository(db)
 	postRepo := repository.NewPostRepository(db)
 	commentRepo := repository.NewCommentRepository(db)
@@ -75,19 +111,42 @@ func main() {
 	postContentRepo := repository.NewPostContentRepository(db)
 	imageRepo := repository.NewImageRepository(db)
 	searchRepo := repository.NewSearchRepository(db)
+	sessionRepo := repository.NewSessionRepository(db)
+	identityRepo := repository.NewIdentityRepository(db)
+	apiTokenRepo := repository.NewAPITokenRepository(db)
+	actionTokenRepo := repository.NewActionTokenRepository(db)
+	mfaRepo := repository.NewMFARepository(db)
+	revisionRepo := repository.NewPostRevisionRepository(db)
+	autosaveRepo := repository.NewPostAutosaveRepository(db)
+	postAuthorRepo := repository.NewPostAuthorRepository(db)
+	statusEventRepo := repository.NewPostStatusEventRepository(db)
+	seriesRepo := repository.NewSeriesRepository(db)
+	slugHistoryRepo := repository.NewPostSlugHistoryRepository(db)
+	trashRepo := repository.NewTrashRepository(db)
 
 	baseService := service.NewBaseService(
 		db,
 		appCache,
-		config.GoogleOauthConfig,
+		oauth.NewRegistry(cfg.OAUTH_PROVIDERS),
 		config.S3Client,
 		storageManager,
+		appMailer,
+		unfurl.New(unfurl.NewFetcher(10*time.Second)),
 		userRepo, postRepo, commentRepo, replyRepo,
 		categoryRepo, tagRepo,
 		postContentRepo, imageRepo,
+		sessionRepo, identityRepo, apiTokenRepo, actionTokenRepo,
+		mfaRepo, revisionRepo, autosaveRepo, postAuthorRepo, statusEventRepo,
+		seriesRepo, slugHistoryRepo, trashRepo,
 	)
 
 	insightService := service.NewInsightService(baseService, searchRepo)
+
+	if len(os.Args) > 1 {
+		runCommand(os.Args[1], insightService)
+		return
+	}
+
 	if err := insightService.InitializeIndexes(); err != nil {
 		log.Printf("Warning: search index initialisation: %v", err)
 	}
@@ -108,8 +167,26 @@ func main() {
 		}
 	}()
 
+	go func() {
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+		for range ticker.C {
+			insightService.PublishScheduledPosts()
+		}
+	}()
+
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for range ticker.C {
+			insightService.PurgeExpiredSessions()
+			insightService.PurgeExpiredActionTokens()
+			insightService.PurgeTrash()
+		}
+	}()
+
 	mainController := controller.NewController(insightService)
-	internal.DefineAPIRoutes(r, mainController)
+	internal.DefineAPIRoutes(r, mainController, insightService, insightService, limiter)
 
 	port := os.Getenv("PORT")
 	if port == "" {
@@ -122,11 +199,25 @@ func main() {
 	}
 }
 
+// runCommand runs a one-off maintenance task instead of the server, as in
+// `./management backfill-post-stats`.
+func runCommand(name string, svc *service.InsightService) {
+	switch name {
+	case "backfill-post-stats":
+		if _, err := svc.BackfillPostStats(); err != nil {
+			log.Fatalf("backfill-post-stats: %v", err)
+		}
+	default:
+		log.Fatalf("unknown command %q (available: backfill-post-stats)", name)
+	}
+}
+
 func ConfigureCORS(r *gin.Engine) {
 	config := cors.DefaultConfig()
 	config.AllowAllOrigins = true
 	config.AllowMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"}
-	config.AllowHeaders = []string{"Origin", "Content-Length", "Content-Type", "Authorization", "X-Requested-With"}
+	config.AllowHeaders = []string{"Origin", "Content-Length", "Content-Type", "Authorization", "X-Requested-With", "If-Match"}
+	config.ExposeHeaders = []string{"ETag"}
 	config.AllowCredentials = true
 	r.Use(cors.New(config))
 }
@@ -78,3 +78,9 @@ func (r *RedisCache) DeletePrefix(prefix string) {
 func (r *RedisCache) Ping() error {
 	return r.client.Ping(context.Background()).Err()
 }
+
+// Client exposes the underlying connection for features that need Redis
+// primitives beyond key/value caching, such as rate limiting.
+func (r *RedisCache) Client() *redis.Client {
+	return r.client
+}
@@ -0,0 +1,482 @@
+// Package highlight splits source code into tokens for syntax highlighting,
+// so code in posts is colored without a highlighter in the browser. Lexing is
+// approximate, as editors do it: keywords, strings, comments, numbers and the
+// like, without parsing.
+package highlight
+
+import (
+	"html"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Kind is what a token is. The names are highlight.js scopes, which the
+// editor's highlighter uses too, so one stylesheet colors both.
+type Kind string
+
+const (
+	Plain    Kind = ""
+	Keyword  Kind = "keyword"
+	Literal  Kind = "literal" // true, nil and the like
+	BuiltIn  Kind = "built_in"
+	Type     Kind = "type"
+	String   Kind = "string"
+	Number   Kind = "number"
+	Comment  Kind = "comment"
+	Function Kind = "title" // the name of a function where it is called or declared
+	Meta     Kind = "meta"  // preprocessor lines, decorators, annotations
+	Tag      Kind = "name"  // markup tag names
+	Attr     Kind = "attr"  // markup attributes and object keys
+	Variable Kind = "variable"
+	Addition Kind = "addition"
+	Deletion Kind = "deletion"
+)
+
+// Token is a run of code of one kind. Joined, a code block's tokens are its
+// code.
+type Token struct {
+	Kind Kind   `json:"kind,omitempty"`
+	Text string `json:"text"`
+}
+
+// Supported reports whether code in language can be highlighted. Names are
+// those the editor offers, and common aliases.
+func Supported(language string) bool {
+	_, ok := languages[strings.ToLower(language)]
+	return ok
+}
+
+// MaxCodeLength is the longest code Tokenize highlights. Longer blocks are
+// left plain: they are rarely read closely, and would cost every render.
+const MaxCodeLength = 64 << 10
+
+// Tokenize splits code into tokens, or returns nil for a language it does not
+// know and for code longer than MaxCodeLength.
+func Tokenize(language, code string) []Token {
+	lang, ok := languages[strings.ToLower(language)]
+	if !ok || len(code) > MaxCodeLength {
+		return nil
+	}
+	return tokenize(lang, code)
+}
+
+func tokenize(lang *language, code string) []Token {
+	l := &lexer{src: code}
+	switch {
+	case lang.markup:
+		l.markup()
+	case lang.diff:
+		l.diff()
+	default:
+		l.code(lang)
+	}
+	return l.tokens
+}
+
+// theme is the palette HTML colors tokens with, GitHub's light one.
+var theme = map[Kind]string{
+	Keyword:  "color:#d73a49",
+	Literal:  "color:#005cc5",
+	BuiltIn:  "color:#005cc5",
+	Type:     "color:#6f42c1",
+	String:   "color:#032f62",
+	Number:   "color:#005cc5",
+	Comment:  "color:#6a737d;font-style:italic",
+	Function: "color:#6f42c1",
+	Meta:     "color:#005cc5",
+	Tag:      "color:#22863a",
+	Attr:     "color:#005cc5",
+	Variable: "color:#e36209",
+	Addition: "color:#22863a;background-color:#f0fff4",
+	Deletion: "color:#b31d28;background-color:#ffeef0",
+}
+
+// HTML renders tokens as escaped text with spans colored by inline styles,
+// for HTML read without the site's stylesheet.
+func HTML(tokens []Token) string {
+	var b strings.Builder
+	for _, t := range tokens {
+		if style, ok := theme[t.Kind]; ok {
+			b.WriteString("<span style=\"" + style + "\">" + html.EscapeString(t.Text) + "</span>")
+		} else {
+			b.WriteString(html.EscapeString(t.Text))
+		}
+	}
+	return b.String()
+}
+
+type lexer struct {
+	src    string
+	pos    int
+	tokens []Token
+	start  int // where the last token starts in src; it ends at pos
+}
+
+// emit adds the next n bytes as a token, joining it to the last one when
+// they are of the same kind. Tokens are slices of src, so joining them
+// copies nothing.
+func (l *lexer) emit(kind Kind, n int) {
+	if n <= 0 {
+		return
+	}
+	l.pos += n
+	if last := len(l.tokens) - 1; last >= 0 && l.tokens[last].Kind == kind {
+		l.tokens[last].Text = l.src[l.start:l.pos]
+		return
+	}
+	l.start = l.pos - n
+	l.tokens = append(l.tokens, Token{Kind: kind, Text: l.src[l.start:l.pos]})
+}
+
+func (l *lexer) rest() string {
+	return l.src[l.pos:]
+}
+
+// atLineStart reports whether only spaces precede the position on its line.
+func (l *lexer) atLineStart() bool {
+	for i := l.pos - 1; i >= 0; i-- {
+		switch l.src[i] {
+		case '\n':
+			return true
+		case ' ', '\t':
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// lineLength is the number of bytes from the position to the end of its line.
+func (l *lexer) lineLength() int {
+	if i := strings.IndexByte(l.rest(), '\n'); i >= 0 {
+		return i
+	}
+	return len(l.rest())
+}
+
+// code lexes programming and data languages.
+func (l *lexer) code(lang *language) {
+	for l.pos < len(l.src) {
+		rest := l.rest()
+		c := rest[0]
+
+		if kind, n := l.comment(lang); n > 0 {
+			l.emit(kind, n)
+			continue
+		}
+		if lang.preprocessor && c == '#' && l.atLineStart() {
+			l.emit(Meta, l.lineLength())
+			continue
+		}
+		if n := stringLength(lang, rest); n > 0 {
+			l.emit(l.keyKind(lang, String, n), n)
+			continue
+		}
+		if isDigit(c) || (c == '.' && len(rest) > 1 && isDigit(rest[1]) && !lang.isWordByte('.')) {
+			l.emit(Number, numberLength(rest))
+			continue
+		}
+		if c == '@' && lang.decorators {
+			if n := wordLength(lang, rest[1:]); n > 0 {
+				l.emit(Meta, n+1)
+				continue
+			}
+		}
+		if c == '$' && lang.variables {
+			if n := variableLength(lang, rest); n > 0 {
+				l.emit(Variable, n)
+				continue
+			}
+		}
+		if n := wordLength(lang, rest); n > 0 {
+			l.emit(l.keyKind(lang, l.wordKind(lang, rest[:n], rest[n:]), n), n)
+			continue
+		}
+		_, size := utf8.DecodeRuneInString(rest)
+		l.emit(Plain, size)
+	}
+}
+
+// comment returns the length of the comment at the position, and whether it
+// is a comment or, like a shebang, a directive.
+func (l *lexer) comment(lang *language) (Kind, int) {
+	rest := l.rest()
+	if l.pos == 0 && strings.HasPrefix(rest, "#!") {
+		return Meta, l.lineLength()
+	}
+	for _, pair := range lang.blockComments {
+		if strings.HasPrefix(rest, pair[0]) {
+			if end := strings.Index(rest[len(pair[0]):], pair[1]); end >= 0 {
+				return Comment, len(pair[0]) + end + len(pair[1])
+			}
+			return Comment, len(rest)
+		}
+	}
+	for _, prefix := range lang.lineComments {
+		if !strings.HasPrefix(rest, prefix) {
+			continue
+		}
+		// In shells and YAML a # only starts a comment at the start of a word.
+		if prefix == "#" && l.pos > 0 && !isSpace(l.src[l.pos-1]) {
+			continue
+		}
+		return Comment, l.lineLength()
+	}
+	return Plain, 0
+}
+
+// keyKind is Attr in place of kind for a word or string of length n that is
+// followed by a colon, in languages with keys.
+func (l *lexer) keyKind(lang *language, kind Kind, n int) Kind {
+	if !lang.keys || kind == Keyword || kind == Literal {
+		return kind
+	}
+	after := strings.TrimLeft(l.src[l.pos+n:], " \t")
+	if strings.HasPrefix(after, ":") && !strings.HasPrefix(after, "::") {
+		return Attr
+	}
+	return kind
+}
+
+func (l *lexer) wordKind(lang *language, word, after string) Kind {
+	key := word
+	if lang.caseInsensitive {
+		key = strings.ToLower(word)
+	}
+	switch {
+	case lang.keywords[key]:
+		return Keyword
+	case lang.literals[key]:
+		return Literal
+	case lang.types[key]:
+		return Type
+	case lang.builtIns[key]:
+		return BuiltIn
+	case strings.HasPrefix(strings.TrimLeft(after, " \t"), "(") && !lang.keys:
+		return Function
+	case lang.capitalTypes && isTypeName(word):
+		return Type
+	}
+	return Plain
+}
+
+// stringLength returns the length of the string literal starting rest, 0 if
+// none does. Strings left open run to the end of their line, or of the code
+// for those that may span lines.
+func stringLength(lang *language, rest string) int {
+	for _, d := range lang.strings {
+		if !strings.HasPrefix(rest, d.open) {
+			continue
+		}
+		if d.char {
+			if n := charLength(d, rest); n > 0 {
+				return n
+			}
+			continue
+		}
+		i := len(d.open)
+		for i < len(rest) {
+			switch {
+			case d.escapes && rest[i] == '\\' && i+1 < len(rest):
+				i += 2
+				continue
+			case strings.HasPrefix(rest[i:], d.close):
+				return i + len(d.close)
+			case rest[i] == '\n' && !d.multiline:
+				return i
+			}
+			i++
+		}
+		return len(rest)
+	}
+	return 0
+}
+
+// maxEscapeLength bounds escape sequences in character literals, long
+// enough for \u{10FFFF}.
+const maxEscapeLength = 10
+
+// charLength returns the length of the character literal starting rest, 0
+// if the quote does not start one.
+func charLength(d delimiter, rest string) int {
+	body := rest[len(d.open):]
+	n := 0
+	if d.escapes && strings.HasPrefix(body, "\\") {
+		n = strings.Index(body, d.close)
+		if n < 2 || n > maxEscapeLength {
+			return 0
+		}
+	} else {
+		_, n = utf8.DecodeRuneInString(body)
+		if n == 0 || body[0] == '\n' || !strings.HasPrefix(body[n:], d.close) {
+			return 0
+		}
+	}
+	return len(d.open) + n + len(d.close)
+}
+
+// numberLength returns the length of the number starting rest: digits,
+// prefixes and suffixes, separators, a fraction and an exponent.
+func numberLength(rest string) int {
+	hex := strings.HasPrefix(rest, "0x") || strings.HasPrefix(rest, "0X")
+	i := 0
+	for i < len(rest) {
+		c := rest[i]
+		switch {
+		case isDigit(c) || isLetter(c) || c == '_':
+		case c == '.' && i+1 < len(rest) && isDigit(rest[i+1]):
+		case (c == '+' || c == '-') && !hex && i > 0 && (rest[i-1] == 'e' || rest[i-1] == 'E'):
+		default:
+			return i
+		}
+		i++
+	}
+	return i
+}
+
+// wordLength returns the length of the identifier starting rest.
+func wordLength(lang *language, rest string) int {
+	i := 0
+	for i < len(rest) {
+		r, size := utf8.DecodeRuneInString(rest[i:])
+		switch {
+		case r == '_' || unicode.IsLetter(r) || (r < utf8.RuneSelf && lang.isWordByte(byte(r)) && i > 0):
+		case unicode.IsDigit(r) && i > 0:
+		default:
+			return i
+		}
+		i += size
+	}
+	return i
+}
+
+// maxVariableLength bounds ${...} variables, so that a line of unclosed
+// ones is not searched to its end from each.
+const maxVariableLength = 256
+
+// variableLength returns the length of a $name or ${...} variable.
+func variableLength(lang *language, rest string) int {
+	if strings.HasPrefix(rest, "${") {
+		if len(rest) > maxVariableLength {
+			rest = rest[:maxVariableLength]
+		}
+		if end := strings.IndexAny(rest, "}\n"); end > 0 && rest[end] == '}' {
+			return end + 1
+		}
+		return 0
+	}
+	if n := wordLength(lang, rest[1:]); n > 0 {
+		return n + 1
+	}
+	return 0
+}
+
+// isTypeName reports whether word is capitalized the way type names are,
+// rather than in capitals as constants are.
+func isTypeName(word string) bool {
+	r, _ := utf8.DecodeRuneInString(word)
+	return unicode.IsUpper(r) && strings.IndexFunc(word, unicode.IsLower) >= 0
+}
+
+// markup lexes HTML and XML.
+func (l *lexer) markup() {
+	for l.pos < len(l.src) {
+		rest := l.rest()
+		switch {
+		case strings.HasPrefix(rest, "<!--"):
+			n := len(rest)
+			if end := strings.Index(rest, "-->"); end >= 0 {
+				n = end + 3
+			}
+			l.emit(Comment, n)
+		case strings.HasPrefix(rest, "<!"), strings.HasPrefix(rest, "<?"):
+			n := len(rest)
+			if end := strings.IndexByte(rest, '>'); end >= 0 {
+				n = end + 1
+			}
+			l.emit(Meta, n)
+		case rest[0] == '<' && len(rest) > 1 && (isLetter(rest[1]) || rest[1] == '/'):
+			l.tag()
+		default:
+			n := strings.IndexByte(rest[1:], '<') + 1
+			if n == 0 {
+				n = len(rest)
+			}
+			l.emit(Plain, n)
+		}
+	}
+}
+
+// tag lexes a start or end tag: its name, attributes and their values.
+func (l *lexer) tag() {
+	n := 1
+	if l.rest()[1] == '/' {
+		n = 2
+	}
+	l.emit(Plain, n)
+	l.emit(Tag, markupNameLength(l.rest()))
+	for l.pos < len(l.src) {
+		rest := l.rest()
+		switch c := rest[0]; {
+		case c == '>':
+			l.emit(Plain, 1)
+			return
+		case c == '"' || c == '\'':
+			n := len(rest)
+			if end := strings.IndexByte(rest[1:], c); end >= 0 {
+				n = end + 2
+			}
+			l.emit(String, n)
+		case isLetter(c) || c == '_' || c == ':' || c == '@':
+			l.emit(Attr, markupNameLength(rest))
+		default:
+			l.emit(Plain, 1)
+		}
+	}
+}
+
+func markupNameLength(rest string) int {
+	i := 0
+	for i < len(rest) {
+		c := rest[i]
+		if !(isLetter(c) || isDigit(c) || c == '-' || c == '_' || c == ':' || c == '.' || c == '@') {
+			break
+		}
+		i++
+	}
+	return i
+}
+
+// diff lexes unified diffs line by line.
+func (l *lexer) diff() {
+	for l.pos < len(l.src) {
+		n := l.lineLength()
+		if n < len(l.rest()) {
+			n++ // the newline
+		}
+		line := l.rest()[:n]
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"), strings.HasPrefix(line, "@@"):
+			l.emit(Meta, n)
+		case strings.HasPrefix(line, "+"):
+			l.emit(Addition, n)
+		case strings.HasPrefix(line, "-"):
+			l.emit(Deletion, n)
+		default:
+			l.emit(Plain, n)
+		}
+	}
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func isLetter(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
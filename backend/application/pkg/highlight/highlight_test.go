@@ -0,0 +1,197 @@
+package highlight
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		language string
+		code     string
+		want     []Token
+	}{
+		{
+			language: "go",
+			code:     "func main() { x := \"a\\\"b\" // c\n\treturn 0x1F, 'x', nil }",
+			want: []Token{
+				{Keyword, "func"}, {Plain, " "}, {Function, "main"}, {Plain, "() { x := "},
+				{String, `"a\"b"`}, {Plain, " "}, {Comment, "// c"}, {Plain, "\n\t"},
+				{Keyword, "return"}, {Plain, " "}, {Number, "0x1F"}, {Plain, ", "},
+				{String, "'x'"}, {Plain, ", "}, {Literal, "nil"}, {Plain, " }"},
+			},
+		},
+		{
+			language: "JS",
+			code:     "@dec class Foo { bar(`t`) /* c */ }",
+			want: []Token{
+				{Meta, "@dec"}, {Plain, " "}, {Keyword, "class"}, {Plain, " "}, {Type, "Foo"},
+				{Plain, " { "}, {Function, "bar"}, {Plain, "("}, {String, "`t`"}, {Plain, ") "},
+				{Comment, "/* c */"}, {Plain, " }"},
+			},
+		},
+		{
+			language: "python",
+			code:     "def f(x): return '''doc''' # c",
+			want: []Token{
+				{Keyword, "def"}, {Plain, " "}, {Function, "f"}, {Plain, "(x): "},
+				{Keyword, "return"}, {Plain, " "}, {String, "'''doc'''"}, {Plain, " "}, {Comment, "# c"},
+			},
+		},
+		{
+			language: "c",
+			code:     "#include <a.h>\nint main() { return '\\n'; }",
+			want: []Token{
+				{Meta, "#include <a.h>"}, {Plain, "\n"}, {Type, "int"}, {Plain, " "}, {Function, "main"},
+				{Plain, "() { "}, {Keyword, "return"}, {Plain, " "}, {String, `'\n'`}, {Plain, "; }"},
+			},
+		},
+		{
+			language: "rust",
+			code:     "fn f<'a>(x: &'a str) { 'c' }",
+			want: []Token{
+				{Keyword, "fn"}, {Plain, " f<'a>(x: &'a "}, {Type, "str"}, {Plain, ") { "},
+				{String, "'c'"}, {Plain, " }"},
+			},
+		},
+		{
+			language: "sh",
+			code:     "echo ${HOME} $USER # c\nls a#b",
+			want: []Token{
+				{BuiltIn, "echo"}, {Plain, " "}, {Variable, "${HOME}"}, {Plain, " "}, {Variable, "$USER"},
+				{Plain, " "}, {Comment, "# c"}, {Plain, "\nls a#b"},
+			},
+		},
+		{
+			language: "sql",
+			code:     "SELECT count(*) FROM t WHERE a = 'x' -- c",
+			want: []Token{
+				{Keyword, "SELECT"}, {Plain, " "}, {BuiltIn, "count"}, {Plain, "(*) "}, {Keyword, "FROM"},
+				{Plain, " t "}, {Keyword, "WHERE"}, {Plain, " a = "}, {String, "'x'"}, {Plain, " "},
+				{Comment, "-- c"},
+			},
+		},
+		{
+			language: "json",
+			code:     `{"a": [1.5e-3, true, "s"]}`,
+			want: []Token{
+				{Plain, "{"}, {Attr, `"a"`}, {Plain, ": ["}, {Number, "1.5e-3"}, {Plain, ", "},
+				{Literal, "true"}, {Plain, ", "}, {String, `"s"`}, {Plain, "]}"},
+			},
+		},
+		{
+			language: "yaml",
+			code:     "key: 'v' # c\nother-key: yes",
+			want: []Token{
+				{Attr, "key"}, {Plain, ": "}, {String, "'v'"}, {Plain, " "}, {Comment, "# c"}, {Plain, "\n"},
+				{Attr, "other-key"}, {Plain, ": "}, {Literal, "yes"},
+			},
+		},
+		{
+			language: "html",
+			code:     `<!-- c --><a href="x" disabled>t</a><!DOCTYPE html>`,
+			want: []Token{
+				{Comment, "<!-- c -->"}, {Plain, "<"}, {Tag, "a"}, {Plain, " "}, {Attr, "href"}, {Plain, "="},
+				{String, `"x"`}, {Plain, " "}, {Attr, "disabled"}, {Plain, ">t</"}, {Tag, "a"}, {Plain, ">"},
+				{Meta, "<!DOCTYPE html>"},
+			},
+		},
+		{
+			language: "diff",
+			code:     "--- a\n+++ b\n@@ -1 +1 @@\n-old\n+new\n same",
+			want: []Token{
+				{Meta, "--- a\n+++ b\n@@ -1 +1 @@\n"}, {Deletion, "-old\n"}, {Addition, "+new\n"}, {Plain, " same"},
+			},
+		},
+		{
+			language: "go",
+			code:     "x := `unclosed\nraw",
+			want:     []Token{{Plain, "x := "}, {String, "`unclosed\nraw"}},
+		},
+		{
+			language: "go",
+			code:     "",
+			want:     nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.language, func(t *testing.T) {
+			got := Tokenize(tt.language, tt.code)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Tokenize(%q, %q) =\n%v\nwant\n%v", tt.language, tt.code, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenizeJoinsToCode(t *testing.T) {
+	code := "package main\n\n/* unclosed comment \"é\" 'x ${y `z"
+	for name := range languages {
+		tokens := Tokenize(name, code)
+		var b strings.Builder
+		for i, token := range tokens {
+			if token.Text == "" {
+				t.Errorf("%s: token %d is empty", name, i)
+			}
+			if i > 0 && tokens[i-1].Kind == token.Kind {
+				t.Errorf("%s: tokens %d and %d are both %q", name, i-1, i, token.Kind)
+			}
+			b.WriteString(token.Text)
+		}
+		if b.String() != code {
+			t.Errorf("%s: tokens join to %q", name, b.String())
+		}
+	}
+}
+
+func TestTokenizeSkips(t *testing.T) {
+	if tokens := Tokenize("brainfuck", "+[-]"); tokens != nil {
+		t.Errorf("an unknown language gave %v", tokens)
+	}
+	if tokens := Tokenize("go", strings.Repeat("x", MaxCodeLength+1)); tokens != nil {
+		t.Errorf("code longer than MaxCodeLength gave %d tokens", len(tokens))
+	}
+	if tokens := Tokenize("go", strings.Repeat("x ", MaxCodeLength/2)); tokens == nil {
+		t.Error("code of MaxCodeLength was not highlighted")
+	}
+}
+
+// TestTokenizeLinearTime feeds code that takes quadratic time to lex when
+// tokens are joined by copying or delimiters are searched for from each
+// position. The inputs are longer than MaxCodeLength, so that quadratic
+// lexing shows.
+func TestTokenizeLinearTime(t *testing.T) {
+	const n = 1 << 20
+	tests := []struct {
+		name     string
+		language string
+		code     string
+	}{
+		{name: "one plain token", language: "go", code: strings.Repeat("(", n)},
+		{name: "many tokens", language: "go", code: strings.Repeat("x ", n/2)},
+		{name: "unclosed variables", language: "sh", code: strings.Repeat("${", n/2)},
+		{name: "hashes", language: "c", code: strings.Repeat(" #", n/2)},
+		{name: "unclosed escapes", language: "rust", code: strings.Repeat(`'\`, n/2)},
+		{name: "unclosed tags", language: "html", code: strings.Repeat("<a ", n/3)},
+		{name: "diff lines", language: "diff", code: strings.Repeat("+\n", n/2)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Now()
+			tokenize(languages[tt.language], tt.code)
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("lexing took %v", elapsed)
+			}
+		})
+	}
+}
+
+func TestHTML(t *testing.T) {
+	got := HTML([]Token{{Keyword, "if"}, {Plain, " a < b "}, {String, `"&"`}})
+	want := `<span style="color:#d73a49">if</span> a &lt; b <span style="color:#032f62">&#34;&amp;&#34;</span>`
+	if got != want {
+		t.Errorf("HTML = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,274 @@
+package highlight
+
+import "strings"
+
+// language describes a language's lexical syntax, enough to tell its tokens
+// apart.
+type language struct {
+	keywords, literals, types, builtIns map[string]bool
+	caseInsensitive                     bool
+
+	lineComments  []string
+	blockComments [][2]string
+	strings       []delimiter
+
+	wordBytes    string // besides letters, digits and _, within identifiers
+	preprocessor bool   // # at the start of a line begins a directive
+	decorators   bool   // @name is a decorator or annotation
+	variables    bool   // $name is a variable
+	keys         bool   // a word or string followed by a colon is a key
+	capitalTypes bool   // Capitalized identifiers name types
+
+	markup bool
+	diff   bool
+}
+
+// delimiter is a kind of string literal. Longer openings must come before
+// their prefixes.
+type delimiter struct {
+	open, close string
+	escapes     bool // a backslash escapes the next character
+	multiline   bool
+	// char limits the literal to one character or escape sequence, telling
+	// character literals from other uses of their quote.
+	char bool
+}
+
+func (lang *language) isWordByte(c byte) bool {
+	return strings.IndexByte(lang.wordBytes, c) >= 0
+}
+
+func words(s string) map[string]bool {
+	m := make(map[string]bool)
+	for _, w := range strings.Fields(s) {
+		m[w] = true
+	}
+	return m
+}
+
+var (
+	quoted       = delimiter{open: `"`, close: `"`, escapes: true}
+	singleQuoted = delimiter{open: `'`, close: `'`, escapes: true}
+	backquoted   = delimiter{open: "`", close: "`", escapes: true, multiline: true}
+	charLiteral  = delimiter{open: `'`, close: `'`, escapes: true, char: true}
+
+	cComments = [][2]string{{"/*", "*/"}}
+)
+
+var (
+	golang = &language{
+		keywords:      words("break case chan const continue default defer else fallthrough for func go goto if import interface map package range return select struct switch type var"),
+		literals:      words("true false nil iota"),
+		types:         words("bool byte complex64 complex128 error float32 float64 int int8 int16 int32 int64 rune string uint uint8 uint16 uint32 uint64 uintptr any comparable"),
+		builtIns:      words("append cap clear close complex copy delete imag len make max min new panic print println real recover"),
+		lineComments:  []string{"//"},
+		blockComments: cComments,
+		strings:       []delimiter{quoted, {open: "`", close: "`", multiline: true}, charLiteral},
+	}
+
+	javascript = &language{
+		keywords:      words("async await break case catch class const continue debugger default delete do else export extends finally for from function get if import in instanceof let new of return set static super switch this throw try typeof var void while with yield"),
+		literals:      words("true false null undefined NaN Infinity"),
+		builtIns:      words("Array Boolean Date Error JSON Map Math Number Object Promise Reflect RegExp Set String Symbol WeakMap WeakSet console document globalThis process require module window"),
+		lineComments:  []string{"//"},
+		blockComments: cComments,
+		strings:       []delimiter{quoted, singleQuoted, backquoted},
+		decorators:    true,
+		capitalTypes:  true,
+	}
+
+	typescript = &language{
+		keywords:      words("abstract as async await break case catch class const constructor continue debugger declare default delete do else enum export extends finally for from function get if implements import in infer instanceof interface is keyof let module namespace new of private protected public readonly return satisfies set static super switch this throw try type typeof var void while with yield"),
+		literals:      javascript.literals,
+		types:         words("any bigint boolean never number object string symbol unknown void"),
+		builtIns:      javascript.builtIns,
+		lineComments:  []string{"//"},
+		blockComments: cComments,
+		strings:       []delimiter{quoted, singleQuoted, backquoted},
+		decorators:    true,
+		capitalTypes:  true,
+	}
+
+	python = &language{
+		keywords:     words("and as assert async await break class continue def del elif else except finally for from global if import in is lambda match nonlocal not or pass raise return try while with yield"),
+		literals:     words("True False None"),
+		builtIns:     words("abs all any bool bytes callable chr dict dir enumerate filter float format frozenset getattr hasattr hash help hex id input int isinstance issubclass iter len list map max min next object open ord pow print property range repr reversed round self set setattr slice sorted staticmethod str sum super tuple type zip"),
+		lineComments: []string{"#"},
+		strings: []delimiter{
+			{open: `"""`, close: `"""`, escapes: true, multiline: true},
+			{open: `'''`, close: `'''`, escapes: true, multiline: true},
+			quoted, singleQuoted,
+		},
+		decorators:   true,
+		capitalTypes: true,
+	}
+
+	java = &language{
+		keywords:      words("abstract assert break case catch class continue default do else enum extends final finally for if implements import instanceof interface native new package permits private protected public record return sealed static strictfp super switch synchronized this throw throws transient try var void volatile while yield"),
+		literals:      words("true false null"),
+		types:         words("boolean byte char double float int long short"),
+		lineComments:  []string{"//"},
+		blockComments: cComments,
+		strings:       []delimiter{{open: `"""`, close: `"""`, escapes: true, multiline: true}, quoted, charLiteral},
+		decorators:    true,
+		capitalTypes:  true,
+	}
+
+	kotlin = &language{
+		keywords:      words("abstract as break by catch class companion const constructor continue data do else enum external final finally for fun get if import in infix init inline inner interface internal is lateinit object open operator out override package private protected public reified return sealed set super suspend this throw try typealias val var vararg when where while"),
+		literals:      words("true false null"),
+		types:         words("Any Boolean Byte Char Double Float Int Long Nothing Short String Unit"),
+		lineComments:  []string{"//"},
+		blockComments: cComments,
+		strings:       []delimiter{{open: `"""`, close: `"""`, multiline: true}, quoted, charLiteral},
+		decorators:    true,
+		capitalTypes:  true,
+	}
+
+	swift = &language{
+		keywords:      words("actor as associatedtype async await break case catch class continue default defer deinit do else enum extension fallthrough fileprivate for func guard if import in init inout internal is let mutating open operator private protocol public repeat rethrows return self Self some static struct subscript super switch throw throws try typealias var where while"),
+		literals:      words("true false nil"),
+		types:         words("Any Bool Character Double Float Int String Void"),
+		lineComments:  []string{"//"},
+		blockComments: cComments,
+		strings:       []delimiter{{open: `"""`, close: `"""`, escapes: true, multiline: true}, quoted},
+		decorators:    true,
+		capitalTypes:  true,
+	}
+
+	c = &language{
+		keywords:      words("break case const continue default do else enum extern for goto if inline register restrict return sizeof static struct switch typedef union volatile while"),
+		literals:      words("true false NULL"),
+		types:         words("bool char double float int long short signed unsigned void size_t int8_t int16_t int32_t int64_t uint8_t uint16_t uint32_t uint64_t"),
+		lineComments:  []string{"//"},
+		blockComments: cComments,
+		strings:       []delimiter{quoted, charLiteral},
+		preprocessor:  true,
+	}
+
+	cpp = &language{
+		keywords:      words("alignas alignof auto break case catch class concept const consteval constexpr continue co_await co_return co_yield decltype default delete do else enum explicit export extern final for friend goto if inline mutable namespace new noexcept operator override private protected public register requires return sizeof static static_assert static_cast dynamic_cast reinterpret_cast const_cast struct switch template this throw try typedef typeid typename union using virtual volatile while"),
+		literals:      words("true false nullptr NULL"),
+		types:         words("bool char char8_t char16_t char32_t double float int long short signed unsigned void wchar_t size_t"),
+		builtIns:      words("std string vector map set unique_ptr shared_ptr cout cin endl"),
+		lineComments:  []string{"//"},
+		blockComments: cComments,
+		strings:       []delimiter{quoted, charLiteral},
+		preprocessor:  true,
+	}
+
+	csharp = &language{
+		keywords:      words("abstract as async await base break case catch checked class const continue default delegate do else enum event explicit extern finally fixed for foreach get goto if implicit in init interface internal is lock namespace new operator out override params private protected public readonly record ref return sealed set sizeof stackalloc static struct switch this throw try typeof unchecked unsafe using var virtual void volatile when where while yield"),
+		literals:      words("true false null"),
+		types:         words("bool byte char decimal double dynamic float int long object sbyte short string uint ulong ushort"),
+		lineComments:  []string{"//"},
+		blockComments: cComments,
+		strings:       []delimiter{{open: `@"`, close: `"`, multiline: true}, quoted, charLiteral},
+		preprocessor:  true,
+		capitalTypes:  true,
+	}
+
+	rust = &language{
+		keywords:      words("as async await break const continue crate dyn else enum extern fn for if impl in let loop match mod move mut pub ref return self Self static struct super trait type unsafe use where while"),
+		literals:      words("true false None Some Ok Err"),
+		types:         words("bool char f32 f64 i8 i16 i32 i64 i128 isize str u8 u16 u32 u64 u128 usize String Vec Option Result Box"),
+		lineComments:  []string{"//"},
+		blockComments: cComments,
+		// A quote that does not close soon after is a lifetime.
+		strings:      []delimiter{quoted, charLiteral},
+		capitalTypes: true,
+	}
+
+	php = &language{
+		keywords:      words("abstract and as break case catch class clone const continue declare default do echo else elseif empty enum extends final finally fn for foreach function global if implements include include_once instanceof insteadof interface isset list match namespace new or print private protected public readonly require require_once return static switch throw trait try unset use var while yield"),
+		literals:      words("true false null TRUE FALSE NULL"),
+		lineComments:  []string{"//", "#"},
+		blockComments: cComments,
+		strings:       []delimiter{quoted, singleQuoted},
+		variables:     true,
+		capitalTypes:  true,
+	}
+
+	ruby = &language{
+		keywords:      words("alias and begin break case class def defined? do else elsif end ensure for if in module next not or redo rescue retry return self super then undef unless until when while yield require attr_accessor attr_reader attr_writer private protected public"),
+		literals:      words("true false nil"),
+		lineComments:  []string{"#"},
+		blockComments: [][2]string{{"=begin", "=end"}},
+		strings:       []delimiter{quoted, singleQuoted},
+		wordBytes:     "?!",
+		capitalTypes:  true,
+	}
+
+	shell = &language{
+		keywords:     words("case do done elif else esac fi for function if in select then until while"),
+		builtIns:     words("alias cd declare echo eval exec exit export local printf pwd read readonly return set shift source test trap unset"),
+		lineComments: []string{"#"},
+		strings:      []delimiter{quoted, {open: `'`, close: `'`, multiline: true}},
+		wordBytes:    "-",
+		variables:    true,
+	}
+
+	sql = &language{
+		keywords:        words("add alter and as asc begin between by case cascade check column commit constraint create cross database default delete desc distinct drop else end exists foreign from full group having if in index inner insert into is join key left like limit not offset on or order outer primary references returning right rollback select set table then transaction union unique update using values view when where with"),
+		literals:        words("true false null"),
+		types:           words("bigint boolean bytea char date decimal double float int integer json jsonb numeric real serial smallint text time timestamp timestamptz uuid varchar"),
+		builtIns:        words("avg coalesce count max min now sum"),
+		caseInsensitive: true,
+		lineComments:    []string{"--"},
+		blockComments:   cComments,
+		strings:         []delimiter{{open: `'`, close: `'`, multiline: true}},
+	}
+
+	jsonLang = &language{
+		literals: words("true false null"),
+		strings:  []delimiter{quoted},
+		keys:     true,
+	}
+
+	yaml = &language{
+		literals:     words("true false null yes no on off True False Null"),
+		lineComments: []string{"#"},
+		strings:      []delimiter{quoted, {open: `'`, close: `'`}},
+		wordBytes:    "-.",
+		keys:         true,
+	}
+
+	css = &language{
+		keywords:      words("important"),
+		lineComments:  []string{"//"},
+		blockComments: cComments,
+		strings:       []delimiter{quoted, singleQuoted},
+		wordBytes:     "-",
+		decorators:    true,
+		variables:     true,
+		keys:          true,
+	}
+
+	markup = &language{markup: true}
+	diff   = &language{diff: true}
+)
+
+// languages maps the names code blocks give their language, in lower case,
+// to the language.
+var languages = map[string]*language{
+	"go": golang, "golang": golang,
+	"javascript": javascript, "js": javascript, "jsx": javascript, "mjs": javascript, "cjs": javascript,
+	"typescript": typescript, "ts": typescript, "tsx": typescript,
+	"python": python, "py": python,
+	"java":   java,
+	"kotlin": kotlin, "kt": kotlin,
+	"swift": swift,
+	"c":     c, "h": c,
+	"cpp": cpp, "c++": cpp, "cc": cpp, "hpp": cpp,
+	"csharp": csharp, "cs": csharp, "c#": csharp,
+	"rust": rust, "rs": rust,
+	"php":  php,
+	"ruby": ruby, "rb": ruby,
+	"bash": shell, "sh": shell, "shell": shell, "zsh": shell,
+	"sql": sql, "postgresql": sql, "pgsql": sql,
+	"json": jsonLang,
+	"yaml": yaml, "yml": yaml,
+	"css": css, "scss": css, "less": css,
+	"html": markup, "xml": markup, "svg": markup, "vue": markup,
+	"diff": diff, "patch": diff,
+}
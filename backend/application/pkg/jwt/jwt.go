@@ -7,46 +7,89 @@ import (
 
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/pdhoang91/blog/internal/entities"
+	uuid "github.com/satori/go.uuid"
 )
 
-var jwtSecret []byte
-
-// GetJWTSecret returns the JWT signing secret from env or a default fallback.
-func GetJWTSecret() []byte {
-	if jwtSecret == nil {
-		secret := os.Getenv("JWT_SECRET")
-		if secret == "" {
-			// Must be at least 32 characters for HMAC-SHA256
-			secret = "your-super-secret-jwt-key-here"
-		}
-		jwtSecret = []byte(secret)
+const defaultAccessTokenTTL = 15 * time.Minute
+
+// AccessTokenTTL returns the lifetime of access tokens, configurable through
+// JWT_ACCESS_TOKEN_TTL as a Go duration (e.g. "15m").
+func AccessTokenTTL() time.Duration {
+	if d, err := time.ParseDuration(os.Getenv("JWT_ACCESS_TOKEN_TTL")); err == nil && d > 0 {
+		return d
 	}
-	return jwtSecret
+	return defaultAccessTokenTTL
 }
 
-// GenerateJWT creates a signed JWT token for the given user.
-func GenerateJWT(user *entities.User) (string, error) {
+// GenerateJWT creates a short-lived signed access token for the given user.
+// sessionID binds the token to a login session so it can be revoked server-side.
+func GenerateJWT(user *entities.User, sessionID string) (string, error) {
 	if user == nil {
 		return "", errors.New("user is nil")
 	}
 
-	token := jwt.New(jwt.SigningMethodHS256)
-	claims := token.Claims.(jwt.MapClaims)
+	now := time.Now()
+	claims := jwt.MapClaims{}
 	claims["user_id"] = user.ID.String()
+	claims["sid"] = sessionID
 	claims["email"] = user.Email
 	claims["name"] = user.Name
 	claims["role"] = string(user.Role)
-	claims["exp"] = time.Now().Add(time.Hour * 72).Unix()
+	claims["iat"] = now.Unix()
+	claims["exp"] = now.Add(AccessTokenTTL()).Unix()
 
-	return token.SignedString(GetJWTSecret())
+	return sign(claims)
 }
 
 // VerifyJWT validates a JWT token string and returns the parsed token.
 func VerifyJWT(tokenString string) (*jwt.Token, error) {
-	return jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("unexpected signing method")
-		}
-		return GetJWTSecret(), nil
-	})
+	return jwt.Parse(tokenString, keyFunc)
+}
+
+// ActionClaims are carried by single-purpose tokens such as email verification
+// and password reset links. The ID (jti) lets the caller enforce one-time use.
+type ActionClaims struct {
+	Purpose string `json:"purpose"`
+	Email   string `json:"email"`
+	jwt.RegisteredClaims
+}
+
+// GenerateActionToken signs a token that is only valid for purpose and expires after ttl.
+// It returns the token together with its jti.
+func GenerateActionToken(user *entities.User, purpose string, ttl time.Duration) (string, string, error) {
+	if user == nil {
+		return "", "", errors.New("user is nil")
+	}
+
+	now := time.Now()
+	jti := uuid.NewV4().String()
+	claims := ActionClaims{
+		Purpose: purpose,
+		Email:   user.Email,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
+			Subject:   user.ID.String(),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+	}
+	token, err := sign(claims)
+	if err != nil {
+		return "", "", err
+	}
+	return token, jti, nil
+}
+
+// VerifyActionToken validates the signature and expiry of an action token and
+// checks that it was issued for purpose.
+func VerifyActionToken(tokenString, purpose string) (*ActionClaims, error) {
+	claims := &ActionClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, keyFunc)
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid || claims.Purpose != purpose || claims.ID == "" {
+		return nil, errors.New("invalid action token")
+	}
+	return claims, nil
 }
@@ -0,0 +1,293 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// Supported signing algorithms.
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+)
+
+// defaultSecret is only acceptable in development; Init refuses it otherwise.
+const defaultSecret = "your-super-secret-jwt-key-here"
+
+// Options configures token signing. Keys for RS256/EdDSA are PEM files in
+// KeysDir named <kid>.pem. Private keys can sign and verify; public keys only
+// verify, which keeps tokens of a retired key valid until they expire.
+type Options struct {
+	Algorithm   string
+	Secret      string
+	KeysDir     string
+	ActiveKID   string
+	Development bool
+}
+
+type verificationKey struct {
+	method jwt.SigningMethod
+	key    interface{} // []byte, *rsa.PublicKey or ed25519.PublicKey
+}
+
+type keySet struct {
+	method    jwt.SigningMethod
+	activeKID string
+	signKey   interface{}
+	verify    map[string]verificationKey
+}
+
+var (
+	keysMu  sync.RWMutex
+	current *keySet
+)
+
+// Init loads signing keys. It must be called at startup; until then tokens are
+// signed with HS256 and JWT_SECRET (or the development default).
+func Init(opts Options) error {
+	ks, err := loadKeySet(opts)
+	if err != nil {
+		return err
+	}
+	keysMu.Lock()
+	current = ks
+	keysMu.Unlock()
+	return nil
+}
+
+func keys() *keySet {
+	keysMu.RLock()
+	ks := current
+	keysMu.RUnlock()
+	if ks != nil {
+		return ks
+	}
+
+	ks, _ = loadKeySet(Options{Algorithm: AlgHS256, Secret: os.Getenv("JWT_SECRET"), Development: true})
+	keysMu.Lock()
+	if current == nil {
+		current = ks
+	}
+	ks = current
+	keysMu.Unlock()
+	return ks
+}
+
+func loadKeySet(opts Options) (*keySet, error) {
+	switch strings.ToUpper(opts.Algorithm) {
+	case "", AlgHS256:
+		return loadHMACKey(opts)
+	case AlgRS256, strings.ToUpper(AlgEdDSA):
+		return loadAsymmetricKeys(opts)
+	default:
+		return nil, fmt.Errorf("jwt: unsupported algorithm %q", opts.Algorithm)
+	}
+}
+
+func loadHMACKey(opts Options) (*keySet, error) {
+	secret := opts.Secret
+	if !opts.Development {
+		if secret == "" || secret == defaultSecret {
+			return nil, errors.New("jwt: JWT_SECRET must be set to a non-default value outside development")
+		}
+		if len(secret) < 32 {
+			return nil, errors.New("jwt: JWT_SECRET must be at least 32 characters")
+		}
+	}
+	if secret == "" {
+		secret = defaultSecret
+	}
+
+	// HS256 tokens carry no kid; the single secret is stored under the empty kid.
+	key := []byte(secret)
+	return &keySet{
+		method:  jwt.SigningMethodHS256,
+		signKey: key,
+		verify:  map[string]verificationKey{"": {method: jwt.SigningMethodHS256, key: key}},
+	}, nil
+}
+
+func loadAsymmetricKeys(opts Options) (*keySet, error) {
+	if opts.KeysDir == "" {
+		return nil, fmt.Errorf("jwt: JWT_KEYS_DIR is required for %s", opts.Algorithm)
+	}
+	paths, err := filepath.Glob(filepath.Join(opts.KeysDir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	ks := &keySet{verify: make(map[string]verificationKey)}
+	signers := make(map[string]crypto.Signer)
+	for _, path := range paths {
+		kid := strings.TrimSuffix(filepath.Base(path), ".pem")
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("jwt: read key %s: %w", kid, err)
+		}
+		signer, public, err := parsePEMKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("jwt: parse key %s: %w", kid, err)
+		}
+
+		var method jwt.SigningMethod
+		switch public.(type) {
+		case *rsa.PublicKey:
+			method = jwt.SigningMethodRS256
+		case ed25519.PublicKey:
+			method = jwt.SigningMethodEdDSA
+		default:
+			return nil, fmt.Errorf("jwt: key %s has an unsupported type", kid)
+		}
+		ks.verify[kid] = verificationKey{method: method, key: public}
+		if signer != nil {
+			signers[kid] = signer
+		}
+	}
+
+	activeKID := opts.ActiveKID
+	if activeKID == "" {
+		if len(signers) != 1 {
+			return nil, errors.New("jwt: JWT_ACTIVE_KID is required unless exactly one private key is present")
+		}
+		for kid := range signers {
+			activeKID = kid
+		}
+	}
+	signer, ok := signers[activeKID]
+	if !ok {
+		return nil, fmt.Errorf("jwt: no private key for active kid %q", activeKID)
+	}
+
+	ks.activeKID = activeKID
+	ks.method = ks.verify[activeKID].method
+	if ks.method.Alg() != normaliseAlg(opts.Algorithm) {
+		return nil, fmt.Errorf("jwt: active key %q does not match algorithm %s", activeKID, opts.Algorithm)
+	}
+	ks.signKey = signer
+	return ks, nil
+}
+
+func normaliseAlg(alg string) string {
+	if strings.EqualFold(alg, AlgEdDSA) {
+		return AlgEdDSA
+	}
+	return strings.ToUpper(alg)
+}
+
+// parsePEMKey returns the signer (nil for public keys) and public key in a PEM block.
+func parsePEMKey(data []byte) (crypto.Signer, crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, nil, errors.New("no PEM block found")
+	}
+
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, nil, err
+		}
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, nil, errors.New("unsupported private key")
+		}
+		return signer, signer.Public(), nil
+	case "RSA PRIVATE KEY":
+		key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, nil, err
+		}
+		return key, key.Public(), nil
+	case "PUBLIC KEY":
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, nil, err
+		}
+		return nil, key, nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+}
+
+// sign signs token with the active key, setting the kid header for asymmetric keys.
+func sign(claims jwt.Claims) (string, error) {
+	ks := keys()
+	token := jwt.NewWithClaims(ks.method, claims)
+	if ks.activeKID != "" {
+		token.Header["kid"] = ks.activeKID
+	}
+	return token.SignedString(ks.signKey)
+}
+
+// keyFunc selects the verification key by the token's kid and rejects
+// tokens whose algorithm does not match that key.
+func keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := keys().verify[kid]
+	if !ok {
+		return nil, errors.New("unknown signing key")
+	}
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, errors.New("unexpected signing method")
+	}
+	return key.key, nil
+}
+
+// JWK is a public key in JSON Web Key format.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKS returns every public verification key. It is empty for HS256, whose
+// shared secret must never be published.
+func JWKS() []JWK {
+	ks := keys()
+	kids := make([]string, 0, len(ks.verify))
+	for kid := range ks.verify {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+
+	set := make([]JWK, 0, len(kids))
+	for _, kid := range kids {
+		v := ks.verify[kid]
+		switch key := v.key.(type) {
+		case *rsa.PublicKey:
+			set = append(set, JWK{
+				Kty: "RSA", Kid: kid, Use: "sig", Alg: AlgRS256,
+				N: base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				E: base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			set = append(set, JWK{
+				Kty: "OKP", Kid: kid, Use: "sig", Alg: AlgEdDSA,
+				Crv: "Ed25519",
+				X:   base64.RawURLEncoding.EncodeToString(key),
+			})
+		}
+	}
+	return set
+}
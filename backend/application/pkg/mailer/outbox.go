@@ -0,0 +1,46 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+// OutboxMailer writes each message as an .eml file into a local directory
+// instead of sending it, for development and tests.
+type OutboxMailer struct {
+	dir  string
+	from string
+}
+
+func NewOutboxMailer(dir, from string) *OutboxMailer {
+	if dir == "" {
+		dir = filepath.Join(os.TempDir(), "insight-outbox")
+	}
+	return &OutboxMailer{dir: dir, from: from}
+}
+
+func (m *OutboxMailer) Send(ctx context.Context, msg *Message) error {
+	if err := validateHeader("To", msg.To); err != nil {
+		return err
+	}
+	if err := validateHeader("Subject", msg.Subject); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(m.dir, 0o755); err != nil {
+		return fmt.Errorf("mailer: create outbox: %w", err)
+	}
+	name := fmt.Sprintf("%s-%s.eml", time.Now().UTC().Format("20060102T150405"), uuid.NewV4().String()[:8])
+	if err := os.WriteFile(filepath.Join(m.dir, name), buildRFC822(m.from, msg), 0o644); err != nil {
+		return fmt.Errorf("mailer: write outbox message: %w", err)
+	}
+	return nil
+}
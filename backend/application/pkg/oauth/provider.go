@@ -0,0 +1,316 @@
+// Package oauth provides a registry of OAuth 2.0 / OpenID Connect sign-in
+// providers (Google, GitHub, GitLab and generic OIDC issuers).
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// Supported provider types.
+const (
+	TypeGoogle = "google"
+	TypeGitHub = "github"
+	TypeGitLab = "gitlab"
+	TypeOIDC   = "oidc"
+)
+
+const (
+	maxResponseBytes = 1 << 20
+	exchangeTimeout  = 15 * time.Second
+)
+
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+// Profile is the normalised identity returned by a provider.
+type Profile struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Picture       string
+}
+
+// Config describes one configured provider.
+type Config struct {
+	Name         string // URL segment, e.g. "github" or "corp"
+	Type         string // one of the Type* constants
+	DisplayName  string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+
+	// Issuer is required for TypeOIDC; endpoints are discovered from it
+	// unless set explicitly. BaseURL selects a self-hosted GitLab.
+	Issuer      string
+	BaseURL     string
+	AuthURL     string
+	TokenURL    string
+	UserInfoURL string
+}
+
+// Provider performs the authorization code flow for one configured provider.
+type Provider struct {
+	cfg Config
+
+	mu          sync.Mutex
+	oauthConfig *oauth2.Config
+	userInfoURL string
+}
+
+func newProvider(cfg Config) (*Provider, error) {
+	if cfg.Name == "" || cfg.ClientID == "" {
+		return nil, errors.New("oauth: provider name and client id are required")
+	}
+
+	switch cfg.Type {
+	case TypeGoogle:
+		setDefault(&cfg.AuthURL, "https://accounts.google.com/o/oauth2/auth")
+		setDefault(&cfg.TokenURL, "https://oauth2.googleapis.com/token")
+		setDefault(&cfg.UserInfoURL, "https://www.googleapis.com/oauth2/v3/userinfo")
+		setDefaultScopes(&cfg.Scopes, "openid", "email", "profile")
+		setDefault(&cfg.DisplayName, "Google")
+	case TypeGitHub:
+		setDefault(&cfg.AuthURL, "https://github.com/login/oauth/authorize")
+		setDefault(&cfg.TokenURL, "https://github.com/login/oauth/access_token")
+		setDefault(&cfg.UserInfoURL, "https://api.github.com/user")
+		setDefaultScopes(&cfg.Scopes, "read:user", "user:email")
+		setDefault(&cfg.DisplayName, "GitHub")
+	case TypeGitLab:
+		base := strings.TrimRight(cfg.BaseURL, "/")
+		if base == "" {
+			base = "https://gitlab.com"
+		}
+		setDefault(&cfg.AuthURL, base+"/oauth/authorize")
+		setDefault(&cfg.TokenURL, base+"/oauth/token")
+		setDefault(&cfg.UserInfoURL, base+"/oauth/userinfo")
+		setDefaultScopes(&cfg.Scopes, "openid", "email", "profile")
+		setDefault(&cfg.DisplayName, "GitLab")
+	case TypeOIDC:
+		if cfg.Issuer == "" && (cfg.AuthURL == "" || cfg.TokenURL == "" || cfg.UserInfoURL == "") {
+			return nil, fmt.Errorf("oauth: provider %q needs an issuer or explicit endpoints", cfg.Name)
+		}
+		setDefaultScopes(&cfg.Scopes, "openid", "email", "profile")
+		setDefault(&cfg.DisplayName, cfg.Name)
+	default:
+		return nil, fmt.Errorf("oauth: provider %q has unknown type %q", cfg.Name, cfg.Type)
+	}
+
+	return &Provider{cfg: cfg}, nil
+}
+
+func setDefault(v *string, def string) {
+	if *v == "" {
+		*v = def
+	}
+}
+
+func setDefaultScopes(v *[]string, scopes ...string) {
+	if len(*v) == 0 {
+		*v = scopes
+	}
+}
+
+func (p *Provider) Name() string        { return p.cfg.Name }
+func (p *Provider) DisplayName() string { return p.cfg.DisplayName }
+
+// resolve returns the oauth2 configuration, running OIDC discovery on first use.
+// A failed discovery is retried on the next call.
+func (p *Provider) resolve(ctx context.Context) (*oauth2.Config, string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.oauthConfig != nil {
+		return p.oauthConfig, p.userInfoURL, nil
+	}
+
+	authURL, tokenURL, userInfoURL := p.cfg.AuthURL, p.cfg.TokenURL, p.cfg.UserInfoURL
+	if authURL == "" || tokenURL == "" || userInfoURL == "" {
+		doc, err := discover(ctx, p.cfg.Issuer)
+		if err != nil {
+			return nil, "", err
+		}
+		setDefault(&authURL, doc.AuthorizationEndpoint)
+		setDefault(&tokenURL, doc.TokenEndpoint)
+		setDefault(&userInfoURL, doc.UserInfoEndpoint)
+		if authURL == "" || tokenURL == "" || userInfoURL == "" {
+			return nil, "", fmt.Errorf("oauth: discovery for %q is missing endpoints", p.cfg.Name)
+		}
+	}
+
+	p.oauthConfig = &oauth2.Config{
+		ClientID:     p.cfg.ClientID,
+		ClientSecret: p.cfg.ClientSecret,
+		RedirectURL:  p.cfg.RedirectURL,
+		Scopes:       p.cfg.Scopes,
+		Endpoint:     oauth2.Endpoint{AuthURL: authURL, TokenURL: tokenURL},
+	}
+	p.userInfoURL = userInfoURL
+	return p.oauthConfig, p.userInfoURL, nil
+}
+
+// AuthCodeURL returns the provider's authorization URL for state, using PKCE with verifier.
+func (p *Provider) AuthCodeURL(ctx context.Context, state, verifier string) (string, error) {
+	conf, _, err := p.resolve(ctx)
+	if err != nil {
+		return "", err
+	}
+	return conf.AuthCodeURL(state, oauth2.S256ChallengeOption(verifier)), nil
+}
+
+// Exchange redeems an authorization code and fetches the signed-in user's profile.
+func (p *Provider) Exchange(ctx context.Context, code, verifier string) (*Profile, error) {
+	conf, userInfoURL, err := p.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, exchangeTimeout)
+	defer cancel()
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, httpClient)
+	token, err := conf.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrExchange, err)
+	}
+	client := conf.Client(ctx, token)
+
+	var profile *Profile
+	if p.cfg.Type == TypeGitHub {
+		profile, err = fetchGitHubProfile(ctx, client, userInfoURL)
+	} else {
+		profile, err = fetchOIDCProfile(ctx, client, userInfoURL)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if profile.Subject == "" {
+		return nil, fmt.Errorf("oauth: %s returned no subject", p.cfg.Name)
+	}
+	return profile, nil
+}
+
+// ErrExchange is returned when the provider rejects the authorization code.
+var ErrExchange = errors.New("oauth: code exchange failed")
+
+type discoveryDocument struct {
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserInfoEndpoint      string `json:"userinfo_endpoint"`
+}
+
+func discover(ctx context.Context, issuer string) (*discoveryDocument, error) {
+	url := strings.TrimRight(issuer, "/") + "/.well-known/openid-configuration"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	var doc discoveryDocument
+	if err := doJSON(httpClient, req, &doc); err != nil {
+		return nil, fmt.Errorf("oauth: discovery for %s: %w", issuer, err)
+	}
+	return &doc, nil
+}
+
+func getJSON(ctx context.Context, client *http.Client, url string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	return doJSON(client, req, v)
+}
+
+func doJSON(client *http.Client, req *http.Request, v any) error {
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %d", req.URL, resp.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, maxResponseBytes)).Decode(v)
+}
+
+// fetchOIDCProfile reads the standard OIDC userinfo claims (Google, GitLab, generic issuers).
+func fetchOIDCProfile(ctx context.Context, client *http.Client, url string) (*Profile, error) {
+	var claims struct {
+		Sub           string `json:"sub"`
+		Email         string `json:"email"`
+		EmailVerified any    `json:"email_verified"`
+		Name          string `json:"name"`
+		Picture       string `json:"picture"`
+	}
+	if err := getJSON(ctx, client, url, &claims); err != nil {
+		return nil, fmt.Errorf("oauth: userinfo: %w", err)
+	}
+	return &Profile{
+		Subject:       claims.Sub,
+		Email:         claims.Email,
+		EmailVerified: claimBool(claims.EmailVerified),
+		Name:          claims.Name,
+		Picture:       claims.Picture,
+	}, nil
+}
+
+// claimBool accepts both JSON booleans and the "true"/"false" strings some issuers send.
+func claimBool(v any) bool {
+	switch b := v.(type) {
+	case bool:
+		return b
+	case string:
+		return b == "true"
+	}
+	return false
+}
+
+// fetchGitHubProfile reads the GitHub user and, since the public email may be
+// hidden, its primary verified address from /user/emails.
+func fetchGitHubProfile(ctx context.Context, client *http.Client, url string) (*Profile, error) {
+	var user struct {
+		ID        int64  `json:"id"`
+		Login     string `json:"login"`
+		Name      string `json:"name"`
+		AvatarURL string `json:"avatar_url"`
+	}
+	if err := getJSON(ctx, client, url, &user); err != nil {
+		return nil, fmt.Errorf("oauth: github user: %w", err)
+	}
+
+	profile := &Profile{
+		Name:    user.Name,
+		Picture: user.AvatarURL,
+	}
+	if user.ID != 0 {
+		profile.Subject = fmt.Sprintf("%d", user.ID)
+	}
+	if profile.Name == "" {
+		profile.Name = user.Login
+	}
+
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	if err := getJSON(ctx, client, strings.TrimSuffix(url, "/user")+"/user/emails", &emails); err != nil {
+		return nil, fmt.Errorf("oauth: github emails: %w", err)
+	}
+	for _, e := range emails {
+		if e.Primary {
+			profile.Email = e.Email
+			profile.EmailVerified = e.Verified
+			break
+		}
+	}
+	return profile, nil
+}
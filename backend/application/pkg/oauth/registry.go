@@ -0,0 +1,48 @@
+package oauth
+
+import (
+	"log"
+	"sort"
+)
+
+// Registry holds the enabled sign-in providers keyed by name.
+type Registry struct {
+	providers map[string]*Provider
+}
+
+// NewRegistry builds a registry from provider configs. Invalid entries are
+// logged and skipped so one misconfigured provider does not block startup.
+func NewRegistry(configs []Config) *Registry {
+	r := &Registry{providers: make(map[string]*Provider)}
+	for _, cfg := range configs {
+		p, err := newProvider(cfg)
+		if err != nil {
+			log.Printf("Warning: skipping OAuth provider: %v", err)
+			continue
+		}
+		r.providers[cfg.Name] = p
+	}
+	return r
+}
+
+// Get returns the provider registered under name.
+func (r *Registry) Get(name string) (*Provider, bool) {
+	if r == nil {
+		return nil, false
+	}
+	p, ok := r.providers[name]
+	return p, ok
+}
+
+// Providers returns the registered providers sorted by name.
+func (r *Registry) Providers() []*Provider {
+	if r == nil {
+		return nil
+	}
+	list := make([]*Provider, 0, len(r.providers))
+	for _, p := range r.providers {
+		list = append(list, p)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name() < list[j].Name() })
+	return list
+}
@@ -0,0 +1,65 @@
+// Package ratelimit implements token-bucket rate limiting with in-memory and
+// Redis backends. Buckets start full, hold at most Limit.Burst tokens and refill
+// continuously so that Burst tokens are restored every Limit.Per.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Limit describes a bucket: Burst requests, refilled evenly over Per.
+type Limit struct {
+	Burst int
+	Per   time.Duration
+}
+
+// PerMinute allows n requests per minute with a burst of n.
+func PerMinute(n int) Limit { return Limit{Burst: n, Per: time.Minute} }
+
+// PerHour allows n requests per hour with a burst of n.
+func PerHour(n int) Limit { return Limit{Burst: n, Per: time.Hour} }
+
+// interval is the time it takes to refill one token.
+func (l Limit) interval() time.Duration {
+	if l.Burst <= 0 {
+		return l.Per
+	}
+	return l.Per / time.Duration(l.Burst)
+}
+
+// Result is the outcome of taking a token from a bucket.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// RetryAfter is how long until a token is available; zero when Allowed.
+	RetryAfter time.Duration
+}
+
+// Limiter takes one token from the bucket identified by key.
+type Limiter interface {
+	Allow(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// take applies the token-bucket arithmetic shared by both backends. tokens and
+// last describe the bucket as stored; the new state is returned alongside the result.
+func take(tokens float64, last, now time.Time, limit Limit) (float64, Result) {
+	burst := float64(limit.Burst)
+	if last.IsZero() {
+		tokens = burst
+	} else if elapsed := now.Sub(last); elapsed > 0 {
+		tokens = math.Min(burst, tokens+float64(elapsed)/float64(limit.interval()))
+	}
+
+	res := Result{Limit: limit.Burst}
+	if tokens >= 1 {
+		tokens--
+		res.Allowed = true
+		res.Remaining = int(tokens)
+		return tokens, res
+	}
+	res.RetryAfter = time.Duration((1 - tokens) * float64(limit.interval()))
+	return tokens, res
+}
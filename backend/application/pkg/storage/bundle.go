@@ -0,0 +1,131 @@
+package storage
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/textproto"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// maxBundleFileSize matches the limit on images uploaded one by one.
+const maxBundleFileSize = 10 << 20
+
+// resizedVariant matches the size suffix WordPress adds to resized copies of
+// an upload, as in photo-300x200.jpg.
+var resizedVariant = regexp.MustCompile(`-\d+x\d+(\.[A-Za-z0-9]+)$`)
+
+// Bundle is a zip archive of the files imported content refers to, such as a
+// copy of WordPress' wp-content/uploads directory.
+type Bundle struct {
+	files  map[string]*zip.File   // by cleaned path inside the archive
+	byBase map[string][]*zip.File // by file name
+}
+
+// OpenBundle reads the archive's table of contents.
+func OpenBundle(r io.ReaderAt, size int64) (*Bundle, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bundle: %w", err)
+	}
+	b := &Bundle{files: make(map[string]*zip.File), byBase: make(map[string][]*zip.File)}
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		name := strings.TrimPrefix(path.Clean("/"+f.Name), "/")
+		b.files[name] = f
+		base := path.Base(name)
+		b.byBase[base] = append(b.byBase[base], f)
+	}
+	return b, nil
+}
+
+// Find returns the file a content URL points at. It matches the longest tail
+// of the URL path found in the archive, then a file name that occurs only
+// once, and finally the original of a resized WordPress image.
+func (b *Bundle) Find(rawURL string) (*zip.File, bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, false
+	}
+	p := strings.TrimPrefix(path.Clean("/"+u.Path), "/")
+	if p == "" {
+		return nil, false
+	}
+	if f, ok := b.find(p); ok {
+		return f, true
+	}
+	if original := resizedVariant.ReplaceAllString(p, "$1"); original != p {
+		return b.find(original)
+	}
+	return nil, false
+}
+
+func (b *Bundle) find(p string) (*zip.File, bool) {
+	segments := strings.Split(p, "/")
+	for i := range segments {
+		if f, ok := b.files[strings.Join(segments[i:], "/")]; ok {
+			return f, true
+		}
+	}
+	if matches := b.byBase[segments[len(segments)-1]]; len(matches) == 1 {
+		return matches[0], true
+	}
+	return nil, false
+}
+
+// FileHeader loads f so it can be passed to UploadImage like an uploaded file.
+func (b *Bundle) FileHeader(f *zip.File) (*multipart.FileHeader, error) {
+	if f.UncompressedSize64 > maxBundleFileSize {
+		return nil, fmt.Errorf("%s is larger than %d MB", f.Name, maxBundleFileSize>>20)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", f.Name, err)
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(io.LimitReader(rc, maxBundleFileSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", f.Name, err)
+	}
+	if len(data) > maxBundleFileSize {
+		return nil, fmt.Errorf("%s is larger than %d MB", f.Name, maxBundleFileSize>>20)
+	}
+	return fileHeader(path.Base(f.Name), data)
+}
+
+// fileHeader wraps data in a multipart.FileHeader. Its fields holding the
+// content are unexported, so the file goes through an in-memory form.
+func fileHeader(filename string, data []byte) (*multipart.FileHeader, error) {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename=%q`, filename))
+	h.Set("Content-Type", "application/octet-stream")
+	part, err := w.CreatePart(h)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := part.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	form, err := multipart.NewReader(&body, w.Boundary()).ReadForm(int64(len(data)) + 1<<20)
+	if err != nil {
+		return nil, err
+	}
+	files := form.File["file"]
+	if len(files) == 0 {
+		return nil, fmt.Errorf("failed to load %s", filename)
+	}
+	return files[0], nil
+}
@@ -280,15 +280,15 @@ func (m *Manager) DeleteImage(ctx context.Context, imageID string) error {
 // ProcessJSONContent replaces image URLs with data-image-id in a JSON document tree (for saving)
 func (m *Manager) ProcessJSONContent(doc json.RawMessage) json.RawMessage {
 	return m.walkJSONTree(doc, func(node map[string]interface{}) {
-		nodeType, _ := node["type"].(string)
-		if nodeType != "image" && nodeType != "imageBlock" {
+		srcAttr := imageSrcAttr(node)
+		if srcAttr == "" {
 			return
 		}
 		attrs, ok := node["attrs"].(map[string]interface{})
 		if !ok {
 			return
 		}
-		src, ok := attrs["src"].(string)
+		src, ok := attrs[srcAttr].(string)
 		if !ok || src == "" {
 			return
 		}
@@ -296,7 +296,7 @@ func (m *Manager) ProcessJSONContent(doc json.RawMessage) json.RawMessage {
 		matches := re.FindStringSubmatch(src)
 		if len(matches) >= 2 {
 			attrs["dataImageId"] = matches[1]
-			delete(attrs, "src")
+			delete(attrs, srcAttr)
 		}
 	})
 }
@@ -338,8 +338,8 @@ func (m *Manager) GetDirectImageURLBatch(imageIDs []string) map[string]string {
 // ProcessJSONContentForDisplayWithURLs replaces data-image-id with URLs from the provided map.
 func (m *Manager) ProcessJSONContentForDisplayWithURLs(doc json.RawMessage, urlMap map[string]string) json.RawMessage {
 	return m.walkJSONTree(doc, func(node map[string]interface{}) {
-		nodeType, _ := node["type"].(string)
-		if nodeType != "image" && nodeType != "imageBlock" {
+		srcAttr := imageSrcAttr(node)
+		if srcAttr == "" {
 			return
 		}
 		attrs, ok := node["attrs"].(map[string]interface{})
@@ -351,9 +351,9 @@ func (m *Manager) ProcessJSONContentForDisplayWithURLs(doc json.RawMessage, urlM
 			return
 		}
 		if url, exists := urlMap[imageID]; exists {
-			attrs["src"] = url
+			attrs[srcAttr] = url
 		} else {
-			attrs["src"] = m.GetImageURL(imageID)
+			attrs[srcAttr] = m.GetImageURL(imageID)
 		}
 	})
 }
@@ -362,8 +362,8 @@ func (m *Manager) ProcessJSONContentForDisplayWithURLs(doc json.RawMessage, urlM
 func (m *Manager) ExtractImageIDsFromJSON(doc json.RawMessage) []string {
 	var imageIDs []string
 	m.walkJSONTree(doc, func(node map[string]interface{}) {
-		nodeType, _ := node["type"].(string)
-		if nodeType != "image" && nodeType != "imageBlock" {
+		srcAttr := imageSrcAttr(node)
+		if srcAttr == "" {
 			return
 		}
 		attrs, ok := node["attrs"].(map[string]interface{})
@@ -374,7 +374,7 @@ func (m *Manager) ExtractImageIDsFromJSON(doc json.RawMessage) []string {
 			imageIDs = append(imageIDs, id)
 			return
 		}
-		if src, ok := attrs["src"].(string); ok {
+		if src, ok := attrs[srcAttr].(string); ok {
 			re := regexp.MustCompile(`/images/v2/([^/'"?\s]+)`)
 			matches := re.FindStringSubmatch(src)
 			if len(matches) >= 2 {
@@ -385,14 +385,21 @@ func (m *Manager) ExtractImageIDsFromJSON(doc json.RawMessage) []string {
 	return imageIDs
 }
 
-// ExtractPlainTextFromJSON walks a JSON document tree and extracts all text content
+// ExtractPlainTextFromJSON walks a JSON document tree and extracts all text content,
+// including the LaTeX source of math nodes. Footnote and callout text is in their text nodes.
 func (m *Manager) ExtractPlainTextFromJSON(doc json.RawMessage) string {
 	var parts []string
 	m.walkJSONTree(doc, func(node map[string]interface{}) {
-		if node["type"] == "text" {
+		switch node["type"] {
+		case "text":
 			if text, ok := node["text"].(string); ok && text != "" {
 				parts = append(parts, text)
 			}
+		case "inlineMath", "blockMath":
+			attrs, _ := node["attrs"].(map[string]interface{})
+			if latex, ok := attrs["latex"].(string); ok && latex != "" {
+				parts = append(parts, latex)
+			}
 		}
 	})
 	return strings.Join(parts, " ")
@@ -449,6 +456,37 @@ func (m *Manager) CleanupOrphanedImages(ctx context.Context, userID uuid.UUID) (
 	return deletedCount, nil
 }
 
+// DeleteUnreferencedImages removes those of imageIDs that no post references
+// anymore, e.g. after the posts using them were purged
+func (m *Manager) DeleteUnreferencedImages(ctx context.Context, imageIDs []uuid.UUID) (int, error) {
+	if len(imageIDs) == 0 {
+		return 0, nil
+	}
+
+	var unreferenced []entities.Image
+	err := m.db.Where(`
+		id IN ? AND
+		id NOT IN (
+			SELECT DISTINCT image_id FROM image_references
+			WHERE image_id IS NOT NULL
+		)
+	`, imageIDs).Find(&unreferenced).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to find unreferenced images: %w", err)
+	}
+
+	deletedCount := 0
+	for _, img := range unreferenced {
+		if err := m.DeleteImage(ctx, img.ID.String()); err != nil {
+			fmt.Printf("Warning: failed to delete unreferenced image %s: %v\n", img.ID, err)
+			continue
+		}
+		deletedCount++
+	}
+
+	return deletedCount, nil
+}
+
 // CleanupUserImages removes all images for a user (for user deletion)
 func (m *Manager) CleanupUserImages(ctx context.Context, userID uuid.UUID) error {
 	var userImages []entities.Image
@@ -503,6 +541,19 @@ func (m *Manager) createImageReference(imageID string, postID uuid.UUID, refType
 	return m.db.Create(ref).Error
 }
 
+// imageSrcAttr names the attribute holding the URL of the image a node
+// shows: the image itself, or the preview picture of an embedded link. It is
+// "" for nodes without one.
+func imageSrcAttr(node map[string]interface{}) string {
+	switch node["type"] {
+	case "image", "imageBlock":
+		return "src"
+	case "embed":
+		return "image"
+	}
+	return ""
+}
+
 func (m *Manager) walkJSONTree(doc json.RawMessage, visitor func(node map[string]interface{})) json.RawMessage {
 	var node map[string]interface{}
 	if err := json.Unmarshal(doc, &node); err != nil {
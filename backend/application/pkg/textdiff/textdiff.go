@@ -0,0 +1,172 @@
+// Package textdiff computes word-level differences between two texts using
+// Myers' O(ND) algorithm.
+package textdiff
+
+import "strings"
+
+// Op says how a segment relates the old text to the new one.
+type Op string
+
+const (
+	Equal  Op = "equal"
+	Insert Op = "insert"
+	Delete Op = "delete"
+)
+
+// maxEdits bounds the work spent on very different texts. Past it the diff
+// degrades to deleting the old text and inserting the new one.
+const maxEdits = 4000
+
+// Segment is a run of consecutive words sharing the same Op.
+type Segment struct {
+	Op   Op     `json:"op"`
+	Text string `json:"text"`
+}
+
+// Words diffs a and b word by word. Whitespace only separates words, so
+// re-wrapping text produces no changes.
+func Words(a, b string) []Segment {
+	return Diff(strings.Fields(a), strings.Fields(b))
+}
+
+// Diff returns the segments turning a into b. Words within a segment are
+// joined by single spaces.
+func Diff(a, b []string) []Segment {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var ops []Op
+	var words []string
+	emit := func(op Op, tokens ...string) {
+		for _, t := range tokens {
+			ops = append(ops, op)
+			words = append(words, t)
+		}
+	}
+
+	emit(Equal, a[:prefix]...)
+	midA, midB := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	if middle, ok := myers(midA, midB); ok {
+		for _, e := range middle {
+			emit(e.op, e.word)
+		}
+	} else {
+		emit(Delete, midA...)
+		emit(Insert, midB...)
+	}
+	emit(Equal, a[len(a)-suffix:]...)
+
+	var segments []Segment
+	for start := 0; start < len(ops); {
+		end := start + 1
+		for end < len(ops) && ops[end] == ops[start] {
+			end++
+		}
+		segments = append(segments, Segment{Op: ops[start], Text: strings.Join(words[start:end], " ")})
+		start = end
+	}
+	return segments
+}
+
+// Counts returns how many words were inserted and deleted.
+func Counts(segments []Segment) (inserted, deleted int) {
+	for _, s := range segments {
+		switch s.Op {
+		case Insert:
+			inserted += len(strings.Fields(s.Text))
+		case Delete:
+			deleted += len(strings.Fields(s.Text))
+		}
+	}
+	return inserted, deleted
+}
+
+type edit struct {
+	op   Op
+	word string
+}
+
+// myers returns the word edits turning a into b, or false if they need more
+// than maxEdits insertions and deletions.
+func myers(a, b []string) ([]edit, bool) {
+	n, m := len(a), len(b)
+	if n == 0 && m == 0 {
+		return nil, true
+	}
+
+	// v[k] is the furthest x reached on diagonal k = x - y. trace[d] keeps the
+	// diagonals -d-1..d+1 as they were before step d, for the backtrack.
+	limit := n + m
+	if limit > maxEdits {
+		limit = maxEdits
+	}
+	off := limit + 1
+	v := make([]int, 2*limit+3)
+	var trace [][]int
+
+	found := -1
+	for d := 0; d <= limit && found < 0; d++ {
+		trace = append(trace, append([]int(nil), v[off-d-1:off+d+2]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[off+k-1] < v[off+k+1]) {
+				x = v[off+k+1]
+			} else {
+				x = v[off+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[off+k] = x
+			if x >= n && y >= m {
+				found = d
+				break
+			}
+		}
+	}
+	if found < 0 {
+		return nil, false
+	}
+
+	var edits []edit
+	x, y := n, m
+	for d := found; d >= 0; d-- {
+		snap := trace[d]
+		at := func(k int) int { return snap[k+d+1] }
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			edits = append(edits, edit{Equal, a[x-1]})
+			x--
+			y--
+		}
+		if d > 0 {
+			if x == prevX {
+				edits = append(edits, edit{Insert, b[y-1]})
+			} else {
+				edits = append(edits, edit{Delete, a[x-1]})
+			}
+		}
+		x, y = prevX, prevY
+	}
+
+	for i, j := 0, len(edits)-1; i < j; i, j = i+1, j-1 {
+		edits[i], edits[j] = edits[j], edits[i]
+	}
+	return edits, true
+}
@@ -0,0 +1,186 @@
+package textdiff
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWords(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want []Segment
+	}{
+		{
+			name: "identical",
+			a:    "the quick brown fox",
+			b:    "the quick brown fox",
+			want: []Segment{{Equal, "the quick brown fox"}},
+		},
+		{
+			name: "both empty",
+			a:    "",
+			b:    "  \n ",
+			want: nil,
+		},
+		{
+			name: "rewrapped",
+			a:    "the quick\nbrown   fox",
+			b:    "the quick brown fox",
+			want: []Segment{{Equal, "the quick brown fox"}},
+		},
+		{
+			name: "insert into empty",
+			a:    "",
+			b:    "hello world",
+			want: []Segment{{Insert, "hello world"}},
+		},
+		{
+			name: "delete everything",
+			a:    "hello world",
+			b:    "",
+			want: []Segment{{Delete, "hello world"}},
+		},
+		{
+			name: "shared prefix and suffix",
+			a:    "one two three four five",
+			b:    "one two 3 four five",
+			want: []Segment{
+				{Equal, "one two"},
+				{Delete, "three"},
+				{Insert, "3"},
+				{Equal, "four five"},
+			},
+		},
+		{
+			name: "append",
+			a:    "one two",
+			b:    "one two three four",
+			want: []Segment{{Equal, "one two"}, {Insert, "three four"}},
+		},
+		{
+			name: "prepend",
+			a:    "three four",
+			b:    "one two three four",
+			want: []Segment{{Insert, "one two"}, {Equal, "three four"}},
+		},
+		{
+			name: "repeated words do not overlap prefix and suffix",
+			a:    "a a a",
+			b:    "a a",
+			want: []Segment{{Equal, "a a"}, {Delete, "a"}},
+		},
+		{
+			name: "edits in the middle",
+			a:    "start a b c middle d e f end",
+			b:    "start a c middle x d f end",
+			want: []Segment{
+				{Equal, "start a"},
+				{Delete, "b"},
+				{Equal, "c middle"},
+				{Insert, "x"},
+				{Equal, "d"},
+				{Delete, "e"},
+				{Equal, "f end"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Words(tt.a, tt.b)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Words(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+			checkRoundTrip(t, got, tt.a, tt.b)
+		})
+	}
+}
+
+func TestDiffFallsBackPastMaxEdits(t *testing.T) {
+	// Every other word changes, so the shortest edit script needs two edits
+	// per pair: more than maxEdits in total.
+	pairs := maxEdits/2 + 100
+	var a, b []string
+	for i := 0; i < pairs; i++ {
+		a = append(a, fmt.Sprintf("old%d", i), fmt.Sprintf("same%d", i))
+		b = append(b, fmt.Sprintf("new%d", i), fmt.Sprintf("same%d", i))
+	}
+
+	got := Diff(a, b)
+	want := []Segment{
+		{Delete, strings.Join(a[:len(a)-1], " ")},
+		{Insert, strings.Join(b[:len(b)-1], " ")},
+		{Equal, a[len(a)-1]},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Diff returned %d segments, want the delete/insert fallback", len(got))
+	}
+	checkRoundTrip(t, got, strings.Join(a, " "), strings.Join(b, " "))
+}
+
+func TestDiffBelowMaxEdits(t *testing.T) {
+	pairs := maxEdits/4 - 10
+	var a, b []string
+	for i := 0; i < pairs; i++ {
+		a = append(a, fmt.Sprintf("old%d", i), fmt.Sprintf("same%d", i))
+		b = append(b, fmt.Sprintf("new%d", i), fmt.Sprintf("same%d", i))
+	}
+
+	got := Diff(a, b)
+	if len(got) != 3*pairs {
+		t.Fatalf("Diff returned %d segments, want %d", len(got), 3*pairs)
+	}
+	inserted, deleted := Counts(got)
+	if inserted != pairs || deleted != pairs {
+		t.Fatalf("Counts = %d, %d, want %d, %d", inserted, deleted, pairs, pairs)
+	}
+	checkRoundTrip(t, got, strings.Join(a, " "), strings.Join(b, " "))
+}
+
+func TestCounts(t *testing.T) {
+	tests := []struct {
+		name              string
+		segments          []Segment
+		inserted, deleted int
+	}{
+		{name: "none", segments: nil},
+		{name: "equal only", segments: []Segment{{Equal, "a b c"}}},
+		{
+			name:     "mixed",
+			segments: []Segment{{Equal, "a"}, {Delete, "b c"}, {Insert, "d e f"}, {Equal, "g"}, {Delete, "h"}},
+			inserted: 3,
+			deleted:  3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inserted, deleted := Counts(tt.segments)
+			if inserted != tt.inserted || deleted != tt.deleted {
+				t.Fatalf("Counts = %d, %d, want %d, %d", inserted, deleted, tt.inserted, tt.deleted)
+			}
+		})
+	}
+}
+
+// checkRoundTrip verifies that the segments rebuild both texts: equal and
+// deleted words give the old one, equal and inserted words the new one.
+func checkRoundTrip(t *testing.T, segments []Segment, a, b string) {
+	t.Helper()
+	var old, updated []string
+	for _, s := range segments {
+		if s.Op != Insert {
+			old = append(old, s.Text)
+		}
+		if s.Op != Delete {
+			updated = append(updated, s.Text)
+		}
+	}
+	if got, want := strings.Join(old, " "), strings.Join(strings.Fields(a), " "); got != want {
+		t.Errorf("old text rebuilt as %q, want %q", got, want)
+	}
+	if got, want := strings.Join(updated, " "), strings.Join(strings.Fields(b), " "); got != want {
+		t.Errorf("new text rebuilt as %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,28 @@
+package tiptap
+
+import "github.com/pdhoang91/blog/pkg/highlight"
+
+// CodeTokens returns the highlighted code of each code block, in document
+// order, for renderers to color it without a highlighter of their own.
+// Blocks in languages the highlighter does not know have nil tokens, and a
+// document without highlighted blocks gets nil. The document is left as it
+// is: tokens are for display only, and never part of saved content.
+func (n *Node) CodeTokens() [][]highlight.Token {
+	var blocks [][]highlight.Token
+	highlighted := false
+	n.Walk(func(node *Node) {
+		if node.Type != "codeBlock" {
+			return
+		}
+		tokens := highlight.Tokenize(node.attrString("language"), textOf(node))
+		if len(tokens) == 0 {
+			tokens = nil
+		}
+		blocks = append(blocks, tokens)
+		highlighted = highlighted || tokens != nil
+	})
+	if !highlighted {
+		return nil
+	}
+	return blocks
+}
@@ -0,0 +1,65 @@
+package tiptap
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/pdhoang91/blog/pkg/highlight"
+)
+
+func TestCodeTokens(t *testing.T) {
+	content := json.RawMessage(`{"type":"doc","content":[
+		{"type":"codeBlock","attrs":{"language":"go"},"content":[{"type":"text","text":"return nil"}]},
+		{"type":"codeBlock","attrs":{"language":"cobol"},"content":[{"type":"text","text":"STOP RUN."}]},
+		{"type":"blockquote","content":[
+			{"type":"codeBlock","attrs":{"language":"sh"},"content":[{"type":"text","text":"echo $HOME"}]}
+		]}
+	]}`)
+	doc, err := Parse(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	before, err := doc.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := [][]highlight.Token{
+		{{Kind: highlight.Keyword, Text: "return"}, {Kind: highlight.Plain, Text: " "}, {Kind: highlight.Literal, Text: "nil"}},
+		nil,
+		{{Kind: highlight.BuiltIn, Text: "echo"}, {Kind: highlight.Plain, Text: " "}, {Kind: highlight.Variable, Text: "$HOME"}},
+	}
+	if got := doc.CodeTokens(); !reflect.DeepEqual(got, want) {
+		t.Errorf("CodeTokens() = %v, want %v", got, want)
+	}
+
+	// The tokens stay out of the document, which must still save as it is.
+	after, err := doc.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(before, after) {
+		t.Errorf("CodeTokens changed the document:\n%s\nto\n%s", before, after)
+	}
+	if err := Validate(after, DefaultLimits); err != nil {
+		t.Errorf("the document no longer validates: %v", err)
+	}
+}
+
+func TestCodeTokensWithoutHighlightedCode(t *testing.T) {
+	for _, content := range []string{
+		`{"type":"doc","content":[{"type":"paragraph","content":[{"type":"text","text":"return nil"}]}]}`,
+		`{"type":"doc","content":[{"type":"codeBlock","attrs":{"language":"cobol"},"content":[{"type":"text","text":"STOP RUN."}]}]}`,
+		`{"type":"doc","content":[{"type":"codeBlock","attrs":{"language":"go"}}]}`,
+	} {
+		doc, err := Parse(json.RawMessage(content))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := doc.CodeTokens(); got != nil {
+			t.Errorf("CodeTokens() = %v for %s, want nil", got, content)
+		}
+	}
+}
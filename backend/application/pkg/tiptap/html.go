@@ -0,0 +1,378 @@
+package tiptap
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+var (
+	reSpaces       = regexp.MustCompile(`[ \t\n\r\f]+`)
+	reCodeLanguage = regexp.MustCompile(`(?:^|\s)(?:language|lang|brush)[-:]\s*([A-Za-z0-9_+#-]+)`)
+	reYouTubeEmbed = regexp.MustCompile(`^https://(?:www\.)?youtube(?:-nocookie)?\.com/embed/[A-Za-z0-9_-]+`)
+)
+
+// droppedElements are removed with everything inside them.
+var droppedElements = map[string]bool{
+	"script": true, "style": true, "noscript": true, "template": true, "head": true,
+	"title": true, "meta": true, "link": true, "object": true, "embed": true,
+	"form": true, "input": true, "button": true, "select": true, "textarea": true,
+	"svg": true, "math": true, "canvas": true, "audio": true, "video": true,
+}
+
+// transparentBlocks only group other blocks; their content is kept.
+var transparentBlocks = map[string]bool{
+	"div": true, "section": true, "article": true, "header": true, "footer": true,
+	"main": true, "aside": true, "nav": true, "center": true, "details": true,
+	"summary": true, "dl": true, "dt": true, "dd": true, "address": true,
+	"html": true, "body": true, "tbody": true, "thead": true, "tfoot": true,
+}
+
+var blockElements = map[string]bool{
+	"p": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"ul": true, "ol": true, "li": true, "blockquote": true, "pre": true, "hr": true,
+	"figure": true, "table": true, "tr": true, "iframe": true,
+}
+
+var inlineMarks = map[string]string{
+	"strong": "bold", "b": "bold",
+	"em": "italic", "i": "italic", "cite": "italic",
+	"u": "underline", "ins": "underline",
+	"s": "strike", "strike": "strike", "del": "strike",
+	"code": "code", "kbd": "code", "samp": "code", "tt": "code",
+	"sub": "subscript", "sup": "superscript",
+	"mark": "highlight",
+}
+
+// FromHTML converts HTML into a document. Only what the editor can show is
+// kept: scripts, styles, forms and attributes like event handlers disappear,
+// unknown elements are replaced by their content and unsafe URLs are dropped.
+func FromHTML(src string) *Node {
+	context := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+	nodes, err := html.ParseFragment(strings.NewReader(src), context)
+	if err != nil {
+		// The tokenizer only fails on read errors, which a string cannot have.
+		return Doc(nil)
+	}
+	return Doc(htmlBlocks(nodes))
+}
+
+func htmlChildren(n *html.Node) []*html.Node {
+	var children []*html.Node
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		children = append(children, c)
+	}
+	return children
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func isBlockHTML(n *html.Node) bool {
+	return n.Type == html.ElementNode && (blockElements[n.Data] || transparentBlocks[n.Data])
+}
+
+// htmlBlocks converts a sequence of nodes into blocks, gathering loose inline
+// content into paragraphs.
+func htmlBlocks(nodes []*html.Node) []*Node {
+	var blocks []*Node
+	var run []*Node
+	flush := func() {
+		blocks = append(blocks, splitImages(cleanInline(run))...)
+		run = nil
+	}
+	for _, n := range nodes {
+		if n.Type == html.ElementNode && droppedElements[n.Data] {
+			continue
+		}
+		if !isBlockHTML(n) {
+			run = append(run, htmlInline(n, nil, false)...)
+			continue
+		}
+		flush()
+		blocks = append(blocks, htmlBlock(n)...)
+	}
+	flush()
+	return blocks
+}
+
+func htmlBlock(n *html.Node) []*Node {
+	switch n.Data {
+	case "p":
+		return splitImages(cleanInline(htmlInlineChildren(n, nil, false)))
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		level, _ := strconv.Atoi(n.Data[1:])
+		var content []*Node
+		for _, node := range cleanInline(htmlInlineChildren(n, nil, false)) {
+			if node.Type != "image" {
+				content = append(content, node)
+			}
+		}
+		return []*Node{heading(level, content)}
+	case "ul", "ol":
+		return []*Node{htmlList(n)}
+	case "li":
+		return htmlBlocks(htmlChildren(n))
+	case "blockquote":
+		return []*Node{{Type: "blockquote", Content: orParagraph(htmlBlocks(htmlChildren(n)))}}
+	case "pre":
+		return []*Node{htmlCodeBlock(n)}
+	case "hr":
+		return []*Node{{Type: "horizontalRule"}}
+	case "figure":
+		if img := findElement(n, "img"); img != nil {
+			src := safeImageURL(attr(img, "src"))
+			if src == "" {
+				return nil
+			}
+			caption := ""
+			if fc := findElement(n, "figcaption"); fc != nil {
+				caption = strings.TrimSpace(reSpaces.ReplaceAllString(textContent(fc), " "))
+			}
+			return []*Node{imageBlock(src, attr(img, "alt"), attr(img, "title"), caption)}
+		}
+		return htmlBlocks(htmlChildren(n))
+	case "table":
+		return htmlTable(n)
+	case "tr":
+		return htmlBlocks(htmlChildren(n))
+	case "iframe":
+		if src := attr(n, "src"); reYouTubeEmbed.MatchString(src) {
+			return []*Node{{Type: "youtube", Attrs: map[string]interface{}{"src": src}}}
+		}
+		return nil
+	}
+	return htmlBlocks(htmlChildren(n))
+}
+
+func htmlList(n *html.Node) *Node {
+	list := &Node{Type: "bulletList"}
+	if n.Data == "ol" {
+		list.Type = "orderedList"
+		start := 1
+		if s, err := strconv.Atoi(attr(n, "start")); err == nil {
+			start = s
+		}
+		list.Attrs = map[string]interface{}{"start": start}
+	}
+	for _, c := range htmlChildren(n) {
+		if c.Type != html.ElementNode {
+			continue
+		}
+		var content []*Node
+		if c.Data == "li" {
+			content = htmlBlocks(htmlChildren(c))
+		} else {
+			// Nested lists written directly inside the list.
+			content = htmlBlocks([]*html.Node{c})
+		}
+		list.Content = append(list.Content, &Node{Type: "listItem", Content: leadingParagraph(content)})
+	}
+	if len(list.Content) == 0 {
+		list.Content = []*Node{{Type: "listItem", Content: []*Node{paragraph(nil)}}}
+	}
+	return list
+}
+
+func htmlCodeBlock(n *html.Node) *Node {
+	language := ""
+	classes := attr(n, "class")
+	if code := findElement(n, "code"); code != nil {
+		classes += " " + attr(code, "class")
+	}
+	if m := reCodeLanguage.FindStringSubmatch(classes); m != nil {
+		language = strings.ToLower(m[1])
+	}
+	return codeBlock(language, strings.TrimSuffix(strings.TrimPrefix(textContent(n), "\n"), "\n"))
+}
+
+func htmlTable(n *html.Node) []*Node {
+	var rows []*html.Node
+	var collect func(*html.Node)
+	collect = func(n *html.Node) {
+		for _, c := range htmlChildren(n) {
+			if c.Type != html.ElementNode {
+				continue
+			}
+			switch c.Data {
+			case "tr":
+				rows = append(rows, c)
+			case "thead", "tbody", "tfoot":
+				collect(c)
+			}
+		}
+	}
+	collect(n)
+
+	table := &Node{Type: "table"}
+	for _, r := range rows {
+		row := &Node{Type: "tableRow"}
+		for _, c := range htmlChildren(r) {
+			if c.Type != html.ElementNode || (c.Data != "td" && c.Data != "th") {
+				continue
+			}
+			cellType := "tableCell"
+			if c.Data == "th" {
+				cellType = "tableHeader"
+			}
+			attrs := map[string]interface{}{"colspan": 1, "rowspan": 1, "colwidth": nil}
+			if span, err := strconv.Atoi(attr(c, "colspan")); err == nil && span > 1 {
+				attrs["colspan"] = span
+			}
+			if span, err := strconv.Atoi(attr(c, "rowspan")); err == nil && span > 1 {
+				attrs["rowspan"] = span
+			}
+			row.Content = append(row.Content, &Node{
+				Type:    cellType,
+				Attrs:   attrs,
+				Content: orParagraph(htmlBlocks(htmlChildren(c))),
+			})
+		}
+		if len(row.Content) > 0 {
+			table.Content = append(table.Content, row)
+		}
+	}
+	if len(table.Content) == 0 {
+		return nil
+	}
+	return []*Node{table}
+}
+
+func htmlInlineChildren(n *html.Node, marks []Mark, pre bool) []*Node {
+	var out []*Node
+	for _, c := range htmlChildren(n) {
+		out = append(out, htmlInline(c, marks, pre)...)
+	}
+	return out
+}
+
+// htmlInline converts inline content. Images come out as inline image nodes
+// for splitImages to turn into blocks.
+func htmlInline(n *html.Node, marks []Mark, pre bool) []*Node {
+	switch n.Type {
+	case html.TextNode:
+		text := n.Data
+		if !pre {
+			text = reSpaces.ReplaceAllString(text, " ")
+		}
+		if text == "" {
+			return nil
+		}
+		return []*Node{textNode(text, marks)}
+	case html.ElementNode:
+	default:
+		return nil
+	}
+
+	if droppedElements[n.Data] {
+		return nil
+	}
+	switch n.Data {
+	case "br":
+		return []*Node{{Type: "hardBreak"}}
+	case "img":
+		src := safeImageURL(attr(n, "src"))
+		if src == "" {
+			return nil
+		}
+		return []*Node{{Type: "image", Attrs: map[string]interface{}{
+			"src": src, "alt": attr(n, "alt"), "title": attr(n, "title"),
+		}}}
+	case "a":
+		if href := safeURL(attr(n, "href")); href != "" {
+			marks = withMark(marks, link(href, attr(n, "title")))
+		}
+	case "pre":
+		pre = true
+	default:
+		if mark, ok := inlineMarks[n.Data]; ok {
+			if mark == "code" {
+				return []*Node{{Type: "text", Text: textContent(n), Marks: []Mark{{Type: "code"}}}}
+			}
+			marks = withMark(marks, Mark{Type: mark})
+		}
+	}
+	nodes := htmlInlineChildren(n, marks, pre)
+	if isBlockHTML(n) {
+		// A block nested in inline content still reads as a separate line.
+		nodes = append([]*Node{{Type: "hardBreak"}}, nodes...)
+	}
+	return nodes
+}
+
+// cleanInline collapses the whitespace left between inline nodes and trims
+// the edges of a paragraph.
+func cleanInline(nodes []*Node) []*Node {
+	var out []*Node
+	lastSpace := true
+	for _, node := range nodes {
+		switch node.Type {
+		case "text":
+			if isCode(node) {
+				lastSpace = false
+				break
+			}
+			if lastSpace {
+				node.Text = strings.TrimLeft(node.Text, " ")
+			}
+			if node.Text == "" {
+				continue
+			}
+			lastSpace = strings.HasSuffix(node.Text, " ")
+		case "hardBreak":
+			if n := len(out); n > 0 && out[n-1].Type == "text" && !isCode(out[n-1]) {
+				out[n-1].Text = strings.TrimRight(out[n-1].Text, " ")
+			}
+			lastSpace = true
+		default:
+			lastSpace = true
+		}
+		out = append(out, node)
+	}
+	return trimBreaks(out)
+}
+
+func isCode(node *Node) bool {
+	return len(node.Marks) > 0 && node.Marks[0].Type == "code"
+}
+
+func findElement(n *html.Node, tag string) *html.Node {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && c.Data == tag {
+			return c
+		}
+		if found := findElement(c, tag); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+func textContent(n *html.Node) string {
+	var b strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		switch {
+		case n.Type == html.TextNode:
+			b.WriteString(n.Data)
+		case n.Type == html.ElementNode && n.Data == "br":
+			b.WriteByte('\n')
+		case n.Type == html.ElementNode && droppedElements[n.Data]:
+		default:
+			for c := n.FirstChild; c != nil; c = c.NextSibling {
+				walk(c)
+			}
+		}
+	}
+	walk(n)
+	return b.String()
+}
@@ -0,0 +1,585 @@
+package tiptap
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	reATXHeading   = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	reThematic     = regexp.MustCompile(`^ {0,3}(?:(?:-[ \t]*){3,}|(?:\*[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	reBullet       = regexp.MustCompile(`^( {0,3})([-*+])([ \t]+|$)(.*)$`)
+	reOrdered      = regexp.MustCompile(`^( {0,3})(\d{1,9})([.)])([ \t]+|$)(.*)$`)
+	reBlockquote   = regexp.MustCompile(`^ {0,3}> ?(.*)$`)
+	reSetext1      = regexp.MustCompile(`^ {0,3}=+[ \t]*$`)
+	reSetext2      = regexp.MustCompile(`^ {0,3}-+[ \t]*$`)
+	reTableDelim   = regexp.MustCompile(`^[ \t]*\|?[ \t]*:?-+:?[ \t]*(?:\|[ \t]*:?-+:?[ \t]*)*\|?[ \t]*$`)
+	reTaskMarker   = regexp.MustCompile(`^\[([ xX])\](?:[ \t]+|$)(.*)$`)
+	reHTMLBlock    = regexp.MustCompile(`^ {0,3}(?:<!--|</?(?i:address|article|aside|blockquote|details|div|dl|figure|footer|h[1-6]|header|hr|iframe|ol|p|pre|section|table|ul|video)(?:[\s/>]|$))`)
+	reRefDef       = regexp.MustCompile(`^ {0,3}\[([^\]]+)\]:[ \t]*<?([^\s>]+)>?(?:[ \t]+(?:"([^"]*)"|'([^']*)'|\(([^)]*)\)))?[ \t]*$`)
+	reAutolinkURL  = regexp.MustCompile(`^<((?i:https?|mailto|ftp):[^<>\s]*)>`)
+	reAutolinkMail = regexp.MustCompile(`^<([a-zA-Z0-9.!#$%&'*+/=?^_{|}~-]+@[a-zA-Z0-9](?:[a-zA-Z0-9-]*[a-zA-Z0-9])?(?:\.[a-zA-Z0-9](?:[a-zA-Z0-9-]*[a-zA-Z0-9])?)*)>`)
+	reBareURL      = regexp.MustCompile(`^(?:https?://|www\.)[^\s<]*[^\s<?!.,:*_~)'"]`)
+	reInlineTag    = regexp.MustCompile(`^(?:<!--[\s\S]*?-->|</?[a-zA-Z][a-zA-Z0-9-]*(?:\s[^<>]*)?/?>)`)
+	reLineBreakTag = regexp.MustCompile(`^<(?i:br)\s*/?>`)
+	reEntity       = regexp.MustCompile(`^&(?:#[0-9]{1,7}|#[xX][0-9a-fA-F]{1,6}|[a-zA-Z][a-zA-Z0-9]{1,31});`)
+)
+
+type mdRef struct {
+	href, title string
+}
+
+// maxNesting bounds how deep quotes, list items and link labels nest. Every
+// level parses its text again, so anything deeper is kept as plain text.
+const maxNesting = 16
+
+type mdParser struct {
+	refs  map[string]mdRef
+	depth int // quotes and list items around the lines being parsed
+}
+
+// FromMarkdown converts CommonMark, with the GitHub extensions people use most
+// (tables, task lists, strikethrough and bare links), into a document. Raw
+// HTML blocks go through FromHTML and unsafe link targets are dropped.
+func FromMarkdown(src string) *Node {
+	p := &mdParser{refs: map[string]mdRef{}}
+	lines := p.collectRefs(splitLines(src))
+	return Doc(p.blocks(lines))
+}
+
+// splitLines normalizes line endings and expands tabs to four-column stops.
+func splitLines(src string) []string {
+	src = strings.ReplaceAll(src, "\r\n", "\n")
+	src = strings.ReplaceAll(src, "\r", "\n")
+	lines := strings.Split(src, "\n")
+	for i, line := range lines {
+		if !strings.Contains(line, "\t") {
+			continue
+		}
+		var b strings.Builder
+		col := 0
+		for _, r := range line {
+			if r == '\t' {
+				n := 4 - col%4
+				b.WriteString(strings.Repeat(" ", n))
+				col += n
+				continue
+			}
+			b.WriteRune(r)
+			col++
+		}
+		lines[i] = b.String()
+	}
+	return lines
+}
+
+// collectRefs takes link reference definitions out of the text, leaving code
+// blocks alone.
+func (p *mdParser) collectRefs(lines []string) []string {
+	out := lines[:0:0]
+	var fence string
+	for _, line := range lines {
+		if fence != "" {
+			if isFenceClose(line, fence) {
+				fence = ""
+			}
+			out = append(out, line)
+			continue
+		}
+		if f, _, ok := fenceOpen(line); ok {
+			fence = f
+			out = append(out, line)
+			continue
+		}
+		if m := reRefDef.FindStringSubmatch(line); m != nil && indentOf(line) < 4 {
+			key := refKey(m[1])
+			if _, seen := p.refs[key]; !seen {
+				p.refs[key] = mdRef{href: m[2], title: m[3] + m[4] + m[5]}
+			}
+			continue
+		}
+		out = append(out, line)
+	}
+	return out
+}
+
+func refKey(label string) string {
+	return strings.ToLower(strings.Join(strings.Fields(label), " "))
+}
+
+func isBlank(line string) bool {
+	return strings.TrimSpace(line) == ""
+}
+
+func indentOf(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+// fenceOpen reports whether line opens a fenced code block, returning the
+// fence and the info string.
+func fenceOpen(line string) (fence, info string, ok bool) {
+	if indentOf(line) > 3 {
+		return "", "", false
+	}
+	rest := strings.TrimLeft(line, " ")
+	if rest == "" || (rest[0] != '`' && rest[0] != '~') {
+		return "", "", false
+	}
+	n := len(rest) - len(strings.TrimLeft(rest, rest[:1]))
+	if n < 3 {
+		return "", "", false
+	}
+	info = strings.TrimSpace(rest[n:])
+	if rest[0] == '`' && strings.Contains(info, "`") {
+		return "", "", false
+	}
+	return rest[:n], info, true
+}
+
+func isFenceClose(line, fence string) bool {
+	if indentOf(line) > 3 {
+		return false
+	}
+	rest := strings.TrimSpace(line)
+	return strings.HasPrefix(rest, fence) && strings.Trim(rest, fence[:1]) == ""
+}
+
+// interrupts reports whether line starts a block that ends a paragraph.
+func interrupts(line string) bool {
+	if reATXHeading.MatchString(line) || reThematic.MatchString(line) ||
+		reBlockquote.MatchString(line) || reHTMLBlock.MatchString(line) {
+		return true
+	}
+	if _, _, ok := fenceOpen(line); ok {
+		return true
+	}
+	if m := reBullet.FindStringSubmatch(line); m != nil && strings.TrimSpace(m[4]) != "" {
+		return true
+	}
+	if m := reOrdered.FindStringSubmatch(line); m != nil && m[2] == "1" && strings.TrimSpace(m[5]) != "" {
+		return true
+	}
+	return false
+}
+
+func (p *mdParser) blocks(lines []string) []*Node {
+	var out []*Node
+	for i := 0; i < len(lines); {
+		if isBlank(lines[i]) {
+			i++
+			continue
+		}
+		nodes, n := p.block(lines[i:])
+		out = append(out, nodes...)
+		i += n
+	}
+	return out
+}
+
+// block parses the block starting at lines[0], returning its nodes and the
+// number of lines it took.
+func (p *mdParser) block(lines []string) ([]*Node, int) {
+	line := lines[0]
+	if fence, info, ok := fenceOpen(line); ok {
+		return p.fencedCode(lines, fence, info)
+	}
+	if indentOf(line) >= 4 {
+		return p.indentedCode(lines)
+	}
+	if m := reATXHeading.FindStringSubmatch(line); m != nil {
+		return []*Node{heading(len(m[1]), p.inline(strings.TrimSpace(m[2]), nil))}, 1
+	}
+	if reThematic.MatchString(line) {
+		return []*Node{{Type: "horizontalRule"}}, 1
+	}
+	if reBlockquote.MatchString(line) {
+		return p.blockquote(lines)
+	}
+	if _, ok := listMarker(line); ok {
+		return p.list(lines)
+	}
+	if reHTMLBlock.MatchString(line) {
+		return p.htmlBlock(lines)
+	}
+	if len(lines) > 1 && strings.Contains(line, "|") && reTableDelim.MatchString(lines[1]) {
+		if nodes, n := p.table(lines); n > 0 {
+			return nodes, n
+		}
+	}
+	return p.paragraph(lines)
+}
+
+func (p *mdParser) fencedCode(lines []string, fence, info string) ([]*Node, int) {
+	indent := indentOf(lines[0])
+	var code []string
+	i := 1
+	for ; i < len(lines); i++ {
+		if isFenceClose(lines[i], fence) {
+			i++
+			break
+		}
+		line := lines[i]
+		strip := indentOf(line)
+		if strip > indent {
+			strip = indent
+		}
+		code = append(code, line[strip:])
+	}
+	language := ""
+	if fields := strings.Fields(info); len(fields) > 0 {
+		language = strings.TrimPrefix(fields[0], "language-")
+	}
+	return []*Node{codeBlock(language, strings.Join(code, "\n"))}, i
+}
+
+func (p *mdParser) indentedCode(lines []string) ([]*Node, int) {
+	var code []string
+	i := 0
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if isBlank(line) {
+			code = append(code, "")
+			continue
+		}
+		if indentOf(line) < 4 {
+			break
+		}
+		code = append(code, line[4:])
+	}
+	for len(code) > 0 && code[len(code)-1] == "" {
+		code = code[:len(code)-1]
+	}
+	return []*Node{codeBlock("", strings.Join(code, "\n"))}, i
+}
+
+func (p *mdParser) blockquote(lines []string) ([]*Node, int) {
+	var inner []string
+	i := 0
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if m := reBlockquote.FindStringSubmatch(line); m != nil {
+			inner = append(inner, m[1])
+			continue
+		}
+		// Lazy continuation of a paragraph inside the quote.
+		if !isBlank(line) && len(inner) > 0 && !isBlank(inner[len(inner)-1]) && !interrupts(line) {
+			inner = append(inner, line)
+			continue
+		}
+		break
+	}
+	return []*Node{{Type: "blockquote", Content: orParagraph(p.nested(inner))}}, i
+}
+
+// nested parses the lines inside a quote or list item. Past maxNesting they
+// become the text of a single paragraph.
+func (p *mdParser) nested(lines []string) []*Node {
+	if p.depth >= maxNesting {
+		var text []string
+		for _, line := range lines {
+			if !isBlank(line) {
+				text = append(text, strings.TrimSpace(line))
+			}
+		}
+		return splitImages(p.inline(strings.Join(text, "\n"), nil))
+	}
+	p.depth++
+	defer func() { p.depth-- }()
+	return p.blocks(lines)
+}
+
+// htmlBlock hands raw HTML, up to the next blank line, to the HTML converter.
+func (p *mdParser) htmlBlock(lines []string) ([]*Node, int) {
+	i := 0
+	for ; i < len(lines) && !isBlank(lines[i]); i++ {
+	}
+	if strings.HasPrefix(strings.TrimSpace(lines[0]), "<!--") {
+		// Comments may span blank lines.
+		for j := 0; j < len(lines); j++ {
+			if strings.Contains(lines[j], "-->") {
+				if j+1 > i {
+					i = j + 1
+				}
+				break
+			}
+		}
+	}
+	doc := FromHTML(strings.Join(lines[:i], "\n"))
+	if len(doc.Content) == 1 && doc.Content[0].Type == "paragraph" && len(doc.Content[0].Content) == 0 {
+		return nil, i
+	}
+	return doc.Content, i
+}
+
+type mdListMarker struct {
+	ordered bool
+	delim   byte // '-', '*', '+' or '.', ')'
+	start   int
+	width   int // columns before the item's content
+	content string
+}
+
+func listMarker(line string) (mdListMarker, bool) {
+	if reThematic.MatchString(line) {
+		return mdListMarker{}, false
+	}
+	if m := reBullet.FindStringSubmatch(line); m != nil {
+		width := len(m[1]) + 1 + len(m[3])
+		if len(m[3]) > 4 || m[3] == "" {
+			width = len(m[1]) + 2
+		}
+		return mdListMarker{delim: m[2][0], width: width, content: m[4]}, true
+	}
+	if m := reOrdered.FindStringSubmatch(line); m != nil {
+		start, _ := strconv.Atoi(m[2])
+		width := len(m[1]) + len(m[2]) + 1 + len(m[4])
+		if len(m[4]) > 4 || m[4] == "" {
+			width = len(m[1]) + len(m[2]) + 2
+		}
+		return mdListMarker{ordered: true, delim: m[3][0], start: start, width: width, content: m[5]}, true
+	}
+	return mdListMarker{}, false
+}
+
+type mdListItem struct {
+	width int
+	lines []string
+}
+
+func (p *mdParser) list(lines []string) ([]*Node, int) {
+	first, _ := listMarker(lines[0])
+	var items []mdListItem
+	i := 0
+	for i < len(lines) {
+		line := lines[i]
+		if isBlank(line) {
+			if len(items) == 0 {
+				break
+			}
+			// A blank line ends the list unless what follows still belongs to it.
+			j := i + 1
+			for j < len(lines) && isBlank(lines[j]) {
+				j++
+			}
+			cur := &items[len(items)-1]
+			if j == len(lines) {
+				break
+			}
+			next, isItem := listMarker(lines[j])
+			if indentOf(lines[j]) >= cur.width || (isItem && next.ordered == first.ordered && next.delim == first.delim) {
+				for ; i < j; i++ {
+					cur.lines = append(cur.lines, "")
+				}
+				continue
+			}
+			break
+		}
+
+		if m, ok := listMarker(line); ok && (len(items) == 0 || indentOf(line) < items[len(items)-1].width) {
+			if m.ordered != first.ordered || m.delim != first.delim {
+				break
+			}
+			items = append(items, mdListItem{width: m.width, lines: []string{m.content}})
+			i++
+			continue
+		}
+
+		cur := &items[len(items)-1]
+		if indentOf(line) >= cur.width {
+			cur.lines = append(cur.lines, line[cur.width:])
+			i++
+			continue
+		}
+		// Lazy continuation of the item's last paragraph.
+		if last := cur.lines[len(cur.lines)-1]; !isBlank(last) && !interrupts(line) {
+			cur.lines = append(cur.lines, strings.TrimLeft(line, " "))
+			i++
+			continue
+		}
+		break
+	}
+
+	tasks := !first.ordered
+	checked := make([]bool, len(items))
+	for k, item := range items {
+		m := reTaskMarker.FindStringSubmatch(item.lines[0])
+		if m == nil {
+			tasks = false
+			break
+		}
+		checked[k] = m[1] != " "
+	}
+
+	list := &Node{Type: "bulletList"}
+	itemType := "listItem"
+	switch {
+	case tasks:
+		list.Type, itemType = "taskList", "taskItem"
+	case first.ordered:
+		list.Type = "orderedList"
+		list.Attrs = map[string]interface{}{"start": first.start}
+	}
+	for k, item := range items {
+		itemLines := item.lines
+		if tasks {
+			itemLines = append([]string{reTaskMarker.FindStringSubmatch(itemLines[0])[2]}, itemLines[1:]...)
+		}
+		node := &Node{Type: itemType, Content: leadingParagraph(p.nested(itemLines))}
+		if tasks {
+			node.Attrs = map[string]interface{}{"checked": checked[k]}
+		}
+		list.Content = append(list.Content, node)
+	}
+	return []*Node{list}, i
+}
+
+// leadingParagraph makes sure blocks start with a paragraph, which list items
+// require.
+func leadingParagraph(blocks []*Node) []*Node {
+	if len(blocks) == 0 || blocks[0].Type != "paragraph" {
+		return append([]*Node{paragraph(nil)}, blocks...)
+	}
+	return blocks
+}
+
+func orParagraph(blocks []*Node) []*Node {
+	if len(blocks) == 0 {
+		return []*Node{paragraph(nil)}
+	}
+	return blocks
+}
+
+// splitRow splits a table row into trimmed cells, honouring escaped pipes.
+func splitRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, `\|`) {
+		line = line[:len(line)-1]
+	}
+	var cells []string
+	var cell strings.Builder
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && i+1 < len(line) && line[i+1] == '|':
+			cell.WriteByte('|')
+			i++
+		case line[i] == '|':
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+		default:
+			cell.WriteByte(line[i])
+		}
+	}
+	return append(cells, strings.TrimSpace(cell.String()))
+}
+
+func (p *mdParser) table(lines []string) ([]*Node, int) {
+	header := splitRow(lines[0])
+	delims := splitRow(lines[1])
+	if len(header) != len(delims) {
+		return nil, 0
+	}
+	aligns := make([]string, len(delims))
+	for k, d := range delims {
+		left, right := strings.HasPrefix(d, ":"), strings.HasSuffix(d, ":")
+		switch {
+		case left && right:
+			aligns[k] = "center"
+		case right:
+			aligns[k] = "right"
+		}
+	}
+
+	row := func(cells []string, cellType string) *Node {
+		node := &Node{Type: "tableRow"}
+		for k := range header {
+			text := ""
+			if k < len(cells) {
+				text = cells[k]
+			}
+			para := paragraph(p.inline(text, nil))
+			if aligns[k] != "" {
+				para.Attrs = map[string]interface{}{"textAlign": aligns[k]}
+			}
+			node.Content = append(node.Content, &Node{
+				Type:    cellType,
+				Attrs:   map[string]interface{}{"colspan": 1, "rowspan": 1, "colwidth": nil},
+				Content: []*Node{para},
+			})
+		}
+		return node
+	}
+
+	table := &Node{Type: "table", Content: []*Node{row(header, "tableHeader")}}
+	i := 2
+	for ; i < len(lines); i++ {
+		if isBlank(lines[i]) || interrupts(lines[i]) {
+			break
+		}
+		table.Content = append(table.Content, row(splitRow(lines[i]), "tableCell"))
+	}
+	return []*Node{table}, i
+}
+
+func (p *mdParser) paragraph(lines []string) ([]*Node, int) {
+	text := []string{strings.TrimLeft(lines[0], " ")}
+	i := 1
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if reSetext1.MatchString(line) {
+			return []*Node{heading(1, p.inline(strings.Join(text, "\n"), nil))}, i + 1
+		}
+		if reSetext2.MatchString(line) {
+			return []*Node{heading(2, p.inline(strings.Join(text, "\n"), nil))}, i + 1
+		}
+		if isBlank(line) || interrupts(line) {
+			break
+		}
+		text = append(text, strings.TrimLeft(line, " "))
+	}
+	joined := strings.TrimRight(strings.Join(text, "\n"), " ")
+	return splitImages(p.inline(joined, nil)), i
+}
+
+// splitImages turns inline content into blocks: paragraphs, with each image
+// pulled out into an image block of its own as the editor has no inline images.
+func splitImages(inline []*Node) []*Node {
+	var blocks []*Node
+	var run []*Node
+	flush := func() {
+		if len(run) > 0 && strings.TrimSpace((&Node{Content: run}).PlainText()) != "" {
+			blocks = append(blocks, paragraph(trimBreaks(run)))
+		}
+		run = nil
+	}
+	for _, node := range inline {
+		if node.Type != "image" {
+			run = append(run, node)
+			continue
+		}
+		flush()
+		attrs := node.Attrs
+		blocks = append(blocks, imageBlock(attrs["src"].(string), attrs["alt"].(string), attrs["title"].(string), ""))
+	}
+	flush()
+	return blocks
+}
+
+// trimBreaks drops hard breaks and spaces left at the edges of a paragraph
+// after images were taken out of it.
+func trimBreaks(nodes []*Node) []*Node {
+	for len(nodes) > 0 && nodes[0].Type == "hardBreak" {
+		nodes = nodes[1:]
+	}
+	for len(nodes) > 0 && nodes[len(nodes)-1].Type == "hardBreak" {
+		nodes = nodes[:len(nodes)-1]
+	}
+	if len(nodes) > 0 && nodes[0].Type == "text" {
+		nodes[0].Text = strings.TrimLeft(nodes[0].Text, " ")
+	}
+	if last := len(nodes) - 1; last >= 0 && nodes[last].Type == "text" {
+		nodes[last].Text = strings.TrimRight(nodes[last].Text, " ")
+	}
+	return mergeText(nodes)
+}
@@ -0,0 +1,645 @@
+package tiptap
+
+import (
+	"bytes"
+	"html"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// inlineToken is either finished nodes or a run of emphasis delimiters still
+// waiting for a partner.
+type inlineToken struct {
+	nodes []*Node
+
+	delim    byte
+	count    int // delimiters left unmatched
+	orig     int
+	canOpen  bool
+	canClose bool
+}
+
+type emphasisMatch struct {
+	open, close int
+	mark        string
+}
+
+type inlineParser struct {
+	p      *mdParser
+	src    string
+	marks  []Mark
+	depth  int // link labels around src
+	tokens []inlineToken
+	text   bytes.Buffer
+
+	// Lookups remembered so that no part of src is scanned over and over.
+	brackets     map[int]int          // '[' index to its ']', see bracketEnd
+	found        map[byte]indexResult // see indexFrom
+	noCodeClose  map[int]int          // backtick run length to where no closing run follows
+	noCommentEnd bool                 // no "-->" follows the last "<!--"
+}
+
+type indexResult struct {
+	from, index int
+}
+
+// maxLinkParens bounds the parentheses nesting in a link destination.
+const maxLinkParens = 32
+
+// maxLabelLength is the longest link label that can match a reference.
+const maxLabelLength = 999
+
+// inline parses Markdown inline content. marks apply to every node produced,
+// as when parsing the text of a link.
+func (p *mdParser) inline(src string, marks []Mark) []*Node {
+	ip := &inlineParser{p: p, src: src, marks: marks}
+	ip.parse()
+	return ip.nodes()
+}
+
+// label parses the text of a link or image. Labels nested past maxNesting
+// are kept as they are.
+func (ip *inlineParser) label(src string, marks []Mark) []*Node {
+	if ip.depth >= maxNesting {
+		return []*Node{textNode(src, marks)}
+	}
+	sub := &inlineParser{p: ip.p, src: src, marks: marks, depth: ip.depth + 1}
+	sub.parse()
+	return sub.nodes()
+}
+
+func (ip *inlineParser) flush() {
+	if ip.text.Len() == 0 {
+		return
+	}
+	ip.tokens = append(ip.tokens, inlineToken{nodes: []*Node{textNode(ip.text.String(), ip.marks)}})
+	ip.text.Reset()
+}
+
+func (ip *inlineParser) emit(nodes ...*Node) {
+	ip.flush()
+	ip.tokens = append(ip.tokens, inlineToken{nodes: nodes})
+}
+
+func (ip *inlineParser) parse() {
+	src := ip.src
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '\\' && i+1 < len(src):
+			switch {
+			case src[i+1] == '\n':
+				ip.emit(&Node{Type: "hardBreak"})
+				i = skipSpaces(src, i+2)
+			case isASCIIPunct(src[i+1]):
+				ip.text.WriteByte(src[i+1])
+				i += 2
+			default:
+				ip.text.WriteByte(c)
+				i++
+			}
+
+		case c == '\n':
+			line := ip.text.Bytes()
+			trimmed := bytes.TrimRight(line, " ")
+			hard := len(line)-len(trimmed) >= 2
+			ip.text.Truncate(len(trimmed))
+			if hard {
+				ip.emit(&Node{Type: "hardBreak"})
+			} else {
+				ip.text.WriteByte(' ')
+			}
+			i = skipSpaces(src, i+1)
+
+		case c == '`':
+			i = ip.codeSpan(i)
+
+		case c == '!' && i+1 < len(src) && src[i+1] == '[':
+			if end, ok := ip.linkOrImage(i+1, true); ok {
+				i = end
+			} else {
+				ip.text.WriteByte(c)
+				i++
+			}
+
+		case c == '[':
+			if end, ok := ip.linkOrImage(i, false); ok {
+				i = end
+			} else {
+				ip.text.WriteByte(c)
+				i++
+			}
+
+		case c == '<':
+			i = ip.angle(i)
+
+		case c == '*' || c == '_' || c == '~':
+			i = ip.delimiterRun(i)
+
+		case c == '&':
+			if m := reEntity.FindString(src[i:]); m != "" {
+				ip.text.WriteString(html.UnescapeString(m))
+				i += len(m)
+			} else {
+				ip.text.WriteByte(c)
+				i++
+			}
+
+		case (c == 'h' || c == 'w') && atWordStart(src, i):
+			if m := reBareURL.FindString(src[i:]); m != "" {
+				href := m
+				if strings.HasPrefix(m, "www.") {
+					href = "http://" + m
+				}
+				ip.emit(textNode(m, withMark(ip.marks, link(href, ""))))
+				i += len(m)
+			} else {
+				ip.text.WriteByte(c)
+				i++
+			}
+
+		default:
+			ip.text.WriteByte(c)
+			i++
+		}
+	}
+	ip.flush()
+}
+
+func skipSpaces(s string, i int) int {
+	for i < len(s) && s[i] == ' ' {
+		i++
+	}
+	return i
+}
+
+func isASCIIPunct(c byte) bool {
+	return strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", c) >= 0
+}
+
+func atWordStart(s string, i int) bool {
+	if i == 0 {
+		return true
+	}
+	r, _ := utf8.DecodeLastRuneInString(s[:i])
+	return unicode.IsSpace(r) || r == '(' || r == '*' || r == '_' || r == '~'
+}
+
+// codeSpan parses a code span opened by the backtick run at i. Code spans
+// carry only the code mark, which excludes all others in the editor.
+func (ip *inlineParser) codeSpan(i int) int {
+	src := ip.src
+	n := backtickRun(src, i)
+	if end := ip.codeSpanEnd(i+n, n); end >= 0 {
+		code := strings.ReplaceAll(src[i+n:end-n], "\n", " ")
+		if len(code) > 2 && code[0] == ' ' && code[len(code)-1] == ' ' && strings.TrimSpace(code) != "" {
+			code = code[1 : len(code)-1]
+		}
+		ip.emit(&Node{Type: "text", Text: code, Marks: []Mark{{Type: "code"}}})
+		return end
+	}
+	ip.text.WriteString(src[i : i+n])
+	return i + n
+}
+
+func backtickRun(s string, i int) int {
+	n := 0
+	for i+n < len(s) && s[i+n] == '`' {
+		n++
+	}
+	return n
+}
+
+// codeSpanEnd returns the index after the run of n backticks that closes a
+// code span whose text starts at from, or -1 if there is none.
+func (ip *inlineParser) codeSpanEnd(from, n int) int {
+	if at, ok := ip.noCodeClose[n]; ok && from >= at {
+		return -1
+	}
+	src := ip.src
+	for j := from; j < len(src); {
+		if src[j] != '`' {
+			j++
+			continue
+		}
+		m := backtickRun(src, j)
+		if m == n {
+			return j + m
+		}
+		j += m
+	}
+	if ip.noCodeClose == nil {
+		ip.noCodeClose = map[int]int{}
+	}
+	ip.noCodeClose[n] = from
+	return -1
+}
+
+// indexFrom returns the index of the first c at or after from, or -1.
+func (ip *inlineParser) indexFrom(c byte, from int) int {
+	if r, ok := ip.found[c]; ok && r.from <= from && (r.index < 0 || from <= r.index) {
+		return r.index
+	}
+	index := strings.IndexByte(ip.src[from:], c)
+	if index >= 0 {
+		index += from
+	}
+	if ip.found == nil {
+		ip.found = map[byte]indexResult{}
+	}
+	ip.found[c] = indexResult{from: from, index: index}
+	return index
+}
+
+// angle handles autolinks and inline HTML at i. Line breaks are kept, other
+// tags dropped.
+func (ip *inlineParser) angle(i int) int {
+	rest := ip.src[i:]
+	if m := reAutolinkURL.FindStringSubmatch(rest); m != nil {
+		if href := safeURL(m[1]); href != "" {
+			ip.emit(textNode(m[1], withMark(ip.marks, link(href, ""))))
+		} else {
+			ip.text.WriteString(m[1])
+		}
+		return i + len(m[0])
+	}
+	if m := reAutolinkMail.FindStringSubmatch(rest); m != nil {
+		ip.emit(textNode(m[1], withMark(ip.marks, link("mailto:"+m[1], ""))))
+		return i + len(m[0])
+	}
+	if m := reLineBreakTag.FindString(rest); m != "" {
+		ip.emit(&Node{Type: "hardBreak"})
+		return i + len(m)
+	}
+	comment := strings.HasPrefix(rest, "<!--")
+	if comment && !ip.noCommentEnd {
+		ip.noCommentEnd = !strings.Contains(rest[4:], "-->")
+	}
+	if !comment || !ip.noCommentEnd {
+		if m := reInlineTag.FindString(rest); m != "" {
+			return i + len(m)
+		}
+	}
+	ip.text.WriteByte('<')
+	return i + 1
+}
+
+// bracketEnd returns the index of the ']' closing the '[' at i, skipping
+// escapes, code spans and nested brackets, or -1. A scan settles every
+// bracket it passes, so later brackets are looked up instead of scanned.
+func (ip *inlineParser) bracketEnd(i int) int {
+	if end, ok := ip.brackets[i]; ok {
+		return end
+	}
+	if ip.brackets == nil {
+		ip.brackets = map[int]int{}
+	}
+	src := ip.src
+	var open []int
+	for j := i; j < len(src); j++ {
+		switch src[j] {
+		case '\\':
+			j++
+		case '`':
+			n := backtickRun(src, j)
+			if end := ip.codeSpanEnd(j+n, n); end >= 0 {
+				j = end - 1
+			} else {
+				j += n - 1
+			}
+		case '[':
+			if end, ok := ip.brackets[j]; ok {
+				if end < 0 {
+					j = len(src) // unclosed, so is everything around it
+				} else {
+					j = end
+				}
+				continue
+			}
+			open = append(open, j)
+		case ']':
+			ip.brackets[open[len(open)-1]] = j
+			if open = open[:len(open)-1]; len(open) == 0 {
+				return j
+			}
+		}
+	}
+	for _, k := range open {
+		ip.brackets[k] = -1
+	}
+	return -1
+}
+
+// linkDestination parses "(href "title")" at i, returning the index after it.
+func (ip *inlineParser) linkDestination(i int) (href, title string, end int, ok bool) {
+	src := ip.src
+	if i >= len(src) || src[i] != '(' {
+		return "", "", 0, false
+	}
+	j := skipWhitespace(src, i+1)
+	if j < len(src) && src[j] == '<' {
+		k := ip.indexFrom('>', j+1)
+		if nl := ip.indexFrom('\n', j+1); k < 0 || (nl >= 0 && nl < k) {
+			return "", "", 0, false
+		}
+		href = src[j+1 : k]
+		j = k + 1
+	} else {
+		start, depth := j, 0
+		for ; j < len(src); j++ {
+			c := src[j]
+			if c == '\\' && j+1 < len(src) {
+				j++
+				continue
+			}
+			if c == '(' {
+				if depth++; depth > maxLinkParens {
+					return "", "", 0, false
+				}
+			} else if c == ')' {
+				if depth == 0 {
+					break
+				}
+				depth--
+			} else if c == ' ' || c == '\n' || c == '\t' {
+				break
+			}
+		}
+		href = src[start:j]
+	}
+
+	j = skipWhitespace(src, j)
+	if j < len(src) && (src[j] == '"' || src[j] == '\'' || src[j] == '(') {
+		closer := src[j]
+		if closer == '(' {
+			closer = ')'
+		}
+		k := ip.indexFrom(closer, j+1)
+		if k < 0 {
+			return "", "", 0, false
+		}
+		title = src[j+1 : k]
+		j = skipWhitespace(src, k+1)
+	}
+	if j >= len(src) || src[j] != ')' {
+		return "", "", 0, false
+	}
+	return unescapeMarkdown(href), unescapeMarkdown(title), j + 1, true
+}
+
+func skipWhitespace(s string, i int) int {
+	for i < len(s) && (s[i] == ' ' || s[i] == '\n' || s[i] == '\t') {
+		i++
+	}
+	return i
+}
+
+func unescapeMarkdown(s string) string {
+	if !strings.ContainsAny(s, "\\&") {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && isASCIIPunct(s[i+1]) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return html.UnescapeString(b.String())
+}
+
+// linkOrImage parses a link, or an image when image is set, whose label opens
+// at i. Inline, full, collapsed and shortcut reference forms are understood.
+func (ip *inlineParser) linkOrImage(i int, image bool) (int, bool) {
+	src := ip.src
+	end := ip.bracketEnd(i)
+	if end < 0 {
+		return 0, false
+	}
+	label := src[i+1 : end]
+
+	href, title, next, ok := ip.linkDestination(end + 1)
+	if !ok {
+		key := label
+		next = end + 1
+		if next < len(src) && src[next] == '[' {
+			if refEnd := ip.bracketEnd(next); refEnd > 0 {
+				if ref := src[next+1 : refEnd]; ref != "" {
+					key = ref
+				}
+				next = refEnd + 1
+			}
+		}
+		if len(ip.p.refs) == 0 || len(key) > maxLabelLength {
+			return 0, false
+		}
+		ref, found := ip.p.refs[refKey(key)]
+		if !found {
+			return 0, false
+		}
+		href, title = ref.href, ref.title
+	}
+
+	if image {
+		alt := (&Node{Content: ip.label(label, nil)}).PlainText()
+		if src := safeImageURL(href); src != "" {
+			ip.emit(&Node{Type: "image", Attrs: map[string]interface{}{"src": src, "alt": alt, "title": title}})
+		} else {
+			ip.text.WriteString(alt)
+		}
+		return next, true
+	}
+
+	marks := ip.marks
+	if safe := safeURL(href); safe != "" {
+		marks = withMark(marks, link(safe, title))
+	}
+	ip.emit(ip.label(label, marks)...)
+	return next, true
+}
+
+// delimiterRun records a run of '*', '_' or '~' for emphasis matching, with
+// CommonMark's flanking rules deciding whether it may open or close.
+func (ip *inlineParser) delimiterRun(i int) int {
+	src := ip.src
+	c := src[i]
+	n := 0
+	for i+n < len(src) && src[i+n] == c {
+		n++
+	}
+
+	before, after := ' ', ' '
+	if i > 0 {
+		before, _ = utf8.DecodeLastRuneInString(src[:i])
+	}
+	if i+n < len(src) {
+		after, _ = utf8.DecodeRuneInString(src[i+n:])
+	}
+	leftFlanking := !unicode.IsSpace(after) &&
+		(!isPunct(after) || unicode.IsSpace(before) || isPunct(before))
+	rightFlanking := !unicode.IsSpace(before) &&
+		(!isPunct(before) || unicode.IsSpace(after) || isPunct(after))
+
+	canOpen, canClose := leftFlanking, rightFlanking
+	if c == '_' {
+		canOpen = leftFlanking && (!rightFlanking || isPunct(before))
+		canClose = rightFlanking && (!leftFlanking || isPunct(after))
+	}
+	if c == '~' && n > 2 {
+		canOpen, canClose = false, false
+	}
+
+	ip.flush()
+	ip.tokens = append(ip.tokens, inlineToken{delim: c, count: n, orig: n, canOpen: canOpen, canClose: canClose})
+	return i + n
+}
+
+func isPunct(r rune) bool {
+	return unicode.IsPunct(r) || unicode.IsSymbol(r)
+}
+
+// matchEmphasis pairs delimiter runs following CommonMark's process_emphasis.
+// Matches come out properly nested: a pair never straddles another.
+func (ip *inlineParser) matchEmphasis() []emphasisMatch {
+	var matches []emphasisMatch
+	// openers are the runs that may still open, innermost last. bottom keeps,
+	// per kind of closer, how far down openers were already searched in vain.
+	var openers []int
+	bottom := map[[3]int]int{}
+	for c := range ip.tokens {
+		closer := &ip.tokens[c]
+		if closer.delim == 0 {
+			continue
+		}
+		for closer.canClose && closer.count > 0 {
+			kind := [3]int{int(closer.delim), closer.orig % 3, 0}
+			if closer.canOpen {
+				kind[2] = 1
+			}
+			o := len(openers) - 1
+			for ; o >= bottom[kind]; o-- {
+				opener := &ip.tokens[openers[o]]
+				if opener.delim != closer.delim {
+					continue
+				}
+				if closer.delim == '~' {
+					if opener.count == closer.count {
+						break
+					}
+					continue
+				}
+				// The "rule of three" keeps "*foo**bar*" from pairing wrongly.
+				if (opener.canClose || closer.canOpen) && (opener.orig+closer.orig)%3 == 0 &&
+					!(opener.orig%3 == 0 && closer.orig%3 == 0) {
+					continue
+				}
+				break
+			}
+			if o < bottom[kind] {
+				bottom[kind] = len(openers)
+				break
+			}
+
+			opener := &ip.tokens[openers[o]]
+			use, mark := 1, "italic"
+			switch {
+			case closer.delim == '~':
+				use, mark = closer.count, "strike"
+			case opener.count >= 2 && closer.count >= 2:
+				use, mark = 2, "bold"
+			}
+			opener.count -= use
+			closer.count -= use
+			matches = append(matches, emphasisMatch{open: openers[o], close: c, mark: mark})
+			// Runs in between can no longer pair with anything outside.
+			if opener.count == 0 {
+				o--
+			}
+			openers = openers[:o+1]
+			for k, b := range bottom {
+				if b > len(openers) {
+					bottom[k] = len(openers)
+				}
+			}
+		}
+		if closer.canOpen && closer.count > 0 {
+			openers = append(openers, c)
+		}
+	}
+	return matches
+}
+
+func (ip *inlineParser) nodes() []*Node {
+	matches := ip.matchEmphasis()
+	sort.SliceStable(matches, func(a, b int) bool {
+		if matches[a].open != matches[b].open {
+			return matches[a].open < matches[b].open
+		}
+		return matches[a].close > matches[b].close
+	})
+
+	// Walk the tokens keeping the matches around them, outermost first, and
+	// how many of those add each mark.
+	var open []emphasisMatch
+	var order []string
+	active := map[string]int{}
+	next := 0
+	var out []*Node
+	for t, tok := range ip.tokens {
+		for len(open) > 0 && open[len(open)-1].close <= t {
+			mark := open[len(open)-1].mark
+			open = open[:len(open)-1]
+			if active[mark]--; active[mark] == 0 {
+				order = removeString(order, mark)
+			}
+		}
+
+		nodes := tok.nodes
+		if tok.delim != 0 {
+			nodes = nil
+			if tok.count > 0 {
+				nodes = []*Node{textNode(strings.Repeat(string(tok.delim), tok.count), ip.marks)}
+			}
+		}
+		for _, node := range nodes {
+			for _, mark := range order {
+				addMark(node, Mark{Type: mark})
+			}
+			out = append(out, node)
+		}
+
+		for ; next < len(matches) && matches[next].open == t; next++ {
+			m := matches[next]
+			open = append(open, m)
+			if active[m.mark]++; active[m.mark] == 1 {
+				order = append(order, m.mark)
+			}
+		}
+	}
+	return mergeText(out)
+}
+
+func removeString(list []string, s string) []string {
+	for i, item := range list {
+		if item == s {
+			return append(list[:i], list[i+1:]...)
+		}
+	}
+	return list
+}
+
+// addMark adds m to a text node that does not have it yet. Code text takes no
+// other marks.
+func addMark(node *Node, m Mark) {
+	if node.Type != "text" {
+		return
+	}
+	for _, existing := range node.Marks {
+		if existing.Type == m.Type || existing.Type == "code" {
+			return
+		}
+	}
+	node.Marks = append(node.Marks, m)
+}
@@ -0,0 +1,246 @@
+package tiptap
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFromMarkdown(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			name: "heading with emphasis",
+			src:  "# Hi *there*",
+			want: `<h1 id="hi-there">Hi <em>there</em></h1>`,
+		},
+		{
+			name: "setext heading",
+			src:  "Title\n---",
+			want: `<h2 id="title">Title</h2>`,
+		},
+		{
+			name: "hard and soft breaks",
+			src:  "a  \nb\nc",
+			want: "<p>a<br>b c</p>",
+		},
+		{
+			name: "marks",
+			src:  "**bold** _it_ ~~s~~ `c`",
+			want: "<p><strong>bold</strong> <em>it</em> <s>s</s> <code>c</code></p>",
+		},
+		{
+			name: "nested emphasis",
+			src:  "***both*** *a **b** c*",
+			want: "<p><strong><em>both</em></strong> <em>a </em><em><strong>b</strong></em><em> c</em></p>",
+		},
+		{
+			name: "rule of three",
+			src:  "*foo**bar*",
+			want: "<p><em>foo**bar</em></p>",
+		},
+		{
+			name: "intraword underscores",
+			src:  "snake_case_name",
+			want: "<p>snake_case_name</p>",
+		},
+		{
+			name: "unmatched delimiters",
+			src:  "2 * 3 and a*",
+			want: "<p>2 * 3 and a*</p>",
+		},
+		{
+			name: "code span with longer fence",
+			src:  "``a ` b``",
+			want: "<p><code>a ` b</code></p>",
+		},
+		{
+			name: "unclosed code span",
+			src:  "``a`",
+			want: "<p>``a`</p>",
+		},
+		{
+			name: "inline link with title",
+			src:  `[x](http://a.b "t")`,
+			want: `<p><a href="http://a.b" title="t">x</a></p>`,
+		},
+		{
+			name: "link destination in angle brackets",
+			src:  "[x](<http://a.b/c d>)",
+			want: `<p><a href="http://a.b/c d">x</a></p>`,
+		},
+		{
+			name: "balanced parentheses in destination",
+			src:  "[x](http://a.b/(c))",
+			want: `<p><a href="http://a.b/(c)">x</a></p>`,
+		},
+		{
+			name: "reference links",
+			src:  "[full][r] [r][] [r]\n\n[R]: http://r.s 'T'",
+			want: `<p><a href="http://r.s" title="T">full</a> <a href="http://r.s" title="T">r</a> <a href="http://r.s" title="T">r</a></p>`,
+		},
+		{
+			name: "undefined reference",
+			src:  "[a][missing] [b]",
+			want: "<p>[a][missing] [b]</p>",
+		},
+		{
+			name: "brackets in link text",
+			src:  "[a [b] c](http://x.y)",
+			want: `<p><a href="http://x.y">a [b] c</a></p>`,
+		},
+		{
+			name: "unsafe link dropped",
+			src:  "<script>x</script>[bad](javascript:alert(1))",
+			want: "<p>xbad</p>",
+		},
+		{
+			name: "autolinks",
+			src:  "<http://a.b> <me@x.y> www.c.d.",
+			want: `<p><a href="http://a.b">http://a.b</a> <a href="mailto:me@x.y">me@x.y</a> <a href="http://www.c.d">www.c.d</a>.</p>`,
+		},
+		{
+			name: "image becomes a block",
+			src:  "before ![i](http://a/i.png) after",
+			want: "<p>before</p>\n<figure><img src=\"http://a/i.png\" alt=\"i\"></figure>\n<p>after</p>",
+		},
+		{
+			name: "entities and escapes",
+			src:  `&amp; \*not\*`,
+			want: "<p>&amp; *not*</p>",
+		},
+		{
+			name: "unclosed comment kept as text",
+			src:  "a <!-- b",
+			want: "<p>a &lt;!-- b</p>",
+		},
+		{
+			name: "nested quotes",
+			src:  "> q\n> > r",
+			want: "<blockquote>\n<p>q</p>\n<blockquote>\n<p>r</p>\n</blockquote>\n</blockquote>",
+		},
+		{
+			name: "lazy quote continuation",
+			src:  "> a\nb",
+			want: "<blockquote>\n<p>a b</p>\n</blockquote>",
+		},
+		{
+			name: "nested lists",
+			src:  "- a\n  - b\n- c",
+			want: "<ul>\n<li>\n<p>a</p>\n<ul>\n<li>b</li>\n</ul>\n</li>\n<li>c</li>\n</ul>",
+		},
+		{
+			name: "ordered list start",
+			src:  "3. x\n4. y",
+			want: "<ol start=\"3\">\n<li>x</li>\n<li>y</li>\n</ol>",
+		},
+		{
+			name: "fenced code",
+			src:  "~~~\na < b\n~~~",
+			want: "<pre><code>a &lt; b</code></pre>",
+		},
+		{
+			name: "table",
+			src:  "| a | b |\n|---|:-:|\n| 1 | 2 |",
+			want: "<table>\n<thead>\n<tr><th>a</th><th style=\"text-align: center\">b</th></tr>\n</thead>\n<tbody>\n<tr><td>1</td><td style=\"text-align: center\">2</td></tr>\n</tbody>\n</table>",
+		},
+		{
+			name: "thematic break",
+			src:  "a\n\n***\n\nb",
+			want: "<p>a</p>\n<hr>\n<p>b</p>",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := strings.TrimSpace(FromMarkdown(tt.src).HTML()); got != tt.want {
+				t.Errorf("FromMarkdown(%q).HTML() =\n%s\nwant\n%s", tt.src, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromMarkdownTaskList(t *testing.T) {
+	doc := FromMarkdown("- [ ] todo\n- [x] done")
+	list := doc.Content[0]
+	if list.Type != "taskList" || len(list.Content) != 2 {
+		t.Fatalf("got %s with %d items, want a taskList with 2", list.Type, len(list.Content))
+	}
+	for i, want := range []bool{false, true} {
+		item := list.Content[i]
+		if item.Type != "taskItem" || item.attrBool("checked") != want {
+			t.Errorf("item %d: got %s checked=%v, want taskItem checked=%v", i, item.Type, item.attrBool("checked"), want)
+		}
+	}
+}
+
+func TestFromMarkdownNestingLimit(t *testing.T) {
+	var list strings.Builder
+	for i := 0; i < 100; i++ {
+		list.WriteString(strings.Repeat("  ", i) + "- item\n")
+	}
+	list.WriteString(strings.Repeat("  ", 100) + "- deep\n")
+	tests := []struct {
+		name string
+		src  string
+	}{
+		{name: "quotes", src: strings.Repeat(">", 100) + " deep"},
+		{name: "lists", src: list.String()},
+		{name: "quoted lists", src: strings.Repeat("> - ", 50) + "deep"},
+		{name: "link labels", src: strings.Repeat("[", 100) + "deep" + strings.Repeat("](http://x.y)", 100)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := FromMarkdown(tt.src)
+			data, err := doc.JSON()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := Validate(data, DefaultLimits); err != nil {
+				t.Fatalf("document is not valid: %v", err)
+			}
+			if !strings.Contains(doc.PlainText(), "deep") {
+				t.Errorf("the innermost text was lost: %q", doc.PlainText())
+			}
+		})
+	}
+}
+
+// TestFromMarkdownLinearTime feeds inputs that take quadratic time to parse
+// when delimiters, brackets or nesting levels are rescanned.
+func TestFromMarkdownLinearTime(t *testing.T) {
+	const n = 80000
+	var list strings.Builder
+	for i := 0; i < 2000; i++ {
+		list.WriteString(strings.Repeat("  ", i) + "- x\n")
+	}
+	tests := []struct {
+		name string
+		src  string
+	}{
+		{name: "unclosed links", src: strings.Repeat("[a](", n)},
+		{name: "unclosed angle destinations", src: strings.Repeat("[a](<", n)},
+		{name: "unclosed titles", src: strings.Repeat("[a](b (", n)},
+		{name: "nested brackets", src: strings.Repeat("[", n) + strings.Repeat("]", n)},
+		{name: "nested links", src: strings.Repeat("[", n/10) + "a" + strings.Repeat("](b)", n/10)},
+		{name: "emphasis", src: strings.Repeat("*a", n)},
+		{name: "underscores", src: strings.Repeat("_a ", n)},
+		{name: "nested emphasis", src: strings.Repeat("*a ", n/2) + strings.Repeat("a* ", n/2)},
+		{name: "backticks", src: strings.Repeat("`a``", n)},
+		{name: "comments", src: strings.Repeat("<!--", n)},
+		{name: "hard breaks", src: strings.Repeat("a  \n", n)},
+		{name: "quotes", src: strings.Repeat(">", n/4) + " x"},
+		{name: "lists", src: list.String()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Now()
+			FromMarkdown(tt.src)
+			if elapsed := time.Since(start); elapsed > 5*time.Second {
+				t.Errorf("parsing took %v", elapsed)
+			}
+		})
+	}
+}
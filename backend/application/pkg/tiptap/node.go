@@ -0,0 +1,258 @@
+// Package tiptap builds TipTap (ProseMirror) documents, the JSON format the
+// editor stores post content in, from Markdown and HTML.
+package tiptap
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Node is one node of a document. Text nodes carry Text and Marks, all others
+// Content.
+type Node struct {
+	Type    string                 `json:"type"`
+	Attrs   map[string]interface{} `json:"attrs,omitempty"`
+	Content []*Node                `json:"content,omitempty"`
+	Text    string                 `json:"text,omitempty"`
+	Marks   []Mark                 `json:"marks,omitempty"`
+}
+
+// Mark is inline formatting on a text node, like bold or a link.
+type Mark struct {
+	Type  string                 `json:"type"`
+	Attrs map[string]interface{} `json:"attrs,omitempty"`
+}
+
+// maxHeadingLevel is the deepest heading the editor offers; deeper ones are
+// clamped to it.
+const maxHeadingLevel = 5
+
+// Doc wraps blocks in a document node. An empty document gets an empty
+// paragraph, as the editor cannot hold a document without blocks.
+func Doc(blocks []*Node) *Node {
+	if len(blocks) == 0 {
+		blocks = []*Node{{Type: "paragraph"}}
+	}
+	return &Node{Type: "doc", Content: blocks}
+}
+
+// Parse decodes a stored document.
+func Parse(data json.RawMessage) (*Node, error) {
+	var doc Node
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// JSON encodes the document for PostContent.Content.
+func (n *Node) JSON() (json.RawMessage, error) {
+	return json.Marshal(n)
+}
+
+// Walk calls fn for n and each node below it, parents first.
+func (n *Node) Walk(fn func(*Node)) {
+	fn(n)
+	for _, child := range n.Content {
+		child.Walk(fn)
+	}
+}
+
+// Images returns the image nodes of the document in order.
+func (n *Node) Images() []*Node {
+	var images []*Node
+	n.Walk(func(node *Node) {
+		if node.Type == "image" || node.Type == "imageBlock" {
+			images = append(images, node)
+		}
+	})
+	return images
+}
+
+// PlainText joins the text of the document, blocks separated by spaces.
+func (n *Node) PlainText() string {
+	var text []byte
+	n.Walk(func(node *Node) {
+		if node.Type == "text" {
+			if len(text) > 0 && text[len(text)-1] != ' ' {
+				text = append(text, ' ')
+			}
+			text = append(text, node.Text...)
+		}
+	})
+	return string(text)
+}
+
+// calloutVariants are the kinds of callout, GitHub's alert types.
+var calloutVariants = []string{"note", "tip", "important", "warning", "caution"}
+
+// calloutTitles head callouts where nothing else shows their kind.
+var calloutTitles = map[string]string{
+	"note":      "Note",
+	"tip":       "Tip",
+	"important": "Important",
+	"warning":   "Warning",
+	"caution":   "Caution",
+}
+
+// calloutVariant returns the kind of a callout, a note unless set otherwise.
+func calloutVariant(n *Node) string {
+	if variant := n.attrString("variant"); calloutTitles[variant] != "" {
+		return variant
+	}
+	return "note"
+}
+
+// footnoteNumbers numbers the footnotes of the document from 1, in the order
+// they are defined, which the editor keeps the order they are referenced in.
+func (n *Node) footnoteNumbers() map[string]int {
+	numbers := make(map[string]int)
+	n.Walk(func(node *Node) {
+		if node.Type != "footnote" {
+			return
+		}
+		if id := node.attrString("id"); id != "" && numbers[id] == 0 {
+			numbers[id] = len(numbers) + 1
+		}
+	})
+	return numbers
+}
+
+// attrString returns the string attribute key, or "" if it is unset or not a
+// string.
+func (n *Node) attrString(key string) string {
+	s, _ := n.Attrs[key].(string)
+	return s
+}
+
+// attrInt returns the numeric attribute key. Decoded JSON holds numbers as
+// float64, documents built here as int.
+func (n *Node) attrInt(key string, fallback int) int {
+	switch v := n.Attrs[key].(type) {
+	case int:
+		return v
+	case float64:
+		return int(v)
+	}
+	return fallback
+}
+
+func (n *Node) attrBool(key string) bool {
+	b, _ := n.Attrs[key].(bool)
+	return b
+}
+
+func (m Mark) attrString(key string) string {
+	s, _ := m.Attrs[key].(string)
+	return s
+}
+
+func textNode(text string, marks []Mark) *Node {
+	node := &Node{Type: "text", Text: text}
+	if len(marks) > 0 {
+		node.Marks = append([]Mark(nil), marks...)
+	}
+	return node
+}
+
+func heading(level int, content []*Node) *Node {
+	if level > maxHeadingLevel {
+		level = maxHeadingLevel
+	}
+	return &Node{Type: "heading", Attrs: map[string]interface{}{"level": level}, Content: content}
+}
+
+func paragraph(content []*Node) *Node {
+	return &Node{Type: "paragraph", Content: content}
+}
+
+func codeBlock(language, code string) *Node {
+	node := &Node{Type: "codeBlock", Attrs: map[string]interface{}{"language": nil}}
+	if language != "" {
+		node.Attrs["language"] = language
+	}
+	if code != "" {
+		node.Content = []*Node{{Type: "text", Text: code}}
+	}
+	return node
+}
+
+func imageBlock(src, alt, title, caption string) *Node {
+	return &Node{Type: "imageBlock", Attrs: map[string]interface{}{
+		"src":       src,
+		"alt":       alt,
+		"title":     title,
+		"caption":   caption,
+		"alignment": "center",
+		"width":     "100%",
+	}}
+}
+
+func link(href, title string) Mark {
+	attrs := map[string]interface{}{"href": href}
+	if title != "" {
+		attrs["title"] = title
+	}
+	return Mark{Type: "link", Attrs: attrs}
+}
+
+// withMark returns marks plus m, leaving marks itself untouched. m replaces
+// a mark of the same type, as a link inside a link's text wins over it.
+func withMark(marks []Mark, m Mark) []Mark {
+	out := make([]Mark, 0, len(marks)+1)
+	for _, existing := range marks {
+		if existing.Type != m.Type {
+			out = append(out, existing)
+		}
+	}
+	return append(out, m)
+}
+
+// mergeText joins adjacent text nodes with the same marks, which the editor
+// would do itself on load.
+func mergeText(nodes []*Node) []*Node {
+	out := nodes[:0]
+	var last *Node // text node the current run is merged into
+	var run []string
+	flush := func() {
+		if len(run) > 1 {
+			last.Text = strings.Join(run, "")
+		}
+		run = run[:0]
+	}
+	for _, node := range nodes {
+		if node.Type == "text" && node.Text == "" {
+			continue
+		}
+		if last != nil && node.Type == "text" && sameMarks(last.Marks, node.Marks) {
+			run = append(run, node.Text)
+			continue
+		}
+		flush()
+		out = append(out, node)
+		last = nil
+		if node.Type == "text" {
+			last = node
+			run = append(run, node.Text)
+		}
+	}
+	flush()
+	return out
+}
+
+func sameMarks(a, b []Mark) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Type != b[i].Type || len(a[i].Attrs) != len(b[i].Attrs) {
+			return false
+		}
+		for k, v := range a[i].Attrs {
+			if b[i].Attrs[k] != v {
+				return false
+			}
+		}
+	}
+	return true
+}
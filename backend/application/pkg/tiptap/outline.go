@@ -0,0 +1,114 @@
+package tiptap
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pdhoang91/blog/pkg/utils"
+)
+
+// WordsPerMinute is the reading speed reading times assume, the one the
+// editor's word counter uses.
+const WordsPerMinute = 200
+
+// Heading is an entry in a document's table of contents. IDs are derived
+// from the heading text, so they stay the same while the text does.
+type Heading struct {
+	ID    string `json:"id"`
+	Level int    `json:"level"`
+	Text  string `json:"text"`
+}
+
+// Stats describes a document for listings and the post page.
+type Stats struct {
+	Words       int
+	ReadingTime int // minutes, at least 1 for a document with any words
+	Headings    []Heading
+	// FirstImage is the source of the first image with a safe URL, "" if
+	// there is none.
+	FirstImage string
+}
+
+// Stats counts the words of the document and collects its headings and first
+// image.
+func (n *Node) Stats() Stats {
+	var text strings.Builder
+	collectText(&text, n)
+	stats := Stats{Words: len(strings.Fields(text.String()))}
+	if stats.Words > 0 {
+		stats.ReadingTime = (stats.Words + WordsPerMinute - 1) / WordsPerMinute
+	}
+
+	ids := n.headingIDs()
+	n.Walk(func(node *Node) {
+		switch node.Type {
+		case "heading":
+			id, ok := ids[node]
+			if !ok {
+				return
+			}
+			level := node.attrInt("level", 1)
+			if level < 1 || level > 6 {
+				level = 1
+			}
+			stats.Headings = append(stats.Headings, Heading{ID: id, Level: level, Text: headingTitle(node)})
+		case "image", "imageBlock":
+			if stats.FirstImage == "" {
+				stats.FirstImage = safeImageURL(node.attrString("src"))
+			}
+		}
+	})
+	return stats
+}
+
+// collectText writes the text below n with blocks and line breaks kept apart
+// by spaces, so words either side of them are counted separately.
+func collectText(b *strings.Builder, n *Node) {
+	switch n.Type {
+	case "text":
+		b.WriteString(n.Text)
+		return
+	case "hardBreak":
+		b.WriteByte(' ')
+		return
+	}
+	for _, child := range n.Content {
+		collectText(b, child)
+	}
+	b.WriteByte(' ')
+}
+
+// headingIDs gives every heading with text its anchor ID, numbered in
+// document order. The table of contents and rendered headings both use it.
+func (n *Node) headingIDs() map[*Node]string {
+	ids := make(map[*Node]string)
+	used := make(map[string]bool)
+	n.Walk(func(node *Node) {
+		if node.Type != "heading" {
+			return
+		}
+		if title := headingTitle(node); title != "" {
+			ids[node] = anchorID(title, used)
+		}
+	})
+	return ids
+}
+
+func headingTitle(n *Node) string {
+	return strings.Join(strings.Fields(textOf(n)), " ")
+}
+
+// anchorID makes an ID for a heading from its text, numbering repeats as
+// GitHub does: "setup", "setup-1", "setup-2".
+func anchorID(title string, used map[string]bool) string {
+	base := utils.CreateSlug(title)
+	if base == "" {
+		base = "section"
+	}
+	id := base
+	for i := 1; used[id]; i++ {
+		id = base + "-" + strconv.Itoa(i)
+	}
+	used[id] = true
+	return id
+}
@@ -0,0 +1,53 @@
+package tiptap
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	doc := FromMarkdown("# Setup\n\nOne two three.\n\n## Setup\n\n#\n\n### Setup\n\n![a](http://x.y/a.png)\n\n## Việt Nam")
+	stats := doc.Stats()
+
+	if stats.Words != 8 {
+		t.Errorf("Words = %d, want 8", stats.Words)
+	}
+	if stats.ReadingTime != 1 {
+		t.Errorf("ReadingTime = %d, want 1", stats.ReadingTime)
+	}
+	if stats.FirstImage != "http://x.y/a.png" {
+		t.Errorf("FirstImage = %q", stats.FirstImage)
+	}
+	want := []Heading{
+		{ID: "setup", Level: 1, Text: "Setup"},
+		{ID: "setup-1", Level: 2, Text: "Setup"},
+		{ID: "setup-2", Level: 3, Text: "Setup"},
+		{ID: "viet-nam", Level: 2, Text: "Việt Nam"},
+	}
+	if !reflect.DeepEqual(stats.Headings, want) {
+		t.Errorf("Headings = %+v, want %+v", stats.Headings, want)
+	}
+}
+
+func TestStatsEmpty(t *testing.T) {
+	stats := Doc(nil).Stats()
+	if stats.Words != 0 || stats.ReadingTime != 0 || len(stats.Headings) != 0 {
+		t.Errorf("Stats of an empty document = %+v", stats)
+	}
+}
+
+// TestHTMLHeadingIDs checks that rendered headings carry the IDs of the
+// table of contents, so its links land on them.
+func TestHTMLHeadingIDs(t *testing.T) {
+	doc := FromMarkdown("## Intro\n\n> ## Intro\n\n## \n\n- item\n\n## Intro")
+	html := doc.HTML()
+	for _, h := range doc.Stats().Headings {
+		if !strings.Contains(html, `id="`+h.ID+`"`) {
+			t.Errorf("no heading with id %q in\n%s", h.ID, html)
+		}
+	}
+	if got := strings.Count(html, " id="); got != 3 {
+		t.Errorf("%d headings have an id, want 3:\n%s", got, html)
+	}
+}
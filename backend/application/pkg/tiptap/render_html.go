@@ -0,0 +1,413 @@
+package tiptap
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/pdhoang91/blog/pkg/highlight"
+)
+
+var reVideoID = regexp.MustCompile(`^[A-Za-z0-9_-]{6,20}$`)
+
+// markTags are the elements marks other than links render as.
+var markTags = map[string]string{
+	"bold":        "strong",
+	"italic":      "em",
+	"underline":   "u",
+	"strike":      "s",
+	"code":        "code",
+	"highlight":   "mark",
+	"subscript":   "sub",
+	"superscript": "sup",
+}
+
+// HTML renders the document as semantic HTML: no editor classes, and styles
+// only for text alignment and highlighted code, so it reads the same without
+// the site's stylesheet. Image sources are used as they are, so stored
+// documents need their image IDs resolved first. Links and images with unsafe
+// URLs are left out. Headings carry the IDs the table of contents links to.
+func (n *Node) HTML() string {
+	b := &htmlWriter{headings: n.headingIDs(), footnotes: n.footnoteNumbers(), referenced: make(map[string]bool)}
+	if n.Type == "doc" {
+		renderHTMLBlocks(b, n.Content)
+	} else {
+		renderHTMLBlocks(b, []*Node{n})
+	}
+	return b.String()
+}
+
+// htmlWriter collects the rendered document, with the anchors headings get
+// and what footnotes need to link to each other.
+type htmlWriter struct {
+	strings.Builder
+	headings   map[*Node]string
+	footnotes  map[string]int  // footnote numbers by ID
+	referenced map[string]bool // footnotes with a reference rendered so far
+}
+
+func renderHTMLBlocks(b *htmlWriter, nodes []*Node) {
+	for _, node := range nodes {
+		renderHTMLBlock(b, node)
+	}
+}
+
+func renderHTMLBlock(b *htmlWriter, n *Node) {
+	switch n.Type {
+	case "paragraph":
+		b.WriteString("<p" + alignStyle(n) + ">")
+		renderHTMLInline(b, n.Content)
+		b.WriteString("</p>\n")
+	case "heading":
+		level := n.attrInt("level", 1)
+		if level < 1 || level > 6 {
+			level = 1
+		}
+		id := ""
+		if anchor, ok := b.headings[n]; ok {
+			id = ` id="` + html.EscapeString(anchor) + `"`
+		}
+		fmt.Fprintf(b, "<h%d%s%s>", level, id, alignStyle(n))
+		renderHTMLInline(b, n.Content)
+		fmt.Fprintf(b, "</h%d>\n", level)
+	case "blockquote":
+		b.WriteString("<blockquote>\n")
+		renderHTMLBlocks(b, n.Content)
+		b.WriteString("</blockquote>\n")
+	case "bulletList":
+		b.WriteString("<ul>\n")
+		renderHTMLItems(b, n.Content)
+		b.WriteString("</ul>\n")
+	case "orderedList":
+		if start := n.attrInt("start", 1); start != 1 {
+			fmt.Fprintf(b, "<ol start=\"%d\">\n", start)
+		} else {
+			b.WriteString("<ol>\n")
+		}
+		renderHTMLItems(b, n.Content)
+		b.WriteString("</ol>\n")
+	case "taskList":
+		b.WriteString("<ul class=\"task-list\">\n")
+		renderHTMLItems(b, n.Content)
+		b.WriteString("</ul>\n")
+	case "codeBlock":
+		b.WriteString("<pre><code")
+		if lang := n.attrString("language"); lang != "" {
+			b.WriteString(" class=\"language-" + html.EscapeString(lang) + "\"")
+		}
+		code := textOf(n)
+		if tokens := highlight.Tokenize(n.attrString("language"), code); tokens != nil {
+			b.WriteString(">" + highlight.HTML(tokens) + "</code></pre>\n")
+		} else {
+			b.WriteString(">" + html.EscapeString(code) + "</code></pre>\n")
+		}
+	case "horizontalRule":
+		b.WriteString("<hr>\n")
+	case "imageBlock":
+		src := safeImageURL(n.attrString("src"))
+		if src == "" {
+			return
+		}
+		b.WriteString("<figure>")
+		renderHTMLImage(b, n, src)
+		if caption := n.attrString("caption"); caption != "" {
+			b.WriteString("<figcaption>" + html.EscapeString(caption) + "</figcaption>")
+		}
+		b.WriteString("</figure>\n")
+	case "image":
+		if src := safeImageURL(n.attrString("src")); src != "" {
+			b.WriteString("<p>")
+			renderHTMLImage(b, n, src)
+			b.WriteString("</p>\n")
+		}
+	case "table":
+		renderHTMLTable(b, n)
+	case "youtube":
+		if src := PlayerURL(n.attrString("src")); src != "" {
+			renderHTMLPlayer(b, src)
+		}
+	case "embed":
+		renderHTMLEmbed(b, n)
+	case "callout":
+		variant := calloutVariant(n)
+		b.WriteString("<aside class=\"callout callout-" + variant + "\">\n")
+		b.WriteString("<p><strong>" + calloutTitles[variant] + "</strong></p>\n")
+		renderHTMLBlocks(b, n.Content)
+		b.WriteString("</aside>\n")
+	case "blockMath":
+		// KaTeX and MathJax typeset LaTeX between these delimiters.
+		b.WriteString("<div class=\"math math-display\">\\[" + html.EscapeString(n.attrString("latex")) + "\\]</div>\n")
+	case "footnotes":
+		renderHTMLFootnotes(b, n)
+	case "text", "hardBreak", "inlineMath", "footnoteReference":
+		// Inline content where a block belongs, as in a hand-built document.
+		b.WriteString("<p>")
+		renderHTMLInline(b, []*Node{n})
+		b.WriteString("</p>\n")
+	default:
+		renderHTMLBlocks(b, n.Content)
+	}
+}
+
+func renderHTMLPlayer(b *htmlWriter, src string) {
+	fmt.Fprintf(b, "<iframe src=\"%s\" width=\"640\" height=\"360\" allowfullscreen></iframe>\n", html.EscapeString(src))
+}
+
+// renderHTMLEmbed renders a link preview as a card linking to the page, or
+// as the page's player when it has one that may be embedded.
+func renderHTMLEmbed(b *htmlWriter, n *Node) {
+	link := safeURL(n.attrString("url"))
+	if link == "" {
+		return
+	}
+	if player := PlayerURL(n.attrString("video")); player != "" {
+		renderHTMLPlayer(b, player)
+		return
+	}
+	title := n.attrString("title")
+	if title == "" {
+		title = link
+	}
+	b.WriteString("<figure class=\"link-preview\"><a href=\"" + html.EscapeString(link) + "\">")
+	if image := safeImageURL(n.attrString("image")); image != "" {
+		b.WriteString("<img src=\"" + html.EscapeString(image) + "\" alt=\"\">")
+	}
+	b.WriteString("<strong>" + html.EscapeString(title) + "</strong>")
+	if desc := n.attrString("description"); desc != "" {
+		b.WriteString("<span>" + html.EscapeString(desc) + "</span>")
+	}
+	b.WriteString("</a>")
+	if site := n.attrString("siteName"); site != "" {
+		b.WriteString("<figcaption>" + html.EscapeString(site) + "</figcaption>")
+	}
+	b.WriteString("</figure>\n")
+}
+
+// renderHTMLFootnotes renders footnote definitions as a numbered list, each
+// linking back to where it is first referenced.
+func renderHTMLFootnotes(b *htmlWriter, n *Node) {
+	if len(n.Content) == 0 {
+		return
+	}
+	b.WriteString("<section class=\"footnotes\">\n<ol>\n")
+	for _, footnote := range n.Content {
+		id := html.EscapeString(footnote.attrString("id"))
+		b.WriteString("<li id=\"fn-" + id + "\">")
+		if len(footnote.Content) == 1 && footnote.Content[0].Type == "paragraph" {
+			renderHTMLInline(b, footnote.Content[0].Content)
+		} else {
+			b.WriteString("\n")
+			renderHTMLBlocks(b, footnote.Content)
+		}
+		if b.referenced[footnote.attrString("id")] {
+			b.WriteString(" <a href=\"#fnref-" + id + "\">↩</a>")
+		}
+		b.WriteString("</li>\n")
+	}
+	b.WriteString("</ol>\n</section>\n")
+}
+
+func renderHTMLItems(b *htmlWriter, items []*Node) {
+	for _, item := range items {
+		b.WriteString("<li>")
+		if item.Type == "taskItem" {
+			if item.attrBool("checked") {
+				b.WriteString("<input type=\"checkbox\" checked disabled> ")
+			} else {
+				b.WriteString("<input type=\"checkbox\" disabled> ")
+			}
+		}
+		// A list item holding one paragraph renders without the <p>, as
+		// editors and Markdown renderers do for tight lists.
+		if len(item.Content) == 1 && item.Content[0].Type == "paragraph" {
+			renderHTMLInline(b, item.Content[0].Content)
+		} else {
+			b.WriteString("\n")
+			renderHTMLBlocks(b, item.Content)
+		}
+		b.WriteString("</li>\n")
+	}
+}
+
+func renderHTMLTable(b *htmlWriter, n *Node) {
+	rows := n.Content
+	if len(rows) == 0 {
+		return
+	}
+	b.WriteString("<table>\n")
+	if isHeaderRow(rows[0]) {
+		b.WriteString("<thead>\n")
+		renderHTMLRow(b, rows[0])
+		b.WriteString("</thead>\n")
+		rows = rows[1:]
+	}
+	if len(rows) > 0 {
+		b.WriteString("<tbody>\n")
+		for _, row := range rows {
+			renderHTMLRow(b, row)
+		}
+		b.WriteString("</tbody>\n")
+	}
+	b.WriteString("</table>\n")
+}
+
+func isHeaderRow(row *Node) bool {
+	for _, cell := range row.Content {
+		if cell.Type != "tableHeader" {
+			return false
+		}
+	}
+	return len(row.Content) > 0
+}
+
+func renderHTMLRow(b *htmlWriter, row *Node) {
+	b.WriteString("<tr>")
+	for _, cell := range row.Content {
+		tag := "td"
+		if cell.Type == "tableHeader" {
+			tag = "th"
+		}
+		b.WriteString("<" + tag)
+		if span := cell.attrInt("colspan", 1); span > 1 {
+			fmt.Fprintf(b, " colspan=\"%d\"", span)
+		}
+		if span := cell.attrInt("rowspan", 1); span > 1 {
+			fmt.Fprintf(b, " rowspan=\"%d\"", span)
+		}
+		if len(cell.Content) == 1 && cell.Content[0].Type == "paragraph" {
+			b.WriteString(alignStyle(cell.Content[0]) + ">")
+			renderHTMLInline(b, cell.Content[0].Content)
+		} else {
+			b.WriteString(">")
+			renderHTMLBlocks(b, cell.Content)
+		}
+		b.WriteString("</" + tag + ">")
+	}
+	b.WriteString("</tr>\n")
+}
+
+func renderHTMLImage(b *htmlWriter, n *Node, src string) {
+	b.WriteString("<img src=\"" + html.EscapeString(src) + "\" alt=\"" + html.EscapeString(n.attrString("alt")) + "\"")
+	if title := n.attrString("title"); title != "" {
+		b.WriteString(" title=\"" + html.EscapeString(title) + "\"")
+	}
+	b.WriteString(">")
+}
+
+func renderHTMLInline(b *htmlWriter, nodes []*Node) {
+	for _, n := range nodes {
+		switch n.Type {
+		case "text":
+			renderHTMLText(b, n)
+		case "hardBreak":
+			b.WriteString("<br>")
+		case "image":
+			if src := safeImageURL(n.attrString("src")); src != "" {
+				renderHTMLImage(b, n, src)
+			}
+		case "inlineMath":
+			b.WriteString("<span class=\"math math-inline\">\\(" + html.EscapeString(n.attrString("latex")) + "\\)</span>")
+		case "footnoteReference":
+			renderHTMLFootnoteReference(b, n)
+		default:
+			renderHTMLInline(b, n.Content)
+		}
+	}
+}
+
+// renderHTMLFootnoteReference renders a reference as its footnote's number,
+// linking to the footnote. References to footnotes that are not defined are
+// left out.
+func renderHTMLFootnoteReference(b *htmlWriter, n *Node) {
+	id := n.attrString("id")
+	number, ok := b.footnotes[id]
+	if !ok {
+		return
+	}
+	b.WriteString("<sup")
+	if !b.referenced[id] {
+		b.WriteString(" id=\"fnref-" + html.EscapeString(id) + "\"")
+		b.referenced[id] = true
+	}
+	fmt.Fprintf(b, "><a href=\"#fn-%s\">%d</a></sup>", html.EscapeString(id), number)
+}
+
+// renderHTMLText wraps the text in one element per mark, the link outermost.
+func renderHTMLText(b *htmlWriter, n *Node) {
+	var open, close []string
+	for _, m := range n.Marks {
+		if m.Type != "link" {
+			continue
+		}
+		if href := safeURL(m.attrString("href")); href != "" {
+			tag := "<a href=\"" + html.EscapeString(href) + "\""
+			if title := m.attrString("title"); title != "" {
+				tag += " title=\"" + html.EscapeString(title) + "\""
+			}
+			open = append(open, tag+">")
+			close = append(close, "</a>")
+		}
+	}
+	for _, m := range n.Marks {
+		if tag, ok := markTags[m.Type]; ok {
+			open = append(open, "<"+tag+">")
+			close = append(close, "</"+tag+">")
+		}
+	}
+	for _, tag := range open {
+		b.WriteString(tag)
+	}
+	b.WriteString(html.EscapeString(n.Text))
+	for i := len(close) - 1; i >= 0; i-- {
+		b.WriteString(close[i])
+	}
+}
+
+// PlayerURL returns the URL to embed a video in an iframe with, or "" for
+// sources that may not be embedded; only YouTube videos may. The editor keeps
+// the URL a video was added with, which may be its page or a short link.
+func PlayerURL(src string) string {
+	if reYouTubeEmbed.MatchString(src) {
+		return src
+	}
+	u, err := url.Parse(src)
+	if err != nil || !reYouTube.MatchString(src) {
+		return ""
+	}
+	id := u.Query().Get("v")
+	switch {
+	case u.Host == "youtu.be":
+		id = strings.TrimPrefix(u.Path, "/")
+	case strings.HasPrefix(u.Path, "/shorts/"), strings.HasPrefix(u.Path, "/live/"):
+		id = path.Base(u.Path)
+	}
+	if !reVideoID.MatchString(id) {
+		return ""
+	}
+	return "https://www.youtube.com/embed/" + id
+}
+
+func alignStyle(n *Node) string {
+	switch align := n.attrString("textAlign"); align {
+	case "center", "right", "justify":
+		return " style=\"text-align: " + align + "\""
+	}
+	return ""
+}
+
+// textOf joins the text below n, as in a code block.
+func textOf(n *Node) string {
+	var b strings.Builder
+	n.Walk(func(node *Node) {
+		switch node.Type {
+		case "text":
+			b.WriteString(node.Text)
+		case "hardBreak":
+			b.WriteByte('\n')
+		}
+	})
+	return b.String()
+}
@@ -0,0 +1,457 @@
+package tiptap
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// reMarkdownSpecial matches characters that would start inline syntax.
+	reMarkdownSpecial = regexp.MustCompile("[\\\\`*_\\[\\]<>~]|&(?:#?[A-Za-z0-9]+;)")
+	// reBlockStart matches the start of a line that would read as a block:
+	// a heading, quote, list item, rule or setext underline.
+	reBlockStart = regexp.MustCompile(`^(\s*)([#>+=-]|\d+[.)])`)
+	reBackticks  = regexp.MustCompile("`+")
+)
+
+// markdownMarks are the delimiters of marks with Markdown syntax; the other
+// marks fall back to inline HTML, which GitHub-flavoured Markdown allows.
+var markdownMarks = map[string][2]string{
+	"bold":        {"**", "**"},
+	"italic":      {"*", "*"},
+	"strike":      {"~~", "~~"},
+	"underline":   {"<u>", "</u>"},
+	"highlight":   {"<mark>", "</mark>"},
+	"subscript":   {"<sub>", "</sub>"},
+	"superscript": {"<sup>", "</sup>"},
+}
+
+// Markdown renders the document as GitHub-flavoured Markdown. Like HTML, it
+// expects image IDs to be resolved already.
+func (n *Node) Markdown() string {
+	blocks := n.Content
+	if n.Type != "doc" {
+		blocks = []*Node{n}
+	}
+	out := markdownBlocks(blocks, false)
+	if out == "" {
+		return ""
+	}
+	return out + "\n"
+}
+
+// markdownBlocks renders blocks separated by blank lines, or by single line
+// breaks when tight, as in the items of a list.
+func markdownBlocks(nodes []*Node, tight bool) string {
+	var parts []string
+	for i, node := range nodes {
+		md := markdownBlock(node)
+		if md == "" {
+			continue
+		}
+		// Two lists in a row would merge into one; a comment keeps them apart.
+		if i > 0 && isList(node) && isList(nodes[i-1]) {
+			parts = append(parts, "<!-- -->")
+		}
+		parts = append(parts, md)
+	}
+	sep := "\n\n"
+	if tight {
+		sep = "\n"
+	}
+	return strings.Join(parts, sep)
+}
+
+func isList(n *Node) bool {
+	return n.Type == "bulletList" || n.Type == "orderedList" || n.Type == "taskList"
+}
+
+func markdownBlock(n *Node) string {
+	switch n.Type {
+	case "paragraph":
+		return escapeBlockStarts(markdownInline(n.Content, false))
+	case "heading":
+		level := n.attrInt("level", 1)
+		if level < 1 || level > 6 {
+			level = 1
+		}
+		text := strings.ReplaceAll(markdownInline(n.Content, false), "\\\n", " ")
+		return strings.Repeat("#", level) + " " + text
+	case "blockquote":
+		return prefixLines(markdownBlocks(n.Content, false), "> ", "> ")
+	case "bulletList", "taskList":
+		return markdownList(n, func(int) string { return "- " })
+	case "orderedList":
+		start := n.attrInt("start", 1)
+		return markdownList(n, func(i int) string { return strconv.Itoa(start+i) + ". " })
+	case "codeBlock":
+		code := textOf(n)
+		fence := "```"
+		for _, run := range reBackticks.FindAllString(code, -1) {
+			if len(run) >= len(fence) {
+				fence = strings.Repeat("`", len(run)+1)
+			}
+		}
+		return fence + n.attrString("language") + "\n" + code + "\n" + fence
+	case "horizontalRule":
+		return "---"
+	case "image", "imageBlock":
+		return markdownImage(n)
+	case "table":
+		return markdownTable(n)
+	case "youtube":
+		if src := n.attrString("src"); PlayerURL(src) != "" {
+			return "<" + src + ">"
+		}
+		return ""
+	case "embed":
+		return markdownEmbed(n)
+	case "callout":
+		alert := "> [!" + strings.ToUpper(calloutVariant(n)) + "]"
+		if body := markdownBlocks(n.Content, false); body != "" {
+			return alert + "\n" + prefixLines(body, "> ", "> ")
+		}
+		return alert
+	case "blockMath":
+		return "$$\n" + strings.TrimSpace(n.attrString("latex")) + "\n$$"
+	case "footnotes":
+		return markdownFootnotes(n)
+	case "text", "hardBreak", "inlineMath", "footnoteReference":
+		return escapeBlockStarts(markdownInline([]*Node{n}, false))
+	}
+	return markdownBlocks(n.Content, false)
+}
+
+// markdownList renders the items of a list. Lists whose items hold a single
+// paragraph, perhaps followed by nested lists, come out tight, without blank
+// lines between items.
+func markdownList(n *Node, marker func(int) string) string {
+	tight := true
+	for _, item := range n.Content {
+		for i, block := range item.Content {
+			if i == 0 && block.Type != "paragraph" || i > 0 && !isList(block) {
+				tight = false
+			}
+		}
+	}
+	var items []string
+	for i, item := range n.Content {
+		m := marker(i)
+		if item.Type == "taskItem" {
+			if item.attrBool("checked") {
+				m += "[x] "
+			} else {
+				m += "[ ] "
+			}
+		}
+		body := markdownBlocks(item.Content, tight)
+		if body == "" {
+			items = append(items, strings.TrimRight(m, " "))
+			continue
+		}
+		// Continuation lines line up with the text after the list marker.
+		indent := strings.Repeat(" ", len(marker(i)))
+		items = append(items, prefixLines(body, m, indent))
+	}
+	if tight {
+		return strings.Join(items, "\n")
+	}
+	return strings.Join(items, "\n\n")
+}
+
+func markdownImage(n *Node) string {
+	src := safeImageURL(n.attrString("src"))
+	if src == "" {
+		return ""
+	}
+	title := n.attrString("title")
+	if title == "" {
+		title = n.attrString("caption")
+	}
+	md := "![" + escapeMarkdown(n.attrString("alt")) + "](" + markdownURL(src)
+	if title != "" {
+		md += " " + strconv.Quote(title)
+	}
+	return md + ")"
+}
+
+// markdownEmbed renders a link preview as a link titled like the page, with
+// its description quoted below. Videos come out as bare links, which many
+// Markdown renderers turn into players.
+func markdownEmbed(n *Node) string {
+	link := safeURL(n.attrString("url"))
+	if link == "" {
+		return ""
+	}
+	title := n.attrString("title")
+	if title == "" || PlayerURL(n.attrString("video")) != "" {
+		return "<" + link + ">"
+	}
+	md := "[" + escapeMarkdown(title) + "](" + markdownURL(link) + ")"
+	if desc := n.attrString("description"); desc != "" {
+		md += "\n\n" + prefixLines(escapeBlockStarts(escapeMarkdown(desc)), "> ", "> ")
+	}
+	return md
+}
+
+// markdownFootnotes renders footnote definitions labelled with their IDs;
+// Markdown renderers number footnotes themselves. Paragraphs after the first
+// are indented to stay in the footnote.
+func markdownFootnotes(n *Node) string {
+	var defs []string
+	for _, footnote := range n.Content {
+		label := "[^" + footnote.attrString("id") + "]: "
+		body := markdownBlocks(footnote.Content, false)
+		if body == "" {
+			defs = append(defs, strings.TrimRight(label, " "))
+			continue
+		}
+		defs = append(defs, prefixLines(body, label, "    "))
+	}
+	return strings.Join(defs, "\n\n")
+}
+
+// markdownTable renders a GFM table. The first row is the header, as GFM
+// requires one, and gives the column alignment. Merged cells are followed by
+// empty ones to keep the columns.
+func markdownTable(n *Node) string {
+	var rows [][]string
+	var aligns []string
+	columns := 0
+	for r, row := range n.Content {
+		var cells []string
+		for _, cell := range row.Content {
+			if r == 0 {
+				align := ""
+				if len(cell.Content) > 0 {
+					align = cell.Content[0].attrString("textAlign")
+				}
+				aligns = append(aligns, align)
+			}
+			var parts []string
+			for _, block := range cell.Content {
+				if text := markdownInline(block.Content, true); text != "" {
+					parts = append(parts, text)
+				}
+			}
+			cells = append(cells, strings.Join(parts, "<br>"))
+			for span := cell.attrInt("colspan", 1); span > 1; span-- {
+				cells = append(cells, "")
+			}
+		}
+		if len(cells) > columns {
+			columns = len(cells)
+		}
+		rows = append(rows, cells)
+	}
+	if columns == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	for i, cells := range rows {
+		for len(cells) < columns {
+			cells = append(cells, "")
+		}
+		b.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+		if i == 0 {
+			b.WriteString("|")
+			for c := 0; c < columns; c++ {
+				align := ""
+				if c < len(aligns) {
+					align = aligns[c]
+				}
+				b.WriteString(" " + alignDelimiter(align) + " |")
+			}
+			b.WriteString("\n")
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func alignDelimiter(align string) string {
+	switch align {
+	case "left":
+		return ":---"
+	case "center":
+		return ":---:"
+	case "right":
+		return "---:"
+	}
+	return "---"
+}
+
+// markdownInline renders inline content. Marks are opened and closed as the
+// set of marks changes from one node to the next, so text sharing a mark
+// shares one pair of delimiters. In table cells, line breaks become <br> and
+// pipes are escaped.
+func markdownInline(nodes []*Node, inTable bool) string {
+	var b strings.Builder
+	var open []Mark
+	pending := "" // trailing spaces kept outside the marks closed after them
+
+	transition := func(marks []Mark, lead string) {
+		keep := 0
+		for keep < len(open) && keep < len(marks) && sameMarks(open[keep:keep+1], marks[keep:keep+1]) {
+			keep++
+		}
+		for i := len(open) - 1; i >= keep; i-- {
+			b.WriteString(closeMark(open[i]))
+		}
+		b.WriteString(pending + lead)
+		pending = ""
+		for _, m := range marks[keep:] {
+			b.WriteString(openMark(m))
+		}
+		open = marks
+	}
+
+	for _, n := range nodes {
+		switch n.Type {
+		case "text":
+			marks, code := splitCodeMark(n.Marks)
+			text := n.Text
+			lead := text[:len(text)-len(strings.TrimLeft(text, " "))]
+			text = strings.TrimLeft(text, " ")
+			core := strings.TrimRight(text, " ")
+			trail := text[len(core):]
+			if core == "" {
+				pending += lead + trail
+				continue
+			}
+			transition(marks, lead)
+			if code {
+				b.WriteString(codeSpan(core))
+			} else {
+				escaped := escapeMarkdown(core)
+				if inTable {
+					escaped = strings.ReplaceAll(escaped, "|", "\\|")
+				}
+				b.WriteString(escaped)
+			}
+			pending = trail
+		case "hardBreak":
+			transition(open, "")
+			if inTable {
+				b.WriteString("<br>")
+			} else {
+				b.WriteString("\\\n")
+			}
+		case "image":
+			transition(nil, "")
+			b.WriteString(markdownImage(n))
+		case "inlineMath":
+			transition(nil, "")
+			b.WriteString("$" + strings.TrimSpace(n.attrString("latex")) + "$")
+		case "footnoteReference":
+			transition(nil, "")
+			b.WriteString("[^" + n.attrString("id") + "]")
+		default:
+			transition(nil, "")
+			b.WriteString(markdownInline(n.Content, inTable))
+		}
+	}
+	transition(nil, "")
+	return strings.TrimRight(b.String(), " ")
+}
+
+// splitCodeMark takes the code mark out of marks, as code spans are written
+// directly instead of through delimiters, and drops marks Markdown cannot
+// show, like text colour, and links with unsafe URLs.
+func splitCodeMark(marks []Mark) ([]Mark, bool) {
+	var out []Mark
+	code := false
+	for _, m := range marks {
+		if m.Type == "code" {
+			code = true
+			continue
+		}
+		if _, ok := markdownMarks[m.Type]; ok || m.Type == "link" && safeURL(m.attrString("href")) != "" {
+			out = append(out, m)
+		}
+	}
+	return out, code
+}
+
+func openMark(m Mark) string {
+	if m.Type == "link" {
+		return "["
+	}
+	return markdownMarks[m.Type][0]
+}
+
+func closeMark(m Mark) string {
+	if m.Type != "link" {
+		return markdownMarks[m.Type][1]
+	}
+	out := "](" + markdownURL(safeURL(m.attrString("href")))
+	if title := m.attrString("title"); title != "" {
+		out += " " + strconv.Quote(title)
+	}
+	return out + ")"
+}
+
+// codeSpan wraps code in enough backticks not to be closed early by those it
+// contains.
+func codeSpan(code string) string {
+	longest := 0
+	for _, run := range reBackticks.FindAllString(code, -1) {
+		if len(run) > longest {
+			longest = len(run)
+		}
+	}
+	fence := strings.Repeat("`", longest+1)
+	if strings.HasPrefix(code, "`") || strings.HasSuffix(code, "`") {
+		return fence + " " + code + " " + fence
+	}
+	return fence + code + fence
+}
+
+func escapeMarkdown(text string) string {
+	return reMarkdownSpecial.ReplaceAllStringFunc(text, func(s string) string {
+		return "\\" + s
+	})
+}
+
+// escapeBlockStarts escapes what would make a line of a paragraph read as
+// another kind of block.
+func escapeBlockStarts(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if m := reBlockStart.FindStringSubmatchIndex(line); m != nil {
+			// Escape the character ending the marker: "#", ">", "-", or the
+			// dot after a number.
+			at := m[5] - 1
+			lines[i] = line[:at] + "\\" + line[at:]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// markdownURL writes a link destination, in angle brackets when it holds
+// characters that would end it.
+func markdownURL(u string) string {
+	if strings.ContainsAny(u, " ()<>") {
+		return "<" + strings.NewReplacer("<", "%3C", ">", "%3E").Replace(u) + ">"
+	}
+	return u
+}
+
+// prefixLines puts first before the first line of text and rest before the
+// others; blank lines stay blank apart from a quote marker.
+func prefixLines(text, first, rest string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		prefix := rest
+		if i == 0 {
+			prefix = first
+		}
+		if line == "" {
+			lines[i] = strings.TrimRight(prefix, " ")
+			continue
+		}
+		lines[i] = prefix + line
+	}
+	return strings.Join(lines, "\n")
+}
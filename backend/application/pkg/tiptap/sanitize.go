@@ -0,0 +1,41 @@
+package tiptap
+
+import (
+	"net/url"
+	"strings"
+)
+
+// safeURL returns raw if it is fit for a link: a relative URL, a fragment or
+// an http, https or mailto URL. Anything else, javascript: and data: URLs
+// included, yields "".
+func safeURL(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return ""
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "":
+		// Control characters and backslashes let browsers read a scheme
+		// into what parses as a relative URL here.
+		if strings.ContainsAny(raw, "\\\t\r\n") || strings.Contains(strings.SplitN(raw, "/", 2)[0], ":") {
+			return ""
+		}
+		return raw
+	case "http", "https", "mailto":
+		return raw
+	}
+	return ""
+}
+
+// safeImageURL is safeURL for image sources, which must be http(s) or relative.
+func safeImageURL(raw string) string {
+	src := safeURL(raw)
+	if strings.HasPrefix(strings.ToLower(src), "mailto:") {
+		return ""
+	}
+	return src
+}
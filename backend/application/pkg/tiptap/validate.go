@@ -0,0 +1,502 @@
+package tiptap
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Limits bound the size of a document.
+type Limits struct {
+	MaxBytes int // encoded size
+	MaxDepth int // nesting of nodes, the document itself at depth 1
+	MaxNodes int
+}
+
+// DefaultLimits leave room for long posts with many tables and lists.
+var DefaultLimits = Limits{MaxBytes: 2 << 20, MaxDepth: 40, MaxNodes: 50000}
+
+// ValidationError points at the part of a document that is not allowed, as a
+// path like content[2].marks[0].attrs.href.
+type ValidationError struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+func (e *ValidationError) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return e.Path + ": " + e.Message
+}
+
+type attrKind int
+
+const (
+	attrText attrKind = iota
+	attrInt
+	attrBool
+	attrEnum
+	attrLink     // safeURL
+	attrImageSrc // safeImageURL
+	attrVideoSrc // YouTube page or embed URL
+	attrWebURL   // absolute http(s) URL
+	attrColor
+	attrPattern
+	attrIntList // table column widths
+)
+
+type attrSpec struct {
+	kind     attrKind
+	values   []string       // attrEnum
+	pattern  *regexp.Regexp // attrPattern
+	min, max int            // attrInt
+	maxLen   int            // strings, 0 for the default
+	required bool           // must be set, and not to an empty string
+}
+
+// content groups of the schema.
+const (
+	groupBlock  = "block"
+	groupInline = "inline"
+)
+
+type nodeSpec struct {
+	groups  []string
+	content []string // groups or node types allowed as children; nil for leaves
+	attrs   map[string]attrSpec
+	marks   bool // children may carry marks
+}
+
+var (
+	reCSSColor   = regexp.MustCompile(`^(?:#[0-9A-Fa-f]{3,8}|rgba?\(\s*[0-9.%]+(?:\s*,\s*[0-9.%]+){2,3}\s*\)|[A-Za-z]{3,20})$`)
+	reLanguage   = regexp.MustCompile(`^[A-Za-z0-9_+#.-]{1,40}$`)
+	reImageWidth = regexp.MustCompile(`^\d{1,4}(?:px|%)?$`)
+	reImageID    = regexp.MustCompile(`^[A-Za-z0-9._-]{1,100}$`)
+	reYouTube    = regexp.MustCompile(`^https://(?:(?:www\.|m\.)?youtube(?:-nocookie)?\.com|youtu\.be)/`)
+	reFootnoteID = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+)
+
+var (
+	textAlignAttr = attrSpec{kind: attrEnum, values: []string{"left", "center", "right", "justify"}}
+	cellAttrs     = map[string]attrSpec{
+		"colspan":  {kind: attrInt, min: 1, max: 100},
+		"rowspan":  {kind: attrInt, min: 1, max: 1000},
+		"colwidth": {kind: attrIntList, min: 0, max: 10000},
+	}
+	footnoteAttrs = map[string]attrSpec{"id": {kind: attrPattern, pattern: reFootnoteID, required: true}}
+	mathAttrs     = map[string]attrSpec{"latex": {kind: attrText, maxLen: 10000, required: true}}
+	imageAttrs    = map[string]attrSpec{
+		"src":         {kind: attrImageSrc},
+		"alt":         {kind: attrText, maxLen: 1000},
+		"title":       {kind: attrText, maxLen: 1000},
+		"dataImageId": {kind: attrPattern, pattern: reImageID},
+	}
+)
+
+// nodeSchema lists the nodes the editor can produce, with their attributes.
+var nodeSchema = map[string]nodeSpec{
+	"doc":            {content: []string{groupBlock, "footnotes"}},
+	"paragraph":      {groups: []string{groupBlock}, content: []string{groupInline}, marks: true, attrs: map[string]attrSpec{"textAlign": textAlignAttr}},
+	"heading":        {groups: []string{groupBlock}, content: []string{groupInline}, marks: true, attrs: map[string]attrSpec{"level": {kind: attrInt, min: 1, max: 6}, "textAlign": textAlignAttr}},
+	"blockquote":     {groups: []string{groupBlock}, content: []string{groupBlock}},
+	"bulletList":     {groups: []string{groupBlock}, content: []string{"listItem"}},
+	"orderedList":    {groups: []string{groupBlock}, content: []string{"listItem"}, attrs: map[string]attrSpec{"start": {kind: attrInt, min: 0, max: math.MaxInt32}, "type": {kind: attrEnum, values: []string{"1", "a", "A", "i", "I"}}}},
+	"listItem":       {content: []string{groupBlock}},
+	"taskList":       {groups: []string{groupBlock}, content: []string{"taskItem"}},
+	"taskItem":       {content: []string{groupBlock}, attrs: map[string]attrSpec{"checked": {kind: attrBool}}},
+	"codeBlock":      {groups: []string{groupBlock}, content: []string{"text"}, attrs: map[string]attrSpec{"language": {kind: attrPattern, pattern: reLanguage}}},
+	"horizontalRule": {groups: []string{groupBlock}},
+	"image":          {groups: []string{groupBlock, groupInline}, attrs: imageAttrs},
+	"imageBlock": {groups: []string{groupBlock}, attrs: map[string]attrSpec{
+		"src":         {kind: attrImageSrc},
+		"alt":         {kind: attrText, maxLen: 1000},
+		"title":       {kind: attrText, maxLen: 1000},
+		"caption":     {kind: attrText, maxLen: 2000},
+		"alignment":   {kind: attrEnum, values: []string{"left", "center", "right"}},
+		"width":       {kind: attrPattern, pattern: reImageWidth},
+		"dataImageId": {kind: attrPattern, pattern: reImageID},
+	}},
+	"table":       {groups: []string{groupBlock}, content: []string{"tableRow"}},
+	"tableRow":    {content: []string{"tableCell", "tableHeader"}},
+	"tableCell":   {content: []string{groupBlock}, attrs: cellAttrs},
+	"tableHeader": {content: []string{groupBlock}, attrs: cellAttrs},
+	"youtube": {groups: []string{groupBlock}, attrs: map[string]attrSpec{
+		"src":    {kind: attrVideoSrc},
+		"start":  {kind: attrInt, min: 0, max: math.MaxInt32},
+		"width":  {kind: attrInt, min: 0, max: 10000},
+		"height": {kind: attrInt, min: 0, max: 10000},
+	}},
+	"embed": {groups: []string{groupBlock}, attrs: map[string]attrSpec{
+		"url":         {kind: attrWebURL, required: true},
+		"title":       {kind: attrText, maxLen: 300},
+		"description": {kind: attrText, maxLen: 1000},
+		"siteName":    {kind: attrText, maxLen: 200},
+		"image":       {kind: attrImageSrc},
+		"dataImageId": {kind: attrPattern, pattern: reImageID},
+		"video":       {kind: attrVideoSrc},
+	}},
+	"callout": {groups: []string{groupBlock}, content: []string{groupBlock}, attrs: map[string]attrSpec{
+		"variant": {kind: attrEnum, values: calloutVariants},
+	}},
+	"blockMath":         {groups: []string{groupBlock}, attrs: mathAttrs},
+	"inlineMath":        {groups: []string{groupInline}, attrs: mathAttrs},
+	"footnoteReference": {groups: []string{groupInline}, attrs: footnoteAttrs},
+	"footnotes":         {content: []string{"footnote"}},
+	"footnote":          {content: []string{"paragraph"}, attrs: footnoteAttrs},
+	"text":              {groups: []string{groupInline}},
+	"hardBreak":         {groups: []string{groupInline}},
+}
+
+// markSchema lists the marks the editor can produce, with their attributes.
+var markSchema = map[string]map[string]attrSpec{
+	"bold":        nil,
+	"italic":      nil,
+	"underline":   nil,
+	"strike":      nil,
+	"code":        nil,
+	"subscript":   nil,
+	"superscript": nil,
+	"link": {
+		"href":   {kind: attrLink},
+		"target": {kind: attrEnum, values: []string{"_blank", "_self"}},
+		"rel":    {kind: attrPattern, pattern: regexp.MustCompile(`^[a-z ]{0,100}$`)},
+		"class":  {kind: attrPattern, pattern: regexp.MustCompile(`^[A-Za-z0-9_ -]{0,100}$`)},
+		"title":  {kind: attrText, maxLen: 1000},
+	},
+	"highlight": {"color": {kind: attrColor}},
+	"textStyle": {"color": {kind: attrColor}},
+}
+
+// maxAttrLength bounds string attributes without a limit of their own.
+const maxAttrLength = 4096
+
+// Validate checks an encoded document against the editor's schema: known
+// node and mark types in the places the editor allows them, attributes of
+// the right type with safe URLs, and the size limits. Attributes may be null,
+// which the editor reads as their default.
+func Validate(data json.RawMessage, limits Limits) error {
+	if len(data) > limits.MaxBytes {
+		return &ValidationError{Message: fmt.Sprintf("content is larger than %d KB", limits.MaxBytes>>10)}
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var root interface{}
+	if err := dec.Decode(&root); err != nil {
+		return &ValidationError{Message: "content is not valid JSON"}
+	}
+	node, ok := root.(map[string]interface{})
+	if !ok || node["type"] != "doc" {
+		return &ValidationError{Message: "content must be a document with type doc"}
+	}
+	v := &validator{limits: limits}
+	return v.node(node, "", 1, nil)
+}
+
+type validator struct {
+	limits    Limits
+	nodes     int
+	footnotes map[string]bool // IDs of the footnotes defined so far
+}
+
+func (v *validator) node(node map[string]interface{}, path string, depth int, parent *nodeSpec) error {
+	fail := func(at, format string, args ...interface{}) error {
+		return &ValidationError{Path: joinPath(path, at), Message: fmt.Sprintf(format, args...)}
+	}
+
+	v.nodes++
+	if v.nodes > v.limits.MaxNodes {
+		return fail("", "content has more than %d nodes", v.limits.MaxNodes)
+	}
+	if depth > v.limits.MaxDepth {
+		return fail("", "content is nested more than %d levels deep", v.limits.MaxDepth)
+	}
+
+	nodeType, ok := node["type"].(string)
+	if !ok {
+		return fail("type", "node type is missing")
+	}
+	spec, ok := nodeSchema[nodeType]
+	if !ok {
+		return fail("type", "unknown node type %q", nodeType)
+	}
+	if parent != nil && !allows(parent, nodeType, spec) {
+		return fail("type", "%s is not allowed here", nodeType)
+	}
+
+	for _, key := range sortedKeys(node) {
+		value := node[key]
+		switch key {
+		case "type":
+		case "attrs":
+			attrs, ok := value.(map[string]interface{})
+			if value != nil && !ok {
+				return fail("attrs", "attrs must be an object")
+			}
+			if err := checkAttrs(attrs, spec.attrs, joinPath(path, "attrs"), nodeType); err != nil {
+				return err
+			}
+		case "text":
+			text, ok := value.(string)
+			if nodeType != "text" || !ok {
+				return fail("text", "only text nodes have text")
+			}
+			if text == "" {
+				return fail("text", "text nodes cannot be empty")
+			}
+		case "marks":
+			if nodeType != "text" || parent == nil || !parent.marks {
+				if marks, _ := value.([]interface{}); len(marks) > 0 {
+					return fail("marks", "%s cannot have marks here", nodeType)
+				}
+			}
+			if err := v.marks(value, joinPath(path, "marks")); err != nil {
+				return err
+			}
+		case "content":
+			if value == nil {
+				continue
+			}
+			children, ok := value.([]interface{})
+			if !ok {
+				return fail("content", "content must be an array")
+			}
+			if spec.content == nil && len(children) > 0 {
+				return fail("content", "%s cannot have content", nodeType)
+			}
+			for i, child := range children {
+				childPath := joinPath(path, fmt.Sprintf("content[%d]", i))
+				childNode, ok := child.(map[string]interface{})
+				if !ok {
+					return &ValidationError{Path: childPath, Message: "node must be an object"}
+				}
+				if err := v.node(childNode, childPath, depth+1, &spec); err != nil {
+					return err
+				}
+			}
+		default:
+			return fail(key, "unknown field %q", key)
+		}
+	}
+	if nodeType == "text" {
+		if _, ok := node["text"]; !ok {
+			return fail("text", "text nodes need text")
+		}
+	}
+	attrs, _ := node["attrs"].(map[string]interface{})
+	for _, key := range sortedSpecKeys(spec.attrs) {
+		if value, _ := attrs[key].(string); spec.attrs[key].required && value == "" {
+			return fail("attrs."+key, "%s needs a %s", nodeType, key)
+		}
+	}
+	if nodeType == "footnote" {
+		id, _ := attrs["id"].(string)
+		if v.footnotes[id] {
+			return fail("attrs.id", "footnote %q is defined twice", id)
+		}
+		if v.footnotes == nil {
+			v.footnotes = make(map[string]bool)
+		}
+		v.footnotes[id] = true
+	}
+	return nil
+}
+
+func (v *validator) marks(value interface{}, path string) error {
+	if value == nil {
+		return nil
+	}
+	marks, ok := value.([]interface{})
+	if !ok {
+		return &ValidationError{Path: path, Message: "marks must be an array"}
+	}
+	seen := make(map[string]bool)
+	for i, m := range marks {
+		markPath := fmt.Sprintf("%s[%d]", path, i)
+		mark, ok := m.(map[string]interface{})
+		if !ok {
+			return &ValidationError{Path: markPath, Message: "mark must be an object"}
+		}
+		markType, _ := mark["type"].(string)
+		spec, ok := markSchema[markType]
+		if !ok {
+			return &ValidationError{Path: joinPath(markPath, "type"), Message: fmt.Sprintf("unknown mark type %q", markType)}
+		}
+		if seen[markType] {
+			return &ValidationError{Path: joinPath(markPath, "type"), Message: fmt.Sprintf("mark %s is applied twice", markType)}
+		}
+		seen[markType] = true
+		for _, key := range sortedKeys(mark) {
+			value := mark[key]
+			switch key {
+			case "type":
+			case "attrs":
+				attrs, ok := value.(map[string]interface{})
+				if value != nil && !ok {
+					return &ValidationError{Path: joinPath(markPath, "attrs"), Message: "attrs must be an object"}
+				}
+				if err := checkAttrs(attrs, spec, joinPath(markPath, "attrs"), markType); err != nil {
+					return err
+				}
+			default:
+				return &ValidationError{Path: joinPath(markPath, key), Message: fmt.Sprintf("unknown field %q", key)}
+			}
+		}
+		if markType == "link" {
+			attrs, _ := mark["attrs"].(map[string]interface{})
+			if href, _ := attrs["href"].(string); href == "" {
+				return &ValidationError{Path: joinPath(markPath, "attrs.href"), Message: "links need an href"}
+			}
+		}
+	}
+	return nil
+}
+
+// allows reports whether a node of type nodeType fits in parent.
+func allows(parent *nodeSpec, nodeType string, spec nodeSpec) bool {
+	for _, allowed := range parent.content {
+		if allowed == nodeType {
+			return true
+		}
+		for _, group := range spec.groups {
+			if allowed == group {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func checkAttrs(attrs map[string]interface{}, specs map[string]attrSpec, path, owner string) error {
+	for _, key := range sortedKeys(attrs) {
+		value := attrs[key]
+		at := joinPath(path, key)
+		spec, ok := specs[key]
+		if !ok {
+			return &ValidationError{Path: at, Message: fmt.Sprintf("%s has no attribute %q", owner, key)}
+		}
+		if value == nil {
+			continue
+		}
+		if msg := checkAttr(spec, value); msg != "" {
+			return &ValidationError{Path: at, Message: msg}
+		}
+	}
+	return nil
+}
+
+// checkAttr returns what is wrong with value, or "" if nothing.
+func checkAttr(spec attrSpec, value interface{}) string {
+	switch spec.kind {
+	case attrInt:
+		n, ok := value.(json.Number)
+		i, err := n.Int64()
+		if !ok || err != nil {
+			return "must be a whole number"
+		}
+		if i < int64(spec.min) || i > int64(spec.max) {
+			return fmt.Sprintf("must be between %d and %d", spec.min, spec.max)
+		}
+		return ""
+	case attrBool:
+		if _, ok := value.(bool); !ok {
+			return "must be true or false"
+		}
+		return ""
+	case attrIntList:
+		list, ok := value.([]interface{})
+		if !ok || len(list) > 1000 {
+			return "must be a list of numbers"
+		}
+		for _, item := range list {
+			if item == nil {
+				continue
+			}
+			if msg := checkAttr(attrSpec{kind: attrInt, min: spec.min, max: spec.max}, item); msg != "" {
+				return msg
+			}
+		}
+		return ""
+	}
+
+	s, ok := value.(string)
+	if !ok {
+		return "must be a string"
+	}
+	maxLen := spec.maxLen
+	if maxLen == 0 {
+		maxLen = maxAttrLength
+	}
+	if len(s) > maxLen {
+		return fmt.Sprintf("is longer than %d characters", maxLen)
+	}
+	switch spec.kind {
+	case attrEnum:
+		for _, allowed := range spec.values {
+			if s == allowed {
+				return ""
+			}
+		}
+		return fmt.Sprintf("must be one of %s", strings.Join(spec.values, ", "))
+	case attrLink:
+		if safeURL(s) == "" {
+			return "URL must be relative or use http, https or mailto"
+		}
+	case attrImageSrc:
+		if s != "" && safeImageURL(s) == "" {
+			return "image URL must be relative or use http or https"
+		}
+	case attrVideoSrc:
+		if u, err := url.Parse(s); err != nil || !reYouTube.MatchString(s) || u.User != nil {
+			return "video must be a YouTube URL"
+		}
+	case attrWebURL:
+		if u, err := url.Parse(s); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.User != nil {
+			return "must be an http or https URL"
+		}
+	case attrColor:
+		if !reCSSColor.MatchString(s) {
+			return "must be a CSS color"
+		}
+	case attrPattern:
+		if s != "" && !spec.pattern.MatchString(s) {
+			return "has an invalid format"
+		}
+	}
+	return ""
+}
+
+// sortedKeys makes the reported problem the same from one run to the next
+// when a node has several.
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func joinPath(path, key string) string {
+	switch {
+	case key == "":
+		return path
+	case path == "":
+		return key
+	}
+	return path + "." + key
+}
+
+func sortedSpecKeys(m map[string]attrSpec) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,111 @@
+package unfurl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+// ErrBlockedAddress is returned for links that resolve to an address the
+// server must not reach on a user's behalf: loopback, private networks,
+// link-local ranges such as cloud metadata endpoints, and the like.
+var ErrBlockedAddress = errors.New("unfurl: address not allowed")
+
+const maxRedirects = 5
+
+// Fetcher sends the unfurler's requests. *http.Client satisfies it, so tests
+// can unfurl pages from an httptest server with its client.
+type Fetcher interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// NewFetcher returns the client to unfurl untrusted links with. It connects
+// only to public addresses on ports 80 and 443, checked after DNS resolution
+// so a hostname cannot point it inside the network, and gives up after
+// timeout.
+func NewFetcher(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			return checkAddress(address)
+		},
+	}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			// No proxy from the environment: it would make the dialer check
+			// the proxy instead of the link.
+			Proxy: nil,
+			DialContext: func(ctx context.Context, network, address string) (net.Conn, error) {
+				return dialer.DialContext(ctx, network, address)
+			},
+			TLSHandshakeTimeout:   timeout,
+			ResponseHeaderTimeout: timeout,
+			MaxIdleConns:          10,
+			IdleConnTimeout:       30 * time.Second,
+		},
+		CheckRedirect: checkRedirect,
+	}
+}
+
+// checkRedirect is the redirect policy of NewFetcher's client: at most
+// maxRedirects of them, to http(s) URLs only.
+func checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) > maxRedirects {
+		return fmt.Errorf("unfurl: more than %d redirects", maxRedirects)
+	}
+	if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+		return fmt.Errorf("unfurl: redirect to %s URL", req.URL.Scheme)
+	}
+	return nil
+}
+
+// checkAddress accepts an "ip:port" the dialer is about to connect to.
+func checkAddress(address string) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return ErrBlockedAddress
+	}
+	if port := addrPort.Port(); port != 80 && port != 443 {
+		return ErrBlockedAddress
+	}
+	if !IsPublicAddr(addrPort.Addr()) {
+		return ErrBlockedAddress
+	}
+	return nil
+}
+
+// Ranges outside the ones netip classifies that still must not be reached.
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),      // "this network"
+	netip.MustParsePrefix("100.64.0.0/10"),  // carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),   // IETF protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"),  // benchmarking
+	netip.MustParsePrefix("240.0.0.0/4"),    // reserved, and broadcast
+	netip.MustParsePrefix("64:ff9b::/96"),   // NAT64, which embeds IPv4 addresses
+	netip.MustParsePrefix("64:ff9b:1::/48"), // local-use NAT64
+	netip.MustParsePrefix("2001::/32"),      // Teredo
+	netip.MustParsePrefix("2002::/16"),      // 6to4
+	netip.MustParsePrefix("fec0::/10"),      // deprecated site-local
+}
+
+// IsPublicAddr reports whether addr is a globally routable unicast address.
+func IsPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() ||
+		addr.IsMulticast() {
+		return false
+	}
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,263 @@
+// Package unfurl builds link previews from the OpenGraph and oEmbed metadata
+// of a page, for links authors paste into posts.
+package unfurl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// ErrInvalidURL is returned for links that are not absolute http(s) URLs.
+var ErrInvalidURL = errors.New("unfurl: not an http or https URL")
+
+const (
+	// DefaultMaxBytes is how much of a page is read looking for metadata,
+	// which lives in its head.
+	DefaultMaxBytes = 1 << 20
+	maxOEmbedBytes  = 64 << 10
+	userAgent       = "InsightBot/1.0 (link preview)"
+
+	maxTitleLength       = 300
+	maxDescriptionLength = 1000
+	maxSiteNameLength    = 200
+)
+
+// Preview describes a link the way its site presents it.
+type Preview struct {
+	URL         string `json:"url"` // canonical URL of the page, after redirects
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	SiteName    string `json:"site_name,omitempty"`
+	Image       string `json:"image,omitempty"`
+	// Video is the player the page offers for embedding, if any; whether it
+	// may be embedded is for the caller to decide.
+	Video string `json:"video,omitempty"`
+}
+
+// Unfurler fetches pages and reads their metadata.
+type Unfurler struct {
+	fetcher  Fetcher
+	maxBytes int64
+}
+
+// New returns an Unfurler sending its requests through fetcher, which for
+// links from users should be NewFetcher's.
+func New(fetcher Fetcher) *Unfurler {
+	return &Unfurler{fetcher: fetcher, maxBytes: DefaultMaxBytes}
+}
+
+// Unfurl fetches the page at rawURL and returns its preview. Pages that
+// advertise an oEmbed endpoint have it consulted for what their markup
+// leaves out.
+func (u *Unfurler) Unfurl(ctx context.Context, rawURL string) (*Preview, error) {
+	target, err := parseLink(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := u.get(ctx, target.String(), "text/html,application/xhtml+xml")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return nil, fmt.Errorf("unfurl: %s is %q, not a web page", target, mediaType)
+	}
+	meta := readHead(io.LimitReader(resp.Body, u.maxBytes))
+
+	// Relative URLs in the page are relative to where the redirects ended.
+	base := resp.Request.URL
+	preview := &Preview{
+		URL:         canonicalURL(base, first(meta["og:url"], meta["canonical"])),
+		Title:       first(meta["og:title"], meta["twitter:title"]),
+		Description: first(meta["og:description"], meta["twitter:description"], meta["description"]),
+		SiteName:    meta["og:site_name"],
+		Image:       resolve(base, first(meta["og:image:secure_url"], meta["og:image"], meta["twitter:image"]), ""),
+		Video:       resolve(base, first(meta["og:video:secure_url"], meta["og:video:url"], meta["og:video"]), ""),
+	}
+	if endpoint := meta["oembed"]; endpoint != "" && (preview.Title == "" || preview.Image == "" || preview.SiteName == "") {
+		if oembed, err := u.oEmbed(ctx, resolve(base, endpoint, "")); err == nil {
+			preview.Title = first(preview.Title, oembed.Title)
+			preview.SiteName = first(preview.SiteName, oembed.ProviderName)
+			preview.Image = first(preview.Image, resolve(base, oembed.ThumbnailURL, ""))
+		}
+	}
+	preview.Title = first(preview.Title, meta["title"])
+	if preview.SiteName == "" {
+		preview.SiteName = strings.TrimPrefix(base.Hostname(), "www.")
+	}
+
+	preview.Title = truncate(preview.Title, maxTitleLength)
+	preview.Description = truncate(preview.Description, maxDescriptionLength)
+	preview.SiteName = truncate(preview.SiteName, maxSiteNameLength)
+	return preview, nil
+}
+
+type oEmbedResponse struct {
+	Title        string `json:"title"`
+	ProviderName string `json:"provider_name"`
+	ThumbnailURL string `json:"thumbnail_url"`
+}
+
+func (u *Unfurler) oEmbed(ctx context.Context, endpoint string) (*oEmbedResponse, error) {
+	if endpoint == "" {
+		return nil, ErrInvalidURL
+	}
+	resp, err := u.get(ctx, endpoint, "application/json")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var doc oEmbedResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxOEmbedBytes)).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("unfurl: oEmbed: %w", err)
+	}
+	return &doc, nil
+}
+
+func (u *Unfurler) get(ctx context.Context, target, accept string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, ErrInvalidURL
+	}
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Accept", accept)
+	resp, err := u.fetcher.Do(req)
+	if err != nil {
+		if errors.Is(err, ErrBlockedAddress) {
+			return nil, ErrBlockedAddress
+		}
+		return nil, fmt.Errorf("unfurl: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("unfurl: %s returned %d", target, resp.StatusCode)
+	}
+	return resp, nil
+}
+
+// parseLink accepts absolute http(s) URLs without credentials.
+func parseLink(rawURL string) (*url.URL, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.User != nil {
+		return nil, ErrInvalidURL
+	}
+	u.Fragment = ""
+	return u, nil
+}
+
+// readHead collects the metadata in a page's head: OpenGraph and Twitter
+// properties, the description, the title, the canonical link ("canonical")
+// and the JSON oEmbed endpoint ("oembed"). The first value of each wins.
+func readHead(r io.Reader) map[string]string {
+	meta := make(map[string]string)
+	set := func(key, value string) {
+		value = strings.Join(strings.Fields(value), " ")
+		if _, ok := meta[key]; !ok && value != "" {
+			meta[key] = value
+		}
+	}
+
+	z := html.NewTokenizer(r)
+	inTitle := false
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return meta
+		case html.TextToken:
+			if inTitle {
+				set("title", string(z.Text()))
+			}
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			switch atom.Lookup(name) {
+			case atom.Title:
+				inTitle = false
+			case atom.Head:
+				return meta
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+			tag := atom.Lookup(name)
+			if tag == atom.Body {
+				return meta
+			}
+			if tag == atom.Title {
+				inTitle = true
+				continue
+			}
+			attrs := make(map[string]string)
+			for hasAttr {
+				var key, value []byte
+				key, value, hasAttr = z.TagAttr()
+				attrs[string(key)] = string(value)
+			}
+			switch tag {
+			case atom.Meta:
+				key := strings.ToLower(first(attrs["property"], attrs["name"]))
+				if strings.HasPrefix(key, "og:") || strings.HasPrefix(key, "twitter:") || key == "description" {
+					set(key, attrs["content"])
+				}
+			case atom.Link:
+				for _, rel := range strings.Fields(strings.ToLower(attrs["rel"])) {
+					switch {
+					case rel == "canonical":
+						set("canonical", attrs["href"])
+					case rel == "alternate" && strings.EqualFold(attrs["type"], "application/json+oembed"):
+						set("oembed", attrs["href"])
+					}
+				}
+			}
+		}
+	}
+}
+
+// resolve makes ref absolute against base, returning fallback for anything
+// but http(s) URLs.
+func resolve(base *url.URL, ref, fallback string) string {
+	if ref == "" {
+		return fallback
+	}
+	u, err := base.Parse(ref)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.User != nil {
+		return fallback
+	}
+	return u.String()
+}
+
+// canonicalURL is the URL the page names as its own, when on the same host
+// as the page; a page cannot pass itself off as another site's.
+func canonicalURL(base *url.URL, ref string) string {
+	if u, err := url.Parse(resolve(base, ref, "")); err == nil && strings.EqualFold(u.Host, base.Host) {
+		return u.String()
+	}
+	return base.String()
+}
+
+func first(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func truncate(s string, max int) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	runes := []rune(s)
+	return strings.TrimSpace(string(runes[:max-1])) + "…"
+}
@@ -0,0 +1,326 @@
+// Package wxr reads WordPress eXtended RSS (WXR) exports: the posts, their
+// comments, authors and taxonomy.
+package wxr
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Export is the part of a WXR file an import needs.
+type Export struct {
+	Title      string
+	Authors    []Author
+	Categories []Term
+	Tags       []Term
+	Items      []Item
+}
+
+type Author struct {
+	Login       string `xml:"author_login"`
+	Email       string `xml:"author_email"`
+	DisplayName string `xml:"author_display_name"`
+}
+
+// Term is a category or tag. Slug is WordPress' "nicename".
+type Term struct {
+	Slug string
+	Name string
+}
+
+// Item is a post, page or attachment.
+type Item struct {
+	ID       int64
+	Type     string // post, page, attachment, ...
+	Title    string
+	Slug     string
+	Status   string // publish, draft, pending, private, future, trash
+	Creator  string // author login
+	Content  string
+	Excerpt  string
+	PostedAt time.Time // zero when the post was never published
+	Link     string
+	URL      string // file of an attachment
+	ParentID int64
+	// ThumbnailID is the attachment used as the featured image, 0 for none.
+	ThumbnailID int64
+	Categories  []Term
+	Tags        []Term
+	Comments    []Comment
+}
+
+type Comment struct {
+	ID          int64
+	ParentID    int64
+	AuthorName  string
+	AuthorEmail string
+	Content     string
+	PostedAt    time.Time
+	Approved    bool
+	Type        string // "" or "comment" for comments; pingback and trackback otherwise
+}
+
+// raw* mirror the XML. WXR puts its fields in the wp, dc, content and excerpt
+// namespaces; encoding/xml matches on local names, which are unique here.
+type rawExport struct {
+	Channel struct {
+		Title      string        `xml:"title"`
+		Authors    []Author      `xml:"author"`
+		Categories []rawCategory `xml:"category"`
+		Tags       []rawTag      `xml:"tag"`
+		Items      []rawItem     `xml:"item"`
+	} `xml:"channel"`
+}
+
+type rawCategory struct {
+	Nicename string `xml:"category_nicename"`
+	Name     string `xml:"cat_name"`
+}
+
+type rawTag struct {
+	Slug string `xml:"tag_slug"`
+	Name string `xml:"tag_name"`
+}
+
+type rawItem struct {
+	Title      string        `xml:"title"`
+	Link       string        `xml:"link"`
+	Creator    string        `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	Encoded    []rawEncoded  `xml:"encoded"`
+	ID         int64         `xml:"post_id"`
+	DateGMT    string        `xml:"post_date_gmt"`
+	Date       string        `xml:"post_date"`
+	Name       string        `xml:"post_name"`
+	Status     string        `xml:"status"`
+	Parent     int64         `xml:"post_parent"`
+	Type       string        `xml:"post_type"`
+	AttachURL  string        `xml:"attachment_url"`
+	Meta       []rawMeta     `xml:"postmeta"`
+	Categories []rawItemTerm `xml:"category"`
+	Comments   []rawComment  `xml:"comment"`
+}
+
+// rawEncoded is content:encoded or excerpt:encoded, told apart by namespace.
+type rawEncoded struct {
+	XMLName xml.Name
+	Value   string `xml:",chardata"`
+}
+
+type rawMeta struct {
+	Key   string `xml:"meta_key"`
+	Value string `xml:"meta_value"`
+}
+
+type rawItemTerm struct {
+	Domain   string `xml:"domain,attr"`
+	Nicename string `xml:"nicename,attr"`
+	Name     string `xml:",chardata"`
+}
+
+type rawComment struct {
+	ID          int64  `xml:"comment_id"`
+	Author      string `xml:"comment_author"`
+	AuthorEmail string `xml:"comment_author_email"`
+	DateGMT     string `xml:"comment_date_gmt"`
+	Date        string `xml:"comment_date"`
+	Content     string `xml:"comment_content"`
+	Approved    string `xml:"comment_approved"`
+	Type        string `xml:"comment_type"`
+	Parent      int64  `xml:"comment_parent"`
+}
+
+const dateLayout = "2006-01-02 15:04:05"
+
+// parseDate reads a WordPress date, preferring the GMT one. Unpublished posts
+// carry "0000-00-00 00:00:00", which yields the zero time.
+func parseDate(gmt, local string) time.Time {
+	for _, s := range []string{gmt, local} {
+		if t, err := time.Parse(dateLayout, strings.TrimSpace(s)); err == nil && t.Year() > 1 {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// Parse reads a WXR export. Fields are matched by local name, so the 1.0, 1.1
+// and 1.2 versions of the format are read alike.
+func Parse(r io.Reader) (*Export, error) {
+	dec := xml.NewDecoder(r)
+	dec.Strict = false
+	dec.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		if strings.EqualFold(charset, "utf-8") || strings.EqualFold(charset, "us-ascii") {
+			return input, nil
+		}
+		return nil, fmt.Errorf("unsupported charset %q", charset)
+	}
+
+	var raw rawExport
+	if err := dec.Decode(&raw); err != nil {
+		return nil, fmt.Errorf("wxr: %w", err)
+	}
+	if len(raw.Channel.Items) == 0 && raw.Channel.Title == "" {
+		return nil, fmt.Errorf("wxr: not a WordPress export")
+	}
+
+	export := &Export{Title: raw.Channel.Title, Authors: raw.Channel.Authors}
+	for _, c := range raw.Channel.Categories {
+		export.Categories = append(export.Categories, Term{Slug: c.Nicename, Name: strings.TrimSpace(c.Name)})
+	}
+	for _, t := range raw.Channel.Tags {
+		export.Tags = append(export.Tags, Term{Slug: t.Slug, Name: strings.TrimSpace(t.Name)})
+	}
+
+	for _, ri := range raw.Channel.Items {
+		item := Item{
+			ID:       ri.ID,
+			Type:     ri.Type,
+			Title:    strings.TrimSpace(ri.Title),
+			Slug:     ri.Name,
+			Status:   ri.Status,
+			Creator:  ri.Creator,
+			PostedAt: parseDate(ri.DateGMT, ri.Date),
+			Link:     ri.Link,
+			URL:      ri.AttachURL,
+			ParentID: ri.Parent,
+		}
+		for _, enc := range ri.Encoded {
+			switch {
+			case strings.Contains(enc.XMLName.Space, "/excerpt/"):
+				item.Excerpt = strings.TrimSpace(enc.Value)
+			default:
+				item.Content = enc.Value
+			}
+		}
+		for _, meta := range ri.Meta {
+			if meta.Key == "_thumbnail_id" {
+				item.ThumbnailID, _ = strconv.ParseInt(strings.TrimSpace(meta.Value), 10, 64)
+			}
+		}
+		for _, term := range ri.Categories {
+			t := Term{Slug: term.Nicename, Name: strings.TrimSpace(term.Name)}
+			switch term.Domain {
+			case "category":
+				item.Categories = append(item.Categories, t)
+			case "post_tag":
+				item.Tags = append(item.Tags, t)
+			}
+		}
+		for _, rc := range ri.Comments {
+			item.Comments = append(item.Comments, Comment{
+				ID:          rc.ID,
+				ParentID:    rc.Parent,
+				AuthorName:  strings.TrimSpace(rc.Author),
+				AuthorEmail: strings.TrimSpace(rc.AuthorEmail),
+				Content:     rc.Content,
+				PostedAt:    parseDate(rc.DateGMT, rc.Date),
+				Approved:    rc.Approved == "1",
+				Type:        rc.Type,
+			})
+		}
+		export.Items = append(export.Items, item)
+	}
+	return export, nil
+}
+
+// Posts returns the items that are blog posts, leaving out pages,
+// attachments, menu items and trashed posts.
+func (e *Export) Posts() []Item {
+	var posts []Item
+	for _, item := range e.Items {
+		if item.Type == "post" && item.Status != "trash" && item.Status != "auto-draft" {
+			posts = append(posts, item)
+		}
+	}
+	return posts
+}
+
+// AttachmentURLs maps attachment IDs to their file URLs.
+func (e *Export) AttachmentURLs() map[int64]string {
+	urls := make(map[int64]string)
+	for _, item := range e.Items {
+		if item.Type == "attachment" && item.URL != "" {
+			urls[item.ID] = item.URL
+		}
+	}
+	return urls
+}
+
+// Author returns the author with the given login.
+func (e *Export) Author(login string) (Author, bool) {
+	for _, a := range e.Authors {
+		if a.Login == login {
+			return a, true
+		}
+	}
+	return Author{}, false
+}
+
+var (
+	captionShortcode = regexp.MustCompile(`(?s)\[caption[^\]]*\](.*?)\[/caption\]`)
+	captionImage     = regexp.MustCompile(`(?s)^\s*((?:<a\b[^>]*>\s*)?<img\b[^>]*>(?:\s*</a>)?)(.*)$`)
+	embedShortcode   = regexp.MustCompile(`(?s)\[embed[^\]]*\](.*?)\[/embed\]`)
+	galleryShortcode = regexp.MustCompile(`\[gallery[^\]]*\]`)
+)
+
+// HTML returns the item's content as markup. The [caption] shortcode becomes a
+// figure, [embed] leaves its URL and [gallery], whose images are not part of
+// the content, is dropped.
+func (i Item) HTML() string {
+	content := captionShortcode.ReplaceAllStringFunc(i.Content, func(sc string) string {
+		inner := captionShortcode.FindStringSubmatch(sc)[1]
+		m := captionImage.FindStringSubmatch(inner)
+		if m == nil {
+			return inner
+		}
+		return "<figure>" + m[1] + "<figcaption>" + strings.TrimSpace(m[2]) + "</figcaption></figure>"
+	})
+	content = embedShortcode.ReplaceAllString(content, "$1")
+	content = galleryShortcode.ReplaceAllString(content, "")
+	return AutoParagraph(content)
+}
+
+// AutoParagraph mimics WordPress' wpautop for content stored without <p>
+// tags: blank lines separate paragraphs and single newlines become <br>.
+// Content that already has paragraphs is returned as it is.
+func AutoParagraph(content string) string {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	if strings.Contains(content, "<p") || strings.TrimSpace(content) == "" {
+		return content
+	}
+	var b strings.Builder
+	for _, block := range strings.Split(content, "\n\n") {
+		block = strings.TrimSpace(block)
+		if block == "" {
+			continue
+		}
+		if startsWithBlockTag(block) {
+			b.WriteString(block)
+			b.WriteByte('\n')
+			continue
+		}
+		b.WriteString("<p>")
+		b.WriteString(strings.ReplaceAll(block, "\n", "<br />\n"))
+		b.WriteString("</p>\n")
+	}
+	return b.String()
+}
+
+var blockTags = []string{
+	"<div", "<ul", "<ol", "<li", "<blockquote", "<pre", "<h1", "<h2", "<h3", "<h4",
+	"<h5", "<h6", "<table", "<figure", "<hr", "<iframe", "<!--",
+}
+
+func startsWithBlockTag(s string) bool {
+	lower := strings.ToLower(s)
+	for _, tag := range blockTags {
+		if strings.HasPrefix(lower, tag) {
+			return true
+		}
+	}
+	return false
+}
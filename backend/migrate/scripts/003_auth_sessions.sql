@@ -0,0 +1,38 @@
+-- =============================================================
+-- Migration 003 — Login sessions and rotating refresh tokens
+-- A session is one login; every refresh token minted for it shares
+-- the session id, so the session doubles as the token family that is
+-- revoked as a whole when a used refresh token is replayed.
+-- =============================================================
+
+CREATE TABLE IF NOT EXISTS user_sessions (
+    id         UUID        PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id    UUID        NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    expires_at TIMESTAMPTZ NOT NULL,
+    revoked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id         UUID        PRIMARY KEY DEFAULT uuid_generate_v4(),
+    session_id UUID        NOT NULL REFERENCES user_sessions(id) ON DELETE CASCADE,
+    user_id    UUID        NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    -- SHA-256 of the opaque token; the raw value is only ever sent to the client
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    parent_id  UUID        REFERENCES refresh_tokens(id) ON DELETE SET NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at    TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+DROP TRIGGER IF EXISTS update_user_sessions_updated_at ON user_sessions;
+
+CREATE TRIGGER update_user_sessions_updated_at
+    BEFORE UPDATE ON user_sessions
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE INDEX IF NOT EXISTS idx_user_sessions_user_id    ON user_sessions(user_id);
+CREATE INDEX IF NOT EXISTS idx_user_sessions_expires_at ON user_sessions(expires_at);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_session   ON refresh_tokens(session_id);
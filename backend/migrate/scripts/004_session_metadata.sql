@@ -0,0 +1,11 @@
+-- =============================================================
+-- Migration 004 — Session metadata for the session management API
+-- Records where each login came from so users can recognise and
+-- revoke sessions they do not own. last_seen_at is refreshed at
+-- most every ~30s per instance (see ValidateSession).
+-- =============================================================
+
+ALTER TABLE user_sessions ADD COLUMN IF NOT EXISTS device       VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE user_sessions ADD COLUMN IF NOT EXISTS ip_address   VARCHAR(45)  NOT NULL DEFAULT '';
+ALTER TABLE user_sessions ADD COLUMN IF NOT EXISTS user_agent   TEXT         NOT NULL DEFAULT '';
+ALTER TABLE user_sessions ADD COLUMN IF NOT EXISTS last_seen_at TIMESTAMPTZ  NOT NULL DEFAULT CURRENT_TIMESTAMP;
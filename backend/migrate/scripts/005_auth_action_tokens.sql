@@ -0,0 +1,18 @@
+-- =============================================================
+-- Migration 005 — One-time action tokens
+-- Email verification and password reset links are signed JWTs;
+-- this table records each token's jti so a link can only be
+-- redeemed once.
+-- =============================================================
+
+CREATE TABLE IF NOT EXISTS auth_action_tokens (
+    id         UUID        PRIMARY KEY,
+    user_id    UUID        NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    purpose    VARCHAR(32) NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at    TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_auth_action_tokens_user_purpose ON auth_action_tokens(user_id, purpose);
+CREATE INDEX IF NOT EXISTS idx_auth_action_tokens_expires_at   ON auth_action_tokens(expires_at);
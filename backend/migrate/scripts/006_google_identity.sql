@@ -0,0 +1,10 @@
+-- =============================================================
+-- Migration 006 — Unique Google identities
+-- users.google_id now stores the Google subject and is used for
+-- sign-in and account linking, so one Google account may only be
+-- linked to a single user.
+-- =============================================================
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_google_id
+    ON users(google_id)
+    WHERE google_id IS NOT NULL AND google_id <> '';
@@ -0,0 +1,44 @@
+-- =============================================================
+-- Migration 007 — External sign-in identities
+-- Replaces users.google_id with one row per (provider, subject)
+-- so accounts can sign in through GitHub, GitLab and OIDC issuers
+-- as well as Google.
+-- =============================================================
+
+CREATE TABLE IF NOT EXISTS user_identities (
+    id         UUID         PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id    UUID         NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    provider   VARCHAR(50)  NOT NULL,
+    subject    VARCHAR(255) NOT NULL,
+    email      VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_user_identities_subject  UNIQUE (provider, subject),
+    CONSTRAINT uq_user_identities_provider UNIQUE (user_id, provider)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_identities_user_id ON user_identities(user_id);
+
+DROP TRIGGER IF EXISTS update_user_identities_updated_at ON user_identities;
+
+CREATE TRIGGER update_user_identities_updated_at
+    BEFORE UPDATE ON user_identities
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Carry over existing Google links before dropping the column.
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_name = 'users' AND column_name = 'google_id'
+    ) THEN
+        INSERT INTO user_identities (user_id, provider, subject, email)
+        SELECT id, 'google', google_id, COALESCE(email, '')
+        FROM users
+        WHERE google_id IS NOT NULL AND google_id <> ''
+        ON CONFLICT DO NOTHING;
+    END IF;
+END $$;
+
+DROP INDEX IF EXISTS idx_users_google_id;
+ALTER TABLE users DROP COLUMN IF EXISTS google_id;
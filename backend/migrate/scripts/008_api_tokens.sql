@@ -0,0 +1,21 @@
+-- =============================================================
+-- Migration 008 — Personal access tokens
+-- User-managed API tokens for scripts and CI. Only the SHA-256
+-- of the secret is stored; prefix keeps a recognisable fragment
+-- for listing.
+-- =============================================================
+
+CREATE TABLE IF NOT EXISTS api_tokens (
+    id           UUID         PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id      UUID         NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name         VARCHAR(100) NOT NULL,
+    prefix       VARCHAR(16)  NOT NULL,
+    token_hash   VARCHAR(64)  NOT NULL UNIQUE,
+    scopes       VARCHAR(255) NOT NULL DEFAULT '',
+    expires_at   TIMESTAMPTZ,
+    last_used_at TIMESTAMPTZ,
+    revoked_at   TIMESTAMPTZ,
+    created_at   TIMESTAMPTZ  NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_tokens_user_id ON api_tokens(user_id);
@@ -0,0 +1,8 @@
+-- =============================================================
+-- Migration 010 — Login lockout
+-- Consecutive failed sign-ins per account. Reaching the limit
+-- sets locked_until and restarts the count.
+-- =============================================================
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS failed_login_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS locked_until       TIMESTAMPTZ;
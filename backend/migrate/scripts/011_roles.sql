@@ -0,0 +1,19 @@
+-- =============================================================
+-- Migration 011 — Roles
+-- Splits the old "user" role into admin, editor, moderator,
+-- author and reader. Existing users become authors, which keeps
+-- their ability to write posts.
+-- =============================================================
+
+UPDATE users SET role = 'author' WHERE role = 'user';
+ALTER TABLE users ALTER COLUMN role SET DEFAULT 'author';
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+ALTER TABLE users ADD CONSTRAINT users_role_check
+    CHECK (role IN ('admin', 'editor', 'moderator', 'author', 'reader'));
+
+UPDATE mfa_role_policies SET role = 'author'
+WHERE role = 'user' AND NOT EXISTS (SELECT 1 FROM mfa_role_policies WHERE role = 'author');
+DELETE FROM mfa_role_policies WHERE role = 'user';
+
+CREATE INDEX IF NOT EXISTS idx_users_role ON users(role);
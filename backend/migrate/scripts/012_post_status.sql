@@ -0,0 +1,24 @@
+-- =============================================================
+-- Migration 012 — Post lifecycle
+-- Posts move through draft, scheduled, published, unlisted and
+-- archived. Only published posts appear in public listings;
+-- published_at orders them and drives the scheduler. Existing
+-- posts were live already, so they start out published.
+-- =============================================================
+
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'published';
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS published_at TIMESTAMPTZ;
+
+UPDATE posts SET published_at = created_at WHERE published_at IS NULL AND status = 'published';
+
+ALTER TABLE posts ALTER COLUMN status SET DEFAULT 'draft';
+
+ALTER TABLE posts DROP CONSTRAINT IF EXISTS posts_status_check;
+ALTER TABLE posts ADD CONSTRAINT posts_status_check
+    CHECK (status IN ('draft', 'scheduled', 'published', 'unlisted', 'archived'));
+
+-- Public listings and the scheduler both filter on status and sort by published_at.
+CREATE INDEX IF NOT EXISTS idx_posts_status_published_at ON posts(status, published_at DESC)
+    WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_posts_user_status ON posts(user_id, status)
+    WHERE deleted_at IS NULL;
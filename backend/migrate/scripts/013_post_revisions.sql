@@ -0,0 +1,28 @@
+-- =============================================================
+-- Migration 013 — Post revisions
+-- Every save that changes a post's title, excerpt or content
+-- stores a numbered snapshot, so earlier versions can be
+-- compared and restored. Existing posts get their current
+-- state as revision 1.
+-- =============================================================
+
+CREATE TABLE IF NOT EXISTS post_revisions (
+    id              UUID         PRIMARY KEY DEFAULT uuid_generate_v4(),
+    post_id         UUID         NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    revision_number INTEGER      NOT NULL,
+    title           VARCHAR(255) NOT NULL DEFAULT '',
+    excerpt         TEXT         NOT NULL DEFAULT '',
+    content         JSONB,
+    author_id       UUID         REFERENCES users(id) ON DELETE SET NULL,
+    created_at      TIMESTAMPTZ  NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_post_revisions_post_number
+    ON post_revisions(post_id, revision_number DESC);
+
+INSERT INTO post_revisions (post_id, revision_number, title, excerpt, content, author_id, created_at)
+SELECT p.id, 1, coalesce(p.title, ''), coalesce(p.excerpt, ''), pc.content, p.user_id, p.updated_at
+FROM posts p
+LEFT JOIN post_contents pc ON pc.post_id = p.id AND pc.deleted_at IS NULL
+WHERE p.deleted_at IS NULL
+  AND NOT EXISTS (SELECT 1 FROM post_revisions r WHERE r.post_id = p.id);
@@ -0,0 +1,20 @@
+-- =============================================================
+-- Migration 014 — Autosave and optimistic concurrency
+-- posts.version increases with every save; editors send the
+-- version they loaded and get a conflict if it moved on. The
+-- editor's working copy lives in post_autosaves, one per post,
+-- with its own version so two tabs cannot overwrite each other.
+-- =============================================================
+
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+
+CREATE TABLE IF NOT EXISTS post_autosaves (
+    post_id      UUID         PRIMARY KEY REFERENCES posts(id) ON DELETE CASCADE,
+    title        VARCHAR(500) NOT NULL DEFAULT '',
+    excerpt      TEXT         NOT NULL DEFAULT '',
+    content      JSONB,
+    version      INTEGER      NOT NULL DEFAULT 1,
+    post_version INTEGER      NOT NULL,
+    updated_by   UUID         REFERENCES users(id) ON DELETE SET NULL,
+    updated_at   TIMESTAMPTZ  NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
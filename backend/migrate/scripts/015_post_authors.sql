@@ -0,0 +1,26 @@
+-- =============================================================
+-- Migration 015 — Post authors
+-- Posts can have several authors. The owner (posts.user_id) may
+-- invite co-authors, who can edit, and reviewers, who can read
+-- unpublished versions. Invitations stay pending until the
+-- invitee accepts or declines them.
+-- =============================================================
+
+CREATE TABLE IF NOT EXISTS post_authors (
+    post_id      UUID        NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    user_id      UUID        NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role         VARCHAR(20) NOT NULL CHECK (role IN ('owner', 'co_author', 'reviewer')),
+    status       VARCHAR(20) NOT NULL DEFAULT 'pending'
+                             CHECK (status IN ('pending', 'accepted', 'declined')),
+    invited_by   UUID        REFERENCES users(id) ON DELETE SET NULL,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    responded_at TIMESTAMPTZ,
+    PRIMARY KEY (post_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_post_authors_user_status ON post_authors(user_id, status);
+
+INSERT INTO post_authors (post_id, user_id, role, status, created_at, responded_at)
+SELECT id, user_id, 'owner', 'accepted', created_at, created_at
+FROM posts
+ON CONFLICT (post_id, user_id) DO NOTHING;
@@ -0,0 +1,28 @@
+-- =============================================================
+-- Migration 016 — Editorial review
+-- Authors submit drafts for review; editors approve them or
+-- request changes. Only approved posts can be published by
+-- their authors. post_status_events records every status change
+-- with who made it and why.
+-- =============================================================
+
+ALTER TABLE posts DROP CONSTRAINT IF EXISTS posts_status_check;
+ALTER TABLE posts ADD CONSTRAINT posts_status_check
+    CHECK (status IN ('draft', 'in_review', 'changes_requested', 'approved',
+                      'scheduled', 'published', 'unlisted', 'archived'));
+
+CREATE TABLE IF NOT EXISTS post_status_events (
+    id          UUID        PRIMARY KEY,
+    post_id     UUID        NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    from_status VARCHAR(20) NOT NULL DEFAULT '',
+    to_status   VARCHAR(20) NOT NULL,
+    actor_id    UUID        REFERENCES users(id) ON DELETE SET NULL,
+    comment     TEXT        NOT NULL DEFAULT '',
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_post_status_events_post ON post_status_events(post_id, created_at DESC);
+
+-- The review queue lists posts waiting for review, oldest first.
+CREATE INDEX IF NOT EXISTS idx_posts_in_review ON posts(updated_at)
+    WHERE status = 'in_review' AND deleted_at IS NULL;
@@ -0,0 +1,26 @@
+-- =============================================================
+-- Migration 017 — Series
+-- A series groups posts into an ordered, multi-part collection.
+-- A post belongs to at most one series; position orders the
+-- parts and may have gaps after posts are removed.
+-- =============================================================
+
+CREATE TABLE IF NOT EXISTS series (
+    id          UUID         PRIMARY KEY,
+    user_id     UUID         NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    title       VARCHAR(200) NOT NULL,
+    slug        VARCHAR(255) NOT NULL UNIQUE,
+    description TEXT         NOT NULL DEFAULT '',
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_series_user ON series(user_id);
+
+CREATE TABLE IF NOT EXISTS series_posts (
+    post_id   UUID PRIMARY KEY REFERENCES posts(id) ON DELETE CASCADE,
+    series_id UUID NOT NULL REFERENCES series(id) ON DELETE CASCADE,
+    position  INT  NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_series_posts_series ON series_posts(series_id, position);
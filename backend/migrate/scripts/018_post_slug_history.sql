@@ -0,0 +1,18 @@
+-- =============================================================
+-- Migration 018 — Slug history
+-- Renaming a post changes its slug. Old slugs are kept so links
+-- to them can be redirected to the current one. Authors may pin
+-- a custom slug that no longer follows the title.
+-- =============================================================
+
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS slug_pinned BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- An old slug points at exactly one post; it is dropped again if
+-- that post takes the slug back.
+CREATE TABLE IF NOT EXISTS post_slug_history (
+    slug       VARCHAR(255) PRIMARY KEY,
+    post_id    UUID         NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_post_slug_history_post ON post_slug_history(post_id);
@@ -0,0 +1,16 @@
+-- =============================================================
+-- Migration 019 — Trash
+-- Deleted posts, comments and replies stay soft-deleted for a
+-- retention period so their authors can restore them, then a
+-- background job removes them for good. These indexes cover only
+-- the deleted rows: listing one user's trash and finding the
+-- rows that expired.
+-- =============================================================
+
+CREATE INDEX IF NOT EXISTS idx_posts_trash    ON posts(user_id, deleted_at DESC)    WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_comments_trash ON comments(user_id, deleted_at DESC) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_replies_trash  ON replies(user_id, deleted_at DESC)  WHERE deleted_at IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_posts_trash_expiry    ON posts(deleted_at)    WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_comments_trash_expiry ON comments(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_replies_trash_expiry  ON replies(deleted_at)  WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,11 @@
+-- =============================================================
+-- Migration 020 — Post statistics
+-- Word count, reading time (minutes) and the heading-based table
+-- of contents are computed when a post's content is saved, so
+-- listings and the post page no longer derive them client-side.
+-- Existing posts are filled in by `management backfill-post-stats`.
+-- =============================================================
+
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS word_count   INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS reading_time INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS toc          JSONB;
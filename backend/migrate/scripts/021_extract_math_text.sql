@@ -0,0 +1,28 @@
+-- =============================================================
+-- Migration 021 — Math in search text
+-- Posts can hold inline and block math, whose LaTeX source is an
+-- attribute rather than a text node. Search reads it too.
+-- Footnote and callout text is already in text nodes.
+-- =============================================================
+
+CREATE OR REPLACE FUNCTION extract_text_from_json_doc(doc jsonb)
+RETURNS text AS $$
+DECLARE
+    result text := '';
+    child  jsonb;
+BEGIN
+    IF doc IS NULL THEN RETURN ''; END IF;
+    IF doc->>'text' IS NOT NULL THEN
+        result := result || ' ' || (doc->>'text');
+    END IF;
+    IF doc->'attrs'->>'latex' IS NOT NULL THEN
+        result := result || ' ' || (doc->'attrs'->>'latex');
+    END IF;
+    IF doc->'content' IS NOT NULL AND jsonb_typeof(doc->'content') = 'array' THEN
+        FOR child IN SELECT jsonb_array_elements(doc->'content') LOOP
+            result := result || extract_text_from_json_doc(child);
+        END LOOP;
+    END IF;
+    RETURN result;
+END;
+$$ LANGUAGE plpgsql IMMUTABLE;
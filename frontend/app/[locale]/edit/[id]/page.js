@@ -3,50 +3,69 @@
 import { useState, useEffect, useCallback } from 'react';
 import { useRouter } from 'next/navigation';
 import { useParams } from 'next/navigation';
-import { useUser } from '../../../../context/UserContext';
 import { updatePost } from '../../../../services/postService';
-import { usePostName } from '../../../../hooks/usePost';
-import { isSuperAdmin } from '../../../../services/authService';
+import { useMyPost } from '../../../../hooks/usePost';
+import { useUser } from '../../../../context/UserContext';
 import PostEditorPage from '../../../../components/Editor/PostEditorPage';
 
 export default function EditPage() {
   const router = useRouter();
   const params = useParams();
   const id = params?.id;
+  const { post, isValidating, isError } = useMyPost(id);
   const { user } = useUser();
-  const { post, isLoading, isError } = usePostName(id);
   const [initialized, setInitialized] = useState(false);
   const [initialTitle, setInitialTitle] = useState('');
   const [initialContent, setInitialContent] = useState(null);
   const [initialImage, setInitialImage] = useState(null);
+  // The version the editor content was loaded at; saves are checked against it.
+  const [baseVersion, setBaseVersion] = useState(null);
 
   useEffect(() => {
-    if (post && !initialized) {
+    if (post && !isValidating && !initialized) {
       setInitialTitle(post.title || '');
       setInitialContent(post.content || null);
       setInitialImage(post.cover_image || null);
+      setBaseVersion(post.version);
       setInitialized(true);
     }
-  }, [post, initialized]);
+  }, [post, isValidating, initialized]);
+
+  // Editors may open posts they are not an author of; theirs are saved
+  // through the admin route, which asks for their second factor.
+  const anyPost = !!(post && user && post.user?.id !== user.id &&
+    !(post.authors || []).some((author) => author.user?.id === user.id));
 
   const handleUpdate = useCallback(async (selectedCategories, tags, { title, content, imageTitle }) => {
+    const payload = {
+      title,
+      content,
+      cover_image: imageTitle,
+      categories: selectedCategories.map(cat => cat.name),
+      tags,
+    };
     try {
-      const res = await updatePost(post.id, {
-        title,
-        content,
-        cover_image: imageTitle,
-        categories: selectedCategories.map(cat => cat.name),
-        tags,
-      });
+      let res;
+      try {
+        res = await updatePost(post.id, payload, baseVersion, { anyPost });
+      } catch (error) {
+        // Someone saved the post after it was loaded here: let the author decide
+        // whether their copy should replace it.
+        const conflict = error.response?.status === 409 && error.response.data?.details;
+        if (!conflict || !window.confirm('Bài viết đã được sửa ở nơi khác kể từ khi bạn mở. Ghi đè bằng nội dung của bạn?')) {
+          throw error;
+        }
+        res = await updatePost(post.id, payload, conflict.current_version, { anyPost });
+      }
       router.push(`/p/${res.data.slug}`);
     } catch (error) {
       console.error('Failed to update post:', error);
       alert('Không thể cập nhật bài viết.');
     }
-  }, [post?.id, router]);
+  }, [post?.id, baseVersion, anyPost, router]);
 
-  // Permission check
-  const noPermission = post && user && post.user?.id !== user.id && !isSuperAdmin();
+  // The author endpoint answers 403 to anyone who may not edit the post.
+  const noPermission = isError?.response?.status === 403;
   const errorMessage = noPermission
     ? 'Bạn không phải là tác giả của bài viết này.'
     : 'Không thể tìm thấy bài viết bạn muốn chỉnh sửa.';
@@ -60,8 +79,8 @@ export default function EditPage() {
       initialCategories={post?.categories || []}
       initialTags={post?.tags?.map(t => t.name) || []}
       onSave={handleUpdate}
-      isLoadingData={isLoading || !initialized}
-      isErrorData={isError || noPermission}
+      isLoadingData={!isError && !initialized}
+      isErrorData={!!isError}
       errorMessage={errorMessage}
     />
   );
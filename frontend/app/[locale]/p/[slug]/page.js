@@ -1,4 +1,5 @@
 import { cache } from 'react';
+import { permanentRedirect } from 'next/navigation';
 import { fetchAllPostSlugs, fetchPostBySlug } from '../../../lib/api';
 import { setRequestLocale } from 'next-intl/server';
 import PostPageClient from './PostPageClient';
@@ -46,11 +47,16 @@ export default async function PostPage({ params }) {
   try {
     post = await getCachedPost(slug);
     if (post?.content) {
-      initialHtml = renderPostContent(post.content);
+      initialHtml = renderPostContent(post.content, post.toc, post.code_tokens);
     }
   } catch {
     // Fallback to client-side fetching
   }
 
+  // Old slugs of renamed posts are redirected by the API; move the page too.
+  if (post?.slug && post.slug !== slug) {
+    permanentRedirect(`/p/${post.slug}`);
+  }
+
   return <PostPageClient slug={slug} initialPost={post} initialHtml={initialHtml} />;
 }
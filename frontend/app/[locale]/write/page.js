@@ -3,7 +3,7 @@
 import { useCallback } from 'react';
 import { useRouter } from 'next/navigation';
 import { useUser } from '../../../context/UserContext';
-import { createPost } from '../../../services/postService';
+import { createPost, submitPostForReview } from '../../../services/postService';
 import PostEditorPage from '../../../components/Editor/PostEditorPage';
 
 export default function WritePage() {
@@ -19,7 +19,14 @@ export default function WritePage() {
         categories: selectedCategories.map(cat => cat.name),
         tags,
       });
-      router.push(`/p/${res.data.slug}`);
+      if (res.data.status === 'published') {
+        router.push(`/p/${res.data.slug}`);
+        return;
+      }
+      // Authors' posts are saved as drafts and go to the editors for approval.
+      await submitPostForReview(res.data.id);
+      alert('Bài viết đã được gửi để biên tập viên duyệt.');
+      router.push('/');
     } catch (error) {
       console.error('Failed to create post:', error);
       alert('Không thể tạo bài viết.');
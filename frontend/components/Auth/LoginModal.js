@@ -1,8 +1,8 @@
 // components/Auth/LoginModal.js
 import React, { useState, useCallback } from 'react';
 import { motion, AnimatePresence } from 'framer-motion';
-import { GoogleLogo, X, User, Lock } from '@phosphor-icons/react';
-import { loginWithEmailAndPassword, registerUser, loginWithGoogle } from '../../services/authService';
+import { GoogleLogo, X, User, Lock, ShieldCheck } from '@phosphor-icons/react';
+import { loginWithEmailAndPassword, registerUser, loginWithGoogle, verifyMfa } from '../../services/authService';
 import { getUserProfile } from '../../services/userService';
 import { useLoginModal } from '../../hooks/useLoginModal';
 import { useUser } from '../../context/UserContext';
@@ -17,6 +17,8 @@ const LoginModal = ({ isOpen, onClose }) => {
   const [isSignUp, setIsSignUp] = useState(false);
   const [isLoading, setIsLoading] = useState(false);
   const [error, setError] = useState('');
+  const [mfaToken, setMfaToken] = useState('');
+  const [mfaCode, setMfaCode] = useState('');
   const { setUser } = useUser();
   useLoginModal(isOpen, onClose);
   useBodyScrollLock(isOpen);
@@ -26,7 +28,11 @@ const LoginModal = ({ isOpen, onClose }) => {
     setIsLoading(true);
     setError('');
     try {
-      await serviceFn();
+      const result = await serviceFn();
+      if (result?.mfaToken) {
+        setMfaToken(result.mfaToken);
+        return;
+      }
       const userData = await getUserProfile();
       setUser(userData);
       onClose();
@@ -41,6 +47,9 @@ const LoginModal = ({ isOpen, onClose }) => {
   const handleLogin = () =>
     executeAuthFlow(() => loginWithEmailAndPassword(email, password), 'auth.authFailed');
 
+  const handleVerifyMfa = () =>
+    executeAuthFlow(() => verifyMfa(mfaToken, mfaCode), 'auth.mfaFailed');
+
   const handleSignUp = () =>
     executeAuthFlow(async () => {
       await registerUser(email, password);
@@ -129,7 +138,7 @@ const LoginModal = ({ isOpen, onClose }) => {
                 lineHeight: 1.1,
               }}
             >
-              {isSignUp ? t('auth.createAccount') : t('auth.welcomeBack')}
+              {mfaToken ? t('auth.mfaTitle') : isSignUp ? t('auth.createAccount') : t('auth.welcomeBack')}
             </h2>
           </div>
 
@@ -155,6 +164,80 @@ const LoginModal = ({ isOpen, onClose }) => {
             </motion.div>
           )}
 
+          {mfaToken ? (
+          <div style={{ display: 'flex', flexDirection: 'column', gap: '0.75rem' }}>
+            <div style={{ marginBottom: '0.75rem' }}>
+              <label
+                htmlFor="mfa-code-input"
+                style={{
+                  display: 'block',
+                  marginBottom: '0.5rem',
+                  fontFamily: 'var(--font-display)',
+                  fontSize: '0.8125rem',
+                  fontWeight: 600,
+                  letterSpacing: '-0.01em',
+                  color: 'var(--text)',
+                }}
+              >
+                {t('auth.mfaCode')}
+              </label>
+              <div style={{ position: 'relative' }}>
+                <ShieldCheck
+                  style={{
+                    position: 'absolute',
+                    left: '0.875rem',
+                    top: '50%',
+                    transform: 'translateY(-50%)',
+                    width: 13,
+                    height: 13,
+                    color: 'var(--text-muted)',
+                  }}
+                />
+                <input
+                  id="mfa-code-input"
+                  type="text"
+                  inputMode="text"
+                  autoComplete="one-time-code"
+                  autoFocus
+                  value={mfaCode}
+                  onChange={(e) => setMfaCode(e.target.value)}
+                  disabled={isLoading}
+                  placeholder={t('auth.mfaCodePlaceholder')}
+                  style={{
+                    width: '100%',
+                    paddingLeft: '2.5rem',
+                    paddingRight: '1rem',
+                    paddingTop: '0.7rem',
+                    paddingBottom: '0.7rem',
+                    fontFamily: 'var(--font-display)',
+                    fontSize: '0.9375rem',
+                    letterSpacing: '-0.01em',
+                    color: 'var(--text)',
+                    background: 'var(--bg-surface)',
+                    border: '1px solid var(--border)',
+                    borderRadius: '4px',
+                    outline: 'none',
+                    transition: 'border-color 0.2s, background 0.2s',
+                  }}
+                  className="focus:border-[var(--accent)] focus:bg-[var(--bg)]"
+                />
+              </div>
+            </div>
+
+            <Button
+              variant="primary"
+              size="lg"
+              onClick={handleVerifyMfa}
+              disabled={!mfaCode}
+              loading={isLoading}
+              fullWidth
+              style={{ fontSize: '0.9375rem', fontWeight: 600 }}
+            >
+              {t('auth.mfaVerify')}
+            </Button>
+          </div>
+          ) : (
+          <>
           <div style={{ display: 'flex', flexDirection: 'column', gap: '1rem', marginBottom: '1.5rem' }}>
             <div>
               <label
@@ -304,6 +387,8 @@ const LoginModal = ({ isOpen, onClose }) => {
               {isSignUp ? t('auth.haveAccount') : t('auth.noAccount')}
             </Button>
           </div>
+          </>
+          )}
         </motion.div>
       </motion.div>
     </AnimatePresence>
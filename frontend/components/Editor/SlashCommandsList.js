@@ -10,6 +10,9 @@ import {
   FaTasks,
   FaListUl,
   FaListOl,
+  FaInfoCircle,
+  FaSquareRootAlt,
+  FaAsterisk,
 } from 'react-icons/fa'
 
 const COMMANDS = [
@@ -23,6 +26,10 @@ const COMMANDS = [
   { title: 'Code block', icon: FaCode, command: ({ editor }) => editor.chain().focus().toggleCodeBlock().run() },
   { title: 'Table', icon: FaTable, command: ({ editor }) => editor.chain().focus().insertTable({ rows: 3, cols: 3, withHeaderRow: true }).run() },
   { title: 'Divider', icon: FaMinus, command: ({ editor }) => editor.chain().focus().setHorizontalRule().run() },
+  { title: 'Callout', icon: FaInfoCircle, command: ({ editor }) => editor.chain().focus().toggleCallout().run() },
+  { title: 'Math block', icon: FaSquareRootAlt, command: ({ editor }) => editor.chain().focus().insertBlockMath(window.prompt('LaTeX')).run() },
+  { title: 'Inline math', icon: FaSquareRootAlt, command: ({ editor }) => editor.chain().focus().insertInlineMath(window.prompt('LaTeX')).run() },
+  { title: 'Footnote', icon: FaAsterisk, command: ({ editor }) => editor.chain().focus().addFootnote().run() },
 ]
 
 const SlashCommandsList = forwardRef(({ items, command, editor }, ref) => {
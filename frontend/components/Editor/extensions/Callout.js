@@ -0,0 +1,53 @@
+import { Node, mergeAttributes } from '@tiptap/core'
+
+// The kinds of callout, GitHub's alert types; Markdown exports write them as
+// `> [!NOTE]` and the like.
+export const CALLOUT_VARIANTS = ['note', 'tip', 'important', 'warning', 'caution']
+
+// Callout sets blocks apart as a note, tip or warning.
+const Callout = Node.create({
+  name: 'callout',
+  group: 'block',
+  content: 'block+',
+  defining: true,
+
+  addAttributes () {
+    return {
+      variant: { default: 'note' },
+    }
+  },
+
+  parseHTML () {
+    return [
+      {
+        tag: 'aside[data-type="callout"]',
+        getAttrs: (dom) => {
+          const variant = dom.getAttribute('data-variant')
+          return { variant: CALLOUT_VARIANTS.includes(variant) ? variant : 'note' }
+        },
+      },
+    ]
+  },
+
+  renderHTML ({ HTMLAttributes }) {
+    const { variant, ...rest } = HTMLAttributes
+    return [
+      'aside',
+      mergeAttributes(rest, { 'data-type': 'callout', 'data-variant': variant, class: `callout callout-${variant}` }),
+      0,
+    ]
+  },
+
+  addCommands () {
+    return {
+      setCallout: (variant = 'note') => ({ commands }) => {
+        return commands.wrapIn(this.name, { variant })
+      },
+      toggleCallout: (variant = 'note') => ({ commands }) => {
+        return commands.toggleWrap(this.name, { variant })
+      },
+    }
+  },
+})
+
+export default Callout
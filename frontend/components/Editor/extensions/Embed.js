@@ -0,0 +1,131 @@
+import { Node, mergeAttributes } from '@tiptap/core'
+import { Plugin, PluginKey } from '@tiptap/pm/state'
+
+const URL_PATTERN = /^https?:\/\/\S+$/i
+
+// Maps a preview from the unfurl endpoint to the node's attributes.
+const previewAttrs = (preview) => ({
+  url: preview.url,
+  title: preview.title || '',
+  description: preview.description || '',
+  siteName: preview.site_name || '',
+  image: preview.image || null,
+  video: preview.video || null,
+})
+
+// Embed shows a pasted link as a preview card, or as its player for videos.
+// Pass an `unfurl` option (url => preview) to turn links pasted on an empty
+// line into embeds; server-side renderers leave it out.
+const Embed = Node.create({
+  name: 'embed',
+  group: 'block',
+  atom: true,
+  draggable: true,
+
+  addOptions () {
+    return {
+      unfurl: null,
+    }
+  },
+
+  addAttributes () {
+    return {
+      url: { default: null },
+      title: { default: '' },
+      description: { default: '' },
+      siteName: { default: '' },
+      image: { default: null },
+      dataImageId: { default: null },
+      video: { default: null },
+    }
+  },
+
+  parseHTML () {
+    return [
+      {
+        tag: '[data-type="embed"]',
+        getAttrs: (dom) => ({
+          url: dom.getAttribute('data-url'),
+          title: dom.querySelector('strong')?.textContent || '',
+          description: dom.querySelector('span')?.textContent || '',
+          siteName: dom.querySelector('figcaption')?.textContent || '',
+          image: dom.querySelector('img')?.getAttribute('src') || null,
+          video: dom.querySelector('iframe')?.getAttribute('src') || null,
+        }),
+      },
+    ]
+  },
+
+  renderHTML ({ HTMLAttributes }) {
+    const { url, title, description, siteName, image, video, dataImageId, ...rest } = HTMLAttributes
+    if (video) {
+      return [
+        'div',
+        mergeAttributes(rest, { 'data-type': 'embed', 'data-url': url, class: 'embed embed-video youtube-embed' }),
+        ['iframe', { src: video, width: '640', height: '360', frameborder: '0', allowfullscreen: 'true' }],
+      ]
+    }
+    const card = ['a', { href: url, target: '_blank', rel: 'noopener noreferrer nofollow' }]
+    if (image) {
+      card.push(['img', { src: image, alt: '', loading: 'lazy' }])
+    }
+    card.push(['strong', {}, title || url])
+    if (description) {
+      card.push(['span', {}, description])
+    }
+    const figure = ['figure', mergeAttributes(rest, { 'data-type': 'embed', 'data-url': url, class: 'embed embed-card' }), card]
+    if (siteName) {
+      figure.push(['figcaption', {}, siteName])
+    }
+    return figure
+  },
+
+  addCommands () {
+    return {
+      setEmbed: (preview) => ({ commands }) => {
+        return commands.insertContent({ type: this.name, attrs: previewAttrs(preview) })
+      },
+    }
+  },
+
+  addProseMirrorPlugins () {
+    const { unfurl } = this.options
+    if (!unfurl) return []
+    const editor = this.editor
+    const type = this.type
+
+    return [
+      new Plugin({
+        key: new PluginKey('embedPaste'),
+        props: {
+          handlePaste: (view, event) => {
+            const text = event.clipboardData?.getData('text/plain')?.trim()
+            const { $from, empty } = view.state.selection
+            if (!text || !URL_PATTERN.test(text) || !empty ||
+                $from.parent.type.name !== 'paragraph' || $from.parent.content.size > 0) {
+              return false
+            }
+
+            // The empty paragraph the link was pasted on is replaced once the
+            // preview arrives, unless it was typed into in the meantime.
+            const pos = $from.before()
+            const replace = (content) => {
+              const node = editor.state.doc.nodeAt(pos)
+              if (!node || node.type.name !== 'paragraph' || node.content.size > 0) return
+              editor.chain().insertContentAt({ from: pos, to: pos + node.nodeSize }, content).run()
+            }
+            unfurl(text)
+              .then((preview) => replace({ type: type.name, attrs: previewAttrs(preview) }))
+              .catch(() => replace({
+                type: 'paragraph',
+                content: [{ type: 'text', text, marks: [{ type: 'link', attrs: { href: text } }] }],
+              }))
+            return true
+          },
+        },
+      }),
+    ]
+  },
+})
+
+export default Embed
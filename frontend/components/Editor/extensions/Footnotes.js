@@ -0,0 +1,144 @@
+import { Node, mergeAttributes } from '@tiptap/core'
+import { Plugin, PluginKey } from '@tiptap/pm/state'
+
+const newFootnoteId = () => Math.random().toString(36).slice(2, 10)
+
+// FootnotesDocument replaces StarterKit's document so footnote definitions
+// can only come last, after the blocks of the post.
+export const FootnotesDocument = Node.create({
+  name: 'doc',
+  topNode: true,
+  content: 'block+ footnotes?',
+})
+
+// FootnoteReference marks where a footnote applies. References and footnotes
+// are numbered by CSS counters, so they renumber as they move.
+export const FootnoteReference = Node.create({
+  name: 'footnoteReference',
+  group: 'inline',
+  inline: true,
+  atom: true,
+
+  addAttributes () {
+    return {
+      id: { default: null },
+    }
+  },
+
+  parseHTML () {
+    return [
+      {
+        tag: 'sup[data-type="footnote-reference"]',
+        getAttrs: (dom) => ({ id: dom.getAttribute('data-id') }),
+      },
+    ]
+  },
+
+  renderHTML ({ HTMLAttributes }) {
+    const { id, ...rest } = HTMLAttributes
+    return [
+      'sup',
+      mergeAttributes(rest, { 'data-type': 'footnote-reference', 'data-id': id, class: 'footnote-ref' }),
+      ['a', { href: `#fn-${id}`, id: `fnref-${id}` }, ''],
+    ]
+  },
+
+  addCommands () {
+    return {
+      addFootnote: () => ({ commands }) => {
+        return commands.insertContent({ type: this.name, attrs: { id: newFootnoteId() } })
+      },
+    }
+  },
+
+  addProseMirrorPlugins () {
+    return [
+      new Plugin({
+        key: new PluginKey('footnotes'),
+        appendTransaction: (transactions, _oldState, state) => {
+          if (!transactions.some((tr) => tr.docChanged)) return null
+          return syncFootnotes(state)
+        },
+      }),
+    ]
+  },
+})
+
+// Footnotes holds the definitions at the end of the post.
+export const Footnotes = Node.create({
+  name: 'footnotes',
+  content: 'footnote+',
+  isolating: true,
+
+  parseHTML () {
+    return [{ tag: 'section[data-type="footnotes"]' }]
+  },
+
+  renderHTML ({ HTMLAttributes }) {
+    return ['section', mergeAttributes(HTMLAttributes, { 'data-type': 'footnotes', class: 'footnotes' }), ['ol', 0]]
+  },
+})
+
+// Footnote is the text of one footnote.
+export const Footnote = Node.create({
+  name: 'footnote',
+  content: 'paragraph+',
+  isolating: true,
+
+  addAttributes () {
+    return {
+      id: { default: null },
+    }
+  },
+
+  parseHTML () {
+    return [
+      {
+        tag: 'li[data-type="footnote"]',
+        getAttrs: (dom) => ({ id: dom.getAttribute('data-id') }),
+      },
+    ]
+  },
+
+  renderHTML ({ HTMLAttributes }) {
+    const { id, ...rest } = HTMLAttributes
+    return ['li', mergeAttributes(rest, { 'data-type': 'footnote', 'data-id': id, id: `fn-${id}` }), 0]
+  },
+})
+
+// syncFootnotes keeps one definition per referenced footnote, in the order
+// they are first referenced: new references get an empty footnote to write,
+// and footnotes whose references were deleted go too. It returns null when
+// the definitions already match.
+function syncFootnotes (state) {
+  const { doc, schema } = state
+  const ids = []
+  doc.descendants((node) => {
+    if (node.type.name === 'footnoteReference' && node.attrs.id && !ids.includes(node.attrs.id)) {
+      ids.push(node.attrs.id)
+    }
+  })
+
+  const section = doc.lastChild?.type.name === 'footnotes' ? doc.lastChild : null
+  const current = []
+  const defs = new Map()
+  section?.forEach((footnote) => {
+    current.push(footnote.attrs.id)
+    if (!defs.has(footnote.attrs.id)) defs.set(footnote.attrs.id, footnote)
+  })
+  if (current.length === ids.length && current.every((id, i) => id === ids[i])) {
+    return null
+  }
+
+  const tr = state.tr
+  const end = doc.content.size
+  if (section) {
+    tr.delete(end - section.nodeSize, end)
+  }
+  if (ids.length > 0) {
+    const footnotes = ids.map((id) => defs.get(id) ||
+      schema.nodes.footnote.create({ id }, schema.nodes.paragraph.create()))
+    tr.insert(tr.doc.content.size, schema.nodes.footnotes.create(null, footnotes))
+  }
+  return tr
+}
@@ -0,0 +1,80 @@
+import { Node, mergeAttributes } from '@tiptap/core'
+import { renderMath } from '../../../utils/renderMath'
+
+// mathNode creates the node for inline or block math. Both keep their LaTeX
+// source; the page typesets it with KaTeX, and so does the editor, where a
+// double click edits the source.
+const mathNode = ({ name, dataType, displayMode }) => Node.create({
+  name,
+  group: displayMode ? 'block' : 'inline',
+  inline: !displayMode,
+  atom: true,
+
+  addAttributes () {
+    return {
+      latex: { default: '' },
+    }
+  },
+
+  parseHTML () {
+    return [
+      {
+        tag: `[data-type="${dataType}"]`,
+        getAttrs: (dom) => ({ latex: dom.getAttribute('data-latex') || dom.textContent || '' }),
+      },
+    ]
+  },
+
+  renderHTML ({ HTMLAttributes }) {
+    const { latex, ...rest } = HTMLAttributes
+    const className = displayMode ? 'math math-display' : 'math math-inline'
+    return [
+      displayMode ? 'div' : 'span',
+      mergeAttributes(rest, { 'data-type': dataType, 'data-latex': latex, class: className }),
+      latex || '',
+    ]
+  },
+
+  addCommands () {
+    const command = displayMode ? 'insertBlockMath' : 'insertInlineMath'
+    return {
+      [command]: (latex) => ({ commands }) => {
+        if (!latex) return false
+        return commands.insertContent({ type: this.name, attrs: { latex } })
+      },
+    }
+  },
+
+  addNodeView () {
+    return ({ node, getPos, editor }) => {
+      let current = node
+      const dom = document.createElement(displayMode ? 'div' : 'span')
+      dom.setAttribute('data-type', dataType)
+      dom.className = displayMode ? 'math math-display' : 'math math-inline'
+      dom.textContent = current.attrs.latex
+      renderMath(dom, current.attrs.latex, displayMode)
+
+      dom.addEventListener('dblclick', () => {
+        if (!editor.isEditable) return
+        const latex = window.prompt('LaTeX', current.attrs.latex)
+        if (!latex || latex === current.attrs.latex) return
+        editor.chain().setNodeSelection(getPos()).updateAttributes(name, { latex }).run()
+      })
+
+      return {
+        dom,
+        update: (updated) => {
+          if (updated.type.name !== name) return false
+          if (updated.attrs.latex !== current.attrs.latex) {
+            renderMath(dom, updated.attrs.latex, displayMode)
+          }
+          current = updated
+          return true
+        },
+      }
+    }
+  },
+})
+
+export const InlineMath = mathNode({ name: 'inlineMath', dataType: 'inline-math', displayMode: false })
+export const BlockMath = mathNode({ name: 'blockMath', dataType: 'block-math', displayMode: true })
@@ -9,7 +9,7 @@ import { usePostContext } from '../../context/PostContext';
 import { useOutsideClick } from '../../hooks/useOutsideClick';
 import { useScrollEffect } from '../../hooks/useScrollEffect';
 import SimpleSearchBar from '../Shared/SimpleSearchBar';
-import { canWritePosts } from '../../services/authService';
+import { canWritePosts, logout } from '../../services/authService';
 import { useTranslations } from 'next-intl';
 import MobileSlidePanel from './MobileSlidePanel';
 import LanguageTogglePill from '../Shared/LanguageTogglePill';
@@ -93,8 +93,8 @@ const Navbar = () => {
     setIsMobileMenuOpen(false);
   }, [pathname]);
 
-  const handleLogout = () => {
-    localStorage.removeItem('token');
+  const handleLogout = async () => {
+    await logout();
     setUser(null);
     setIsUserMenuOpen(false);
     router.push('/');
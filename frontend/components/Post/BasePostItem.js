@@ -173,7 +173,7 @@ const BasePostItem = ({
         {/* Owner actions */}
         {isPostOwner && (
           <div className="flex gap-2 mt-4">
-            <Link href={`/edit/${post.slug}`} className="action-btn hover:text-[var(--text)] hover:bg-[var(--bg-elevated)]">
+            <Link href={`/edit/${post.id}`} className="action-btn hover:text-[var(--text)] hover:bg-[var(--bg-elevated)]">
               <PencilSimple size={10} />{t('common.edit')}
             </Link>
             <button onClick={handleDelete} className="action-btn hover:text-[#DC2626] hover:bg-[#DC2626]/10">
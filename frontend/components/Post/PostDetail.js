@@ -1,9 +1,11 @@
 'use client';
-import React, { useState, useEffect } from 'react';
+import React, { useState, useEffect, useRef } from 'react';
 import Link from 'next/link';
 import SEOHead from '../SEO/SEOHead';
 import RelatedPosts from './RelatedPosts';
+import TableOfContents from './TableOfContents';
 import { formatDate } from '../../utils/formatDate';
+import { typesetMath } from '../../utils/renderMath';
 import { useTranslations, useLocale } from 'next-intl';
 import { Clock, User, ChatCircle } from '@phosphor-icons/react';
 
@@ -13,17 +15,23 @@ export const PostDetail = ({ post, htmlContent, relatedPosts = [] }) => {
   const t = useTranslations();
   const locale = useLocale();
   const [renderedHTML, setRenderedHTML] = useState(htmlContent || '');
+  const bodyRef = useRef(null);
 
   useEffect(() => {
     if (htmlContent) return;
     if (!post?.content) return;
 
     import('../../utils/renderContent').then(({ renderPostContent }) => {
-      const html = renderPostContent(post.content);
+      const html = renderPostContent(post.content, post.toc);
       if (html) setRenderedHTML(html);
     });
   }, [post?.id, htmlContent]);
 
+  // Math arrives as LaTeX source; KaTeX is fetched only for posts with math.
+  useEffect(() => {
+    typesetMath(bodyRef.current);
+  }, [renderedHTML]);
+
   if (!post) {
     return (
       <div className="flex items-center justify-center h-60 font-display text-[var(--text-muted)]">
@@ -79,14 +87,20 @@ export const PostDetail = ({ post, htmlContent, relatedPosts = [] }) => {
               {post.user.name}
             </span>
           )}
+          {post.reading_time > 0 && (
+            <span>{post.reading_time} {t('post.minRead')}</span>
+          )}
           <Link href="#comments" className="flex items-center gap-[0.35rem] hover:text-[var(--accent)] transition-colors">
             <ChatCircle size={13} weight="regular" />
             {t('post.leaveComment')}
           </Link>
         </div>
 
+        <TableOfContents toc={post.toc} />
+
         {/* Body */}
         <div
+          ref={bodyRef}
           className="post-content reading-content"
           dangerouslySetInnerHTML={{ __html: renderedHTML }}
         />
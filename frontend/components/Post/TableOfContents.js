@@ -0,0 +1,26 @@
+'use client';
+import { useTranslations } from 'next-intl';
+
+// TableOfContents links to the headings of a post. toc comes from the API as
+// {id, level, text} entries in document order; headings are rendered with the
+// same ids. Posts with fewer than two headings get none.
+const TableOfContents = ({ toc }) => {
+  const t = useTranslations();
+  if (!Array.isArray(toc) || toc.length < 2) return null;
+
+  const top = Math.min(...toc.map((h) => h.level));
+  return (
+    <nav className="post-toc" aria-label={t('post.contents')}>
+      <p className="post-toc-title">{t('post.contents')}</p>
+      <ol>
+        {toc.map((heading) => (
+          <li key={heading.id} style={{ paddingLeft: `${(heading.level - top) * 0.9}rem` }}>
+            <a href={`#${heading.id}`}>{heading.text}</a>
+          </li>
+        ))}
+      </ol>
+    </nav>
+  );
+};
+
+export default TableOfContents;
@@ -1,24 +1,59 @@
 // constants/roles.js
 
-// Simple user role constants - only 2 roles
+// Mirrors backend constants/roles.go and constants/permissions.go
 export const USER_ROLES = {
-  USER: 'user',
-  ADMIN: 'admin'
+  ADMIN: 'admin',
+  EDITOR: 'editor',
+  MODERATOR: 'moderator',
+  AUTHOR: 'author',
+  READER: 'reader',
+  // Legacy role name; treated as author
+  USER: 'user'
+};
+
+export const PERMISSIONS = {
+  PUBLISH: 'publish',
+  EDIT_ANY_POST: 'edit_any_post',
+  MODERATE_COMMENTS: 'moderate_comments',
+  MANAGE_TAXONOMY: 'manage_taxonomy',
+  MANAGE_USERS: 'manage_users'
+};
+
+const ROLE_PERMISSIONS = {
+  [USER_ROLES.ADMIN]: Object.values(PERMISSIONS),
+  [USER_ROLES.EDITOR]: [
+    PERMISSIONS.PUBLISH,
+    PERMISSIONS.EDIT_ANY_POST,
+    PERMISSIONS.MODERATE_COMMENTS,
+    PERMISSIONS.MANAGE_TAXONOMY
+  ],
+  [USER_ROLES.MODERATOR]: [PERMISSIONS.MODERATE_COMMENTS],
+  [USER_ROLES.AUTHOR]: [PERMISSIONS.PUBLISH],
+  [USER_ROLES.READER]: []
 };
 
 // Role display names
 export const ROLE_DISPLAY_NAMES = {
-  [USER_ROLES.USER]: 'User',
-  [USER_ROLES.ADMIN]: 'Admin'
+  [USER_ROLES.ADMIN]: 'Admin',
+  [USER_ROLES.EDITOR]: 'Editor',
+  [USER_ROLES.MODERATOR]: 'Moderator',
+  [USER_ROLES.AUTHOR]: 'Author',
+  [USER_ROLES.READER]: 'Reader'
+};
+
+const normalizeRole = (userRole) =>
+  userRole === USER_ROLES.USER ? USER_ROLES.AUTHOR : userRole;
+
+export const hasPermission = (userRole, permission) => {
+  return (ROLE_PERMISSIONS[normalizeRole(userRole)] || []).includes(permission);
 };
 
-// Simple permission checks - admin has all permissions, user has basic permissions
 export const canWritePosts = (userRole) => {
-  return userRole === USER_ROLES.ADMIN;
+  return hasPermission(userRole, PERMISSIONS.PUBLISH);
 };
 
 export const canViewAllProfiles = (userRole) => {
-  return userRole === USER_ROLES.ADMIN;
+  return hasPermission(userRole, PERMISSIONS.MANAGE_USERS);
 };
 
 export const isAdmin = (userRole) => {
@@ -26,9 +61,9 @@ export const isAdmin = (userRole) => {
 };
 
 export const isUser = (userRole) => {
-  return userRole === USER_ROLES.USER;
+  return normalizeRole(userRole) === USER_ROLES.AUTHOR;
 };
 
 export const getRoleDisplayName = (role) => {
-  return ROLE_DISPLAY_NAMES[role] || ROLE_DISPLAY_NAMES[USER_ROLES.USER];
+  return ROLE_DISPLAY_NAMES[normalizeRole(role)] || ROLE_DISPLAY_NAMES[USER_ROLES.AUTHOR];
 };
@@ -34,10 +34,14 @@ const useAuth = () => {
       const hash = typeof window !== 'undefined' ? window.location.hash.substr(1) : '';
       const params = new URLSearchParams(hash);
       const token = params.get('token');
+      const refreshToken = params.get('refresh_token');
 
       if (token) {
         // Lưu token vào localStorage
         localStorage.setItem('token', token);
+        if (refreshToken) {
+          localStorage.setItem('refresh_token', refreshToken);
+        }
 
         // Loại bỏ fragment từ URL
         window.history.replaceState({}, document.title, window.location.pathname);
@@ -53,9 +57,12 @@ const useAuth = () => {
             const payload = JSON.parse(atob(tokenParts[1]));
             const currentTime = Math.floor(Date.now() / 1000);
             
-            // Check if token is expired
+            // Check if token is expired; an expired access token is still
+            // usable when a refresh token can renew it on the first request.
             if (payload.exp && payload.exp > currentTime) {
               setInitialized(true); // Token is valid, trigger SWR
+            } else if (localStorage.getItem('refresh_token')) {
+              setInitialized(true);
             } else {
               // Token expired, remove it
               localStorage.removeItem('token');
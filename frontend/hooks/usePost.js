@@ -1,6 +1,6 @@
 // hooks/usePost.js
 import useSWR from 'swr';
-import { getPostById, getPostBySlug } from '../services/postService';
+import { getMyPost, getPostById, getPostBySlug } from '../services/postService';
 
 export const usePost = (postId) => {
   const { data , error, mutate } = useSWR(postId ? `/posts/${postId}` : null, () => getPostById(postId));
@@ -31,3 +31,26 @@ export const usePostName = (slug, options = {}) => {
     mutate,
   };
 };
+
+// useMyPost loads one of the user's own posts, drafts included, from the
+// author endpoint. It always refetches on mount; isValidating tells whether
+// data may still be a cached copy, which the editor must not start from.
+export const useMyPost = (postId) => {
+  const { data, error, isValidating, mutate } = useSWR(
+    postId ? `/api/me/posts/${postId}` : null,
+    () => getMyPost(postId),
+    {
+      dedupingInterval: 0,
+      revalidateOnFocus: false,
+      revalidateOnMount: true,
+    }
+  );
+
+  return {
+    post: data ?? null,
+    isLoading: !error && !data,
+    isValidating,
+    isError: error,
+    mutate,
+  };
+};
@@ -26,6 +26,13 @@ const decodeJWT = (token) => {
   }
 };
 
+const storeTokens = (data) => {
+  localStorage.setItem('token', data.token);
+  if (data.refresh_token) {
+    localStorage.setItem('refresh_token', data.refresh_token);
+  }
+};
+
 // Get user role from token
 export const getUserRole = () => {
   const token = localStorage.getItem('token');
@@ -63,33 +70,66 @@ export const getRoleDisplayName = (role) => {
     return response.data;
   };
 
+  // Resolves to { mfaToken } when the account needs a second factor; pass it to verifyMfa.
   export const loginWithEmailAndPassword = async (email, password) => {
     try {
       const response = await axiosPublicInstance.post(`/auth/login`, { email, password });
-      localStorage.setItem('token', response.data.token);
+      if (response.data.mfa_required) {
+        return { mfaToken: response.data.mfa_token };
+      }
+      storeTokens(response.data);
+      return {};
     } catch (error) {
       console.error('Login failed:', error);
       throw error;
     }
   };
 
+  // Accepts a 6-digit authenticator code or a recovery code (xxxxx-xxxxx).
+  export const verifyMfa = async (mfaToken, code) => {
+    const isRecoveryCode = code.replace(/\s/g, '').length !== 6;
+    const response = await axiosPublicInstance.post('/auth/mfa/verify', {
+      mfa_token: mfaToken,
+      ...(isRecoveryCode ? { recovery_code: code } : { code }),
+    });
+    storeTokens(response.data);
+  };
+
   export const registerUser = async (email, password) => {
     try {
       const response = await axiosPublicInstance.post(`/auth/register`, { email, password });
-      localStorage.setItem('token', response.data.token);
+      storeTokens(response.data);
     } catch (error) {
       console.error('Sign up failed:', error);
       throw error;
     }
   };
 
-  export const logout = () => {
-    localStorage.removeItem('token');
+  export const logout = async () => {
+    try {
+      await axiosPrivateInstance.post('/auth/logout');
+    } catch (error) {
+      // The session may already be gone server-side; clear local state regardless.
+      console.error('Logout failed:', error);
+    } finally {
+      localStorage.removeItem('token');
+      localStorage.removeItem('refresh_token');
+    }
   };
 
 
 export const loginWithGoogle = () => {
   // Use application service endpoint for Google OAuth (matches redirect_uri in Google Console)
-  window.location.href = `${BASE_API_URL}/auth/google`;
+  loginWithProvider('google');
+};
+
+// Start sign-in with any provider returned by getAuthProviders (e.g. 'github' or a company SSO)
+export const loginWithProvider = (provider) => {
+  window.location.href = `${BASE_API_URL}/auth/${encodeURIComponent(provider)}`;
+};
+
+export const getAuthProviders = async () => {
+  const response = await axiosPublicInstance.get('/auth/providers');
+  return response.data.data;
 };
 
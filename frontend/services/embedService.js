@@ -0,0 +1,9 @@
+// services/embedService.js
+import axiosPrivateInstance from '../utils/axiosPrivateInstance';
+
+// Fetches the preview of a link (title, description, image, and a player
+// for videos) to embed it in a post.
+export const unfurlLink = async (url) => {
+  const response = await axiosPrivateInstance.post('/api/embeds/unfurl', { url });
+  return response.data.data;
+};
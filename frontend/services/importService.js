@@ -0,0 +1,45 @@
+// services/importService.js
+import axiosPrivateInstance from '../utils/axiosPrivateInstance';
+
+// Converts a Markdown or HTML document into editor content. file (a File) is
+// sent instead of content when given; bundle is an optional zip of the images
+// the document uses, which are then uploaded along with it.
+export const convertDocument = async (format, { content, file, bundle } = {}) => {
+  if (!file && !bundle) {
+    const response = await axiosPrivateInstance.post('/api/import/convert', { format, content });
+    return response.data.data;
+  }
+  const form = new FormData();
+  form.append('format', format);
+  if (file) {
+    form.append('file', file);
+  } else {
+    form.append('content', content);
+  }
+  if (bundle) {
+    form.append('bundle', bundle);
+  }
+  const response = await axiosPrivateInstance.post('/api/import/convert', form, {
+    headers: { 'Content-Type': 'multipart/form-data' },
+  });
+  return response.data.data;
+};
+
+// Imports a WordPress export. authors maps author logins to the usernames
+// their posts go to; the others go to the importing user. With dryRun the
+// report tells what would be created without saving anything.
+export const importWordPress = async (file, { bundle, authors, dryRun = false } = {}) => {
+  const form = new FormData();
+  form.append('file', file);
+  if (bundle) {
+    form.append('bundle', bundle);
+  }
+  if (authors) {
+    form.append('authors', JSON.stringify(authors));
+  }
+  form.append('dry_run', dryRun ? 'true' : 'false');
+  const response = await axiosPrivateInstance.post('/admin/import/wordpress', form, {
+    headers: { 'Content-Type': 'multipart/form-data' },
+  });
+  return response.data.data;
+};
@@ -18,8 +18,14 @@ export const getPostBySlug = async (slug) => {
   return response.data.data.post;
 };
 
-export const updatePost = async (id, postData) => {
-  const response = await axiosPrivateInstance.put(`/api/posts/${id}`, postData);
+// version is the post version the edit is based on (the ETag of getMyPost);
+// it goes out as If-Match so the save fails with 409 if someone saved since.
+// anyPost saves through the admin route, for editors changing a post they
+// are not an author of.
+export const updatePost = async (id, postData, version, { anyPost = false } = {}) => {
+  const headers = version === undefined ? undefined : { 'If-Match': `"${version}"` };
+  const url = anyPost ? `/admin/posts/${id}` : `/api/posts/${id}`;
+  const response = await axiosPrivateInstance.put(url, postData, { headers });
   return response.data;
 };
 
@@ -28,6 +34,91 @@ export const deletePost = async (id) => {
   return response.data;
 };
 
+// Own posts in any status (draft, scheduled, published, unlisted, archived).
+export const getMyPosts = (status, page = 1, limit = 10) =>
+  fetchPaginatedList(axiosPrivateInstance, '/api/me/posts', { status, page, limit });
+
+export const getMyPost = async (id) => {
+  const response = await axiosPrivateInstance.get(`/api/me/posts/${id}`);
+  return response.data.data.post;
+};
+
+// Returns the post as a Markdown ('md') or HTML ('html') document. mine
+// exports through the author endpoint, which also covers drafts.
+export const exportPost = async (id, format = 'md', { mine = false } = {}) => {
+  const instance = mine ? axiosPrivateInstance : axiosPublicInstance;
+  const url = mine ? `/api/me/posts/${id}/export` : `/posts/${id}/export`;
+  const response = await instance.get(url, { params: { format }, responseType: 'text' });
+  return response.data;
+};
+
+// Editor working copy. version is the autosave version last seen (0 for the
+// first save); a 409 carries the newer copy in error.response.data.details.
+export const getAutosave = async (postId) => {
+  const response = await axiosPrivateInstance.get(`/api/posts/${postId}/autosave`);
+  return response.data.data;
+};
+
+export const saveAutosave = async (postId, { title, excerpt, content }, version) => {
+  const response = await axiosPrivateInstance.put(`/api/posts/${postId}/autosave`, {
+    title, excerpt, content, version,
+  });
+  return response.data.data;
+};
+
+export const discardAutosave = async (postId) => {
+  const response = await axiosPrivateInstance.delete(`/api/posts/${postId}/autosave`);
+  return response.data;
+};
+
+// Review workflow. Posts by authors start as drafts and need an editor's
+// approval before they can be published.
+export const submitPostForReview = async (postId, comment) => {
+  const response = await axiosPrivateInstance.post(`/api/posts/${postId}/submit`, comment ? { comment } : undefined);
+  return response.data.data;
+};
+
+export const reviewPost = async (postId, decision, comment) => {
+  const response = await axiosPrivateInstance.post(`/api/posts/${postId}/review`, { decision, comment });
+  return response.data.data;
+};
+
+export const getReviewQueue = (page = 1, limit = 10) =>
+  fetchPaginatedList(axiosPrivateInstance, '/api/reviews', { page, limit });
+
+export const getPostHistory = async (postId) => {
+  const response = await axiosPrivateInstance.get(`/api/posts/${postId}/history`);
+  return response.data.data;
+};
+
+// Co-authors and reviewers. Invitations are answered by the invited user,
+// keyed by post ID.
+export const getPostAuthors = async (postId) => {
+  const response = await axiosPrivateInstance.get(`/api/posts/${postId}/authors`);
+  return response.data.data;
+};
+
+export const invitePostAuthor = async (postId, username, role = 'co_author') => {
+  const response = await axiosPrivateInstance.post(`/api/posts/${postId}/authors`, { username, role });
+  return response.data.data;
+};
+
+export const removePostAuthor = async (postId, userId) => {
+  const response = await axiosPrivateInstance.delete(`/api/posts/${postId}/authors/${userId}`);
+  return response.data;
+};
+
+export const getMyInvitations = async () => {
+  const response = await axiosPrivateInstance.get('/api/me/invitations');
+  return response.data.data;
+};
+
+export const respondToInvitation = async (postId, accept) => {
+  const action = accept ? 'accept' : 'decline';
+  const response = await axiosPrivateInstance.post(`/api/me/invitations/${postId}/${action}`);
+  return response.data;
+};
+
 export const getArchiveSummary = async () => {
   const response = await axiosPublicInstance.get('/archive/summary');
   return response.data.data || [];
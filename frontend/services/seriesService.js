@@ -0,0 +1,36 @@
+// services/seriesService.js
+import axiosPublicInstance from '../utils/axiosPublicInstance';
+import axiosPrivateInstance from '../utils/axiosPrivateInstance';
+
+// Public series page: the series with its published parts in order.
+export const getSeriesBySlug = async (slug) => {
+  const response = await axiosPublicInstance.get(`/series/${slug}`);
+  return response.data.data;
+};
+
+// Own series with every part, drafts included.
+export const getMySeries = async () => {
+  const response = await axiosPrivateInstance.get('/api/me/series');
+  return response.data.data;
+};
+
+// postIds lists the parts in reading order.
+export const createSeries = async ({ title, description, postIds }) => {
+  const response = await axiosPrivateInstance.post('/api/series', {
+    title, description, post_ids: postIds,
+  });
+  return response.data.data;
+};
+
+// Omitted fields stay unchanged; postIds replaces the parts and their order.
+export const updateSeries = async (id, { title, description, postIds }) => {
+  const response = await axiosPrivateInstance.put(`/api/series/${id}`, {
+    title, description, post_ids: postIds,
+  });
+  return response.data.data;
+};
+
+export const deleteSeries = async (id) => {
+  const response = await axiosPrivateInstance.delete(`/api/series/${id}`);
+  return response.data;
+};
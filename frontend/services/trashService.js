@@ -0,0 +1,25 @@
+// services/trashService.js
+import axiosPrivateInstance from '../utils/axiosPrivateInstance';
+
+// type is 'posts', 'comments' or 'replies'. all lists everyone's deleted
+// items and needs editor or moderator rights.
+export const getTrash = async (type = 'posts', { page = 1, limit = 20, all = false } = {}) => {
+  const response = await axiosPrivateInstance.get('/api/trash', {
+    params: { type, page, limit, all },
+  });
+  return {
+    items: response.data.data,
+    totalCount: response.data.total_count,
+  };
+};
+
+export const restoreTrashItem = async (type, id) => {
+  const response = await axiosPrivateInstance.post(`/api/trash/${type}/${id}/restore`);
+  return response.data;
+};
+
+// Deletes the item for good instead of waiting for the retention period.
+export const purgeTrashItem = async (type, id) => {
+  const response = await axiosPrivateInstance.delete(`/api/trash/${type}/${id}`);
+  return response.data;
+};
@@ -20,6 +20,55 @@ axiosPrivateInstance.interceptors.request.use(
   (error) => Promise.reject(error)
 );
 
+// A single in-flight refresh is shared by every request that hit a 401,
+// since refresh tokens are single-use.
+let refreshPromise = null;
+
+const refreshAccessToken = async () => {
+  const refreshToken = localStorage.getItem('refresh_token');
+  if (!refreshToken) {
+    throw new Error('No refresh token');
+  }
+  const response = await axios.post(
+    `${BASE_API_URL || 'http://localhost:81'}/auth/refresh`,
+    { refresh_token: refreshToken }
+  );
+  localStorage.setItem('token', response.data.token);
+  localStorage.setItem('refresh_token', response.data.refresh_token);
+  return response.data.token;
+};
+
+axiosPrivateInstance.interceptors.response.use(
+  (response) => response,
+  async (error) => {
+    const original = error.config;
+    if (
+      typeof window === 'undefined' ||
+      error?.response?.status !== 401 ||
+      !original ||
+      original._retry
+    ) {
+      return Promise.reject(error);
+    }
+
+    original._retry = true;
+    try {
+      if (!refreshPromise) {
+        refreshPromise = refreshAccessToken().finally(() => {
+          refreshPromise = null;
+        });
+      }
+      const token = await refreshPromise;
+      original.headers.Authorization = `Bearer ${token}`;
+      return axiosPrivateInstance(original);
+    } catch (refreshError) {
+      localStorage.removeItem('token');
+      localStorage.removeItem('refresh_token');
+      return Promise.reject(error);
+    }
+  }
+);
+
 export default axiosPrivateInstance;
 
 // Removed axiosPrivateInstanceSimple - unified with axiosPrivateInstance
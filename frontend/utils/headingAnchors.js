@@ -0,0 +1,44 @@
+// utils/headingAnchors.js — IDs on rendered headings, for the table of contents
+import { Extension } from '@tiptap/core';
+
+// HeadingAnchors renders a heading's id attribute. It belongs to the renderers
+// only: ids are not part of stored content, and the API rejects them there.
+export const HeadingAnchors = Extension.create({
+  name: 'headingAnchors',
+
+  addGlobalAttributes() {
+    return [
+      {
+        types: ['heading'],
+        attributes: {
+          id: {
+            default: null,
+            parseHTML: () => null,
+            renderHTML: (attributes) => (attributes.id ? { id: attributes.id } : {}),
+          },
+        },
+      },
+    ];
+  },
+});
+
+const hasText = (node) => {
+  if (node.type === 'text') return node.text.trim() !== '';
+  return (node.content || []).some(hasText);
+};
+
+// withHeadingIds returns a copy of doc whose headings carry the ids of toc,
+// the table of contents the API sends along with a post. Both list headings
+// with text in document order, so the API alone decides what ids look like.
+export function withHeadingIds(doc, toc) {
+  if (!Array.isArray(toc) || toc.length === 0) return doc;
+  let next = 0;
+  const visit = (node) => {
+    if (node.type === 'heading' && next < toc.length && hasText(node)) {
+      return { ...node, attrs: { ...node.attrs, id: toc[next++].id } };
+    }
+    if (!node.content) return node;
+    return { ...node, content: node.content.map(visit) };
+  };
+  return visit(doc);
+}
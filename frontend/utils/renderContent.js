@@ -1,19 +1,21 @@
 // Renders post content (JSON document tree) to HTML
 import { generateHTML } from '@tiptap/html';
 import { getExtensions } from './tiptapExtensions';
+import { HeadingAnchors, withHeadingIds } from './headingAnchors';
 
 /**
  * Converts post content (TipTap JSON document tree) to an HTML string.
  *
  * @param {object|string|null} content - The content field from the API response
+ * @param {Array|null} [toc] - The post's table of contents; headings get its ids
  * @returns {string} HTML string ready for rendering
  */
-export function renderPostContent(content) {
+export function renderPostContent(content, toc) {
   if (!content) return '';
 
   try {
     const doc = typeof content === 'string' ? JSON.parse(content) : content;
-    return generateHTML(doc, getExtensions());
+    return generateHTML(withHeadingIds(doc, toc), [...getExtensions(), HeadingAnchors]);
   } catch (e) {
     console.error('Failed to render content:', e);
     return '';
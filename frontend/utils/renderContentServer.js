@@ -1,7 +1,9 @@
 // Server-safe content renderer — no client-only extensions (no ReactNodeViewRenderer)
 import { generateHTML } from '@tiptap/html';
 import { Node, mergeAttributes } from '@tiptap/core';
-import { getBaseExtensions } from './tiptapBaseExtensions';
+import { getBaseExtensions, CodeBlock } from './tiptapBaseExtensions';
+import Embed from '../components/Editor/extensions/Embed';
+import { HeadingAnchors, withHeadingIds } from './headingAnchors';
 
 // Server-safe ImageBlock — same schema and renderHTML as the client version but no addNodeView
 const ImageBlockServer = Node.create({
@@ -52,11 +54,57 @@ const ImageBlockServer = Node.create({
   },
 });
 
-export function renderPostContent(content) {
+// Server-side code block — renders the highlighted tokens the API sends with
+// a post as hljs spans, so pages get colored code without a highlighter.
+const CodeBlockServer = CodeBlock.extend({
+  addAttributes() {
+    return {
+      ...this.parent?.(),
+      tokens: { default: null, rendered: false },
+    };
+  },
+
+  renderHTML({ node, HTMLAttributes }) {
+    const { tokens, language } = node.attrs;
+    if (!Array.isArray(tokens)) {
+      return this.parent?.({ node, HTMLAttributes });
+    }
+    const spans = tokens.map(({ kind, text }) => (kind ? ['span', { class: `hljs-${kind}` }, text] : text));
+    return [
+      'pre',
+      mergeAttributes(this.options.HTMLAttributes, HTMLAttributes),
+      ['code', { class: language ? this.options.languageClassPrefix + language : null }, ...spans],
+    ];
+  },
+});
+
+// withCodeTokens returns a copy of doc whose code blocks carry the tokens of
+// codeTokens, the post's code_tokens: one list per code block, in document
+// order, null for those left plain. They exist only while rendering.
+function withCodeTokens(doc, codeTokens) {
+  if (!Array.isArray(codeTokens) || codeTokens.length === 0) return doc;
+  let next = 0;
+  const visit = (node) => {
+    if (node.type === 'codeBlock') {
+      const tokens = codeTokens[next++];
+      return Array.isArray(tokens) ? { ...node, attrs: { ...node.attrs, tokens } } : node;
+    }
+    if (!node.content) return node;
+    return { ...node, content: node.content.map(visit) };
+  };
+  return visit(doc);
+}
+
+// toc is the post's table of contents; headings get its ids as anchors.
+// codeTokens is its highlighted code.
+export function renderPostContent(content, toc, codeTokens) {
   if (!content) return '';
   try {
     const doc = typeof content === 'string' ? JSON.parse(content) : content;
-    return generateHTML(doc, getBaseExtensions(ImageBlockServer));
+    return generateHTML(withCodeTokens(withHeadingIds(doc, toc), codeTokens), [
+      ...getBaseExtensions(ImageBlockServer, Embed, CodeBlockServer),
+      HeadingAnchors,
+    ]);
   } catch (e) {
     console.error('Failed to render content:', e);
     return '';
@@ -0,0 +1,29 @@
+// Typesets LaTeX with KaTeX, which is loaded only once there is math to show.
+let katexLoader = null
+
+const loadKatex = () => {
+  if (!katexLoader) {
+    katexLoader = Promise.all([import('katex'), import('katex/dist/katex.min.css')])
+      .then(([{ default: katex }]) => katex)
+  }
+  return katexLoader
+}
+
+/**
+ * Renders latex into el, leaving the source in place if it does not parse.
+ */
+export async function renderMath(el, latex, displayMode = false) {
+  const katex = await loadKatex()
+  katex.render(latex || '', el, { displayMode, throwOnError: false })
+}
+
+/**
+ * Typesets the math nodes rendered into root, as on the post page.
+ */
+export function typesetMath(root) {
+  const nodes = root?.querySelectorAll('[data-type="inline-math"], [data-type="block-math"]')
+  if (!nodes?.length) return
+  nodes.forEach((el) => {
+    renderMath(el, el.getAttribute('data-latex'), el.getAttribute('data-type') === 'block-math')
+  })
+}
@@ -20,21 +20,35 @@ import TaskList from '@tiptap/extension-task-list'
 import TaskItem from '@tiptap/extension-task-item'
 import Typography from '@tiptap/extension-typography'
 import { common, createLowlight } from 'lowlight'
+import Embed from '../components/Editor/extensions/Embed'
+import Callout from '../components/Editor/extensions/Callout'
+import { InlineMath, BlockMath } from '../components/Editor/extensions/Mathematics'
+import { FootnotesDocument, FootnoteReference, Footnotes, Footnote } from '../components/Editor/extensions/Footnotes'
 
 const lowlight = createLowlight(common)
 
+export const CodeBlock = CodeBlockLowlight.configure({ lowlight, defaultLanguage: 'plaintext' })
+
 /**
  * Returns all TipTap extensions with a swappable image-block extension.
  * @param {import('@tiptap/core').Extension} imageExtension
  *   Pass ImageBlock (client) from tiptapExtensions.js,
  *   or ImageBlockServer (server) from renderContentServer.js.
+ * @param {import('@tiptap/core').Extension} [embedExtension]
+ *   Embed configured with an unfurl function for the editor; the plain node
+ *   is enough for rendering.
+ * @param {import('@tiptap/core').Extension} [codeBlockExtension]
+ *   CodeBlockServer from renderContentServer.js renders the tokens the API
+ *   highlights code with; the editor highlights as it goes.
  */
-export const getBaseExtensions = (imageExtension) => [
+export const getBaseExtensions = (imageExtension, embedExtension = Embed, codeBlockExtension = CodeBlock) => [
   StarterKit.configure({
+    document: false,
     heading: { levels: [1, 2, 3, 4, 5] },
     codeBlock: false,
   }),
-  CodeBlockLowlight.configure({ lowlight, defaultLanguage: 'plaintext' }),
+  FootnotesDocument,
+  codeBlockExtension,
   Image,
   imageExtension,
   Link.configure({
@@ -49,6 +63,13 @@ export const getBaseExtensions = (imageExtension) => [
   TableHeader,
   TableCell,
   Youtube.configure({ inline: false, HTMLAttributes: { class: 'youtube-embed' } }),
+  embedExtension,
+  Callout,
+  InlineMath,
+  BlockMath,
+  FootnoteReference,
+  Footnotes,
+  Footnote,
   Highlight.configure({ multicolor: true }),
   Color,
   Subscript,
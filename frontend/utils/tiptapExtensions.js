@@ -1,8 +1,10 @@
 // utils/tiptapExtensions.js — Client-side extensions (includes ImageBlock with ReactNodeViewRenderer)
 // Do NOT import this in Server Components — use tiptapBaseExtensions.js instead.
 import ImageBlock from '../components/Editor/extensions/ImageBlock'
+import Embed from '../components/Editor/extensions/Embed'
+import { unfurlLink } from '../services/embedService'
 import { getBaseExtensions } from './tiptapBaseExtensions'
 
 export { getBaseExtensions }
 
-export const getExtensions = () => getBaseExtensions(ImageBlock)
+export const getExtensions = () => getBaseExtensions(ImageBlock, Embed.configure({ unfurl: unfurlLink }))